@@ -1,15 +1,31 @@
 package main
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/breach"
 	"github.com/enson89/user-service-go/internal/cache"
 	"github.com/enson89/user-service-go/internal/config"
 	"github.com/enson89/user-service-go/internal/db"
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/notify"
+	"github.com/enson89/user-service-go/internal/passhash"
+	"github.com/enson89/user-service-go/internal/ratelimit"
 	"github.com/enson89/user-service-go/internal/repository"
 	"github.com/enson89/user-service-go/internal/service"
 	"github.com/enson89/user-service-go/internal/transport/http"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
 
 	_ "github.com/enson89/user-service-go/docs"
 )
@@ -34,6 +50,14 @@ func main() {
 		log.Fatalf("db error: %v", err)
 	}
 	repo := repository.NewUserRepository(pgConn)
+	refreshRepo := repository.NewRefreshTokenRepository(pgConn)
+	resetRepo := repository.NewPasswordResetRepository(pgConn)
+	verifyRepo := repository.NewEmailVerificationRepository(pgConn)
+	mfaRepo := repository.NewMFARepository(pgConn)
+	webAuthnRepo := repository.NewWebAuthnCredentialRepository(pgConn)
+	historyRepo := repository.NewPasswordHistoryRepository(pgConn)
+	emailChangeRepo := repository.NewEmailChangeRepository(pgConn)
+	roleRepo := repository.NewRoleRepository(pgConn)
 
 	// 3. Initialize Redis client
 	rdb := redis.NewClient(&redis.Options{
@@ -41,15 +65,340 @@ func main() {
 		Password: cfg.Redis.Password,
 		DB:       cfg.Redis.DB,
 	})
-	store := cache.NewSessionStore(rdb, cfg.JWT.ExpireHours)
+	store := cache.NewSessionStore(rdb)
+	if _, err := store.HonorLegacyBlacklist(context.Background(), cfg.JWT.ExpireHours); err != nil {
+		log.Fatalf("redis error: %v", err)
+	}
+	if cfg.Redis.MigrateLegacyBlacklist {
+		// not fatal: entries left behind are still honored until they expire
+		if moved, err := store.MigrateLegacyBlacklist(context.Background()); err != nil {
+			log.Printf("blacklist migration error: %v", err)
+		} else if moved > 0 {
+			log.Printf("migrated %d blacklisted tokens to fingerprint keys", moved)
+		}
+	}
+
+	// 4. Load the token signing keyring
+	keys, err := loadKeyring(cfg.JWT, cache.NewKeyringStore(rdb))
+	if err != nil {
+		log.Fatalf("jwt key error: %v", err)
+	}
 
-	// 4. Create the service layer
-	svc := service.NewUserService(repo, store, []byte(cfg.JWT.Secret), cfg.JWT.ExpireHours)
+	tokenOpts := auth.TokenOptions{
+		Issuer:     cfg.JWT.Issuer,
+		Audience:   cfg.JWT.Audience,
+		Algorithms: cfg.JWT.AllowedAlgorithms,
+		Leeway:     cfg.JWT.ClockSkewSeconds,
+	}
+
+	// 5. Create the service layer
+	notifier, err := newNotifier(cfg.Notify)
+	if err != nil {
+		log.Fatalf("notify error: %v", err)
+	}
+	opts := []service.Option{
+		service.WithRefreshTokens(refreshRepo, cfg.JWT.RefreshExpireHours),
+		service.WithTokenOptions(tokenOpts),
+		service.WithPasswordReset(resetRepo, notifier, cfg.PasswordReset.ExpireMinutes),
+	}
+	hasher, err := newPasswordHasher(cfg.PasswordHash)
+	if err != nil {
+		log.Fatalf("config error: passwordHash: %v", err)
+	}
+	opts = append(opts, service.WithPasswordHasher(hasher))
+	breached, err := loadBreachedList(cfg.PasswordPolicy.BreachedListFile)
+	if err != nil {
+		log.Fatalf("config error: passwordPolicy.breachedListFile: %v", err)
+	}
+	opts = append(opts, service.WithPasswordPolicy(service.PasswordPolicy{
+		MinLength:          cfg.PasswordPolicy.MinLength,
+		MaxBytes:           cfg.PasswordPolicy.MaxBytes,
+		RequireUppercase:   cfg.PasswordPolicy.RequireUppercase,
+		RequireLowercase:   cfg.PasswordPolicy.RequireLowercase,
+		RequireDigit:       cfg.PasswordPolicy.RequireDigit,
+		RequireSymbol:      cfg.PasswordPolicy.RequireSymbol,
+		RejectEmailSimilar: cfg.PasswordPolicy.RejectEmailSimilar,
+	}, breached))
+	opts = append(opts, service.WithPasswordHistory(historyRepo, cfg.PasswordPolicy.HistoryDepth))
+	opts = append(opts, service.WithRBAC(roleRepo))
+	if cfg.EmailVerification.Enabled {
+		policy := service.UnverifiedLoginPolicy(cfg.EmailVerification.UnverifiedLogin)
+		switch policy {
+		case service.UnverifiedLoginAllow, service.UnverifiedLoginDeny, service.UnverifiedLoginRestrict:
+		default:
+			log.Fatalf("config error: unknown emailVerification.unverifiedLogin %q", policy)
+		}
+		opts = append(opts, service.WithEmailVerification(verifyRepo, notifier, cfg.EmailVerification.ExpireHours, policy))
+	}
+	if cfg.EmailChange.Enabled {
+		opts = append(opts, service.WithEmailChange(emailChangeRepo, notifier, cfg.EmailChange.ExpireHours))
+	}
+	if cfg.Sessions.Enabled {
+		opts = append(opts, service.WithSessionRegistry(store))
+	}
+	if cfg.Organizations.Enabled {
+		opts = append(opts, service.WithOrganizations(repository.NewOrganizationRepository(pgConn)))
+		if cfg.Invitations.Enabled {
+			opts = append(opts, service.WithInvitations(repository.NewInvitationRepository(pgConn), notifier,
+				cfg.Invitations.ExpireHours))
+		}
+	}
+	if cfg.Groups.Enabled {
+		opts = append(opts, service.WithGroups(repository.NewGroupRepository(pgConn)))
+	}
+	if cfg.Lockout.Enabled {
+		policy, err := lockoutPolicy(cfg.Lockout)
+		if err != nil {
+			log.Fatalf("config error: lockout: %v", err)
+		}
+		opts = append(opts, service.WithLockout(cache.NewLoginAttemptStore(rdb), policy))
+	}
+	if cfg.MagicLink.Enabled {
+		opts = append(opts, service.WithMagicLink(cache.NewMagicLinkStore(rdb), notifier, cfg.MagicLink.ExpireMinutes))
+	}
+	if cfg.MFA.Enabled {
+		cipher, err := newMFACipher(cfg.MFA.EncryptionKey)
+		if err != nil {
+			log.Fatalf("config error: mfa.encryptionKey: %v", err)
+		}
+		opts = append(opts, service.WithMFA(mfaRepo, cache.NewMFAChallengeStore(rdb), cipher, cfg.MFA.Issuer,
+			cfg.MFA.ChallengeExpireMinutes))
+	}
+	if cfg.WebAuthn.Enabled {
+		wa, err := webauthn.New(&webauthn.Config{
+			RPID:          cfg.WebAuthn.RPID,
+			RPDisplayName: cfg.WebAuthn.RPDisplayName,
+			RPOrigins:     cfg.WebAuthn.RPOrigins,
+		})
+		if err != nil {
+			log.Fatalf("config error: webauthn: %v", err)
+		}
+		opts = append(opts, service.WithWebAuthn(webAuthnRepo, cache.NewWebAuthnSessionStore(rdb), wa,
+			cfg.WebAuthn.TimeoutMinutes))
+	}
+	var routerOpts []http.RouterOption
+	// access tokens carry roles and permissions, which only token versions
+	// revoke when they change
+	versions := cache.NewTokenVersionCache(rdb, repo, cfg.JWT.TokenVersionCacheMinutes)
+	opts = append(opts, service.WithTokenVersions(versions))
+	routerOpts = append(routerOpts, http.WithTokenVersions(versions))
+	svc := service.NewUserService(repo, store, keys, cfg.JWT.ExpireHours, opts...)
 
-	// 5. Wire up HTTP transport and start server
-	router := http.NewRouter(svc, []byte(cfg.JWT.Secret), store)
+	// 6. Wire up HTTP transport and start server
+	if cfg.RateLimit.Enabled {
+		policies, err := rateLimitPolicies(cfg.RateLimit.Policies)
+		if err != nil {
+			log.Fatalf("config error: rateLimit: %v", err)
+		}
+		routerOpts = append(routerOpts, http.WithRateLimit(ratelimit.NewRedisLimiter(rdb), policies))
+	}
+	routerOpts = append(routerOpts, http.WithTrustedProxies(cfg.App.TrustedProxies))
+	router, err := http.NewRouter(svc, keys, tokenOpts, store, routerOpts...)
+	if err != nil {
+		log.Fatalf("config error: app.trustedProxies: %v", err)
+	}
 	log.Printf("starting server on :%s (env=%s)", cfg.App.Port, cfg.App.Env)
 	if err = router.Run(":" + cfg.App.Port); err != nil {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// loadKeyring builds the JWT signing keyring described by cfg. Without
+// configured keys it holds the single legacy key.
+func loadKeyring(cfg config.JWTConfig, store auth.KeyringStore) (*auth.Keyring, error) {
+	if len(cfg.Keys) == 0 {
+		key, err := loadSigningKey(cfg.KeyID, cfg.Algorithm, cfg.PrivateKeyFile, cfg.Secret, cfg.AllowedAlgorithms)
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewKeyring(store, auth.KeyEntry{Key: key})
+	}
+	entries := make([]auth.KeyEntry, 0, len(cfg.Keys))
+	for _, kc := range cfg.Keys {
+		secret, err := keySecret(kc)
+		if err != nil {
+			return nil, err
+		}
+		key, err := loadSigningKey(kc.ID, kc.Algorithm, kc.PrivateKeyFile, secret, cfg.AllowedAlgorithms)
+		if err != nil {
+			return nil, err
+		}
+		entry := auth.KeyEntry{Key: key}
+		if entry.ActivateAt, err = parseTime(kc.ActivateAt); err != nil {
+			return nil, fmt.Errorf("key %q activateAt: %w", kc.ID, err)
+		}
+		if entry.RetireAt, err = parseTime(kc.RetireAt); err != nil {
+			return nil, fmt.Errorf("key %q retireAt: %w", kc.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+	return auth.NewKeyring(store, entries...)
+}
+
+// keySecret returns the HMAC secret of a keyring entry, read from its
+// SecretFile if it has one. Every HS256 key needs its own secret, or
+// rotating keys would not rotate the secret.
+func keySecret(kc config.JWTKeyConfig) (string, error) {
+	if kc.Algorithm != "HS256" {
+		return "", nil
+	}
+	secret := kc.Secret
+	if kc.SecretFile != "" {
+		b, err := os.ReadFile(kc.SecretFile)
+		if err != nil {
+			return "", fmt.Errorf("key %q secretFile: %w", kc.ID, err)
+		}
+		secret = strings.TrimSpace(string(b))
+	}
+	if secret == "" {
+		return "", fmt.Errorf("key %q: HS256 keys need a secret or secretFile", kc.ID)
+	}
+	return secret, nil
+}
+
+// loadSigningKey builds one signing key; HS256 keys are signed with secret.
+// A key whose algorithm is not allowed could never verify a token, so it is
+// a configuration error.
+func loadSigningKey(id, algorithm, privateKeyFile, secret string, allowed []string) (*auth.SigningKey, error) {
+	if len(allowed) > 0 && !slices.Contains(allowed, algorithm) {
+		return nil, fmt.Errorf("key %q: algorithm %s is not in allowedAlgorithms", id, algorithm)
+	}
+	if algorithm == "HS256" {
+		return auth.NewHMACKey(id, []byte(secret)), nil
+	}
+	return auth.LoadSigningKey(id, algorithm, privateKeyFile)
+}
+
+// newNotifier builds the notifier selected by cfg.Driver.
+func newNotifier(cfg config.NotifyConfig) (notify.Notifier, error) {
+	var (
+		templates *notify.Templates
+		err       error
+	)
+	if cfg.TemplateDir != "" {
+		templates, err = notify.LoadTemplates(os.DirFS(cfg.TemplateDir), cfg.DefaultLocale, cfg.LinkBaseURL)
+	} else {
+		templates, err = notify.DefaultTemplates(cfg.DefaultLocale, cfg.LinkBaseURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Driver {
+	case "stdout":
+		return notify.NewFileNotifier(os.Stdout, templates), nil
+	case "file":
+		f, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		return notify.NewFileNotifier(f, templates), nil
+	case "smtp":
+		return notify.NewSMTPNotifier(notify.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.SMTP.From,
+		}, templates), nil
+	default:
+		return nil, fmt.Errorf("unknown notify driver %q", cfg.Driver)
+	}
+}
+
+// rateLimitPolicies converts the configured rate limit policies.
+func rateLimitPolicies(cfgs []config.RateLimitPolicyConfig) ([]http.RateLimitPolicy, error) {
+	policies := make([]http.RateLimitPolicy, 0, len(cfgs))
+	for _, pc := range cfgs {
+		switch pc.Identity {
+		case http.IdentityIP, http.IdentityUser, http.IdentityAPIKey:
+		default:
+			return nil, fmt.Errorf("policy %q: unknown identity %q", pc.Name, pc.Identity)
+		}
+		if pc.Name == "" || len(pc.Routes) == 0 || pc.Requests <= 0 || pc.PeriodSeconds <= 0 {
+			return nil, fmt.Errorf("policy %q: name, routes, requests and periodSeconds are required", pc.Name)
+		}
+		policies = append(policies, http.RateLimitPolicy{
+			Name:     pc.Name,
+			Routes:   pc.Routes,
+			Identity: pc.Identity,
+			Limit: ratelimit.Limit{
+				Requests: pc.Requests,
+				Period:   time.Duration(pc.PeriodSeconds) * time.Second,
+				Burst:    pc.Burst,
+			},
+		})
+	}
+	return policies, nil
+}
+
+// lockoutPolicy validates the lockout settings. A threshold or duration of
+// zero would lock everyone out, or no one, at the first failed login.
+func lockoutPolicy(cfg config.LockoutConfig) (service.LockoutPolicy, error) {
+	if cfg.AccountThreshold <= 0 || cfg.IPThreshold <= 0 {
+		return service.LockoutPolicy{}, errors.New("accountThreshold and ipThreshold must be positive")
+	}
+	if cfg.WindowMinutes <= 0 || cfg.BaseMinutes <= 0 || cfg.StrikeResetHours <= 0 {
+		return service.LockoutPolicy{}, errors.New("windowMinutes, baseMinutes and strikeResetHours must be positive")
+	}
+	if cfg.MaxMinutes < cfg.BaseMinutes {
+		return service.LockoutPolicy{}, errors.New("maxMinutes must be at least baseMinutes")
+	}
+	return service.LockoutPolicy{
+		AccountThreshold: int64(cfg.AccountThreshold),
+		IPThreshold:      int64(cfg.IPThreshold),
+		Window:           cfg.WindowMinutes,
+		BaseLockout:      cfg.BaseMinutes,
+		MaxLockout:       cfg.MaxMinutes,
+		StrikeReset:      cfg.StrikeResetHours,
+	}, nil
+}
+
+// newPasswordHasher builds the hasher for the configured algorithm.
+func newPasswordHasher(cfg config.PasswordHashConfig) (*passhash.Hasher, error) {
+	switch cfg.Algorithm {
+	case "bcrypt":
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcryptCost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return passhash.New(passhash.Bcrypt{Cost: cfg.BcryptCost}), nil
+	case "argon2id":
+		return passhash.New(passhash.Argon2id{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown algorithm %q", cfg.Algorithm)
+	}
+}
+
+// loadBreachedList loads the breached password list at path, if any. The
+// checker stays nil without one, as a nil *breach.List is not.
+func loadBreachedList(path string) (service.BreachedPasswordChecker, error) {
+	if path == "" {
+		return nil, nil
+	}
+	list, err := breach.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("loaded %d breached password hashes", list.Len())
+	return list, nil
+}
+
+// newMFACipher builds the cipher for TOTP secrets from a base64 encoded key.
+func newMFACipher(encodedKey string) (*mfa.Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, err
+	}
+	return mfa.NewCipher(key)
+}
+
+// parseTime parses an optional RFC 3339 timestamp.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
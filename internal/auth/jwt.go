@@ -1,19 +1,67 @@
 package auth
 
 import (
+	"errors"
 	"time"
 
 	"github.com/enson89/user-service-go/internal/model"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerateToken creates a signed JWT for the user.
-func GenerateToken(u *model.User, secret []byte, expire time.Duration) (string, error) {
-	claims := jwt.MapClaims{
-		"sub":  u.ID,
-		"role": u.Role,
-		"exp":  time.Now().Add(expire).Unix(),
+// GenerateToken creates a JWT for the user signed with key.
+func GenerateToken(u *model.User, key *SigningKey, opts TokenOptions, expire time.Duration) (string, error) {
+	return SignToken(NewClaims(u, opts, expire), key)
+}
+
+// SignToken signs claims with key.
+func SignToken(claims *Claims, key *SigningKey) (string, error) {
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.sign)
+}
+
+// ParseToken verifies tokStr against the active keys in keys and the rules in
+// opts and returns its claims. The token's kid header selects the key and its
+// algorithm must be the key's own.
+func ParseToken(tokStr string, keys *Keyring, opts TokenOptions) (*Claims, error) {
+	parserOpts := []jwt.ParserOption{
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(opts.Leeway),
+	}
+	if len(opts.Algorithms) > 0 {
+		parserOpts = append(parserOpts, jwt.WithValidMethods(opts.Algorithms))
+	}
+	if opts.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(opts.Issuer))
+	}
+	if opts.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(opts.Audience))
+	}
+	claims := &Claims{}
+	tok, err := jwt.ParseWithClaims(tokStr, claims, func(t *jwt.Token) (interface{}, error) {
+		var (
+			key *SigningKey
+			ok  bool
+		)
+		if kid, hasKid := t.Header["kid"].(string); hasKid {
+			key, ok = keys.Lookup(kid)
+		} else {
+			key, ok = keys.lookupAlg(t.Method.Alg())
+		}
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, errors.New("unexpected signing method")
+		}
+		return key.verify, nil
+	}, parserOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if !tok.Valid {
+		return nil, errors.New("invalid token")
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return claims, nil
 }
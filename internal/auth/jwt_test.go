@@ -14,10 +14,12 @@ import (
 func TestGenerateToken(t *testing.T) {
 	u := &model.User{ID: 123, Role: "user"}
 	secret := []byte("s3cr3t")
+	key := auth.NewHMACKey("test", secret)
 	expire := 5 * time.Minute
+	opts := auth.TokenOptions{Issuer: "user-service", Audience: "cms"}
 
 	// Generate token
-	tokStr, err := auth.GenerateToken(u, secret, expire)
+	tokStr, err := auth.GenerateToken(u, key, opts, expire)
 	require.NoError(t, err)
 	assert.NotEmpty(t, tokStr)
 
@@ -31,15 +33,97 @@ func TestGenerateToken(t *testing.T) {
 	})
 	require.NoError(t, err)
 	assert.True(t, token.Valid)
+	assert.Equal(t, "test", token.Header["kid"])
 
 	// Extract claims
 	claims, ok := token.Claims.(jwt.MapClaims)
 	assert.True(t, ok)
-	assert.Equal(t, float64(123), claims["sub"])
+	assert.Equal(t, "123", claims["sub"])
 	assert.Equal(t, "user", claims["role"])
+	assert.Equal(t, "user-service", claims["iss"])
+	assert.Equal(t, []interface{}{"cms"}, claims["aud"])
+	assert.NotEmpty(t, claims["jti"])
+	assert.NotNil(t, claims["nbf"])
 
 	expVal := int64(claims["exp"].(float64))
 	now := time.Now().Unix()
 	assert.Greater(t, expVal, now)
 	assert.LessOrEqual(t, expVal, now+int64(expire.Seconds())+1)
 }
+
+func TestParseToken(t *testing.T) {
+	u := &model.User{ID: 42, Role: "admin"}
+	key := auth.NewHMACKey("test", []byte("s3cr3t"))
+
+	tokStr, err := auth.GenerateToken(u, key, auth.TokenOptions{}, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := auth.ParseToken(tokStr, auth.NewStaticKeyring(key), auth.TokenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UserID())
+	assert.Equal(t, "admin", claims.Role)
+	assert.NotNil(t, claims.IssuedAt)
+
+	// Wrong secret
+	_, err = auth.ParseToken(tokStr, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("other"))), auth.TokenOptions{})
+	assert.Error(t, err)
+
+	// Unknown kid
+	_, err = auth.ParseToken(tokStr, auth.NewStaticKeyring(auth.NewHMACKey("other", []byte("s3cr3t"))), auth.TokenOptions{})
+	assert.Error(t, err)
+
+	// Expired
+	expired, err := auth.GenerateToken(u, key, auth.TokenOptions{}, -time.Minute)
+	require.NoError(t, err)
+	_, err = auth.ParseToken(expired, auth.NewStaticKeyring(key), auth.TokenOptions{})
+	assert.Error(t, err)
+
+	// Expired, but within the tolerated clock skew
+	_, err = auth.ParseToken(expired, auth.NewStaticKeyring(key), auth.TokenOptions{Leeway: 2 * time.Minute})
+	assert.NoError(t, err)
+}
+
+func TestParseToken_Rules(t *testing.T) {
+	key := auth.NewHMACKey("test", []byte("s3cr3t"))
+	keys := auth.NewStaticKeyring(key)
+	opts := auth.TokenOptions{Issuer: "user-service", Audience: "cms", Algorithms: []string{"HS256"}}
+	valid := func() *auth.Claims {
+		return auth.NewClaims(&model.User{ID: 1, Role: "user"}, opts, time.Minute)
+	}
+
+	tests := []struct {
+		name   string
+		claims func() *auth.Claims
+		opts   auth.TokenOptions
+		ok     bool
+	}{
+		{"valid", valid, opts, true},
+		{"wrong issuer", func() *auth.Claims { c := valid(); c.Issuer = "someone-else"; return c }, opts, false},
+		{"missing issuer", func() *auth.Claims { c := valid(); c.Issuer = ""; return c }, opts, false},
+		{"wrong audience", func() *auth.Claims { c := valid(); c.Audience = jwt.ClaimStrings{"billing"}; return c }, opts, false},
+		{"missing subject", func() *auth.Claims { c := valid(); c.Subject = ""; return c }, opts, false},
+		{"non-numeric subject", func() *auth.Claims { c := valid(); c.Subject = "alice"; return c }, opts, false},
+		{"missing jti", func() *auth.Claims { c := valid(); c.ID = ""; return c }, opts, false},
+		{"missing iat", func() *auth.Claims { c := valid(); c.IssuedAt = nil; return c }, opts, false},
+		{"missing role", func() *auth.Claims { c := valid(); c.Role = ""; return c }, opts, false},
+		{"not yet valid", func() *auth.Claims {
+			c := valid()
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute))
+			return c
+		}, opts, false},
+		{"algorithm not allowed", valid, auth.TokenOptions{Algorithms: []string{"RS256"}}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tok, err := auth.SignToken(tc.claims(), key)
+			require.NoError(t, err)
+
+			_, err = auth.ParseToken(tok, keys, tc.opts)
+			if tc.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoActiveKey  = errors.New("no active signing key")
+	ErrNoStandbyKey = errors.New("no standby signing key to rotate to")
+)
+
+// KeyringStore persists which key is the primary so that a rotation
+// triggered on one replica is picked up by all of them.
+type KeyringStore interface {
+	PrimaryKeyID(ctx context.Context) (string, error)
+	SetPrimaryKeyID(ctx context.Context, kid string) error
+}
+
+// KeyEntry is a key together with the window in which it may be used.
+// A zero ActivateAt or RetireAt leaves that side of the window open.
+type KeyEntry struct {
+	Key        *SigningKey
+	ActivateAt time.Time
+	RetireAt   time.Time
+}
+
+func (e KeyEntry) activeAt(now time.Time) bool {
+	return (e.ActivateAt.IsZero() || !now.Before(e.ActivateAt)) &&
+		(e.RetireAt.IsZero() || now.Before(e.RetireAt))
+}
+
+// Keyring holds every key tokens may be signed with. Tokens signed by any
+// active key verify, while new tokens are signed only with the primary: the
+// key last rotated to, or else the first active key in configuration order.
+type Keyring struct {
+	entries []KeyEntry
+	store   KeyringStore
+
+	mu        sync.RWMutex
+	primaryID string // used when there is no store
+}
+
+// NewKeyring returns a keyring over entries. store may be nil, in which case
+// rotations are only visible to this process.
+func NewKeyring(store KeyringStore, entries ...KeyEntry) (*Keyring, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("keyring needs at least one key")
+	}
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if seen[e.Key.ID] {
+			return nil, fmt.Errorf("duplicate signing key id %q", e.Key.ID)
+		}
+		seen[e.Key.ID] = true
+	}
+	return &Keyring{entries: entries, store: store}, nil
+}
+
+// NewStaticKeyring returns a keyring of always-active keys, the first being primary.
+func NewStaticKeyring(keys ...*SigningKey) *Keyring {
+	entries := make([]KeyEntry, len(keys))
+	for i, k := range keys {
+		entries[i] = KeyEntry{Key: k}
+	}
+	return &Keyring{entries: entries}
+}
+
+// Primary returns the key new tokens are signed with.
+func (k *Keyring) Primary(ctx context.Context) (*SigningKey, error) {
+	now := time.Now()
+	kid, err := k.primaryKeyID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if kid != "" {
+		if key, ok := k.Lookup(kid); ok {
+			return key, nil
+		}
+	}
+	// no rotation yet, or the rotated-to key has since retired
+	for _, e := range k.entries {
+		if e.activeAt(now) {
+			return e.Key, nil
+		}
+	}
+	return nil, ErrNoActiveKey
+}
+
+// Lookup returns the active key with the given ID.
+func (k *Keyring) Lookup(kid string) (*SigningKey, bool) {
+	now := time.Now()
+	for _, e := range k.entries {
+		if e.Key.ID == kid && e.activeAt(now) {
+			return e.Key, true
+		}
+	}
+	return nil, false
+}
+
+// lookupAlg returns the first active key using alg. It serves tokens minted
+// before keys carried a kid header.
+func (k *Keyring) lookupAlg(alg string) (*SigningKey, bool) {
+	now := time.Now()
+	for _, e := range k.entries {
+		if e.Key.Method.Alg() == alg && e.activeAt(now) {
+			return e.Key, true
+		}
+	}
+	return nil, false
+}
+
+// Rotate makes the next active key after the current primary, in
+// configuration order, the new primary and returns it. The previous primary
+// keeps verifying tokens until its RetireAt, so nobody is logged out.
+func (k *Keyring) Rotate(ctx context.Context) (*SigningKey, error) {
+	current, err := k.Primary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	passed := false
+	for _, e := range k.entries {
+		if e.Key.ID == current.ID {
+			passed = true
+			continue
+		}
+		if passed && e.activeAt(now) {
+			if err = k.setPrimaryKeyID(ctx, e.Key.ID); err != nil {
+				return nil, err
+			}
+			return e.Key, nil
+		}
+	}
+	return nil, ErrNoStandbyKey
+}
+
+// JWKSet returns the public keys that are active or not yet activated, so
+// verifiers learn about upcoming keys before tokens are signed with them.
+func (k *Keyring) JWKSet() JWKSet {
+	now := time.Now()
+	var keys []*SigningKey
+	for _, e := range k.entries {
+		if e.RetireAt.IsZero() || now.Before(e.RetireAt) {
+			keys = append(keys, e.Key)
+		}
+	}
+	return NewJWKSet(keys...)
+}
+
+func (k *Keyring) primaryKeyID(ctx context.Context) (string, error) {
+	if k.store != nil {
+		return k.store.PrimaryKeyID(ctx)
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primaryID, nil
+}
+
+func (k *Keyring) setPrimaryKeyID(ctx context.Context, kid string) error {
+	if k.store != nil {
+		return k.store.SetPrimaryKeyID(ctx, kid)
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.primaryID = kid
+	return nil
+}
@@ -0,0 +1,142 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authmocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+func TestKeyring_PrimaryRespectsWindows(t *testing.T) {
+	now := time.Now()
+	retired := auth.NewHMACKey("retired", []byte("a"))
+	current := auth.NewHMACKey("current", []byte("b"))
+	upcoming := auth.NewHMACKey("upcoming", []byte("c"))
+
+	kr, err := auth.NewKeyring(nil,
+		auth.KeyEntry{Key: retired, RetireAt: now.Add(-time.Minute)},
+		auth.KeyEntry{Key: current, ActivateAt: now.Add(-time.Hour)},
+		auth.KeyEntry{Key: upcoming, ActivateAt: now.Add(time.Hour)},
+	)
+	require.NoError(t, err)
+
+	primary, err := kr.Primary(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "current", primary.ID)
+
+	_, ok := kr.Lookup("retired")
+	assert.False(t, ok)
+	_, ok = kr.Lookup("upcoming")
+	assert.False(t, ok)
+
+}
+
+func TestKeyring_JWKSetPublishesUpcomingKeys(t *testing.T) {
+	now := time.Now()
+	newEC := func(id string) *auth.SigningKey {
+		k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		key, err := auth.ParseSigningKey(id, "ES256", pemEncode(t, k))
+		require.NoError(t, err)
+		return key
+	}
+
+	kr, err := auth.NewKeyring(nil,
+		auth.KeyEntry{Key: newEC("retired"), RetireAt: now.Add(-time.Minute)},
+		auth.KeyEntry{Key: newEC("current")},
+		auth.KeyEntry{Key: newEC("upcoming"), ActivateAt: now.Add(time.Hour)},
+	)
+	require.NoError(t, err)
+
+	var kids []string
+	for _, k := range kr.JWKSet().Keys {
+		kids = append(kids, k.Kid)
+	}
+	assert.Equal(t, []string{"current", "upcoming"}, kids)
+}
+
+func TestKeyring_RotateKeepsOldKeyVerifying(t *testing.T) {
+	oldKey := auth.NewHMACKey("old", []byte("a"))
+	newKey := auth.NewHMACKey("new", []byte("b"))
+	kr, err := auth.NewKeyring(nil, auth.KeyEntry{Key: oldKey}, auth.KeyEntry{Key: newKey})
+	require.NoError(t, err)
+
+	u := &model.User{ID: 1, Role: "user"}
+	primary, err := kr.Primary(t.Context())
+	require.NoError(t, err)
+	oldTok, err := auth.GenerateToken(u, primary, auth.TokenOptions{}, time.Minute)
+	require.NoError(t, err)
+
+	rotated, err := kr.Rotate(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "new", rotated.ID)
+
+	primary, err = kr.Primary(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "new", primary.ID)
+
+	// tokens signed before the rotation still verify
+	_, err = auth.ParseToken(oldTok, kr, auth.TokenOptions{})
+	assert.NoError(t, err)
+
+	// nothing left to rotate to
+	_, err = kr.Rotate(t.Context())
+	assert.ErrorIs(t, err, auth.ErrNoStandbyKey)
+}
+
+func TestKeyring_RotateUsesStore(t *testing.T) {
+	store := new(authmocks.MockKeyringStore)
+	kr, err := auth.NewKeyring(store,
+		auth.KeyEntry{Key: auth.NewHMACKey("k1", []byte("a"))},
+		auth.KeyEntry{Key: auth.NewHMACKey("k2", []byte("b"))},
+	)
+	require.NoError(t, err)
+
+	store.On("PrimaryKeyID", mock.Anything).Return("", nil).Once()
+	store.On("SetPrimaryKeyID", mock.Anything, "k2").Return(nil)
+
+	rotated, err := kr.Rotate(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "k2", rotated.ID)
+
+	// another replica sees the rotation through the store
+	store.On("PrimaryKeyID", mock.Anything).Return("k2", nil)
+	primary, err := kr.Primary(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "k2", primary.ID)
+
+	store.AssertExpectations(t)
+}
+
+func TestKeyring_RetiredKeyNoLongerVerifies(t *testing.T) {
+	key := auth.NewHMACKey("k1", []byte("a"))
+	tok, err := auth.GenerateToken(&model.User{ID: 1, Role: "user"}, key, auth.TokenOptions{}, time.Minute)
+	require.NoError(t, err)
+
+	kr, err := auth.NewKeyring(nil,
+		auth.KeyEntry{Key: key, RetireAt: time.Now().Add(-time.Second)},
+		auth.KeyEntry{Key: auth.NewHMACKey("k2", []byte("b"))},
+	)
+	require.NoError(t, err)
+
+	_, err = auth.ParseToken(tok, kr, auth.TokenOptions{})
+	assert.Error(t, err)
+}
+
+func TestNewKeyring_Invalid(t *testing.T) {
+	_, err := auth.NewKeyring(nil)
+	assert.Error(t, err)
+
+	k := auth.NewHMACKey("dup", []byte("a"))
+	_, err = auth.NewKeyring(nil, auth.KeyEntry{Key: k}, auth.KeyEntry{Key: k})
+	assert.Error(t, err)
+}
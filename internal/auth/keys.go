@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key tokens are signed and verified with. Its ID is
+// published as the JWT "kid" header.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	sign   interface{} // []byte for HMAC, crypto.Signer otherwise
+	verify interface{} // []byte for HMAC, crypto.PublicKey otherwise
+}
+
+// NewHMACKey returns a symmetric HS256 key. HMAC keys are never published in a JWKS.
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{ID: id, Method: jwt.SigningMethodHS256, sign: secret, verify: secret}
+}
+
+// LoadSigningKey reads a PEM encoded private key from path for the given algorithm.
+func LoadSigningKey(id, algorithm, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted config
+	if err != nil {
+		return nil, fmt.Errorf("read signing key %q: %w", id, err)
+	}
+	return ParseSigningKey(id, algorithm, data)
+}
+
+// ParseSigningKey parses a PEM encoded private key for one of the RS*, PS*,
+// ES* or EdDSA algorithms and checks that the key fits the algorithm.
+func ParseSigningKey(id, algorithm string, pemData []byte) (*SigningKey, error) {
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil {
+		return nil, fmt.Errorf("signing key %q: unknown algorithm %q", id, algorithm)
+	}
+	var (
+		priv crypto.Signer
+		err  error
+	)
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		priv, err = jwt.ParseRSAPrivateKeyFromPEM(pemData)
+	case *jwt.SigningMethodECDSA:
+		var ec *ecdsa.PrivateKey
+		if ec, err = jwt.ParseECPrivateKeyFromPEM(pemData); err == nil && ec.Curve.Params().BitSize != m.CurveBits {
+			err = fmt.Errorf("curve %s does not match %s", ec.Curve.Params().Name, algorithm)
+		}
+		priv = ec
+	case *jwt.SigningMethodEd25519:
+		var pk crypto.PrivateKey
+		if pk, err = jwt.ParseEdPrivateKeyFromPEM(pemData); err == nil {
+			priv, _ = pk.(crypto.Signer)
+		}
+	default:
+		return nil, fmt.Errorf("signing key %q: %s is not an asymmetric algorithm", id, algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %q: %w", id, err)
+	}
+	return &SigningKey{ID: id, Method: method, sign: priv, verify: priv.Public()}, nil
+}
+
+// JWK is the public part of a SigningKey as a JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set document.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWKSet returns the public JWKs of keys, skipping symmetric ones.
+func NewJWKSet(keys ...*SigningKey) JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range keys {
+		if jwk, ok := k.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JWK returns the public key as a JWK. It reports false for HMAC keys.
+func (k *SigningKey) JWK() (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwk := JWK{Kid: k.ID, Alg: k.Method.Alg(), Use: "sig"}
+	switch pub := k.verify.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = b64(pub.N.Bytes())
+		jwk.E = b64(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8 //nolint:mnd
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = b64(pad(pub.X.Bytes(), size))
+		jwk.Y = b64(pad(pub.Y.Bytes(), size))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = b64(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// pad left-pads b with zeros to size bytes, as JWK EC coordinates must be fixed length.
+func pad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
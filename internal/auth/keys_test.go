@@ -0,0 +1,137 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+func pemEncode(t *testing.T, key interface{}) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestParseSigningKey_RoundTrip(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	cases := []struct {
+		alg string
+		key interface{}
+		kty string
+	}{
+		{"RS256", rsaKey, "RSA"},
+		{"PS256", rsaKey, "RSA"},
+		{"ES256", ecKey, "EC"},
+		{"EdDSA", edKey, "OKP"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.alg, func(t *testing.T) {
+			key, err := auth.ParseSigningKey("k1", tc.alg, pemEncode(t, tc.key))
+			require.NoError(t, err)
+
+			tok, err := auth.GenerateToken(&model.User{ID: 1, Role: "user"}, key, auth.TokenOptions{}, time.Minute)
+			require.NoError(t, err)
+
+			claims, err := auth.ParseToken(tok, auth.NewStaticKeyring(key), auth.TokenOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), claims.UserID())
+
+			jwk, ok := key.JWK()
+			require.True(t, ok)
+			assert.Equal(t, tc.kty, jwk.Kty)
+			assert.Equal(t, "k1", jwk.Kid)
+			assert.Equal(t, tc.alg, jwk.Alg)
+			assert.Equal(t, "sig", jwk.Use)
+		})
+	}
+}
+
+func TestParseSigningKey_Mismatch(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	// P-384 key cannot be used for ES256
+	_, err = auth.ParseSigningKey("k1", "ES256", pemEncode(t, ecKey))
+	assert.Error(t, err)
+
+	// an EC key is not an RSA key
+	_, err = auth.ParseSigningKey("k1", "RS256", pemEncode(t, ecKey))
+	assert.Error(t, err)
+
+	// symmetric algorithms are not loaded from PEM
+	_, err = auth.ParseSigningKey("k1", "HS256", pemEncode(t, ecKey))
+	assert.Error(t, err)
+}
+
+func TestLoadSigningKey(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pemEncode(t, ecKey), 0o600))
+
+	key, err := auth.LoadSigningKey("k1", "ES256", path)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", key.ID)
+
+	_, err = auth.LoadSigningKey("k1", "ES256", filepath.Join(t.TempDir(), "missing.pem"))
+	assert.Error(t, err)
+}
+
+func TestParseToken_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := auth.ParseSigningKey("k1", "RS256", pemEncode(t, rsaKey))
+	require.NoError(t, err)
+
+	// An attacker signs with HS256 using the (public) RSA key bytes as the secret.
+	pubDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": 1, "role": "admin", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	forged.Header["kid"] = "k1"
+	tok, err := forged.SignedString(pubDER)
+	require.NoError(t, err)
+
+	_, err = auth.ParseToken(tok, auth.NewStaticKeyring(key), auth.TokenOptions{})
+	assert.Error(t, err)
+}
+
+func TestNewJWKSet_SkipsHMAC(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ec, err := auth.ParseSigningKey("ec", "ES256", pemEncode(t, ecKey))
+	require.NoError(t, err)
+
+	set := auth.NewJWKSet(auth.NewHMACKey("hs", []byte("secret")), ec)
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "ec", set.Keys[0].Kid)
+	assert.Equal(t, "P-256", set.Keys[0].Crv)
+	// coordinates are fixed length (32 bytes -> 43 base64url chars)
+	assert.Len(t, set.Keys[0].X, 43)
+	assert.Len(t, set.Keys[0].Y, 43)
+
+	assert.Empty(t, auth.NewJWKSet(auth.NewHMACKey("hs", []byte("secret"))).Keys)
+}
@@ -4,19 +4,35 @@ package auth
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type SessionStore interface {
-	BlacklistToken(ctx context.Context, token string) error
+	BlacklistToken(ctx context.Context, token string, ttl time.Duration) error
 	IsBlacklisted(ctx context.Context, token string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64, ttl time.Duration) error
+	RevokedBefore(ctx context.Context, userID int64) (time.Time, error)
+	TouchSession(ctx context.Context, id string) (bool, error)
+}
+
+// RevokedTokenVersion is the token version of deleted users, which no token carries.
+const RevokedTokenVersion = -1
+
+// TokenVersions returns the current token version of users, or
+// RevokedTokenVersion for users that no longer exist.
+type TokenVersions interface {
+	TokenVersion(ctx context.Context, userID int64) (int64, error)
 }
 
 // AuthenticationMiddleware parses and validates the JWT, then checks blacklist.
-func AuthenticationMiddleware(secret []byte, store SessionStore) gin.HandlerFunc {
+// Any token that fails validation, however well signed, is answered with 401.
+// Unless versions is nil, tokens must also carry the user's current token version.
+func AuthenticationMiddleware(keys *Keyring, opts TokenOptions, store SessionStore, versions TokenVersions) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader("Authorization")
 		parts := strings.SplitN(header, " ", 2)
@@ -25,32 +41,119 @@ func AuthenticationMiddleware(secret []byte, store SessionStore) gin.HandlerFunc
 			return
 		}
 		tokStr := parts[1]
-		tok, err := jwt.Parse(tokStr, func(_ *jwt.Token) (interface{}, error) {
-			return secret, nil
-		})
-		if err != nil || !tok.Valid {
+		claims, err := ParseToken(tokStr, keys, opts)
+		if err != nil {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
+		// the checks below fail closed: a token that cannot be checked, e.g.
+		// while Redis is down, is refused with 503
+		ctx := c.Request.Context()
 		// blacklist check
-		if black, _ := store.IsBlacklisted(c.Request.Context(), tokStr); black {
+		black, err := store.IsBlacklisted(ctx, tokStr)
+		if err != nil {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		if black {
 			c.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
-		claims := tok.Claims.(jwt.MapClaims)
-		c.Set("userID", int64(claims["sub"].(float64)))
-		c.Set("role", claims["role"].(string))
+		userID := claims.UserID()
+		// reject tokens issued before the user's last "log out everywhere".
+		// Tokens issued right after it, e.g. by the password change that
+		// triggered it, stay valid. A microsecond is allowed because iat is
+		// parsed from a float and can come out that much low.
+		revokedAt, err := store.RevokedBefore(ctx, userID)
+		if err != nil {
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		}
+		if !revokedAt.IsZero() && claims.IssuedAt.Add(time.Microsecond).Before(revokedAt) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		// reject tokens issued before a role change, password change or deletion
+		if versions != nil {
+			v, err := versions.TokenVersion(ctx, userID)
+			if err != nil {
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+			if claims.TokenVersion != v {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		// reject tokens of revoked sessions, e.g. ones ended from another device
+		if claims.SessionID != "" {
+			live, err := store.TouchSession(ctx, claims.SessionID)
+			if err != nil {
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+			if !live {
+				c.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+		}
+		c.Set("userID", userID)
+		c.Set("role", claims.Role)
+		c.Set("roles", claims.Roles)
+		c.Set("permissions", claims.Permissions)
+		c.Set("restricted", claims.Restricted)
+		c.Set("token", tokStr)
+		c.Set("sessionID", claims.SessionID)
+		c.Set("orgID", claims.OrgID)
 		c.Next()
 	}
 }
 
-// RequireRole enforces a specific role in context.
+// RequireRole enforces a specific role in context, held directly or inherited.
+// Roles held within an organization are in context for tokens scoped to it.
 func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if c.GetString("role") != role {
+		if c.GetString("role") != role && !slices.Contains(c.GetStringSlice("roles"), role) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission enforces that the roles in context grant permission.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(c.GetStringSlice("permissions"), permission) {
 			c.AbortWithStatus(http.StatusForbidden)
 			return
 		}
 		c.Next()
 	}
 }
+
+// RequireOrg enforces a token scoped to the organization whose ID is the
+// path parameter param, so that roles held within one organization are not
+// used on another.
+func RequireOrg(param string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param(param), 10, 64)
+		if err != nil || id != c.GetInt64("orgID") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "token is not scoped to this organization"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectRestricted refuses restricted tokens, such as those of users who have
+// not verified their email.
+func RejectRestricted() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("restricted") {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "email address not verified"})
+			return
+		}
+		c.Next()
+	}
+}
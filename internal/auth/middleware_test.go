@@ -1,14 +1,17 @@
 package auth_test
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enson89/user-service-go/internal/auth"
 	authmocks "github.com/enson89/user-service-go/internal/auth/mocks"
@@ -21,7 +24,7 @@ func TestAuthMiddleware_NoHeader(t *testing.T) {
 	c, _ := gin.CreateTestContext(w)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 
-	m := auth.AuthenticationMiddleware([]byte("secret"), nil)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("secret"))), auth.TokenOptions{}, nil, nil)
 	m(c)
 
 	assert.True(t, c.IsAborted())
@@ -35,7 +38,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 	c.Request.Header.Set("Authorization", "Bearer invalid.token")
 
-	m := auth.AuthenticationMiddleware([]byte("secret"), nil)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("secret"))), auth.TokenOptions{}, nil, nil)
 	m(c)
 
 	assert.True(t, c.IsAborted())
@@ -49,8 +52,8 @@ func TestAuthMiddleware_Blacklisted(t *testing.T) {
 
 	// Generate a valid token
 	u := &model.User{ID: 5, Role: "user"}
-	secret := []byte("s3cr3t")
-	tok, _ := auth.GenerateToken(u, secret, time.Minute)
+	key := auth.NewHMACKey("test", []byte("s3cr3t"))
+	tok, _ := auth.GenerateToken(u, key, auth.TokenOptions{}, time.Minute)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 	c.Request.Header.Set("Authorization", "Bearer "+tok)
 
@@ -58,7 +61,7 @@ func TestAuthMiddleware_Blacklisted(t *testing.T) {
 	store := new(authmocks.MockSessionStore)
 	store.On("IsBlacklisted", mock.Anything, tok).Return(true, nil)
 
-	m := auth.AuthenticationMiddleware(secret, store)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil)
 	m(c)
 
 	assert.True(t, c.IsAborted())
@@ -73,16 +76,17 @@ func TestAuthMiddleware_Success(t *testing.T) {
 
 	// Valid token
 	u := &model.User{ID: 7, Role: "admin"}
-	secret := []byte("topsecret")
-	tok, _ := auth.GenerateToken(u, secret, time.Minute)
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	tok, _ := auth.GenerateToken(u, key, auth.TokenOptions{}, time.Minute)
 	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
 	c.Request.Header.Set("Authorization", "Bearer "+tok)
 
 	// Mock store returns not blacklisted
 	store := new(authmocks.MockSessionStore)
 	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(7)).Return(time.Time{}, nil)
 
-	m := auth.AuthenticationMiddleware(secret, store)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil)
 	m(c)
 
 	assert.False(t, c.IsAborted())
@@ -91,9 +95,148 @@ func TestAuthMiddleware_Success(t *testing.T) {
 	role, _ := c.Get("role")
 	assert.Equal(t, int64(7), userID)
 	assert.Equal(t, "admin", role)
+	assert.Equal(t, tok, c.GetString("token"))
 	store.AssertExpectations(t)
 }
 
+func TestAuthMiddleware_RevokedForUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	// Token issued before the user logged out everywhere
+	u := &model.User{ID: 9, Role: "user"}
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	tok, _ := auth.GenerateToken(u, key, auth.TokenOptions{}, time.Minute)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+tok)
+
+	store := new(authmocks.MockSessionStore)
+	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Now().Add(time.Second), nil)
+
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil)
+	m(c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAuthMiddleware_IssuedWithRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	u := &model.User{ID: 9, Role: "user"}
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	tok, _ := auth.GenerateToken(u, key, auth.TokenOptions{}, time.Minute)
+	claims, err := auth.ParseToken(tok, auth.NewStaticKeyring(key), auth.TokenOptions{})
+	require.NoError(t, err)
+
+	cases := []struct {
+		name      string
+		revokedAt time.Time
+		status    int
+	}{
+		// e.g. the token a password change issues right after logging out everywhere
+		{"issued at revocation", claims.IssuedAt.Time, http.StatusOK},
+		// a millisecond is told apart, unlike with whole-second timestamps
+		{"issued just before revocation", claims.IssuedAt.Add(time.Millisecond), http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+tok)
+
+			store := new(authmocks.MockSessionStore)
+			store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+			store.On("RevokedBefore", mock.Anything, int64(9)).Return(tc.revokedAt, nil)
+
+			m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil)
+			m(c)
+
+			assert.Equal(t, tc.status != http.StatusOK, c.IsAborted())
+			if tc.status != http.StatusOK {
+				assert.Equal(t, tc.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RevokedSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	claims := auth.NewClaims(&model.User{ID: 9, Role: "user"}, auth.TokenOptions{}, time.Minute)
+	claims.SessionID = "s1"
+	tok, err := auth.SignToken(claims, key)
+	require.NoError(t, err)
+
+	store := new(authmocks.MockSessionStore)
+	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+	store.On("TouchSession", mock.Anything, "s1").Return(true, nil).Once()
+	store.On("TouchSession", mock.Anything, "s1").Return(false, nil).Once()
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil)
+
+	// live session
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+tok)
+	m(c)
+	assert.False(t, c.IsAborted())
+	assert.Equal(t, "s1", c.GetString("sessionID"))
+
+	// revoked from another device
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+tok)
+	m(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	store.AssertExpectations(t)
+}
+
+func TestAuthMiddleware_TokenVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	tok, err := auth.GenerateToken(&model.User{ID: 9, Role: "admin", TokenVersion: 2}, key, auth.TokenOptions{}, time.Minute)
+	require.NoError(t, err)
+
+	store := new(authmocks.MockSessionStore)
+	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+	versions := new(authmocks.MockTokenVersions)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, versions)
+
+	tests := []struct {
+		name    string
+		version int64
+		aborted bool
+	}{
+		{"current", 2, false},
+		{"demoted since", 3, true},
+		{"deleted since", auth.RevokedTokenVersion, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			versions.On("TokenVersion", mock.Anything, int64(9)).Return(tc.version, nil).Once()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+tok)
+			m(c)
+			assert.Equal(t, tc.aborted, c.IsAborted())
+		})
+	}
+	versions.AssertExpectations(t)
+}
+
 func TestRequireRole(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
@@ -111,3 +254,180 @@ func TestRequireRole(t *testing.T) {
 	auth.RequireRole("admin")(c)
 	assert.False(t, c.IsAborted())
 }
+
+func TestRequireRole_Inherited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	// an editor inheriting from user
+	c.Set("role", "editor")
+	c.Set("roles", []string{"editor", "user"})
+
+	auth.RequireRole("user")(c)
+	assert.False(t, c.IsAborted())
+
+	auth.RequireRole("admin")(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	claims := auth.NewClaims(&model.User{ID: 9, Role: "admin"}, auth.TokenOptions{}, time.Minute)
+	claims.Roles = []string{"admin", "user"}
+	claims.Permissions = []string{"users:delete", "users:read"}
+	tok, err := auth.SignToken(claims, key)
+	require.NoError(t, err)
+	store := new(authmocks.MockSessionStore)
+	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+
+	r := gin.New()
+	r.Use(auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil))
+	r.DELETE("/user/:id", auth.RequirePermission("users:delete"), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	r.POST("/keys/rotate", auth.RequirePermission("keys:rotate"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		method, path string
+		status       int
+	}{
+		{http.MethodDelete, "/user/3", http.StatusNoContent},
+		{http.MethodPost, "/keys/rotate", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, tc.path)
+	}
+}
+
+func TestRequireOrg(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	claims := auth.NewClaims(&model.User{ID: 9, Role: "user"}, auth.TokenOptions{}, time.Minute)
+	claims.OrgID = 4
+	claims.Permissions = []string{"members:read"}
+	tok, err := auth.SignToken(claims, key)
+	require.NoError(t, err)
+	store := new(authmocks.MockSessionStore)
+	store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+
+	r := gin.New()
+	r.Use(auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, nil))
+	r.GET("/orgs/:id/members", auth.RequireOrg("id"), auth.RequirePermission("members:read"),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{"/orgs/4/members", http.StatusOK},
+		// the permission was granted within organization 4 only
+		{"/orgs/5/members", http.StatusForbidden},
+		{"/orgs/x/members", http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code, tc.path)
+	}
+}
+
+func TestAuthMiddleware_MissingClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	// Well signed, but without sub or role
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	tok := jwt.NewWithClaims(key.Method, jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()})
+	tok.Header["kid"] = "test"
+	tokStr, err := tok.SignedString([]byte("topsecret"))
+	require.NoError(t, err)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+tokStr)
+
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, nil, nil)
+	assert.NotPanics(t, func() { m(c) })
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRejectRestricted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("restricted", true)
+
+	auth.RejectRestricted()(c)
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Tokens without the flag pass
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	auth.RejectRestricted()(c)
+	assert.False(t, c.IsAborted())
+}
+
+func TestAuthMiddleware_LookupFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key := auth.NewHMACKey("test", []byte("topsecret"))
+	claims := auth.NewClaims(&model.User{ID: 9, Role: "user", TokenVersion: 2}, auth.TokenOptions{}, time.Minute)
+	claims.SessionID = "s1"
+	tok, err := auth.SignToken(claims, key)
+	require.NoError(t, err)
+	down := errors.New("redis down")
+
+	tests := []struct {
+		name  string
+		setup func(store *authmocks.MockSessionStore, versions *authmocks.MockTokenVersions)
+	}{
+		{"blacklist", func(store *authmocks.MockSessionStore, _ *authmocks.MockTokenVersions) {
+			store.On("IsBlacklisted", mock.Anything, tok).Return(false, down)
+		}},
+		{"revocation", func(store *authmocks.MockSessionStore, _ *authmocks.MockTokenVersions) {
+			store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+			store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, down)
+		}},
+		{"token version", func(store *authmocks.MockSessionStore, versions *authmocks.MockTokenVersions) {
+			store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+			store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+			versions.On("TokenVersion", mock.Anything, int64(9)).Return(int64(0), down)
+		}},
+		{"session", func(store *authmocks.MockSessionStore, versions *authmocks.MockTokenVersions) {
+			store.On("IsBlacklisted", mock.Anything, tok).Return(false, nil)
+			store.On("RevokedBefore", mock.Anything, int64(9)).Return(time.Time{}, nil)
+			versions.On("TokenVersion", mock.Anything, int64(9)).Return(int64(2), nil)
+			store.On("TouchSession", mock.Anything, "s1").Return(false, down)
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := new(authmocks.MockSessionStore)
+			versions := new(authmocks.MockTokenVersions)
+			tc.setup(store, versions)
+			m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(key), auth.TokenOptions{}, store, versions)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+tok)
+			m(c)
+			assert.True(t, c.IsAborted())
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			store.AssertExpectations(t)
+			versions.AssertExpectations(t)
+		})
+	}
+}
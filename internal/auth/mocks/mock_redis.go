@@ -6,6 +6,7 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -38,16 +39,16 @@ func (_m *MockSessionStore) EXPECT() *MockSessionStore_Expecter {
 }
 
 // BlacklistToken provides a mock function for the type MockSessionStore
-func (_mock *MockSessionStore) BlacklistToken(ctx context.Context, token string) error {
-	ret := _mock.Called(ctx, token)
+func (_mock *MockSessionStore) BlacklistToken(ctx context.Context, token string, ttl time.Duration) error {
+	ret := _mock.Called(ctx, token, ttl)
 
 	if len(ret) == 0 {
 		panic("no return value specified for BlacklistToken")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = returnFunc(ctx, token)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = returnFunc(ctx, token, ttl)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -62,13 +63,14 @@ type MockSessionStore_BlacklistToken_Call struct {
 // BlacklistToken is a helper method to define mock.On call
 //   - ctx
 //   - token
-func (_e *MockSessionStore_Expecter) BlacklistToken(ctx interface{}, token interface{}) *MockSessionStore_BlacklistToken_Call {
-	return &MockSessionStore_BlacklistToken_Call{Call: _e.mock.On("BlacklistToken", ctx, token)}
+//   - ttl
+func (_e *MockSessionStore_Expecter) BlacklistToken(ctx interface{}, token interface{}, ttl interface{}) *MockSessionStore_BlacklistToken_Call {
+	return &MockSessionStore_BlacklistToken_Call{Call: _e.mock.On("BlacklistToken", ctx, token, ttl)}
 }
 
-func (_c *MockSessionStore_BlacklistToken_Call) Run(run func(ctx context.Context, token string)) *MockSessionStore_BlacklistToken_Call {
+func (_c *MockSessionStore_BlacklistToken_Call) Run(run func(ctx context.Context, token string, ttl time.Duration)) *MockSessionStore_BlacklistToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
 	})
 	return _c
 }
@@ -78,7 +80,7 @@ func (_c *MockSessionStore_BlacklistToken_Call) Return(err error) *MockSessionSt
 	return _c
 }
 
-func (_c *MockSessionStore_BlacklistToken_Call) RunAndReturn(run func(ctx context.Context, token string) error) *MockSessionStore_BlacklistToken_Call {
+func (_c *MockSessionStore_BlacklistToken_Call) RunAndReturn(run func(ctx context.Context, token string, ttl time.Duration) error) *MockSessionStore_BlacklistToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -137,3 +139,369 @@ func (_c *MockSessionStore_IsBlacklisted_Call) RunAndReturn(run func(ctx context
 	_c.Call.Return(run)
 	return _c
 }
+
+// RevokeAllForUser provides a mock function for the type MockSessionStore
+func (_mock *MockSessionStore) RevokeAllForUser(ctx context.Context, userID int64, ttl time.Duration) error {
+	ret := _mock.Called(ctx, userID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, time.Duration) error); ok {
+		r0 = returnFunc(ctx, userID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSessionStore_RevokeAllForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllForUser'
+type MockSessionStore_RevokeAllForUser_Call struct {
+	*mock.Call
+}
+
+// RevokeAllForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - ttl
+func (_e *MockSessionStore_Expecter) RevokeAllForUser(ctx interface{}, userID interface{}, ttl interface{}) *MockSessionStore_RevokeAllForUser_Call {
+	return &MockSessionStore_RevokeAllForUser_Call{Call: _e.mock.On("RevokeAllForUser", ctx, userID, ttl)}
+}
+
+func (_c *MockSessionStore_RevokeAllForUser_Call) Run(run func(ctx context.Context, userID int64, ttl time.Duration)) *MockSessionStore_RevokeAllForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockSessionStore_RevokeAllForUser_Call) Return(err error) *MockSessionStore_RevokeAllForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSessionStore_RevokeAllForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64, ttl time.Duration) error) *MockSessionStore_RevokeAllForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokedBefore provides a mock function for the type MockSessionStore
+func (_mock *MockSessionStore) RevokedBefore(ctx context.Context, userID int64) (time.Time, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokedBefore")
+	}
+
+	var r0 time.Time
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (time.Time, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) time.Time); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(time.Time)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSessionStore_RevokedBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokedBefore'
+type MockSessionStore_RevokedBefore_Call struct {
+	*mock.Call
+}
+
+// RevokedBefore is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockSessionStore_Expecter) RevokedBefore(ctx interface{}, userID interface{}) *MockSessionStore_RevokedBefore_Call {
+	return &MockSessionStore_RevokedBefore_Call{Call: _e.mock.On("RevokedBefore", ctx, userID)}
+}
+
+func (_c *MockSessionStore_RevokedBefore_Call) Run(run func(ctx context.Context, userID int64)) *MockSessionStore_RevokedBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSessionStore_RevokedBefore_Call) Return(time time.Time, err error) *MockSessionStore_RevokedBefore_Call {
+	_c.Call.Return(time, err)
+	return _c
+}
+
+func (_c *MockSessionStore_RevokedBefore_Call) RunAndReturn(run func(ctx context.Context, userID int64) (time.Time, error)) *MockSessionStore_RevokedBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchSession provides a mock function for the type MockSessionStore
+func (_mock *MockSessionStore) TouchSession(ctx context.Context, id string) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSessionStore_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type MockSessionStore_TouchSession_Call struct {
+	*mock.Call
+}
+
+// TouchSession is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockSessionStore_Expecter) TouchSession(ctx interface{}, id interface{}) *MockSessionStore_TouchSession_Call {
+	return &MockSessionStore_TouchSession_Call{Call: _e.mock.On("TouchSession", ctx, id)}
+}
+
+func (_c *MockSessionStore_TouchSession_Call) Run(run func(ctx context.Context, id string)) *MockSessionStore_TouchSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockSessionStore_TouchSession_Call) Return(b bool, err error) *MockSessionStore_TouchSession_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockSessionStore_TouchSession_Call) RunAndReturn(run func(ctx context.Context, id string) (bool, error)) *MockSessionStore_TouchSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockKeyringStore creates a new instance of MockKeyringStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockKeyringStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockKeyringStore {
+	mock := &MockKeyringStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockKeyringStore is an autogenerated mock type for the KeyringStore type
+type MockKeyringStore struct {
+	mock.Mock
+}
+
+type MockKeyringStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockKeyringStore) EXPECT() *MockKeyringStore_Expecter {
+	return &MockKeyringStore_Expecter{mock: &_m.Mock}
+}
+
+// PrimaryKeyID provides a mock function for the type MockKeyringStore
+func (_mock *MockKeyringStore) PrimaryKeyID(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PrimaryKeyID")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockKeyringStore_PrimaryKeyID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PrimaryKeyID'
+type MockKeyringStore_PrimaryKeyID_Call struct {
+	*mock.Call
+}
+
+// PrimaryKeyID is a helper method to define mock.On call
+//   - ctx
+func (_e *MockKeyringStore_Expecter) PrimaryKeyID(ctx interface{}) *MockKeyringStore_PrimaryKeyID_Call {
+	return &MockKeyringStore_PrimaryKeyID_Call{Call: _e.mock.On("PrimaryKeyID", ctx)}
+}
+
+func (_c *MockKeyringStore_PrimaryKeyID_Call) Run(run func(ctx context.Context)) *MockKeyringStore_PrimaryKeyID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockKeyringStore_PrimaryKeyID_Call) Return(s string, err error) *MockKeyringStore_PrimaryKeyID_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockKeyringStore_PrimaryKeyID_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *MockKeyringStore_PrimaryKeyID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPrimaryKeyID provides a mock function for the type MockKeyringStore
+func (_mock *MockKeyringStore) SetPrimaryKeyID(ctx context.Context, kid string) error {
+	ret := _mock.Called(ctx, kid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPrimaryKeyID")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, kid)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockKeyringStore_SetPrimaryKeyID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPrimaryKeyID'
+type MockKeyringStore_SetPrimaryKeyID_Call struct {
+	*mock.Call
+}
+
+// SetPrimaryKeyID is a helper method to define mock.On call
+//   - ctx
+//   - kid
+func (_e *MockKeyringStore_Expecter) SetPrimaryKeyID(ctx interface{}, kid interface{}) *MockKeyringStore_SetPrimaryKeyID_Call {
+	return &MockKeyringStore_SetPrimaryKeyID_Call{Call: _e.mock.On("SetPrimaryKeyID", ctx, kid)}
+}
+
+func (_c *MockKeyringStore_SetPrimaryKeyID_Call) Run(run func(ctx context.Context, kid string)) *MockKeyringStore_SetPrimaryKeyID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockKeyringStore_SetPrimaryKeyID_Call) Return(err error) *MockKeyringStore_SetPrimaryKeyID_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockKeyringStore_SetPrimaryKeyID_Call) RunAndReturn(run func(ctx context.Context, kid string) error) *MockKeyringStore_SetPrimaryKeyID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTokenVersions creates a new instance of MockTokenVersions. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenVersions(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTokenVersions {
+	mock := &MockTokenVersions{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTokenVersions is an autogenerated mock type for the TokenVersions type
+type MockTokenVersions struct {
+	mock.Mock
+}
+
+type MockTokenVersions_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTokenVersions) EXPECT() *MockTokenVersions_Expecter {
+	return &MockTokenVersions_Expecter{mock: &_m.Mock}
+}
+
+// TokenVersion provides a mock function for the type MockTokenVersions
+func (_mock *MockTokenVersions) TokenVersion(ctx context.Context, userID int64) (int64, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TokenVersion")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTokenVersions_TokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TokenVersion'
+type MockTokenVersions_TokenVersion_Call struct {
+	*mock.Call
+}
+
+// TokenVersion is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockTokenVersions_Expecter) TokenVersion(ctx interface{}, userID interface{}) *MockTokenVersions_TokenVersion_Call {
+	return &MockTokenVersions_TokenVersion_Call{Call: _e.mock.On("TokenVersion", ctx, userID)}
+}
+
+func (_c *MockTokenVersions_TokenVersion_Call) Run(run func(ctx context.Context, userID int64)) *MockTokenVersions_TokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockTokenVersions_TokenVersion_Call) Return(n int64, err error) *MockTokenVersions_TokenVersion_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockTokenVersions_TokenVersion_Call) RunAndReturn(run func(ctx context.Context, userID int64) (int64, error)) *MockTokenVersions_TokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
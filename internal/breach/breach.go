@@ -0,0 +1,98 @@
+// Package breach checks passwords against a local list of breached
+// passwords, so that no password ever leaves the service.
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // the lists are keyed by SHA-1
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// MinPrefixLength is the shortest hash prefix a list may hold. Each prefix of
+// n hex digits rejects one in 16^n passwords, breached or not.
+const MinPrefixLength = 8
+
+// List holds the uppercase hex SHA-1 hashes of breached passwords, or
+// prefixes of them. Lists of prefixes are far smaller, at the price of
+// rejecting some passwords that were never breached.
+//
+// Lists are held in memory, so they should be bounded to the most common
+// breached passwords, e.g. the top million of Have I Been Pwned, rather than
+// the full corpus.
+type List struct {
+	entries map[string]struct{}
+	// lengths are the distinct lengths of the entries
+	lengths []int
+}
+
+// Load reads a list with one hash or hash prefix per line. Anything after a
+// colon is ignored, so the "HASH:COUNT" files of Have I Been Pwned load as
+// they are. Blank lines and lines starting with # are skipped.
+func Load(r io.Reader) (*List, error) {
+	l := &List{entries: make(map[string]struct{})}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entry, _, _ := strings.Cut(line, ":")
+		entry = strings.ToUpper(strings.TrimSpace(entry))
+		if len(entry) > 2*sha1.Size || !isHex(entry) {
+			return nil, fmt.Errorf("line %d: not a SHA-1 hash or prefix", n)
+		}
+		if len(entry) < MinPrefixLength {
+			return nil, fmt.Errorf("line %d: hash prefix shorter than %d digits", n, MinPrefixLength)
+		}
+		l.entries[entry] = struct{}{}
+		if !slices.Contains(l.lengths, len(entry)) {
+			l.lengths = append(l.lengths, len(entry))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// LoadFile reads a list from the file at path.
+func LoadFile(path string) (*List, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Len returns the number of entries in the list.
+func (l *List) Len() int {
+	return len(l.entries)
+}
+
+// Breached reports whether the hash of password is, or starts with, an
+// entry of the list.
+func (l *List) Breached(password string) bool {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec // see import
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	for _, n := range l.lengths {
+		if _, ok := l.entries[hash[:n]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func isHex(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,38 @@
+package breach_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/breach"
+)
+
+func TestList_Breached(t *testing.T) {
+	// SHA-1 of "password" and a prefix of that of "123456"
+	list, err := breach.Load(strings.NewReader(`# breached passwords
+5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:9545824
+
+7C4A8D09:37359195
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, list.Len())
+
+	assert.True(t, list.Breached("password"))
+	assert.True(t, list.Breached("123456"))
+	assert.False(t, list.Breached("correct horse battery staple"))
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	_, err := breach.Load(strings.NewReader("5BAA61E4\nnot-a-hash\n"))
+	assert.EqualError(t, err, "line 2: not a SHA-1 hash or prefix")
+
+	_, err = breach.Load(strings.NewReader(strings.Repeat("A", 41)))
+	assert.Error(t, err)
+
+	// a short prefix would reject a large share of all passwords
+	_, err = breach.Load(strings.NewReader("5BAA61E4\n7C4A\n"))
+	assert.EqualError(t, err, "line 2: hash prefix shorter than 8 digits")
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const primaryKeyIDKey = "jwt:primary_kid"
+
+// RedisKeyringStore implements auth.KeyringStore using Redis, so every
+// replica signs with the same key after a rotation.
+type RedisKeyringStore struct {
+	client *redis.Client
+}
+
+// NewKeyringStore returns a RedisKeyringStore backed by client.
+func NewKeyringStore(client *redis.Client) *RedisKeyringStore {
+	return &RedisKeyringStore{client: client}
+}
+
+// PrimaryKeyID returns the ID of the key last rotated to, or "" if none.
+func (r *RedisKeyringStore) PrimaryKeyID(ctx context.Context) (string, error) {
+	kid, err := r.client.Get(ctx, primaryKeyIDKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return kid, err
+}
+
+func (r *RedisKeyringStore) SetPrimaryKeyID(ctx context.Context, kid string) error {
+	return r.client.Set(ctx, primaryKeyIDKey, kid, 0).Err()
+}
@@ -0,0 +1,31 @@
+package cache_test
+
+import (
+	"testing"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+func TestRedisKeyringStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewKeyringStore(client)
+
+	// nothing rotated yet
+	mock.ExpectGet("jwt:primary_kid").RedisNil()
+	kid, err := store.PrimaryKeyID(t.Context())
+	assert.NoError(t, err)
+	assert.Empty(t, kid)
+
+	mock.ExpectSet("jwt:primary_kid", "k2", 0).SetVal("OK")
+	assert.NoError(t, store.SetPrimaryKeyID(t.Context(), "k2"))
+
+	mock.ExpectGet("jwt:primary_kid").SetVal("k2")
+	kid, err = store.PrimaryKeyID(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "k2", kid)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
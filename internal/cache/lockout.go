@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	loginFailuresPrefix = "lockout:failures:"
+	loginStrikesPrefix  = "lockout:strikes:"
+	loginLockPrefix     = "lockout:until:"
+)
+
+// recordFailureScript counts a failure, starting the window on the first one.
+const recordFailureScript = `
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`
+
+// RedisLoginAttemptStore implements service.LoginAttemptStore using Redis.
+// For each key, e.g. an account or a client IP, it keeps the failures within
+// the current window, the number of lockouts so far ("strikes") and whether
+// a lockout is in effect.
+type RedisLoginAttemptStore struct {
+	client *redis.Client
+}
+
+// NewLoginAttemptStore returns a RedisLoginAttemptStore backed by client.
+func NewLoginAttemptStore(client *redis.Client) *RedisLoginAttemptStore {
+	return &RedisLoginAttemptStore{client: client}
+}
+
+// RecordFailure counts a failed login for key and returns the failures in
+// the window, which starts with the first failure and lasts for window.
+func (r *RedisLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return r.client.Eval(ctx, recordFailureScript, []string{loginFailuresPrefix + key}, window.Milliseconds()).Int64()
+}
+
+// Strike records a lockout of key, clearing the failures that led to it, and
+// returns the number of lockouts within ttl of each other.
+func (r *RedisLoginAttemptStore) Strike(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	var strikes *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		strikes = p.Incr(ctx, loginStrikesPrefix+key)
+		p.Expire(ctx, loginStrikesPrefix+key, ttl)
+		p.Del(ctx, loginFailuresPrefix+key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strikes.Val(), nil
+}
+
+// Lock locks key out for d.
+func (r *RedisLoginAttemptStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	return r.client.Set(ctx, loginLockPrefix+key, "1", d).Err()
+}
+
+// LockedFor returns how much longer key is locked out, or 0.
+func (r *RedisLoginAttemptStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, loginLockPrefix+key).Result()
+	if err != nil || ttl < 0 {
+		// -2 for no lock; -1 cannot happen as locks are always set with a TTL
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Reset forgets the failures, strikes and lockout of key.
+func (r *RedisLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return r.client.Del(ctx, loginFailuresPrefix+key, loginStrikesPrefix+key, loginLockPrefix+key).Err()
+}
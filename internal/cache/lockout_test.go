@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+func TestRedisLoginAttemptStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewLoginAttemptStore(client)
+
+	mock.Regexp().ExpectEval(`INCR`, []string{"lockout:failures:ip:10.0.0.1"}, int64(900000)).SetVal(int64(3))
+	n, err := store.RecordFailure(t.Context(), "ip:10.0.0.1", 15*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+
+	mock.ExpectTxPipeline()
+	mock.ExpectIncr("lockout:strikes:ip:10.0.0.1").SetVal(2)
+	mock.ExpectExpire("lockout:strikes:ip:10.0.0.1", 24*time.Hour).SetVal(true)
+	mock.ExpectDel("lockout:failures:ip:10.0.0.1").SetVal(1)
+	mock.ExpectTxPipelineExec()
+	strikes, err := store.Strike(t.Context(), "ip:10.0.0.1", 24*time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), strikes)
+
+	mock.ExpectSet("lockout:until:ip:10.0.0.1", "1", 2*time.Minute).SetVal("OK")
+	assert.NoError(t, store.Lock(t.Context(), "ip:10.0.0.1", 2*time.Minute))
+
+	mock.ExpectPTTL("lockout:until:ip:10.0.0.1").SetVal(90 * time.Second)
+	d, err := store.LockedFor(t.Context(), "ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	// not locked
+	mock.ExpectPTTL("lockout:until:ip:10.0.0.2").SetVal(-2)
+	d, err = store.LockedFor(t.Context(), "ip:10.0.0.2")
+	assert.NoError(t, err)
+	assert.Zero(t, d)
+
+	mock.ExpectDel("lockout:failures:account:7", "lockout:strikes:account:7", "lockout:until:account:7").SetVal(2)
+	assert.NoError(t, store.Reset(t.Context(), "account:7"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
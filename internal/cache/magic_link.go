@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const magicLinkPrefix = "magiclink:"
+
+// RedisMagicLinkStore implements service.MagicLinkStore using Redis. Each
+// outstanding link maps the hash of its token to the user it logs in.
+type RedisMagicLinkStore struct {
+	client *redis.Client
+}
+
+// NewMagicLinkStore returns a RedisMagicLinkStore backed by client.
+func NewMagicLinkStore(client *redis.Client) *RedisMagicLinkStore {
+	return &RedisMagicLinkStore{client: client}
+}
+
+// SaveMagicLink stores a link for userID under tokenHash that expires after ttl.
+func (r *RedisMagicLinkStore) SaveMagicLink(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error {
+	return r.client.Set(ctx, magicLinkPrefix+tokenHash, userID, ttl).Err()
+}
+
+// ConsumeMagicLink removes the link stored under tokenHash and returns its
+// user, or 0 if it does not exist, has expired or was already consumed.
+func (r *RedisMagicLinkStore) ConsumeMagicLink(ctx context.Context, tokenHash string) (int64, error) {
+	id, err := r.client.GetDel(ctx, magicLinkPrefix+tokenHash).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return id, err
+}
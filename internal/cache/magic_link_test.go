@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+func TestRedisMagicLinkStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewMagicLinkStore(client)
+
+	mock.ExpectSet("magiclink:h", int64(7), 15*time.Minute).SetVal("OK")
+	assert.NoError(t, store.SaveMagicLink(t.Context(), "h", 7, 15*time.Minute))
+
+	mock.ExpectGetDel("magiclink:h").SetVal("7")
+	id, err := store.ConsumeMagicLink(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	// replayed
+	mock.ExpectGetDel("magiclink:h").RedisNil()
+	id, err = store.ConsumeMagicLink(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.Zero(t, id)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
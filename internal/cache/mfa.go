@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const mfaChallengePrefix = "mfa:challenge:"
+
+// failChallengeScript counts a failure without resurrecting an expired challenge.
+const failChallengeScript = `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+  return 0
+end
+return redis.call("HINCRBY", KEYS[1], "failures", 1)
+`
+
+// RedisMFAChallengeStore implements service.MFAChallengeStore using Redis.
+// Each challenge is a Redis hash holding the user it was issued to and the
+// number of failed attempts made against it.
+type RedisMFAChallengeStore struct {
+	client *redis.Client
+}
+
+// NewMFAChallengeStore returns a RedisMFAChallengeStore backed by client.
+func NewMFAChallengeStore(client *redis.Client) *RedisMFAChallengeStore {
+	return &RedisMFAChallengeStore{client: client}
+}
+
+// CreateChallenge stores a challenge under tokenHash for userID that expires after ttl.
+func (r *RedisMFAChallengeStore) CreateChallenge(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error {
+	key := mfaChallengePrefix + tokenHash
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, key, "user_id", userID, "failures", 0)
+		p.Expire(ctx, key, ttl)
+		return nil
+	})
+	return err
+}
+
+// ChallengeUser returns the user a challenge was issued to, or 0 if it does
+// not exist or has expired.
+func (r *RedisMFAChallengeStore) ChallengeUser(ctx context.Context, tokenHash string) (int64, error) {
+	id, err := r.client.HGet(ctx, mfaChallengePrefix+tokenHash, "user_id").Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// FailChallenge records a failed attempt and returns the number of failures
+// so far, or 0 if the challenge is gone.
+func (r *RedisMFAChallengeStore) FailChallenge(ctx context.Context, tokenHash string) (int64, error) {
+	return r.client.Eval(ctx, failChallengeScript, []string{mfaChallengePrefix + tokenHash}).Int64()
+}
+
+// DeleteChallenge removes a challenge. It reports false if it was already
+// gone, so a challenge can be completed at most once.
+func (r *RedisMFAChallengeStore) DeleteChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	n, err := r.client.Del(ctx, mfaChallengePrefix+tokenHash).Result()
+	return n > 0, err
+}
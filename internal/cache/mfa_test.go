@@ -0,0 +1,51 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+func TestRedisMFAChallengeStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewMFAChallengeStore(client)
+
+	mock.ExpectTxPipeline()
+	mock.ExpectHSet("mfa:challenge:h", "user_id", int64(7), "failures", 0).SetVal(2)
+	mock.ExpectExpire("mfa:challenge:h", 5*time.Minute).SetVal(true)
+	mock.ExpectTxPipelineExec()
+	assert.NoError(t, store.CreateChallenge(t.Context(), "h", 7, 5*time.Minute))
+
+	mock.ExpectHGet("mfa:challenge:h", "user_id").SetVal("7")
+	id, err := store.ChallengeUser(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+
+	// unknown or expired
+	mock.ExpectHGet("mfa:challenge:gone", "user_id").RedisNil()
+	id, err = store.ChallengeUser(t.Context(), "gone")
+	assert.NoError(t, err)
+	assert.Zero(t, id)
+
+	mock.Regexp().ExpectEval(`HINCRBY`, []string{"mfa:challenge:h"}).SetVal(int64(1))
+	n, err := store.FailChallenge(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	mock.ExpectDel("mfa:challenge:h").SetVal(1)
+	ok, err := store.DeleteChallenge(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// completed twice
+	mock.ExpectDel("mfa:challenge:h").SetVal(0)
+	ok, err = store.DeleteChallenge(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
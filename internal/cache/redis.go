@@ -2,6 +2,12 @@ package cache
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -17,19 +23,131 @@ type Config struct {
 // RedisSessionStore implements service.SessionStore using Redis.
 type RedisSessionStore struct {
 	client *redis.Client
-	ttl    time.Duration
+	// legacyUntil is when entries keyed by the raw token have all expired,
+	// see HonorLegacyBlacklist
+	legacyUntil time.Time
 }
 
-// NewSessionStore returns a RedisSessionStore that blacklists tokens for ttl duration.
-func NewSessionStore(client *redis.Client, ttl time.Duration) *RedisSessionStore {
-	return &RedisSessionStore{client: client, ttl: ttl}
+// NewSessionStore returns a RedisSessionStore backed by client.
+func NewSessionStore(client *redis.Client) *RedisSessionStore {
+	return &RedisSessionStore{client: client}
 }
 
-func (r *RedisSessionStore) BlacklistToken(ctx context.Context, token string) error {
-	return r.client.Set(ctx, token, "1", r.ttl).Err()
+// blacklistPrefix namespaces blacklist entries, which are keyed by the
+// SHA-256 of the token so that Redis never holds usable bearer tokens. The
+// version allows changing the key scheme again.
+const blacklistPrefix = "blacklist:v1:"
+
+// legacyBlacklistPattern matches entries of the old scheme, which used the
+// raw JWT as the key: a header starting with the encoding of `{"alg":` and a
+// JSON payload, each starting with "eyJ", the encoding of `{"`.
+const legacyBlacklistPattern = "eyJhbGciOi*.eyJ*.*"
+
+// legacyBlacklistSinceKey holds when an instance first blacklisted tokens
+// by their fingerprint.
+const legacyBlacklistSinceKey = blacklistPrefix + "since"
+
+// BlacklistToken revokes token for ttl, which should match the token's remaining lifetime.
+func (r *RedisSessionStore) BlacklistToken(ctx context.Context, token string, ttl time.Duration) error {
+	return r.client.Set(ctx, blacklistKey(token), "1", ttl).Err()
 }
 
+// IsBlacklisted reports whether token has been revoked. Entries keyed by the
+// raw token, as written before fingerprints were introduced, are honored
+// until they expire, see HonorLegacyBlacklist.
 func (r *RedisSessionStore) IsBlacklisted(ctx context.Context, token string) (bool, error) {
-	n, err := r.client.Exists(ctx, token).Result()
+	keys := []string{blacklistKey(token)}
+	if time.Now().Before(r.legacyUntil) {
+		keys = append(keys, token)
+	}
+	n, err := r.client.Exists(ctx, keys...).Result()
 	return n > 0, err
 }
+
+// HonorLegacyBlacklist makes IsBlacklisted honor entries keyed by the raw
+// token until lifetime, the longest a token lives, has passed since the
+// first instance blacklisted tokens by their fingerprint, and returns when
+// that is. By then the entries older instances wrote before the deploy have
+// expired. It must be called before the store is used.
+func (r *RedisSessionStore) HonorLegacyBlacklist(ctx context.Context, lifetime time.Duration) (time.Time, error) {
+	if err := r.client.SetNX(ctx, legacyBlacklistSinceKey, time.Now().Unix(), 0).Err(); err != nil {
+		return time.Time{}, err
+	}
+	since, err := r.client.Get(ctx, legacyBlacklistSinceKey).Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	r.legacyUntil = time.Unix(since, 0).Add(lifetime)
+	return r.legacyUntil, nil
+}
+
+// MigrateLegacyBlacklist rekeys blacklist entries of the old scheme by the
+// fingerprint of their token, keeping their remaining lifetime, and returns
+// how many it moved. It is safe to run while other instances still write
+// entries of the old scheme, as those are honored anyway.
+func (r *RedisSessionStore) MigrateLegacyBlacklist(ctx context.Context) (int, error) {
+	moved := 0
+	iter := r.client.Scan(ctx, 0, legacyBlacklistPattern, 100).Iterator()
+	for iter.Next(ctx) {
+		token := iter.Val()
+		ttl, err := r.client.PTTL(ctx, token).Result()
+		if err != nil {
+			return moved, err
+		}
+		if ttl <= 0 {
+			// gone in the meantime, or not one of ours as it never expires
+			continue
+		}
+		_, err = r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Set(ctx, blacklistKey(token), "1", ttl)
+			p.Del(ctx, token)
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, iter.Err()
+}
+
+// RevokeAllForUser records that every token issued to userID until now is revoked.
+// ttl should be the longest lifetime a token can have, after which the marker is moot.
+func (r *RedisSessionStore) RevokeAllForUser(ctx context.Context, userID int64, ttl time.Duration) error {
+	now := time.Now()
+	ts := fmt.Sprintf("%d.%06d", now.Unix(), now.Nanosecond()/1000)
+	return r.client.Set(ctx, revokedKey(userID), ts, ttl).Err()
+}
+
+// RevokedBefore returns the time of the user's last RevokeAllForUser, to the
+// microsecond, or the zero time. Markers written in whole seconds are read too.
+func (r *RedisSessionStore) RevokedBefore(ctx context.Context, userID int64) (time.Time, error) {
+	ts, err := r.client.Get(ctx, revokedKey(userID)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	secPart, usecPart, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(secPart, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("revocation marker %q: %w", ts, err)
+	}
+	var usec int64
+	if usecPart != "" {
+		if usec, err = strconv.ParseInt(usecPart, 10, 64); err != nil {
+			return time.Time{}, fmt.Errorf("revocation marker %q: %w", ts, err)
+		}
+	}
+	return time.Unix(sec, usec*1000), nil
+}
+
+func blacklistKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return blacklistPrefix + hex.EncodeToString(sum[:])
+}
+
+func revokedKey(userID int64) string {
+	return fmt.Sprintf("revoked:user:%d", userID)
+}
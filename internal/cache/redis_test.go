@@ -2,35 +2,123 @@
 package cache_test
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/go-redis/redismock/v9"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enson89/user-service-go/internal/cache"
 )
 
+// fingerprints of "tok" and "other"
+const (
+	tokKey   = "blacklist:v1:1a7674eb4ee78df7e1ac439a93c3fa8e3c945784d4dec9fd8e3011738b2f1d62"
+	otherKey = "blacklist:v1:d9298a10d1b0735837dc4bd85dac641b0f3cef27a47e5d53a54f2f3f5b2fcffa"
+)
+
 func TestRedisSessionStore(t *testing.T) {
 	client, mock := redismock.NewClientMock()
-	store := cache.NewSessionStore(client, time.Minute)
+	store := cache.NewSessionStore(client)
 
-	// BlacklistToken
-	mock.ExpectSet("tok", "1", time.Minute).SetVal("OK")
-	assert.NoError(t, store.BlacklistToken(t.Context(), "tok"))
+	// BlacklistToken keys the entry by the token's fingerprint
+	mock.ExpectSet(tokKey, "1", time.Minute).SetVal("OK")
+	assert.NoError(t, store.BlacklistToken(t.Context(), "tok", time.Minute))
 
 	// IsBlacklisted true
-	mock.ExpectExists("tok").SetVal(1)
+	mock.ExpectExists(tokKey).SetVal(1)
 	ok, err := store.IsBlacklisted(t.Context(), "tok")
 	assert.NoError(t, err)
 	assert.True(t, ok)
 
 	// IsBlacklisted false
-	mock.ExpectExists("other").SetVal(0)
+	mock.ExpectExists(otherKey).SetVal(0)
 	ok, err = store.IsBlacklisted(t.Context(), "other")
 	assert.NoError(t, err)
 	assert.False(t, ok)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRedisSessionStore_RevokeAllForUser(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewSessionStore(client)
+
+	// RevokeAllForUser stores the current unix time to the microsecond
+	mock.Regexp().ExpectSet("revoked:user:7", `^\d+\.\d{6}$`, time.Hour).SetVal("OK")
+	assert.NoError(t, store.RevokeAllForUser(t.Context(), 7, time.Hour))
+
+	// RevokedBefore returns the stored time
+	mock.ExpectGet("revoked:user:7").SetVal("1700000000.000250")
+	at, err := store.RevokedBefore(t.Context(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 250000), at)
+
+	// markers stored in whole seconds are still read
+	mock.ExpectGet("revoked:user:7").SetVal("1700000000")
+	at, err = store.RevokedBefore(t.Context(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1700000000, 0), at)
+
+	// RevokedBefore returns zero time when never revoked
+	mock.ExpectGet("revoked:user:8").RedisNil()
+	at, err = store.RevokedBefore(t.Context(), 8)
+	assert.NoError(t, err)
+	assert.True(t, at.IsZero())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisSessionStore_HonorLegacyBlacklist(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewSessionStore(client)
+
+	// entries keyed by the raw token are honored for a token lifetime after
+	// the first instance of the fingerprint scheme started
+	started := time.Now().Add(-time.Hour).Unix()
+	mock.Regexp().ExpectSetNX("blacklist:v1:since", `^\d+$`, 0).SetVal(false)
+	mock.ExpectGet("blacklist:v1:since").SetVal(strconv.FormatInt(started, 10))
+	until, err := store.HonorLegacyBlacklist(t.Context(), 2*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, time.Unix(started, 0).Add(2*time.Hour), until)
+
+	mock.ExpectExists(tokKey, "tok").SetVal(1)
+	ok, err := store.IsBlacklisted(t.Context(), "tok")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// and no longer once they have all expired
+	mock.Regexp().ExpectSetNX("blacklist:v1:since", `^\d+$`, 0).SetVal(false)
+	mock.ExpectGet("blacklist:v1:since").SetVal(strconv.FormatInt(started, 10))
+	_, err = store.HonorLegacyBlacklist(t.Context(), 30*time.Minute)
+	require.NoError(t, err)
+
+	mock.ExpectExists(tokKey).SetVal(0)
+	ok, err = store.IsBlacklisted(t.Context(), "tok")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisSessionStore_MigrateLegacyBlacklist(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewSessionStore(client)
+
+	mock.ExpectScan(0, "eyJhbGciOi*.eyJ*.*", 100).SetVal([]string{"tok", "eyJpersistent"}, 0)
+	mock.ExpectPTTL("tok").SetVal(90 * time.Second)
+	mock.ExpectTxPipeline()
+	mock.ExpectSet(tokKey, "1", 90*time.Second).SetVal("OK")
+	mock.ExpectDel("tok").SetVal(1)
+	mock.ExpectTxPipelineExec()
+	// keys without a TTL are not blacklist entries
+	mock.ExpectPTTL("eyJpersistent").SetVal(-1)
+
+	moved, err := store.MigrateLegacyBlacklist(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
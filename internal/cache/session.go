@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/redis/go-redis/v9"
+)
+
+// Sessions are Redis hashes, indexed per user by a set of their IDs. Entries
+// of the set whose session has expired are pruned when the set is listed.
+const (
+	sessionPrefix      = "session:"
+	userSessionsPrefix = "sessions:user:"
+)
+
+// touchSessionScript records activity without resurrecting a revoked or
+// expired session.
+const touchSessionScript = `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+  return 0
+end
+redis.call("HSET", KEYS[1], "last_seen", ARGV[1])
+return 1
+`
+
+// deleteSessionScript deletes a session only if it belongs to the user.
+//
+// KEYS[1] session; KEYS[2] sessions of the user; ARGV[1] user; ARGV[2] session ID.
+const deleteSessionScript = `
+if redis.call("HGET", KEYS[1], "user_id") ~= ARGV[1] then
+  return 0
+end
+redis.call("DEL", KEYS[1])
+redis.call("SREM", KEYS[2], ARGV[2])
+return 1
+`
+
+// SaveSession records s for ttl, which should be the longest its tokens live.
+// Saving an existing session, e.g. on refresh, keeps its creation time.
+func (r *RedisSessionStore) SaveSession(ctx context.Context, s *model.Session, ttl time.Duration) error {
+	key, userKey := sessionPrefix+s.ID, userSessionsKey(s.UserID)
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, key, "user_id", s.UserID, "jti", s.JTI, "user_agent", s.UserAgent, "ip", s.IP,
+			"last_seen", s.LastSeenAt.Unix())
+		p.HSetNX(ctx, key, "created_at", s.CreatedAt.Unix())
+		p.Expire(ctx, key, ttl)
+		p.SAdd(ctx, userKey, s.ID)
+		p.Expire(ctx, userKey, ttl)
+		return nil
+	})
+	return err
+}
+
+// TouchSession records that the session was just used. It reports false if
+// the session has been revoked or has expired.
+func (r *RedisSessionStore) TouchSession(ctx context.Context, id string) (bool, error) {
+	n, err := r.client.Eval(ctx, touchSessionScript, []string{sessionPrefix + id}, time.Now().Unix()).Int64()
+	return n == 1, err
+}
+
+// ListSessions returns the live sessions of userID, most recently used first.
+func (r *RedisSessionStore) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	userKey := userSessionsKey(userID)
+	ids, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err = r.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, id := range ids {
+			cmds[i] = p.HGetAll(ctx, sessionPrefix+id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]model.Session, 0, len(ids))
+	var expired []any
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			expired = append(expired, ids[i])
+			continue
+		}
+		sessions = append(sessions, model.Session{
+			ID:         ids[i],
+			UserID:     userID,
+			JTI:        fields["jti"],
+			UserAgent:  fields["user_agent"],
+			IP:         fields["ip"],
+			CreatedAt:  unixField(fields["created_at"]),
+			LastSeenAt: unixField(fields["last_seen"]),
+		})
+	}
+	if len(expired) > 0 {
+		if err = r.client.SRem(ctx, userKey, expired...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// DeleteSession revokes a session of userID. It reports false if the user
+// has no such session.
+func (r *RedisSessionStore) DeleteSession(ctx context.Context, userID int64, id string) (bool, error) {
+	n, err := r.client.Eval(ctx, deleteSessionScript, []string{sessionPrefix + id, userSessionsKey(userID)},
+		userID, id).Int64()
+	return n == 1, err
+}
+
+// DeleteAllSessions revokes every session of userID.
+func (r *RedisSessionStore) DeleteAllSessions(ctx context.Context, userID int64) error {
+	userKey := userSessionsKey(userID)
+	ids, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return err
+	}
+	keys := []string{userKey}
+	for _, id := range ids {
+		keys = append(keys, sessionPrefix+id)
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func userSessionsKey(userID int64) string {
+	return userSessionsPrefix + strconv.FormatInt(userID, 10)
+}
+
+func unixField(v string) time.Time {
+	ts, _ := strconv.ParseInt(v, 10, 64)
+	return time.Unix(ts, 0)
+}
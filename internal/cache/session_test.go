@@ -0,0 +1,86 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/cache"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+func TestRedisSessionStore_Sessions(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewSessionStore(client)
+
+	now := time.Unix(1700000000, 0)
+	s := &model.Session{ID: "s1", UserID: 7, JTI: "j1", UserAgent: "curl", IP: "10.0.0.1", CreatedAt: now, LastSeenAt: now}
+	mock.ExpectTxPipeline()
+	mock.ExpectHSet("session:s1", "user_id", int64(7), "jti", "j1", "user_agent", "curl", "ip", "10.0.0.1",
+		"last_seen", int64(1700000000)).SetVal(5)
+	mock.ExpectHSetNX("session:s1", "created_at", int64(1700000000)).SetVal(true)
+	mock.ExpectExpire("session:s1", time.Hour).SetVal(true)
+	mock.ExpectSAdd("sessions:user:7", "s1").SetVal(1)
+	mock.ExpectExpire("sessions:user:7", time.Hour).SetVal(true)
+	mock.ExpectTxPipelineExec()
+	require.NoError(t, store.SaveSession(t.Context(), s, time.Hour))
+
+	mock.Regexp().ExpectEval(`last_seen`, []string{"session:s1"}, `^\d+$`).SetVal(int64(1))
+	live, err := store.TouchSession(t.Context(), "s1")
+	require.NoError(t, err)
+	assert.True(t, live)
+
+	mock.Regexp().ExpectEval(`last_seen`, []string{"session:gone"}, `^\d+$`).SetVal(int64(0))
+	live, err = store.TouchSession(t.Context(), "gone")
+	require.NoError(t, err)
+	assert.False(t, live)
+
+	mock.Regexp().ExpectEval(`SREM`, []string{"session:s1", "sessions:user:7"}, int64(7), "s1").SetVal(int64(1))
+	ok, err := store.DeleteSession(t.Context(), 7, "s1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// someone else's session
+	mock.Regexp().ExpectEval(`SREM`, []string{"session:s2", "sessions:user:7"}, int64(7), "s2").SetVal(int64(0))
+	ok, err = store.DeleteSession(t.Context(), 7, "s2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	mock.ExpectSMembers("sessions:user:7").SetVal([]string{"s1", "s2"})
+	mock.ExpectDel("sessions:user:7", "session:s1", "session:s2").SetVal(3)
+	require.NoError(t, store.DeleteAllSessions(t.Context(), 7))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisSessionStore_ListSessions(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewSessionStore(client)
+
+	mock.ExpectSMembers("sessions:user:7").SetVal([]string{"old", "new", "gone"})
+	mock.ExpectHGetAll("session:old").SetVal(map[string]string{
+		"user_id": "7", "jti": "j1", "user_agent": "curl", "ip": "10.0.0.1",
+		"created_at": "1700000000", "last_seen": "1700000100",
+	})
+	mock.ExpectHGetAll("session:new").SetVal(map[string]string{
+		"user_id": "7", "jti": "j2", "user_agent": "Firefox", "ip": "10.0.0.2",
+		"created_at": "1700000050", "last_seen": "1700000200",
+	})
+	mock.ExpectHGetAll("session:gone").SetVal(map[string]string{})
+	// the expired session is dropped from the index
+	mock.ExpectSRem("sessions:user:7", "gone").SetVal(1)
+
+	sessions, err := store.ListSessions(t.Context(), 7)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "new", sessions[0].ID)
+	assert.Equal(t, "Firefox", sessions[0].UserAgent)
+	assert.Equal(t, time.Unix(1700000050, 0), sessions[0].CreatedAt)
+	assert.Equal(t, "old", sessions[1].ID)
+	assert.Equal(t, "j1", sessions[1].JTI)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
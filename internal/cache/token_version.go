@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/redis/go-redis/v9"
+)
+
+const tokenVersionPrefix = "tokenversion:user:"
+
+// TokenVersionLoader reads the token version of a user from the database. It
+// returns sql.ErrNoRows if there is no such user.
+type TokenVersionLoader interface {
+	TokenVersion(ctx context.Context, userID int64) (int64, error)
+}
+
+// RedisTokenVersionCache implements auth.TokenVersions by caching the token
+// versions of users in Redis, so that checking a token does not take a trip
+// to the database.
+type RedisTokenVersionCache struct {
+	client *redis.Client
+	loader TokenVersionLoader
+	ttl    time.Duration
+}
+
+// NewTokenVersionCache returns a RedisTokenVersionCache that loads versions
+// it does not know from loader and keeps them for ttl.
+func NewTokenVersionCache(client *redis.Client, loader TokenVersionLoader, ttl time.Duration) *RedisTokenVersionCache {
+	return &RedisTokenVersionCache{client: client, loader: loader, ttl: ttl}
+}
+
+// TokenVersion returns the token version of userID, or auth.RevokedTokenVersion
+// if the user does not exist.
+func (r *RedisTokenVersionCache) TokenVersion(ctx context.Context, userID int64) (int64, error) {
+	key := tokenVersionKey(userID)
+	v, err := r.client.Get(ctx, key).Int64()
+	if err == nil || !errors.Is(err, redis.Nil) {
+		return v, err
+	}
+	v, err = r.loader.TokenVersion(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		v, err = auth.RevokedTokenVersion, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	// NX, so a version loaded before a concurrent bump cannot replace the
+	// bumped one stored by SetTokenVersion
+	return v, r.client.SetNX(ctx, key, v, r.ttl).Err()
+}
+
+// SetTokenVersion stores the new token version of userID after a bump.
+func (r *RedisTokenVersionCache) SetTokenVersion(ctx context.Context, userID, version int64) error {
+	return r.client.Set(ctx, tokenVersionKey(userID), version, r.ttl).Err()
+}
+
+// DeleteTokenVersion drops the cached token version of userID, so that it is
+// loaded from the database on the next check.
+func (r *RedisTokenVersionCache) DeleteTokenVersion(ctx context.Context, userID int64) error {
+	return r.client.Del(ctx, tokenVersionKey(userID)).Err()
+}
+
+func tokenVersionKey(userID int64) string {
+	return tokenVersionPrefix + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,58 @@
+package cache_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+type stubVersionLoader map[int64]int64
+
+func (l stubVersionLoader) TokenVersion(_ context.Context, userID int64) (int64, error) {
+	v, ok := l[userID]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return v, nil
+}
+
+func TestRedisTokenVersionCache(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	versions := cache.NewTokenVersionCache(client, stubVersionLoader{7: 2}, time.Hour)
+
+	// cached
+	mock.ExpectGet("tokenversion:user:7").SetVal("3")
+	v, err := versions.TokenVersion(t.Context(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	// loaded on a miss
+	mock.ExpectGet("tokenversion:user:7").RedisNil()
+	mock.ExpectSetNX("tokenversion:user:7", int64(2), time.Hour).SetVal(true)
+	v, err = versions.TokenVersion(t.Context(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	// deleted users are cached too
+	mock.ExpectGet("tokenversion:user:8").RedisNil()
+	mock.ExpectSetNX("tokenversion:user:8", int64(auth.RevokedTokenVersion), time.Hour).SetVal(true)
+	v, err = versions.TokenVersion(t.Context(), 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(auth.RevokedTokenVersion), v)
+
+	mock.ExpectSet("tokenversion:user:7", int64(4), time.Hour).SetVal("OK")
+	require.NoError(t, versions.SetTokenVersion(t.Context(), 7, 4))
+
+	mock.ExpectDel("tokenversion:user:7").SetVal(1)
+	require.NoError(t, versions.DeleteTokenVersion(t.Context(), 7))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
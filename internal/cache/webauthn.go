@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const webAuthnSessionPrefix = "webauthn:session:"
+
+// RedisWebAuthnSessionStore implements service.WebAuthnSessionStore using
+// Redis. It keeps the challenge of each WebAuthn ceremony between its begin
+// and finish requests.
+type RedisWebAuthnSessionStore struct {
+	client *redis.Client
+}
+
+// NewWebAuthnSessionStore returns a RedisWebAuthnSessionStore backed by client.
+func NewWebAuthnSessionStore(client *redis.Client) *RedisWebAuthnSessionStore {
+	return &RedisWebAuthnSessionStore{client: client}
+}
+
+// SaveSession stores the session data of a ceremony under key for ttl,
+// replacing any earlier ceremony under the same key.
+func (r *RedisWebAuthnSessionStore) SaveSession(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, webAuthnSessionPrefix+key, data, ttl).Err()
+}
+
+// TakeSession removes and returns the session data stored under key, or nil
+// if there is none, so each challenge can be answered at most once.
+func (r *RedisWebAuthnSessionStore) TakeSession(ctx context.Context, key string) ([]byte, error) {
+	data, err := r.client.GetDel(ctx, webAuthnSessionPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	return data, err
+}
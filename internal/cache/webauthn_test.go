@@ -0,0 +1,32 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/cache"
+)
+
+func TestRedisWebAuthnSessionStore(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	store := cache.NewWebAuthnSessionStore(client)
+
+	mock.ExpectSet("webauthn:session:k", []byte(`{"challenge":"c"}`), 5*time.Minute).SetVal("OK")
+	assert.NoError(t, store.SaveSession(t.Context(), "k", []byte(`{"challenge":"c"}`), 5*time.Minute))
+
+	mock.ExpectGetDel("webauthn:session:k").SetVal(`{"challenge":"c"}`)
+	data, err := store.TakeSession(t.Context(), "k")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"challenge":"c"}`, string(data))
+
+	// already answered or expired
+	mock.ExpectGetDel("webauthn:session:k").RedisNil()
+	data, err = store.TakeSession(t.Context(), "k")
+	assert.NoError(t, err)
+	assert.Nil(t, data)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -12,6 +12,10 @@ import (
 type AppConfig struct {
 	Env  string `mapstructure:"env"`
 	Port string `mapstructure:"port"`
+	// TrustedProxies are the IPs or CIDR ranges of the proxies whose
+	// X-Forwarded-For headers tell the client IP. None are trusted by
+	// default, so the client IP is the peer address of the connection.
+	TrustedProxies []string `mapstructure:"trustedProxies"`
 }
 
 type DBConfig struct {
@@ -27,18 +31,219 @@ type RedisConfig struct {
 	Addr     string `mapstructure:"addr"`
 	Password string `mapstructure:"password"`
 	DB       int    `mapstructure:"db"`
+	// MigrateLegacyBlacklist rekeys blacklist entries stored under raw
+	// tokens by older versions on startup.
+	MigrateLegacyBlacklist bool `mapstructure:"migrateLegacyBlacklist"`
 }
 
 type JWTConfig struct {
-	Secret      string        `mapstructure:"secret"`
+	Secret             string        `mapstructure:"secret"`
+	ExpireHours        time.Duration `mapstructure:"expireHours"`
+	RefreshExpireHours time.Duration `mapstructure:"refreshExpireHours"`
+	// Algorithm is HS256 (signed with Secret) or an asymmetric algorithm
+	// (RS256, ES256, EdDSA, ...) signed with the key in PrivateKeyFile.
+	// They describe the only key unless Keys is set.
+	Algorithm      string `mapstructure:"algorithm"`
+	KeyID          string `mapstructure:"keyId"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	// Keys is the signing keyring, in rotation order.
+	Keys []JWTKeyConfig `mapstructure:"keys"`
+	// Issuer and Audience are stamped into every token and required on
+	// every token presented back.
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// AllowedAlgorithms limits the accepted signing algorithms; empty
+	// allows those of the configured keys.
+	AllowedAlgorithms []string      `mapstructure:"allowedAlgorithms"`
+	ClockSkewSeconds  time.Duration `mapstructure:"clockSkewSeconds"`
+	// TokenVersionCacheMinutes is how long the token versions of users,
+	// which role changes, password changes and deletion bump to revoke
+	// every token of a user, are cached in Redis.
+	TokenVersionCacheMinutes time.Duration `mapstructure:"tokenVersionCacheMinutes"`
+}
+
+// JWTKeyConfig describes one keyring entry. HS256 keys are signed with their
+// own Secret, or the contents of SecretFile, so that rotating to a new key
+// rotates the secret. ActivateAt and RetireAt are RFC 3339 timestamps;
+// empty leaves that side of the validity window open.
+type JWTKeyConfig struct {
+	ID             string `mapstructure:"id"`
+	Algorithm      string `mapstructure:"algorithm"`
+	Secret         string `mapstructure:"secret"`
+	SecretFile     string `mapstructure:"secretFile"`
+	PrivateKeyFile string `mapstructure:"privateKeyFile"`
+	ActivateAt     string `mapstructure:"activateAt"`
+	RetireAt       string `mapstructure:"retireAt"`
+}
+
+type PasswordResetConfig struct {
+	ExpireMinutes time.Duration `mapstructure:"expireMinutes"`
+}
+
+type PasswordHashConfig struct {
+	// Algorithm new passwords are hashed with, "bcrypt" or "argon2id".
+	// Existing hashes are upgraded to it, and to the parameters below, as
+	// their users log in.
+	Algorithm  string `mapstructure:"algorithm"`
+	BcryptCost int    `mapstructure:"bcryptCost"`
+	// Argon2Memory is in KiB.
+	Argon2Memory      uint32 `mapstructure:"argon2Memory"`
+	Argon2Iterations  uint32 `mapstructure:"argon2Iterations"`
+	Argon2Parallelism uint8  `mapstructure:"argon2Parallelism"`
+}
+
+type PasswordPolicyConfig struct {
+	// MinLength counts characters; MaxBytes is capped at what the password
+	// hash takes, 72 bytes for bcrypt.
+	MinLength          int  `mapstructure:"minLength"`
+	MaxBytes           int  `mapstructure:"maxBytes"`
+	RequireUppercase   bool `mapstructure:"requireUppercase"`
+	RequireLowercase   bool `mapstructure:"requireLowercase"`
+	RequireDigit       bool `mapstructure:"requireDigit"`
+	RequireSymbol      bool `mapstructure:"requireSymbol"`
+	RejectEmailSimilar bool `mapstructure:"rejectEmailSimilar"`
+	// HistoryDepth is how many of the latest passwords, the current one
+	// included, cannot be chosen again.
+	HistoryDepth int `mapstructure:"historyDepth"`
+	// BreachedListFile lists the SHA-1 hashes, or hash prefixes, of the
+	// most common breached passwords, one per line; empty skips the check.
+	BreachedListFile string `mapstructure:"breachedListFile"`
+}
+
+type EmailVerificationConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
 	ExpireHours time.Duration `mapstructure:"expireHours"`
+	// UnverifiedLogin is "allow", "deny" or "restrict" (issue tokens that
+	// only reach the routes open to unverified users).
+	UnverifiedLogin string `mapstructure:"unverifiedLogin"`
+}
+
+type EmailChangeConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	ExpireHours time.Duration `mapstructure:"expireHours"`
+}
+
+type SessionsConfig struct {
+	// Enabled tracks every login as a session that users can list and end.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type OrganizationsConfig struct {
+	// Enabled lets users create organizations and get tokens scoped to them.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type InvitationsConfig struct {
+	// Enabled lets organization admins invite people by email; it takes
+	// organizations to be enabled.
+	Enabled     bool          `mapstructure:"enabled"`
+	ExpireHours time.Duration `mapstructure:"expireHours"`
+}
+
+type GroupsConfig struct {
+	// Enabled lets admins manage groups of users and grant roles to them.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type LockoutConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// AccountThreshold and IPThreshold are the failed logins within
+	// WindowMinutes that lock an account or a client IP out.
+	AccountThreshold int           `mapstructure:"accountThreshold"`
+	IPThreshold      int           `mapstructure:"ipThreshold"`
+	WindowMinutes    time.Duration `mapstructure:"windowMinutes"`
+	// The first lockout lasts BaseMinutes and each further one within
+	// StrikeResetHours twice as long as the one before, up to MaxMinutes.
+	BaseMinutes      time.Duration `mapstructure:"baseMinutes"`
+	MaxMinutes       time.Duration `mapstructure:"maxMinutes"`
+	StrikeResetHours time.Duration `mapstructure:"strikeResetHours"`
+}
+
+type RateLimitConfig struct {
+	Enabled  bool                    `mapstructure:"enabled"`
+	Policies []RateLimitPolicyConfig `mapstructure:"policies"`
+}
+
+// RateLimitPolicyConfig allows every identity Requests per PeriodSeconds to
+// Routes, in bursts of up to Burst requests (default Requests).
+type RateLimitPolicyConfig struct {
+	Name string `mapstructure:"name"`
+	// Routes are "METHOD /path" or "/path" as registered on the router,
+	// e.g. "POST /v1/login" or "/v1/user/:id"; "*" is any route.
+	Routes []string `mapstructure:"routes"`
+	// Identity is "ip", "user" or "api_key" (the X-API-Key header).
+	Identity      string `mapstructure:"identity"`
+	Requests      int    `mapstructure:"requests"`
+	PeriodSeconds int    `mapstructure:"periodSeconds"`
+	Burst         int    `mapstructure:"burst"`
+}
+
+type MagicLinkConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	ExpireMinutes time.Duration `mapstructure:"expireMinutes"`
+}
+
+type MFAConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Issuer names the service in authenticator apps.
+	Issuer string `mapstructure:"issuer"`
+	// EncryptionKey is the base64 encoded 32 byte key TOTP secrets are
+	// encrypted with at rest.
+	EncryptionKey          string        `mapstructure:"encryptionKey"`
+	ChallengeExpireMinutes time.Duration `mapstructure:"challengeExpireMinutes"`
+}
+
+type WebAuthnConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// RPID is the relying party ID: the domain credentials are bound to.
+	RPID          string `mapstructure:"rpID"`
+	RPDisplayName string `mapstructure:"rpDisplayName"`
+	// RPOrigins are the origins of the pages allowed to use the credentials.
+	RPOrigins      []string      `mapstructure:"rpOrigins"`
+	TimeoutMinutes time.Duration `mapstructure:"timeoutMinutes"`
+}
+
+type NotifyConfig struct {
+	// Driver is "stdout", "file" (append to File) or "smtp".
+	Driver string `mapstructure:"driver"`
+	File   string `mapstructure:"file"`
+	// TemplateDir overrides the built-in message templates.
+	TemplateDir   string `mapstructure:"templateDir"`
+	DefaultLocale string `mapstructure:"defaultLocale"`
+	// LinkBaseURL is where links in messages point to, e.g. the frontend.
+	LinkBaseURL string     `mapstructure:"linkBaseURL"`
+	SMTP        SMTPConfig `mapstructure:"smtp"`
+}
+
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
 }
 
 type Config struct {
-	App   AppConfig   `mapstructure:"app"`
-	DB    DBConfig    `mapstructure:"db"`
-	Redis RedisConfig `mapstructure:"redis"`
-	JWT   JWTConfig   `mapstructure:"jwt"`
+	App           AppConfig           `mapstructure:"app"`
+	DB            DBConfig            `mapstructure:"db"`
+	Redis         RedisConfig         `mapstructure:"redis"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	PasswordReset PasswordResetConfig `mapstructure:"passwordReset"`
+
+	PasswordHash      PasswordHashConfig      `mapstructure:"passwordHash"`
+	PasswordPolicy    PasswordPolicyConfig    `mapstructure:"passwordPolicy"`
+	EmailVerification EmailVerificationConfig `mapstructure:"emailVerification"`
+	EmailChange       EmailChangeConfig       `mapstructure:"emailChange"`
+	Sessions          SessionsConfig          `mapstructure:"sessions"`
+	Organizations     OrganizationsConfig     `mapstructure:"organizations"`
+	Invitations       InvitationsConfig       `mapstructure:"invitations"`
+	Groups            GroupsConfig            `mapstructure:"groups"`
+	Notify            NotifyConfig            `mapstructure:"notify"`
+	Lockout           LockoutConfig           `mapstructure:"lockout"`
+	RateLimit         RateLimitConfig         `mapstructure:"rateLimit"`
+	MagicLink         MagicLinkConfig         `mapstructure:"magicLink"`
+	MFA               MFAConfig               `mapstructure:"mfa"`
+	WebAuthn          WebAuthnConfig          `mapstructure:"webauthn"`
 }
 
 // nolint:nestif
@@ -51,6 +256,7 @@ func Load() (*Config, error) {
 	// Defaults ensures your service has sane fallback values if neither a config file nor env var is present.
 	viper.SetDefault("app.env", "dev")
 	viper.SetDefault("app.port", "8080")
+	viper.SetDefault("app.trustedProxies", []string{})
 	viper.SetDefault("db.host", "localhost")
 	viper.SetDefault("db.port", 5432)
 	viper.SetDefault("db.user", "postgres")
@@ -60,8 +266,73 @@ func Load() (*Config, error) {
 	viper.SetDefault("redis.addr", "localhost:6379")
 	viper.SetDefault("redis.password", "")
 	viper.SetDefault("redis.db", 0)
+	viper.SetDefault("redis.migrateLegacyBlacklist", true)
 	viper.SetDefault("jwt.secret", "supersecretkey")
 	viper.SetDefault("jwt.expireHours", 2)
+	viper.SetDefault("jwt.refreshExpireHours", 720)
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.keyId", "default")
+	viper.SetDefault("jwt.privateKeyFile", "")
+	viper.SetDefault("jwt.issuer", "user-service")
+	viper.SetDefault("jwt.audience", "cms")
+	viper.SetDefault("jwt.allowedAlgorithms", []string{})
+	viper.SetDefault("jwt.clockSkewSeconds", 30)
+	viper.SetDefault("jwt.tokenVersionCacheMinutes", 60)
+	viper.SetDefault("passwordReset.expireMinutes", 30)
+	viper.SetDefault("passwordHash.algorithm", "bcrypt")
+	viper.SetDefault("passwordHash.bcryptCost", 10)
+	viper.SetDefault("passwordHash.argon2Memory", 65536)
+	viper.SetDefault("passwordHash.argon2Iterations", 3)
+	viper.SetDefault("passwordHash.argon2Parallelism", 4)
+	viper.SetDefault("passwordPolicy.minLength", 8)
+	viper.SetDefault("passwordPolicy.maxBytes", 72)
+	viper.SetDefault("passwordPolicy.requireUppercase", false)
+	viper.SetDefault("passwordPolicy.requireLowercase", false)
+	viper.SetDefault("passwordPolicy.requireDigit", false)
+	viper.SetDefault("passwordPolicy.requireSymbol", false)
+	viper.SetDefault("passwordPolicy.rejectEmailSimilar", true)
+	viper.SetDefault("passwordPolicy.historyDepth", 5)
+	viper.SetDefault("passwordPolicy.breachedListFile", "")
+	viper.SetDefault("emailVerification.enabled", true)
+	viper.SetDefault("emailVerification.expireHours", 48)
+	viper.SetDefault("emailVerification.unverifiedLogin", "allow")
+	viper.SetDefault("emailChange.enabled", true)
+	viper.SetDefault("emailChange.expireHours", 24)
+	viper.SetDefault("sessions.enabled", false)
+	viper.SetDefault("organizations.enabled", false)
+	viper.SetDefault("invitations.enabled", false)
+	viper.SetDefault("invitations.expireHours", 168)
+	viper.SetDefault("groups.enabled", false)
+	viper.SetDefault("lockout.enabled", false)
+	viper.SetDefault("lockout.accountThreshold", 5)
+	viper.SetDefault("lockout.ipThreshold", 20)
+	viper.SetDefault("lockout.windowMinutes", 15)
+	viper.SetDefault("lockout.baseMinutes", 1)
+	viper.SetDefault("lockout.maxMinutes", 60)
+	viper.SetDefault("lockout.strikeResetHours", 24)
+	viper.SetDefault("rateLimit.enabled", false)
+	viper.SetDefault("rateLimit.policies", []map[string]any{})
+	viper.SetDefault("magicLink.enabled", false)
+	viper.SetDefault("magicLink.expireMinutes", 15)
+	viper.SetDefault("mfa.enabled", false)
+	viper.SetDefault("mfa.issuer", "User Service")
+	viper.SetDefault("mfa.encryptionKey", "")
+	viper.SetDefault("mfa.challengeExpireMinutes", 5)
+	viper.SetDefault("webauthn.enabled", false)
+	viper.SetDefault("webauthn.rpID", "localhost")
+	viper.SetDefault("webauthn.rpDisplayName", "User Service")
+	viper.SetDefault("webauthn.rpOrigins", []string{"http://localhost:3000"})
+	viper.SetDefault("webauthn.timeoutMinutes", 5)
+	viper.SetDefault("notify.driver", "stdout")
+	viper.SetDefault("notify.file", "")
+	viper.SetDefault("notify.templateDir", "")
+	viper.SetDefault("notify.defaultLocale", "en")
+	viper.SetDefault("notify.linkBaseURL", "http://localhost:3000")
+	viper.SetDefault("notify.smtp.host", "localhost")
+	viper.SetDefault("notify.smtp.port", 587)
+	viper.SetDefault("notify.smtp.username", "")
+	viper.SetDefault("notify.smtp.password", "")
+	viper.SetDefault("notify.smtp.from", "no-reply@localhost")
 
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath("./internal/config")
@@ -91,5 +362,19 @@ func Load() (*Config, error) {
 
 	// Convert expireHours from int to time.Duration into a Go time.Duration for easy use downstream
 	cfg.JWT.ExpireHours = time.Duration(viper.GetInt("jwt.expireHours")) * time.Hour
+	cfg.JWT.RefreshExpireHours = time.Duration(viper.GetInt("jwt.refreshExpireHours")) * time.Hour
+	cfg.JWT.ClockSkewSeconds = time.Duration(viper.GetInt("jwt.clockSkewSeconds")) * time.Second
+	cfg.JWT.TokenVersionCacheMinutes = time.Duration(viper.GetInt("jwt.tokenVersionCacheMinutes")) * time.Minute
+	cfg.PasswordReset.ExpireMinutes = time.Duration(viper.GetInt("passwordReset.expireMinutes")) * time.Minute
+	cfg.EmailVerification.ExpireHours = time.Duration(viper.GetInt("emailVerification.expireHours")) * time.Hour
+	cfg.EmailChange.ExpireHours = time.Duration(viper.GetInt("emailChange.expireHours")) * time.Hour
+	cfg.Invitations.ExpireHours = time.Duration(viper.GetInt("invitations.expireHours")) * time.Hour
+	cfg.Lockout.WindowMinutes = time.Duration(viper.GetInt("lockout.windowMinutes")) * time.Minute
+	cfg.Lockout.BaseMinutes = time.Duration(viper.GetInt("lockout.baseMinutes")) * time.Minute
+	cfg.Lockout.MaxMinutes = time.Duration(viper.GetInt("lockout.maxMinutes")) * time.Minute
+	cfg.Lockout.StrikeResetHours = time.Duration(viper.GetInt("lockout.strikeResetHours")) * time.Hour
+	cfg.MagicLink.ExpireMinutes = time.Duration(viper.GetInt("magicLink.expireMinutes")) * time.Minute
+	cfg.MFA.ChallengeExpireMinutes = time.Duration(viper.GetInt("mfa.challengeExpireMinutes")) * time.Minute
+	cfg.WebAuthn.TimeoutMinutes = time.Duration(viper.GetInt("webauthn.timeoutMinutes")) * time.Minute
 	return &cfg, nil
 }
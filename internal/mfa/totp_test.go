@@ -0,0 +1,107 @@
+package mfa_test
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/mfa"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors.
+func rfcSecret() string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+}
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// the RFC lists 8 digit codes; 6 digit codes are their last six digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for ts, want := range vectors {
+		code, err := mfa.TOTPCode(rfcSecret(), time.Unix(ts, 0))
+		require.NoError(t, err)
+		assert.Equal(t, want, code, ts)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := mfa.NewTOTPSecret()
+	require.NoError(t, err)
+	now := time.Unix(1_700_000_000, 0)
+
+	code, err := mfa.TOTPCode(secret, now)
+	require.NoError(t, err)
+	step, ok := mfa.ValidateTOTP(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	// one period of drift either way is tolerated
+	_, ok = mfa.ValidateTOTP(secret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+	_, ok = mfa.ValidateTOTP(secret, code, now.Add(-30*time.Second))
+	assert.True(t, ok)
+
+	// but not more
+	_, ok = mfa.ValidateTOTP(secret, code, now.Add(90*time.Second))
+	assert.False(t, ok)
+	_, ok = mfa.ValidateTOTP(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = mfa.ValidateTOTP("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := mfa.TOTPURI("User Service", "user@x.com", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/User Service:user@x.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "User Service", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+}
+
+func TestCipher(t *testing.T) {
+	c, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+
+	enc, err := c.Encrypt("JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.NotContains(t, enc, "JBSWY3DPEHPK3PXP")
+	dec, err := c.Decrypt(enc)
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", dec)
+
+	// a different key cannot read it
+	other, err := mfa.NewCipher(append(make([]byte, 31), 1))
+	require.NoError(t, err)
+	_, err = other.Decrypt(enc)
+	assert.Error(t, err)
+
+	_, err = mfa.NewCipher([]byte("short"))
+	assert.Error(t, err)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := mfa.NewRecoveryCodes(mfa.RecoveryCodeCount)
+	require.NoError(t, err)
+	assert.Len(t, codes, mfa.RecoveryCodeCount)
+	seen := map[string]bool{}
+	for _, c := range codes {
+		assert.Regexp(t, `^[a-z2-7]{5}-[a-z2-7]{5}$`, c)
+		assert.False(t, seen[c])
+		seen[c] = true
+	}
+	assert.Equal(t, "abcde-fghij", mfa.NormalizeRecoveryCode(" ABCDE fghij"))
+	assert.Equal(t, "abcde-fghij", mfa.NormalizeRecoveryCode("abcdefghij"))
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Group is a team of users, e.g. an editorial team. The roles granted to a
+// group are held by its members, and by the members of its subgroups.
+type Group struct {
+	ID          int64     `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	// Members, Subgroups and Roles are only set on a single group, and
+	// hold its direct members and subgroups and the roles granted to it.
+	Members   []GroupMember `db:"-" json:"members,omitempty"`
+	Subgroups []Group       `db:"-" json:"subgroups,omitempty"`
+	Roles     []string      `db:"-" json:"roles,omitempty"`
+}
+
+// GroupMember makes a user a member of a group.
+type GroupMember struct {
+	UserID    int64     `db:"user_id" json:"user_id"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// UserMFA is a user's TOTP second factor. It is pending until EnabledAt is
+// set by confirming a first code. TOTPSecret is encrypted at rest.
+type UserMFA struct {
+	UserID     int64      `db:"user_id" json:"user_id"`
+	TOTPSecret string     `db:"totp_secret" json:"-"`
+	EnabledAt  *time.Time `db:"enabled_at" json:"enabled_at,omitempty"`
+	// LastTOTPStep is the time step of the last accepted code, which may
+	// not be accepted again.
+	LastTOTPStep int64     `db:"last_totp_step" json:"-"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// TOTPEnrollment is handed to a user enrolling an authenticator app. URI is
+// the otpauth:// URI to render as a QR code, Secret the same key for manual entry.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
@@ -0,0 +1,21 @@
+package model
+
+// Templates a Notification can be rendered from.
+const (
+	TemplatePasswordReset     = "password_reset"
+	TemplateEmailVerification = "email_verification"
+	TemplateMagicLink         = "magic_link"
+	TemplateEmailChange       = "email_change"
+	TemplateEmailChangeNotice = "email_change_notice"
+	TemplateOrgInvitation     = "org_invitation"
+	TemplatePasskeyAdded      = "passkey_added"
+)
+
+// Notification is a message to a user, rendered from Template with Data.
+// An empty Locale leaves the choice of language to the notifier.
+type Notification struct {
+	To       string
+	Template string
+	Locale   string
+	Data     map[string]string
+}
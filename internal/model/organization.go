@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Organization is a tenant: a customer whose users are members of it.
+type Organization struct {
+	ID        int64     `db:"id" json:"id"`
+	Slug      string    `db:"slug" json:"slug"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// Role is the role of the user the organization was listed for.
+	Role string `db:"role" json:"role,omitempty"`
+}
+
+// Membership makes a user a member of an organization, holding Role
+// within it.
+type Membership struct {
+	OrgID     int64     `db:"org_id" json:"org_id"`
+	UserID    int64     `db:"user_id" json:"user_id"`
+	Email     string    `db:"email" json:"email"`
+	Role      string    `db:"role" json:"role"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Invitation invites Email to become a member of an organization, holding
+// Role within it. Only the SHA-256 of the token sent to the invitee is
+// stored.
+type Invitation struct {
+	ID         int64      `db:"id" json:"id"`
+	OrgID      int64      `db:"org_id" json:"org_id"`
+	Email      string     `db:"email" json:"email"`
+	Role       string     `db:"role" json:"role"`
+	TokenHash  string     `db:"token_hash" json:"-"`
+	InvitedBy  *int64     `db:"invited_by" json:"invited_by,omitempty"`
+	ExpiresAt  time.Time  `db:"expires_at" json:"expires_at"`
+	AcceptedAt *time.Time `db:"accepted_at" json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
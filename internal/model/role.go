@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Permissions checked by the API, as seeded by the migrations.
+const (
+	PermissionUsersRead     = "users:read"
+	PermissionUsersDelete   = "users:delete"
+	PermissionUsersUnlock   = "users:unlock"
+	PermissionKeysRotate    = "keys:rotate"
+	PermissionRolesRead     = "roles:read"
+	PermissionRolesManage   = "roles:manage"
+	PermissionMembersRead   = "members:read"
+	PermissionMembersManage = "members:manage"
+	PermissionGroupsRead    = "groups:read"
+	PermissionGroupsManage  = "groups:manage"
+)
+
+// Roles seeded by the migrations. RoleUser is granted to every new user.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Roles members hold within an organization, as seeded by the migrations.
+const (
+	RoleOrgMember = "org_member"
+	RoleOrgAdmin  = "org_admin"
+)
+
+// Role grants its permissions, and those it inherits from its parent, to
+// the users that hold it.
+type Role struct {
+	ID          int64     `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	ParentID    *int64    `db:"parent_id" json:"parent_id,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	// Permissions the role grants itself, without those it inherits.
+	Permissions []string `db:"-" json:"permissions"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Session is a login of a user on one device. It lasts as long as its tokens
+// are refreshed; JTI is the ID of the latest access token issued to it.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     int64     `json:"-"`
+	JTI        string    `json:"jti"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Current marks the session of the caller when sessions are listed.
+	Current bool `json:"current"`
+}
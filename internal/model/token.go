@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// RefreshToken is a persisted refresh token. Only the SHA-256 of the opaque
+// token is stored. Tokens produced by rotating one another share a FamilyID.
+type RefreshToken struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	FamilyID  string     `db:"family_id" json:"family_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	// OrgID is the organization the family's access tokens are scoped to.
+	OrgID *int64 `db:"org_id" json:"org_id,omitempty"`
+}
+
+// PasswordResetToken is a single-use token that lets a user set a new
+// password. Only the SHA-256 of the token sent to the user is stored.
+type PasswordResetToken struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// EmailVerificationToken is a single-use token proving that a user receives
+// mail at their address. Only the SHA-256 of the token is stored.
+type EmailVerificationToken struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// EmailChangeToken is a single-use token proving that a user receives mail
+// at NewEmail, the address they asked to change to. Only the SHA-256 of the
+// token is stored.
+type EmailChangeToken struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	NewEmail  string     `db:"new_email" json:"new_email"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TokenPair is handed to clients after a successful login or refresh.
+type TokenPair struct {
+	AccessToken  string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// MFAToken is returned by a password login instead of the tokens above
+	// when the user has yet to present a second factor. ExpiresIn is then
+	// its lifetime.
+	MFAToken string `json:"mfa_token,omitempty"`
+}
@@ -10,4 +10,10 @@ type User struct {
 	Name         string    `db:"name" json:"name"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	// EmailVerifiedAt is nil until the user proves they own Email.
+	EmailVerifiedAt *time.Time `db:"email_verified_at" json:"email_verified_at,omitempty"`
+	// LockedUntil is set while password logins are refused after too many failures.
+	LockedUntil *time.Time `db:"locked_until" json:"locked_until,omitempty"`
+	// TokenVersion is embedded in access tokens; raising it revokes them all.
+	TokenVersion int64 `db:"token_version" json:"-"`
 }
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// WebAuthnCredential is a passkey or security key registered by a user.
+// SignCount is the authenticator's signature counter as of its last use;
+// CloneDetectedAt is set once the counter went backwards, after which the
+// credential is no longer accepted.
+type WebAuthnCredential struct {
+	ID              int64      `db:"id" json:"id"`
+	UserID          int64      `db:"user_id" json:"user_id"`
+	CredentialID    []byte     `db:"credential_id" json:"credential_id"`
+	PublicKey       []byte     `db:"public_key" json:"-"`
+	AttestationType string     `db:"attestation_type" json:"attestation_type"`
+	AAGUID          []byte     `db:"aaguid" json:"aaguid,omitempty"`
+	SignCount       int64      `db:"sign_count" json:"sign_count"`
+	Transports      string     `db:"transports" json:"transports,omitempty"`
+	BackupEligible  bool       `db:"backup_eligible" json:"backup_eligible"`
+	BackupState     bool       `db:"backup_state" json:"backup_state"`
+	CloneDetectedAt *time.Time `db:"clone_detected_at" json:"clone_detected_at,omitempty"`
+	LastUsedAt      *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// FileNotifier writes rendered notifications to a file or stdout instead of
+// delivering them. It is meant for development and tests, as the output then
+// holds whatever tokens are being sent.
+type FileNotifier struct {
+	templates *Templates
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewFileNotifier returns a FileNotifier writing to w.
+func NewFileNotifier(w io.Writer, templates *Templates) *FileNotifier {
+	return &FileNotifier{templates: templates, w: w}
+}
+
+// Notify renders n and appends it to the sink.
+func (f *FileNotifier) Notify(ctx context.Context, n model.Notification) error {
+	msg, err := f.templates.Render(ctx, n)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = fmt.Fprintf(f.w, "To: %s\nSubject: %s\n\n%s\n----\n", msg.To, msg.Subject, msg.Text)
+	return err
+}
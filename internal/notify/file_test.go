@@ -0,0 +1,28 @@
+package notify_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/notify"
+)
+
+func TestFileNotifier(t *testing.T) {
+	templates, err := notify.DefaultTemplates("en", "http://localhost:3000")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	n := notify.NewFileNotifier(&buf, templates)
+
+	err = n.Notify(t.Context(), model.Notification{
+		To:       "user@x.com",
+		Template: model.TemplateEmailVerification,
+		Data:     map[string]string{"token": "abc"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "To: user@x.com\nSubject: Confirm your email address\n")
+	assert.Contains(t, buf.String(), "http://localhost:3000/verify-email?token=abc")
+}
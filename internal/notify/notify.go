@@ -0,0 +1,36 @@
+// Package notify renders notifications from per-locale templates and
+// delivers them by SMTP or to a local file sink.
+package notify
+
+import (
+	"context"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, n model.Notification) error
+}
+
+// Message is a rendered notification.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string // optional
+}
+
+type localeKey struct{}
+
+// WithLocale returns a context whose notifications are rendered in locale
+// unless they name one themselves.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom returns the locale set by WithLocale, or "".
+func LocaleFrom(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
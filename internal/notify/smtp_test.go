@@ -0,0 +1,111 @@
+package notify_test
+
+import (
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/notify"
+)
+
+// fakeSMTP accepts a single session and hands the envelope and message to the returned channel.
+func fakeSMTP(t *testing.T) (string, int, <-chan [3]string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	got := make(chan [3]string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		var from, to string
+		_ = tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				_ = tp.PrintfLine("250 localhost")
+			case "MAIL":
+				from = line
+				_ = tp.PrintfLine("250 OK")
+			case "RCPT":
+				to = line
+				_ = tp.PrintfLine("250 OK")
+			case "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				_ = tp.PrintfLine("250 OK")
+				got <- [3]string{from, to, string(data)}
+			case "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				return
+			default:
+				_ = tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p, got
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	host, port, got := fakeSMTP(t)
+	templates, err := notify.DefaultTemplates("en", "https://app.example.com")
+	require.NoError(t, err)
+	n := notify.NewSMTPNotifier(notify.SMTPConfig{Host: host, Port: port, From: "no-reply@example.com"}, templates)
+
+	err = n.Notify(t.Context(), model.Notification{
+		To:       "user@x.com",
+		Template: model.TemplatePasswordReset,
+		Locale:   "de",
+		Data:     map[string]string{"token": "abc"},
+	})
+	require.NoError(t, err)
+
+	session := <-got
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", session[0])
+	assert.Equal(t, "RCPT TO:<user@x.com>", session[1])
+
+	msg, err := mail.ReadMessage(strings.NewReader(session[2]))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Passwort zurücksetzen", subject)
+	assert.Equal(t, "user@x.com", msg.Header.Get("To"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		types = append(types, strings.SplitN(part.Header.Get("Content-Type"), ";", 2)[0])
+	}
+	assert.Equal(t, []string{"text/plain", "text/html"}, types)
+}
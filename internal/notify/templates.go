@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+//go:embed templates
+var defaultTemplates embed.FS
+
+// Templates renders notifications. Each template is a set of files in a
+// directory named after its locale:
+//
+//	<locale>/<template>.subject.tmpl  (text, required)
+//	<locale>/<template>.txt.tmpl      (text, required)
+//	<locale>/<template>.html.tmpl     (HTML, optional)
+type Templates struct {
+	defaultLocale string
+	baseURL       string
+	subject       map[string]*texttemplate.Template
+	text          map[string]*texttemplate.Template
+	html          map[string]*htmltemplate.Template
+}
+
+// templateData is what templates are executed with. BaseURL is where links
+// in messages point to, e.g. the frontend that hosts the reset form.
+type templateData struct {
+	To      string
+	Locale  string
+	BaseURL string
+	Data    map[string]string
+}
+
+// DefaultTemplates returns the templates built into the binary.
+func DefaultTemplates(defaultLocale, baseURL string) (*Templates, error) {
+	sub, err := fs.Sub(defaultTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	return LoadTemplates(sub, defaultLocale, baseURL)
+}
+
+// LoadTemplates parses every template in fsys. Every template must exist in
+// defaultLocale, which is used when a notification's locale has no template.
+func LoadTemplates(fsys fs.FS, defaultLocale, baseURL string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		subject:       map[string]*texttemplate.Template{},
+		text:          map[string]*texttemplate.Template{},
+		html:          map[string]*htmltemplate.Template{},
+	}
+	files, err := fs.Glob(fsys, "*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		locale := path.Dir(file)
+		name, kind, ok := strings.Cut(strings.TrimSuffix(path.Base(file), ".tmpl"), ".")
+		if !ok {
+			return nil, fmt.Errorf("template %s: want <name>.<subject|txt|html>.tmpl", file)
+		}
+		key := locale + "/" + name
+		switch kind {
+		case "subject":
+			t.subject[key], err = texttemplate.New(file).Option("missingkey=error").Parse(string(data))
+		case "txt":
+			t.text[key], err = texttemplate.New(file).Option("missingkey=error").Parse(string(data))
+		case "html":
+			t.html[key], err = htmltemplate.New(file).Option("missingkey=error").Parse(string(data))
+		default:
+			return nil, fmt.Errorf("template %s: unknown kind %q", file, kind)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for key := range t.text {
+		if _, ok := t.subject[key]; !ok {
+			return nil, fmt.Errorf("template %s has no subject", key)
+		}
+	}
+	for key := range t.subject {
+		if _, ok := t.text[key]; !ok {
+			return nil, fmt.Errorf("template %s has no text body", key)
+		}
+		locale, name, _ := strings.Cut(key, "/")
+		if _, ok := t.text[defaultLocale+"/"+name]; !ok && locale != defaultLocale {
+			return nil, fmt.Errorf("template %s is missing from default locale %s", name, defaultLocale)
+		}
+	}
+	return t, nil
+}
+
+// Render renders n in its locale, else the locale of ctx, falling back from
+// a regional locale such as de-AT to its language and then to the default.
+func (t *Templates) Render(ctx context.Context, n model.Notification) (Message, error) {
+	locale := t.resolve(n.Template, n.Locale, LocaleFrom(ctx))
+	if locale == "" {
+		return Message{}, fmt.Errorf("no template %q", n.Template)
+	}
+	key := locale + "/" + n.Template
+	data := templateData{To: n.To, Locale: locale, BaseURL: t.baseURL, Data: n.Data}
+
+	var subject, text, html bytes.Buffer
+	if err := t.subject[key].Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.text[key].Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if tmpl, ok := t.html[key]; ok {
+		if err := tmpl.Execute(&html, data); err != nil {
+			return Message{}, err
+		}
+	}
+	return Message{
+		To:      n.To,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// resolve returns the first of the candidate locales that has template name.
+func (t *Templates) resolve(name string, locales ...string) string {
+	for _, locale := range append(locales, t.defaultLocale) {
+		if locale == "" {
+			continue
+		}
+		if _, ok := t.text[locale+"/"+name]; ok {
+			return locale
+		}
+		if lang, _, regional := strings.Cut(locale, "-"); regional {
+			if _, ok := t.text[lang+"/"+name]; ok {
+				return lang
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package notify_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/notify"
+)
+
+func TestDefaultTemplates_Render(t *testing.T) {
+	templates, err := notify.DefaultTemplates("en", "https://app.example.com/")
+	require.NoError(t, err)
+
+	n := model.Notification{
+		To:       "user@x.com",
+		Template: model.TemplatePasswordReset,
+		Data:     map[string]string{"token": "abc"},
+	}
+	msg, err := templates.Render(t.Context(), n)
+	require.NoError(t, err)
+	assert.Equal(t, "user@x.com", msg.To)
+	assert.Equal(t, "Reset your password", msg.Subject)
+	assert.Contains(t, msg.Text, "https://app.example.com/reset-password?token=abc")
+	assert.Contains(t, msg.HTML, `href="https://app.example.com/reset-password?token=abc"`)
+
+	// every built-in template renders in every locale
+	data := map[string]string{"token": "abc", "new_email": "new@x.com", "org": "Acme"}
+	for _, tmpl := range []string{
+		model.TemplatePasswordReset, model.TemplateEmailVerification, model.TemplateMagicLink,
+		model.TemplateEmailChange, model.TemplateEmailChangeNotice, model.TemplateOrgInvitation,
+		model.TemplatePasskeyAdded,
+	} {
+		for _, locale := range []string{"en", "de"} {
+			_, err = templates.Render(t.Context(), model.Notification{Template: tmpl, Locale: locale, Data: data})
+			assert.NoError(t, err, "%s/%s", locale, tmpl)
+		}
+	}
+}
+
+func TestTemplates_LocaleSelection(t *testing.T) {
+	templates, err := notify.DefaultTemplates("en", "")
+	require.NoError(t, err)
+	data := map[string]string{"token": "abc"}
+
+	tests := []struct {
+		name      string
+		locale    string
+		ctxLocale string
+		subject   string
+	}{
+		{"default", "", "", "Reset your password"},
+		{"explicit", "de", "", "Passwort zurücksetzen"},
+		{"regional falls back to language", "de-AT", "", "Passwort zurücksetzen"},
+		{"unknown falls back to default", "fr", "", "Reset your password"},
+		{"from context", "", "de", "Passwort zurücksetzen"},
+		{"notification wins over context", "en", "de", "Reset your password"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			if tc.ctxLocale != "" {
+				ctx = notify.WithLocale(ctx, tc.ctxLocale)
+			}
+			msg, err := templates.Render(ctx, model.Notification{
+				Template: model.TemplatePasswordReset, Locale: tc.locale, Data: data,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.subject, msg.Subject)
+		})
+	}
+}
+
+func TestTemplates_HTMLEscapes(t *testing.T) {
+	templates, err := notify.LoadTemplates(fstest.MapFS{
+		"en/hi.subject.tmpl": {Data: []byte("Hi {{.Data.name}}")},
+		"en/hi.txt.tmpl":     {Data: []byte("Hi {{.Data.name}}")},
+		"en/hi.html.tmpl":    {Data: []byte("<p>Hi {{.Data.name}}</p>")},
+	}, "en", "")
+	require.NoError(t, err)
+
+	msg, err := templates.Render(t.Context(), model.Notification{Template: "hi", Data: map[string]string{"name": "<b>x</b>"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi <b>x</b>", msg.Text)
+	assert.Equal(t, "<p>Hi &lt;b&gt;x&lt;/b&gt;</p>", msg.HTML)
+
+	// unknown templates and missing data are errors, not empty messages
+	_, err = templates.Render(t.Context(), model.Notification{Template: "bye"})
+	assert.Error(t, err)
+	_, err = templates.Render(t.Context(), model.Notification{Template: "hi"})
+	assert.Error(t, err)
+}
+
+func TestLoadTemplates_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"no subject", fstest.MapFS{
+			"en/hi.txt.tmpl": {Data: []byte("Hi")},
+		}},
+		{"no text body", fstest.MapFS{
+			"en/hi.subject.tmpl": {Data: []byte("Hi")},
+		}},
+		{"missing from default locale", fstest.MapFS{
+			"de/hi.subject.tmpl": {Data: []byte("Hallo")},
+			"de/hi.txt.tmpl":     {Data: []byte("Hallo")},
+		}},
+		{"unknown kind", fstest.MapFS{
+			"en/hi.md.tmpl": {Data: []byte("Hi")},
+		}},
+		{"parse error", fstest.MapFS{
+			"en/hi.subject.tmpl": {Data: []byte("Hi {{")},
+			"en/hi.txt.tmpl":     {Data: []byte("Hi")},
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := notify.LoadTemplates(tc.fsys, "en", "")
+			assert.Error(t, err)
+		})
+	}
+}
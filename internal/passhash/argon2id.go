@@ -0,0 +1,104 @@
+package passhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Default Argon2id parameters, the second recommended option of RFC 9106.
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 4
+	argon2SaltLength         = 16
+	argon2KeyLength          = 32
+)
+
+// Argon2id hashes with Argon2id. Memory is in KiB; zero fields take the
+// defaults of RFC 9106.
+type Argon2id struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func (a Argon2id) withDefaults() Argon2id {
+	if a.Memory == 0 {
+		a.Memory = defaultArgon2Memory
+	}
+	if a.Iterations == 0 {
+		a.Iterations = defaultArgon2Iterations
+	}
+	if a.Parallelism == 0 {
+		a.Parallelism = defaultArgon2Parallelism
+	}
+	return a
+}
+
+// Hash implements Scheme. The hash reads
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+func (a Argon2id) Hash(password string) (string, error) {
+	a = a.withDefaults()
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.Iterations, a.Memory, a.Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Iterations, a.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify implements Scheme.
+func (Argon2id) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false, err
+	}
+	got := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism,
+		uint32(len(key))) //nolint:gosec // keys are short
+	return subtle.ConstantTimeCompare(got, key) == 1, nil
+}
+
+// Outdated implements Scheme.
+func (a Argon2id) Outdated(hash string) bool {
+	params, _, _, err := parseArgon2id(hash)
+	return err != nil || params != a.withDefaults()
+}
+
+// MaxBytes implements Scheme.
+func (Argon2id) MaxBytes() int {
+	return 0
+}
+
+func (Argon2id) owns(hash string) bool {
+	return hasID(hash, "argon2id")
+}
+
+// parseArgon2id splits an Argon2id PHC string into its parts.
+func parseArgon2id(hash string) (params Argon2id, salt, key []byte, err error) {
+	malformed := fmt.Errorf("%w: malformed argon2id hash", ErrUnknownHash)
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	fields := strings.Split(hash, "$")
+	if len(fields) != 6 || fields[1] != "argon2id" {
+		return params, nil, nil, malformed
+	}
+	var version int
+	if _, err = fmt.Sscanf(fields[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrUnknownHash)
+	}
+	if _, err = fmt.Sscanf(fields[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, malformed
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(fields[4]); err != nil {
+		return params, nil, nil, malformed
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(fields[5]); err != nil || len(key) == 0 {
+		return params, nil, nil, malformed
+	}
+	return params, salt, key, nil
+}
@@ -0,0 +1,52 @@
+package passhash
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptMaxBytes is the length after which bcrypt refuses passwords.
+const bcryptMaxBytes = 72
+
+// Bcrypt hashes with bcrypt at Cost, or bcrypt.DefaultCost if unset.
+type Bcrypt struct {
+	Cost int
+}
+
+func (b Bcrypt) cost() int {
+	if b.Cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return b.Cost
+}
+
+// Hash implements Scheme.
+func (b Bcrypt) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost())
+	return string(hash), err
+}
+
+// Verify implements Scheme.
+func (Bcrypt) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Outdated implements Scheme.
+func (b Bcrypt) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != b.cost()
+}
+
+// MaxBytes implements Scheme.
+func (Bcrypt) MaxBytes() int {
+	return bcryptMaxBytes
+}
+
+func (Bcrypt) owns(hash string) bool {
+	return hasID(hash, "2a", "2b", "2y")
+}
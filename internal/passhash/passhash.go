@@ -0,0 +1,91 @@
+// Package passhash hashes passwords with bcrypt or Argon2id. Hashes are
+// stored in PHC string format, which names the algorithm and its parameters,
+// so that a Hasher verifies hashes made with any of them and can tell which
+// ones are due for an upgrade.
+package passhash
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnknownHash is returned for hashes of no supported algorithm.
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Scheme is a password hashing algorithm with its parameters.
+type Scheme interface {
+	// Hash returns the PHC string of a new salted hash of password.
+	Hash(password string) (string, error)
+	// Verify reports whether hash, of this scheme, is a hash of password.
+	Verify(hash, password string) (bool, error)
+	// Outdated reports whether hash, of this scheme, was made with other
+	// parameters.
+	Outdated(hash string) bool
+	// MaxBytes is the longest password the scheme hashes in full, or 0.
+	MaxBytes() int
+	// owns reports whether hash was made with this scheme.
+	owns(hash string) bool
+}
+
+// Hasher hashes new passwords with its preferred scheme and verifies hashes
+// of any scheme.
+type Hasher struct {
+	preferred Scheme
+	schemes   []Scheme
+}
+
+// New returns a Hasher that prefers the given scheme.
+func New(preferred Scheme) *Hasher {
+	return &Hasher{
+		preferred: preferred,
+		schemes:   []Scheme{preferred, Bcrypt{}, Argon2id{}},
+	}
+}
+
+// Hash hashes password with the preferred scheme.
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify reports whether hash is a hash of password.
+func (h *Hasher) Verify(hash, password string) (bool, error) {
+	s := h.scheme(hash)
+	if s == nil {
+		return false, ErrUnknownHash
+	}
+	return s.Verify(hash, password)
+}
+
+// NeedsRehash reports whether hash was made with another scheme than the
+// preferred one, or with other parameters.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	if !h.preferred.owns(hash) {
+		return true
+	}
+	return h.preferred.Outdated(hash)
+}
+
+// MaxBytes is the longest password the preferred scheme hashes in full, or
+// 0 if there is no limit.
+func (h *Hasher) MaxBytes() int {
+	return h.preferred.MaxBytes()
+}
+
+func (h *Hasher) scheme(hash string) Scheme {
+	for _, s := range h.schemes {
+		if s.owns(hash) {
+			return s
+		}
+	}
+	return nil
+}
+
+// hasID reports whether the PHC string hash starts with one of ids.
+func hasID(hash string, ids ...string) bool {
+	for _, id := range ids {
+		if strings.HasPrefix(hash, "$"+id+"$") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package passhash_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/passhash"
+)
+
+// small parameters keep the tests fast
+var testArgon2id = passhash.Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2id(t *testing.T) {
+	h := passhash.New(testArgon2id)
+
+	hash, err := h.Hash("correct")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	ok, err := h.Verify(hash, "correct")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = h.Verify(hash, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(hash))
+	assert.Zero(t, h.MaxBytes())
+}
+
+func TestArgon2id_KnownHash(t *testing.T) {
+	// made by the reference implementation: echo -n password | argon2 somesalt -id -t 2 -m 16 -p 1
+	const hash = "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	ok, err := passhash.New(passhash.Bcrypt{}).Verify(hash, "password")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestHasher_VerifiesOtherSchemes(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	require.NoError(t, err)
+	h := passhash.New(testArgon2id)
+
+	ok, err := h.Verify(string(legacy), "correct")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	// bcrypt hashes are upgraded to the preferred Argon2id
+	assert.True(t, h.NeedsRehash(string(legacy)))
+
+	_, err = h.Verify("plaintext", "plaintext")
+	assert.ErrorIs(t, err, passhash.ErrUnknownHash)
+	_, err = h.Verify("$argon2id$v=19$m=1024$c29tZXNhbHQ$", "correct")
+	assert.ErrorIs(t, err, passhash.ErrUnknownHash)
+}
+
+func TestBcrypt(t *testing.T) {
+	h := passhash.New(passhash.Bcrypt{Cost: bcrypt.MinCost})
+
+	hash, err := h.Hash("correct")
+	require.NoError(t, err)
+	ok, err := h.Verify(hash, "correct")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = h.Verify(hash, "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 72, h.MaxBytes())
+
+	// a change of cost or scheme calls for a rehash
+	assert.False(t, h.NeedsRehash(hash))
+	assert.True(t, passhash.New(passhash.Bcrypt{Cost: bcrypt.MinCost + 1}).NeedsRehash(hash))
+	assert.True(t, passhash.New(testArgon2id).NeedsRehash(hash))
+	argonHash, err := passhash.New(testArgon2id).Hash("correct")
+	require.NoError(t, err)
+	assert.True(t, h.NeedsRehash(argonHash))
+	assert.True(t, passhash.New(passhash.Argon2id{Memory: 2048, Iterations: 1, Parallelism: 1}).NeedsRehash(argonHash))
+}
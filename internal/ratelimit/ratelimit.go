@@ -0,0 +1,90 @@
+// Package ratelimit limits request rates with token buckets kept in Redis,
+// so that every instance of the service shares the same budget.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// allowScript implements the generic cell rate algorithm, a token bucket that
+// only stores the time at which the bucket will be full again ("tat").
+// Times are in milliseconds and taken from the Redis server, so that clock
+// skew between instances does not matter.
+//
+// KEYS[1] bucket; ARGV[1] requests per period; ARGV[2] period; ARGV[3] burst.
+// Returns {allowed, remaining, retry after, reset after}.
+const allowScript = `
+local rate = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+
+local interval = period / rate
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - burst * interval
+if now < allow_at then
+  return {0, 0, math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+
+redis.call("SET", KEYS[1], new_tat, "PX", math.ceil(new_tat - now))
+return {1, math.floor((now - allow_at) / interval), 0, math.ceil(new_tat - now)}
+`
+
+// Limit allows Requests per Period, in bursts of up to Burst requests.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// Result is the outcome of a request against a Limit.
+type Result struct {
+	Allowed bool
+	// Remaining is how many more requests would be allowed right now.
+	Remaining int
+	// RetryAfter is how long a denied request has to wait.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again.
+	ResetAfter time.Duration
+}
+
+// RedisLimiter enforces limits with buckets stored in Redis.
+type RedisLimiter struct {
+	client *redis.Client
+}
+
+// NewRedisLimiter returns a RedisLimiter backed by client.
+func NewRedisLimiter(client *redis.Client) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// Allow takes a token from the bucket of key, if it has one.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Requests
+	}
+	vals, err := l.client.Eval(ctx, allowScript, []string{keyPrefix + key},
+		limit.Requests, limit.Period.Milliseconds(), burst).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Allowed:    vals[0] == 1,
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		ResetAfter: time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
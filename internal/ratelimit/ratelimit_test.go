@@ -0,0 +1,36 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/ratelimit"
+)
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	limiter := ratelimit.NewRedisLimiter(client)
+	limit := ratelimit.Limit{Requests: 10, Period: time.Minute}
+
+	// the burst defaults to the number of requests per period
+	mock.Regexp().ExpectEval(`GCRA|TIME`, []string{"ratelimit:login:ip:10.0.0.1"}, 10, int64(60000), 10).
+		SetVal([]interface{}{int64(1), int64(9), int64(0), int64(6000)})
+	res, err := limiter.Allow(t.Context(), "login:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.Equal(t, &ratelimit.Result{Allowed: true, Remaining: 9, ResetAfter: 6 * time.Second}, res)
+
+	mock.Regexp().ExpectEval(`TIME`, []string{"ratelimit:login:ip:10.0.0.1"}, 10, int64(60000), 3).
+		SetVal([]interface{}{int64(0), int64(0), int64(4500), int64(18000)})
+	limit.Burst = 3
+	res, err = limiter.Allow(t.Context(), "login:ip:10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 4500*time.Millisecond, res.RetryAfter)
+	assert.Equal(t, 18*time.Second, res.ResetAfter)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
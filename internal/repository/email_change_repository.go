@@ -0,0 +1,80 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// EmailChangeRepository manages hashed tokens confirming email changes.
+type EmailChangeRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmailChangeRepository constructs a new EmailChangeRepository.
+func NewEmailChangeRepository(db *sqlx.DB) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+// Create inserts a change token and sets its generated ID.
+func (r *EmailChangeRepository) Create(ctx context.Context, t *model.EmailChangeToken) error {
+	const query = `
+        INSERT INTO email_change_tokens (user_id, new_email, token_hash, expires_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+	return r.db.GetContext(ctx, &t.ID, query, t.UserID, t.NewEmail, t.TokenHash, t.ExpiresAt)
+}
+
+// GetByHash fetches a change token by its hash. Returns (nil, nil) if not found.
+func (r *EmailChangeRepository) GetByHash(ctx context.Context, hash string) (*model.EmailChangeToken, error) {
+	var t model.EmailChangeToken
+	const query = `
+        SELECT id, user_id, new_email, token_hash, expires_at, used_at, created_at
+        FROM email_change_tokens
+        WHERE token_hash = $1
+    `
+	err := r.db.GetContext(ctx, &t, query, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed atomically spends a token. It reports false if the token had
+// already been used.
+func (r *EmailChangeRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	const query = `
+        UPDATE email_change_tokens
+           SET used_at = NOW()
+         WHERE id = $1 AND used_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// InvalidateForUser spends every outstanding token of a user, cancelling
+// their pending change.
+func (r *EmailChangeRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	const query = `
+        UPDATE email_change_tokens
+           SET used_at = NOW()
+         WHERE user_id = $1 AND used_at IS NULL
+    `
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,79 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// EmailVerificationRepository manages hashed email verification tokens.
+type EmailVerificationRepository struct {
+	db *sqlx.DB
+}
+
+// NewEmailVerificationRepository constructs a new EmailVerificationRepository.
+func NewEmailVerificationRepository(db *sqlx.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// Create inserts a verification token and sets its generated ID.
+func (r *EmailVerificationRepository) Create(ctx context.Context, t *model.EmailVerificationToken) error {
+	const query = `
+        INSERT INTO email_verification_tokens (user_id, token_hash, expires_at)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `
+	return r.db.GetContext(ctx, &t.ID, query, t.UserID, t.TokenHash, t.ExpiresAt)
+}
+
+// GetByHash fetches a verification token by its hash. Returns (nil, nil) if not found.
+func (r *EmailVerificationRepository) GetByHash(ctx context.Context, hash string) (*model.EmailVerificationToken, error) {
+	var t model.EmailVerificationToken
+	const query = `
+        SELECT id, user_id, token_hash, expires_at, used_at, created_at
+        FROM email_verification_tokens
+        WHERE token_hash = $1
+    `
+	err := r.db.GetContext(ctx, &t, query, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed atomically spends a token. It reports false if the token had
+// already been used.
+func (r *EmailVerificationRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	const query = `
+        UPDATE email_verification_tokens
+           SET used_at = NOW()
+         WHERE id = $1 AND used_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// InvalidateForUser spends every outstanding token of a user.
+func (r *EmailVerificationRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	const query = `
+        UPDATE email_verification_tokens
+           SET used_at = NOW()
+         WHERE user_id = $1 AND used_at IS NULL
+    `
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
@@ -0,0 +1,316 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// subgroupsCTE resolves a group ($1) together with its subgroups, their
+// subgroups and so on, as descendants.
+const subgroupsCTE = `
+        WITH RECURSIVE descendants AS (
+            SELECT $1::BIGINT AS id
+            UNION
+            SELECT s.subgroup_id
+              FROM group_subgroups s
+              JOIN descendants d ON s.group_id = d.id
+        )
+`
+
+// memberIDsQuery selects the members of a group ($1), directly or through
+// its subgroups.
+const memberIDsQuery = subgroupsCTE + `
+        SELECT DISTINCT user_id
+          FROM group_members
+         WHERE group_id IN (SELECT id FROM descendants)
+         ORDER BY user_id
+`
+
+// lockGroupsQuery locks a group ($1) and its subgroups. New members and
+// subgroups reference the groups they join, and wait for the lock.
+const lockGroupsQuery = subgroupsCTE + `
+        SELECT id
+          FROM groups
+         WHERE id IN (SELECT id FROM descendants)
+           FOR UPDATE
+`
+
+// GroupRepository manages groups, their members, subgroups and roles.
+type GroupRepository struct {
+	db *sqlx.DB
+}
+
+// NewGroupRepository constructs a new GroupRepository.
+func NewGroupRepository(db *sqlx.DB) *GroupRepository {
+	return &GroupRepository{db: db}
+}
+
+// Create inserts a group and sets its ID and CreatedAt. It reports false if
+// a group of that name exists.
+func (r *GroupRepository) Create(ctx context.Context, g *model.Group) (bool, error) {
+	const query = `
+        INSERT INTO groups (name, description)
+        VALUES ($1, $2)
+        RETURNING id, created_at
+    `
+	err := r.db.QueryRowxContext(ctx, query, g.Name, g.Description).Scan(&g.ID, &g.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Get fetches a group by ID, without its members, subgroups and roles.
+// Returns (nil, nil) if not found.
+func (r *GroupRepository) Get(ctx context.Context, id int64) (*model.Group, error) {
+	var g model.Group
+	const query = `
+        SELECT id, name, description, created_at
+          FROM groups
+         WHERE id = $1
+    `
+	if err := r.db.GetContext(ctx, &g, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &g, nil
+}
+
+// List returns every group, sorted by name.
+func (r *GroupRepository) List(ctx context.Context) ([]model.Group, error) {
+	groups := []model.Group{}
+	const query = `
+        SELECT id, name, description, created_at
+          FROM groups
+         ORDER BY name
+    `
+	if err := r.db.SelectContext(ctx, &groups, query); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Rename renames a group. It reports false if another group has the name,
+// and returns sql.ErrNoRows if there is no group with id.
+func (r *GroupRepository) Rename(ctx context.Context, id int64, name string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE groups SET name = $2 WHERE id = $1`, id, name)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, sql.ErrNoRows
+	}
+	return true, nil
+}
+
+// Delete deletes a group, with its memberships and role grants, and returns
+// the IDs of the users that were its members, directly or through its
+// subgroups, in ascending order. It reports false if that would leave
+// nobody holding the admin role, and returns sql.ErrNoRows if there is no
+// group with id.
+func (r *GroupRepository) Delete(ctx context.Context, id int64) ([]int64, bool, error) {
+	return r.keepAdmin(ctx, id, `DELETE FROM groups WHERE id = $1`, id)
+}
+
+// Members returns the users that are direct members of a group, sorted by
+// email.
+func (r *GroupRepository) Members(ctx context.Context, id int64) ([]model.GroupMember, error) {
+	members := []model.GroupMember{}
+	const query = `
+        SELECT gm.user_id, u.email, gm.created_at
+          FROM group_members gm
+          JOIN users u ON u.id = gm.user_id
+         WHERE gm.group_id = $1
+         ORDER BY u.email
+    `
+	if err := r.db.SelectContext(ctx, &members, query, id); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember makes a user a member of a group. Adding a member again is not
+// an error.
+func (r *GroupRepository) AddMember(ctx context.Context, groupID, userID int64) error {
+	const query = `
+        INSERT INTO group_members (group_id, user_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `
+	_, err := r.db.ExecContext(ctx, query, groupID, userID)
+	return err
+}
+
+// RemoveMember removes a user from a group. It reports false if that would
+// leave nobody holding the admin role, and returns sql.ErrNoRows if they
+// were not a direct member.
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, userID int64) (bool, error) {
+	_, removed, err := r.keepAdmin(ctx, 0, `DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`,
+		groupID, userID)
+	return removed, err
+}
+
+// Subgroups returns the direct subgroups of a group, sorted by name.
+func (r *GroupRepository) Subgroups(ctx context.Context, id int64) ([]model.Group, error) {
+	groups := []model.Group{}
+	const query = `
+        SELECT g.id, g.name, g.description, g.created_at
+          FROM group_subgroups s
+          JOIN groups g ON g.id = s.subgroup_id
+         WHERE s.group_id = $1
+         ORDER BY g.name
+    `
+	if err := r.db.SelectContext(ctx, &groups, query, id); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// AddSubgroup makes subgroupID a subgroup of groupID. It reports false if
+// that would make a group its own subgroup, directly or through others.
+// Additions of subgroups are serialized, so that concurrent ones cannot
+// close a cycle either. Adding a subgroup again is not an error.
+func (r *GroupRepository) AddSubgroup(ctx context.Context, groupID, subgroupID int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `LOCK TABLE group_subgroups IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return false, err
+	}
+	var cycle bool
+	const check = subgroupsCTE + `
+        SELECT EXISTS (SELECT 1 FROM descendants WHERE id = $2)
+    `
+	if err = tx.GetContext(ctx, &cycle, check, subgroupID, groupID); err != nil {
+		return false, err
+	}
+	if cycle {
+		return false, nil
+	}
+	const insert = `
+        INSERT INTO group_subgroups (group_id, subgroup_id)
+        VALUES ($1, $2)
+        ON CONFLICT DO NOTHING
+    `
+	if _, err = tx.ExecContext(ctx, insert, groupID, subgroupID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// RemoveSubgroup removes subgroupID from the subgroups of groupID and
+// returns the IDs of the users that are members of subgroupID, as Delete
+// does. It reports false if that would leave nobody holding the admin role,
+// and returns sql.ErrNoRows if it was not a direct subgroup.
+func (r *GroupRepository) RemoveSubgroup(ctx context.Context, groupID, subgroupID int64) ([]int64, bool, error) {
+	const query = `DELETE FROM group_subgroups WHERE group_id = $1 AND subgroup_id = $2`
+	return r.keepAdmin(ctx, subgroupID, query, groupID, subgroupID)
+}
+
+// Roles returns the names of the roles granted to a group, sorted by name.
+func (r *GroupRepository) Roles(ctx context.Context, id int64) ([]string, error) {
+	roles := []string{}
+	const query = `
+        SELECT r.name
+          FROM group_roles gr
+          JOIN roles r ON r.id = gr.role_id
+         WHERE gr.group_id = $1
+         ORDER BY r.name
+    `
+	if err := r.db.SelectContext(ctx, &roles, query, id); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// GrantRole grants the role named role to a group. Granting a role the
+// group already holds is not an error.
+func (r *GroupRepository) GrantRole(ctx context.Context, groupID int64, role string) error {
+	const query = `
+        INSERT INTO group_roles (group_id, role_id)
+        SELECT $1, id FROM roles WHERE name = $2
+        ON CONFLICT DO NOTHING
+    `
+	_, err := r.db.ExecContext(ctx, query, groupID, role)
+	return err
+}
+
+// RevokeRole takes the role named role from a group and returns the IDs of
+// the users that are its members, as Delete does. It reports false if that
+// would leave nobody holding the admin role, and returns sql.ErrNoRows if
+// the group did not hold it.
+func (r *GroupRepository) RevokeRole(ctx context.Context, groupID int64, role string) ([]int64, bool, error) {
+	const query = `
+        DELETE FROM group_roles
+         WHERE group_id = $1
+           AND role_id = (SELECT id FROM roles WHERE name = $2)
+    `
+	return r.keepAdmin(ctx, groupID, query, groupID, role)
+}
+
+// keepAdmin runs a statement in a transaction, which it rolls back and
+// reports false if the statement leaves nobody holding the admin role, as
+// counted by keepHolder. It returns sql.ErrNoRows if the statement affected
+// no row. Unless membersOf is 0, it also returns the IDs of the members of
+// that group as of the statement: the group and its subgroups are locked,
+// so that no member joins them until the transaction ends.
+func (r *GroupRepository) keepAdmin(ctx context.Context, membersOf int64, query string, args ...any) ([]int64, bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var members []int64
+	kept, err := keepHolder(ctx, tx, model.RoleAdmin, func() error {
+		if membersOf != 0 {
+			if _, err := tx.ExecContext(ctx, lockGroupsQuery, membersOf); err != nil {
+				return err
+			}
+			members = []int64{}
+			if err := tx.SelectContext(ctx, &members, memberIDsQuery, membersOf); err != nil {
+				return err
+			}
+		}
+		res, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if err != nil || !kept {
+		return nil, false, err
+	}
+	return members, true, tx.Commit()
+}
@@ -0,0 +1,175 @@
+package repository_test
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestGroupRepository_Create(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+	g := &model.Group{Name: "editors", Description: "Editorial team"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO groups (name, description) VALUES ($1, $2) RETURNING id, created_at`)).
+		WithArgs("editors", "Editorial team").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	created, err := repo.Create(t.Context(), g)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(3), g.ID)
+
+	// the name is taken
+	mock.ExpectQuery(`INSERT INTO groups`).WillReturnError(&pq.Error{Code: "23505"})
+	created, err = repo.Create(t.Context(), &model.Group{Name: "editors"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_Rename(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+	query := regexp.QuoteMeta(`UPDATE groups SET name = $2 WHERE id = $1`)
+
+	mock.ExpectExec(query).WithArgs(int64(3), "desk").WillReturnResult(sqlmock.NewResult(0, 1))
+	renamed, err := repo.Rename(t.Context(), 3, "desk")
+	require.NoError(t, err)
+	assert.True(t, renamed)
+
+	mock.ExpectExec(query).WithArgs(int64(3), "taken").WillReturnError(&pq.Error{Code: "23505"})
+	renamed, err = repo.Rename(t.Context(), 3, "taken")
+	require.NoError(t, err)
+	assert.False(t, renamed)
+
+	mock.ExpectExec(query).WithArgs(int64(4), "desk").WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = repo.Rename(t.Context(), 4, "desk")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expectGroupMembers expects the group id and its subgroups to be locked
+// and the IDs of their members to be read.
+func expectGroupMembers(mock sqlmock.Sqlmock, id int64, members ...int64) {
+	mock.ExpectExec(`WITH RECURSIVE descendants AS \( SELECT \$1::BIGINT AS id UNION SELECT s\.subgroup_id .*\) ` +
+		regexp.QuoteMeta(`SELECT id FROM groups WHERE id IN (SELECT id FROM descendants) FOR UPDATE`)).
+		WithArgs(id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	rows := sqlmock.NewRows([]string{"user_id"})
+	for _, m := range members {
+		rows.AddRow(m)
+	}
+	mock.ExpectQuery(`WITH RECURSIVE descendants AS \( SELECT \$1::BIGINT AS id UNION SELECT s\.subgroup_id .*\) ` +
+		regexp.QuoteMeta(`SELECT DISTINCT user_id FROM group_members WHERE group_id IN (SELECT id FROM descendants) ORDER BY user_id`)).
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+func TestGroupRepository_Delete(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// the members are read in the transaction that deletes the group
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	expectGroupMembers(mock, 3, 7, 8)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM groups WHERE id = $1`)).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectCommit()
+
+	members, deleted, err := repo.Delete(t.Context(), 3)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, []int64{7, 8}, members)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_AddSubgroup(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+	lock := regexp.QuoteMeta(`LOCK TABLE group_subgroups IN SHARE ROW EXCLUSIVE MODE`)
+	check := `WITH RECURSIVE descendants AS \(.*\) ` +
+		regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM descendants WHERE id = $2)`)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(lock).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(check).WithArgs(int64(5), int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO group_subgroups (group_id, subgroup_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+	)).
+		WithArgs(int64(3), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	added, err := repo.AddSubgroup(t.Context(), 3, 5)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	// 3 is already below 5, so 5 cannot go below 3
+	mock.ExpectBegin()
+	mock.ExpectExec(lock).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(check).WithArgs(int64(3), int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	added, err = repo.AddSubgroup(t.Context(), 5, 3)
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_RevokeRole(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT id FROM roles WHERE name = $1 FOR UPDATE`)).
+		WithArgs("admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	expectGroupMembers(mock, 3, 7)
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM group_roles WHERE group_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)`,
+	)).
+		WithArgs(int64(3), "editor").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, _, err := repo.RevokeRole(t.Context(), 3, "editor")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGroupRepository_RemoveMember_KeepsLastAdmin(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewGroupRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`)).
+		WithArgs(int64(3), int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, false)
+	mock.ExpectRollback()
+
+	removed, err := repo.RemoveMember(t.Context(), 3, 7)
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
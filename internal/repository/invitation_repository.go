@@ -0,0 +1,119 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// invitationColumns selects a model.Invitation from org_invitations i,
+// joined with roles r.
+const invitationColumns = `i.id, i.org_id, i.email, r.name AS role, i.token_hash, i.invited_by,
+               i.expires_at, i.accepted_at, i.revoked_at, i.created_at`
+
+// InvitationRepository manages hashed invitations to organizations.
+type InvitationRepository struct {
+	db *sqlx.DB
+}
+
+// NewInvitationRepository constructs a new InvitationRepository.
+func NewInvitationRepository(db *sqlx.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// Create inserts an invitation and sets its generated ID and CreatedAt.
+func (r *InvitationRepository) Create(ctx context.Context, inv *model.Invitation) error {
+	const query = `
+        INSERT INTO org_invitations (org_id, email, role_id, token_hash, invited_by, expires_at)
+        SELECT $1, $2, id, $4, $5, $6 FROM roles WHERE name = $3
+        RETURNING id, created_at
+    `
+	return r.db.QueryRowxContext(ctx, query, inv.OrgID, inv.Email, inv.Role, inv.TokenHash, inv.InvitedBy, inv.ExpiresAt).
+		Scan(&inv.ID, &inv.CreatedAt)
+}
+
+// GetByHash fetches an invitation by the hash of its token. Returns
+// (nil, nil) if not found.
+func (r *InvitationRepository) GetByHash(ctx context.Context, hash string) (*model.Invitation, error) {
+	var inv model.Invitation
+	const query = `
+        SELECT ` + invitationColumns + `
+          FROM org_invitations i
+          JOIN roles r ON r.id = i.role_id
+         WHERE i.token_hash = $1
+    `
+	if err := r.db.GetContext(ctx, &inv, query, hash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// ListPending returns the invitations of an organization that can still be
+// accepted, newest first.
+func (r *InvitationRepository) ListPending(ctx context.Context, orgID int64) ([]model.Invitation, error) {
+	invitations := []model.Invitation{}
+	const query = `
+        SELECT ` + invitationColumns + `
+          FROM org_invitations i
+          JOIN roles r ON r.id = i.role_id
+         WHERE i.org_id = $1
+           AND i.accepted_at IS NULL AND i.revoked_at IS NULL AND i.expires_at > NOW()
+         ORDER BY i.created_at DESC
+    `
+	if err := r.db.SelectContext(ctx, &invitations, query, orgID); err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// Revoke withdraws a pending invitation of an organization. It reports
+// false if there is none with id.
+func (r *InvitationRepository) Revoke(ctx context.Context, orgID, id int64) (bool, error) {
+	const query = `
+        UPDATE org_invitations
+           SET revoked_at = NOW()
+         WHERE id = $1 AND org_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, id, orgID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// MarkAccepted atomically spends an invitation. It reports false if it had
+// already been accepted or revoked.
+func (r *InvitationRepository) MarkAccepted(ctx context.Context, id int64) (bool, error) {
+	const query = `
+        UPDATE org_invitations
+           SET accepted_at = NOW()
+         WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Release restores an invitation spent by MarkAccepted, for an acceptance
+// that failed afterwards.
+func (r *InvitationRepository) Release(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE org_invitations SET accepted_at = NULL WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,100 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestInvitationRepository_Create(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewInvitationRepository(sqlx.NewDb(db, "sqlmock"))
+	inviter := int64(7)
+	inv := &model.Invitation{
+		OrgID: 4, Email: "new@x.com", Role: model.RoleOrgMember, TokenHash: "hash",
+		InvitedBy: &inviter, ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO org_invitations (org_id, email, role_id, token_hash, invited_by, expires_at) `+
+			`SELECT $1, $2, id, $4, $5, $6 FROM roles WHERE name = $3 RETURNING id, created_at`,
+	)).
+		WithArgs(int64(4), "new@x.com", model.RoleOrgMember, "hash", &inviter, inv.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, time.Now()))
+
+	require.NoError(t, repo.Create(t.Context(), inv))
+	assert.Equal(t, int64(9), inv.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_GetByHash_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewInvitationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`SELECT i\.id, .* FROM org_invitations i JOIN roles r ON r\.id = i\.role_id ` +
+		regexp.QuoteMeta(`WHERE i.token_hash = $1`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	inv, err := repo.GetByHash(t.Context(), "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, inv)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_MarkAccepted(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewInvitationRepository(sqlx.NewDb(db, "sqlmock"))
+	query := regexp.QuoteMeta(
+		`UPDATE org_invitations SET accepted_at = NOW() WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL`,
+	)
+
+	mock.ExpectExec(query).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 1))
+	accepted, err := repo.MarkAccepted(t.Context(), 9)
+	require.NoError(t, err)
+	assert.True(t, accepted)
+
+	// already spent
+	mock.ExpectExec(query).WithArgs(int64(9)).WillReturnResult(sqlmock.NewResult(0, 0))
+	accepted, err = repo.MarkAccepted(t.Context(), 9)
+	require.NoError(t, err)
+	assert.False(t, accepted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_Revoke(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewInvitationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE org_invitations SET revoked_at = NOW() `+
+			`WHERE id = $1 AND org_id = $2 AND accepted_at IS NULL AND revoked_at IS NULL`,
+	)).
+		WithArgs(int64(9), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	revoked, err := repo.Revoke(t.Context(), 4, 9)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInvitationRepository_Release(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewInvitationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE org_invitations SET accepted_at = NULL WHERE id = $1`)).
+		WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Release(t.Context(), 9))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,157 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// MFARepository manages users' TOTP secrets and hashed recovery codes.
+type MFARepository struct {
+	db *sqlx.DB
+}
+
+// NewMFARepository constructs a new MFARepository.
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// Get fetches the second factor of a user. Returns (nil, nil) if not found.
+func (r *MFARepository) Get(ctx context.Context, userID int64) (*model.UserMFA, error) {
+	var m model.UserMFA
+	const query = `
+        SELECT user_id, totp_secret, enabled_at, last_totp_step, created_at
+        FROM user_mfa
+        WHERE user_id = $1
+    `
+	err := r.db.GetContext(ctx, &m, query, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SavePending stores a new, not yet enabled TOTP secret for a user,
+// replacing an earlier pending one. An enabled secret is left untouched.
+func (r *MFARepository) SavePending(ctx context.Context, userID int64, secret string) error {
+	const query = `
+        INSERT INTO user_mfa (user_id, totp_secret)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE
+           SET totp_secret = EXCLUDED.totp_secret, last_totp_step = 0, created_at = NOW()
+         WHERE user_mfa.enabled_at IS NULL
+    `
+	_, err := r.db.ExecContext(ctx, query, userID, secret)
+	return err
+}
+
+// Enable turns on a user's pending TOTP secret, recording step as used, and
+// replaces their recovery codes with codeHashes. It reports false if there
+// was no pending secret to enable.
+func (r *MFARepository) Enable(ctx context.Context, userID, step int64, codeHashes []string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	const enable = `
+        UPDATE user_mfa
+           SET enabled_at = NOW(), last_totp_step = $2
+         WHERE user_id = $1 AND enabled_at IS NULL
+    `
+	res, err := tx.ExecContext(ctx, enable, userID, step)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err = replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// ClaimTOTPStep atomically records step as the last used time step. It
+// reports false if that or a later step was used before, so each code is
+// accepted at most once.
+func (r *MFARepository) ClaimTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	const query = `
+        UPDATE user_mfa
+           SET last_totp_step = $2
+         WHERE user_id = $1 AND last_totp_step < $2
+    `
+	res, err := r.db.ExecContext(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// UseRecoveryCode atomically spends the recovery code with codeHash. It
+// reports false if the user has no such unused code.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	const query = `
+        UPDATE mfa_recovery_codes
+           SET used_at = NOW()
+         WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// Delete removes a user's TOTP secret and recovery codes.
+func (r *MFARepository) Delete(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID int64, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	const insert = `
+        INSERT INTO mfa_recovery_codes (user_id, code_hash)
+        VALUES ($1, $2)
+    `
+	for _, h := range codeHashes {
+		if _, err := tx.ExecContext(ctx, insert, userID, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestMFA_Get(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`SELECT user_id, totp_secret, enabled_at, last_totp_step, created_at FROM user_mfa WHERE user_id = $1`,
+	)
+	now := time.Now()
+	mock.ExpectQuery(query).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "totp_secret", "enabled_at", "last_totp_step", "created_at"}).
+			AddRow(7, "enc", now, 42, now))
+	mock.ExpectQuery(query).
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+
+	m, err := repo.Get(t.Context(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "enc", m.TOTPSecret)
+	assert.NotNil(t, m.EnabledAt)
+	assert.Equal(t, int64(42), m.LastTOTPStep)
+
+	m, err = repo.Get(t.Context(), 8)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFA_SavePending(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO user_mfa (user_id, totp_secret)`)).
+		WithArgs(int64(7), "enc").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SavePending(t.Context(), 7, "enc"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFA_Enable(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	enable := regexp.QuoteMeta(
+		`UPDATE user_mfa SET enabled_at = NOW(), last_totp_step = $2 WHERE user_id = $1 AND enabled_at IS NULL`,
+	)
+	mock.ExpectBegin()
+	mock.ExpectExec(enable).WithArgs(int64(7), int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`)).
+		WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 0))
+	insert := regexp.QuoteMeta(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`)
+	mock.ExpectExec(insert).WithArgs(int64(7), "h1").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(insert).WithArgs(int64(7), "h2").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	// nothing pending, e.g. already enabled
+	mock.ExpectBegin()
+	mock.ExpectExec(enable).WithArgs(int64(7), int64(43)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	ok, err := repo.Enable(t.Context(), 7, 42, []string{"h1", "h2"})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.Enable(t.Context(), 7, 43, []string{"h3"})
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFA_ClaimTOTPStep(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`UPDATE user_mfa SET last_totp_step = $2 WHERE user_id = $1 AND last_totp_step < $2`,
+	)
+	mock.ExpectExec(query).WithArgs(int64(7), int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(int64(7), int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.ClaimTOTPStep(t.Context(), 7, 42)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the same code again
+	ok, err = repo.ClaimTOTPStep(t.Context(), 7, 42)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFA_UseRecoveryCode(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`UPDATE mfa_recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+	)
+	mock.ExpectExec(query).WithArgs(int64(7), "h").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(int64(7), "h").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.UseRecoveryCode(t.Context(), 7, "h")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.UseRecoveryCode(t.Context(), 7, "h")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFA_Delete(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewMFARepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`)).
+		WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM user_mfa WHERE user_id = $1`)).
+		WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.Delete(t.Context(), 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
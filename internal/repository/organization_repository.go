@@ -0,0 +1,222 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// OrganizationRepository manages organizations and their members.
+type OrganizationRepository struct {
+	db *sqlx.DB
+}
+
+// NewOrganizationRepository constructs a new OrganizationRepository.
+func NewOrganizationRepository(db *sqlx.DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+// Create inserts org, with ownerID as its first member holding role, and
+// sets its ID and CreatedAt. It reports false if the slug is taken.
+func (r *OrganizationRepository) Create(ctx context.Context, org *model.Organization, ownerID int64, role string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	const insert = `
+        INSERT INTO organizations (slug, name)
+        VALUES ($1, $2)
+        RETURNING id, created_at
+    `
+	if err = tx.QueryRowxContext(ctx, insert, org.Slug, org.Name).Scan(&org.ID, &org.CreatedAt); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	const member = `
+        INSERT INTO memberships (org_id, user_id, role_id)
+        SELECT $1, $2, id FROM roles WHERE name = $3
+    `
+	if _, err = tx.ExecContext(ctx, member, org.ID, ownerID, role); err != nil {
+		return false, err
+	}
+	org.Role = role
+	return true, tx.Commit()
+}
+
+// Get returns the organization with id. Returns (nil, nil) if not found.
+func (r *OrganizationRepository) Get(ctx context.Context, id int64) (*model.Organization, error) {
+	var org model.Organization
+	const query = `
+        SELECT id, slug, name, created_at
+          FROM organizations
+         WHERE id = $1
+    `
+	if err := r.db.GetContext(ctx, &org, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ListForUser returns the organizations a user is a member of, with the
+// role they hold in each, sorted by name.
+func (r *OrganizationRepository) ListForUser(ctx context.Context, userID int64) ([]model.Organization, error) {
+	orgs := []model.Organization{}
+	const query = `
+        SELECT o.id, o.slug, o.name, o.created_at, r.name AS role
+          FROM memberships m
+          JOIN organizations o ON o.id = m.org_id
+          JOIN roles r ON r.id = m.role_id
+         WHERE m.user_id = $1
+         ORDER BY o.name
+    `
+	if err := r.db.SelectContext(ctx, &orgs, query, userID); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// membershipColumns selects a model.Membership from memberships m, joined
+// with users u and roles r.
+const membershipColumns = `m.org_id, m.user_id, u.email, r.name AS role, m.created_at`
+
+// Membership returns the membership of a user in an organization. Returns
+// (nil, nil) if they are not a member.
+func (r *OrganizationRepository) Membership(ctx context.Context, orgID, userID int64) (*model.Membership, error) {
+	var m model.Membership
+	const query = `
+        SELECT ` + membershipColumns + `
+          FROM memberships m
+          JOIN users u ON u.id = m.user_id
+          JOIN roles r ON r.id = m.role_id
+         WHERE m.org_id = $1 AND m.user_id = $2
+    `
+	if err := r.db.GetContext(ctx, &m, query, orgID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMembers returns the members of an organization, sorted by email.
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error) {
+	members := []model.Membership{}
+	const query = `
+        SELECT ` + membershipColumns + `
+          FROM memberships m
+          JOIN users u ON u.id = m.user_id
+          JOIN roles r ON r.id = m.role_id
+         WHERE m.org_id = $1
+         ORDER BY u.email
+    `
+	if err := r.db.SelectContext(ctx, &members, query, orgID); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// AddMember makes a user a member of an organization holding role. Adding
+// an existing member leaves their role as it is.
+func (r *OrganizationRepository) AddMember(ctx context.Context, orgID, userID int64, role string) error {
+	const query = `
+        INSERT INTO memberships (org_id, user_id, role_id)
+        SELECT $1, $2, id FROM roles WHERE name = $3
+        ON CONFLICT DO NOTHING
+    `
+	_, err := r.db.ExecContext(ctx, query, orgID, userID, role)
+	return err
+}
+
+// SetMemberRole changes the role a member holds in an organization. It
+// reports false if the user is not a member, or is its last admin and
+// role would demote them.
+func (r *OrganizationRepository) SetMemberRole(ctx context.Context, orgID, userID int64, role string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if role != model.RoleOrgAdmin {
+		last, err := lastOrgAdmin(ctx, tx, orgID, userID)
+		if err != nil || last {
+			return false, err
+		}
+	}
+	const query = `
+        UPDATE memberships
+           SET role_id = (SELECT id FROM roles WHERE name = $3)
+         WHERE org_id = $1 AND user_id = $2
+    `
+	res, err := tx.ExecContext(ctx, query, orgID, userID, role)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
+// RemoveMember removes a user from an organization. It reports false if
+// they are not a member, or are its last admin.
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	last, err := lastOrgAdmin(ctx, tx, orgID, userID)
+	if err != nil || last {
+		return false, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM memberships WHERE org_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	return true, tx.Commit()
+}
+
+// lastOrgAdmin locks an organization, which serializes changes to its
+// members, and reports whether userID is its only admin.
+func lastOrgAdmin(ctx context.Context, tx *sqlx.Tx, orgID, userID int64) (bool, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM organizations WHERE id = $1 FOR UPDATE`, orgID); err != nil {
+		return false, err
+	}
+	var last bool
+	const query = `
+        SELECT COALESCE(BOOL_AND(m.user_id = $2), FALSE)
+          FROM memberships m
+          JOIN roles r ON r.id = m.role_id
+         WHERE m.org_id = $1 AND r.name = $3
+    `
+	if err := tx.GetContext(ctx, &last, query, orgID, userID, model.RoleOrgAdmin); err != nil {
+		return false, err
+	}
+	return last, nil
+}
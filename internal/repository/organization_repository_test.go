@@ -0,0 +1,138 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestOrganizationRepository_Create(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+	org := &model.Organization{Slug: "acme", Name: "Acme"}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO organizations (slug, name) VALUES ($1, $2) RETURNING id, created_at`)).
+		WithArgs("acme", "Acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(4, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO memberships (org_id, user_id, role_id) SELECT $1, $2, id FROM roles WHERE name = $3`,
+	)).
+		WithArgs(int64(4), int64(7), model.RoleOrgAdmin).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.Create(t.Context(), org, 7, model.RoleOrgAdmin)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(4), org.ID)
+	assert.Equal(t, model.RoleOrgAdmin, org.Role)
+
+	// the slug is taken
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO organizations`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	created, err = repo.Create(t.Context(), &model.Organization{Slug: "acme"}, 8, model.RoleOrgAdmin)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_ListForUser(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT o.id, o.slug, o.name, o.created_at, r.name AS role FROM memberships m ` +
+			`JOIN organizations o ON o.id = m.org_id JOIN roles r ON r.id = m.role_id WHERE m.user_id = $1 ORDER BY o.name`,
+	)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "name", "created_at", "role"}).
+			AddRow(4, "acme", "Acme", time.Now(), model.RoleOrgAdmin))
+
+	orgs, err := repo.ListForUser(t.Context(), 7)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, model.RoleOrgAdmin, orgs[0].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_Membership_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`SELECT m\.org_id, m\.user_id, u\.email, r\.name AS role, m\.created_at FROM memberships m .* `+
+		regexp.QuoteMeta(`WHERE m.org_id = $1 AND m.user_id = $2`)).
+		WithArgs(int64(4), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"org_id"}))
+
+	m, err := repo.Membership(t.Context(), 4, 7)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_SetMemberRole(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT id FROM organizations WHERE id = $1 FOR UPDATE`)).
+		WithArgs(int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(BOOL_AND(m.user_id = $2), FALSE)`)).
+		WithArgs(int64(4), int64(7), model.RoleOrgAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE memberships SET role_id = (SELECT id FROM roles WHERE name = $3) WHERE org_id = $1 AND user_id = $2`,
+	)).
+		WithArgs(int64(4), int64(7), model.RoleOrgMember).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updated, err := repo.SetMemberRole(t.Context(), 4, 7, model.RoleOrgMember)
+	require.NoError(t, err)
+	assert.True(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_RemoveMember_KeepsLastAdmin(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM organizations`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COALESCE\(BOOL_AND`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(true))
+	mock.ExpectRollback()
+
+	removed, err := repo.RemoveMember(t.Context(), 4, 7)
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrganizationRepository_AddMember(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewOrganizationRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO memberships (org_id, user_id, role_id) SELECT $1, $2, id FROM roles WHERE name = $3 `+
+			`ON CONFLICT DO NOTHING`,
+	)).
+		WithArgs(int64(4), int64(8), model.RoleOrgMember).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.AddMember(t.Context(), 4, 8, model.RoleOrgMember))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// PasswordHistoryRepository remembers the password hashes users had, so
+// that recent passwords are not chosen again.
+type PasswordHistoryRepository struct {
+	db *sqlx.DB
+}
+
+// NewPasswordHistoryRepository constructs a new PasswordHistoryRepository.
+func NewPasswordHistoryRepository(db *sqlx.DB) *PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+// Add records hash as the latest password hash of a user.
+func (r *PasswordHistoryRepository) Add(ctx context.Context, userID int64, hash string) error {
+	const query = `
+        INSERT INTO password_history (user_id, password_hash)
+        VALUES ($1, $2)
+    `
+	_, err := r.db.ExecContext(ctx, query, userID, hash)
+	return err
+}
+
+// Recent returns the latest n password hashes of a user, newest first.
+func (r *PasswordHistoryRepository) Recent(ctx context.Context, userID int64, n int) ([]string, error) {
+	hashes := []string{}
+	const query = `
+        SELECT password_hash
+        FROM password_history
+        WHERE user_id = $1
+        ORDER BY id DESC
+        LIMIT $2
+    `
+	if err := r.db.SelectContext(ctx, &hashes, query, userID, n); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// Trim forgets all but the latest keep password hashes of a user.
+func (r *PasswordHistoryRepository) Trim(ctx context.Context, userID int64, keep int) error {
+	const query = `
+        DELETE FROM password_history
+         WHERE user_id = $1
+           AND id NOT IN (
+               SELECT id FROM password_history
+                WHERE user_id = $1
+                ORDER BY id DESC
+                LIMIT $2
+           )
+    `
+	_, err := r.db.ExecContext(ctx, query, userID, keep)
+	return err
+}
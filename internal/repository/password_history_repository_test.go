@@ -0,0 +1,52 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestPasswordHistory_Add(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordHistoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)`)).
+		WithArgs(int64(7), "hash").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	assert.NoError(t, repo.Add(t.Context(), 7, "hash"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordHistory_Recent(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordHistoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT password_hash FROM password_history WHERE user_id = $1 ORDER BY id DESC LIMIT $2`,
+	)).
+		WithArgs(int64(7), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow("h2").AddRow("h1"))
+
+	hashes, err := repo.Recent(t.Context(), 7, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"h2", "h1"}, hashes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordHistory_Trim(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordHistoryRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM password_history WHERE user_id = $1 AND id NOT IN (`)).
+		WithArgs(int64(7), 5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.Trim(t.Context(), 7, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,95 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestPasswordReset_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewPasswordResetRepository(sqlx.NewDb(db, "sqlmock"))
+
+	pr := &model.PasswordResetToken{UserID: 7, TokenHash: "h", ExpiresAt: time.Now()}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id`,
+	)).
+		WithArgs(pr.UserID, pr.TokenHash, pr.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+
+	err = repo.Create(t.Context(), pr)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), pr.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordReset_GetByHash(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordResetRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`SELECT id, user_id, token_hash, expires_at, used_at, created_at FROM password_reset_tokens WHERE token_hash = $1`,
+	)
+	now := time.Now()
+	mock.ExpectQuery(query).
+		WithArgs("h").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "token_hash", "expires_at", "used_at", "created_at"}).
+			AddRow(1, 7, "h", now, nil, now))
+	mock.ExpectQuery(query).
+		WithArgs("nope").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	pr, err := repo.GetByHash(t.Context(), "h")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), pr.UserID)
+	assert.Nil(t, pr.UsedAt)
+
+	pr, err = repo.GetByHash(t.Context(), "nope")
+	assert.NoError(t, err)
+	assert.Nil(t, pr)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordReset_MarkUsed(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordResetRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`UPDATE password_reset_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`,
+	)
+	mock.ExpectExec(query).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.MarkUsed(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// single use
+	claimed, err = repo.MarkUsed(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPasswordReset_InvalidateForUser(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewPasswordResetRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+	)).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.InvalidateForUser(t.Context(), 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,90 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// RefreshTokenRepository manages hashed refresh tokens.
+type RefreshTokenRepository struct {
+	db *sqlx.DB
+}
+
+// NewRefreshTokenRepository constructs a new RefreshTokenRepository.
+func NewRefreshTokenRepository(db *sqlx.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create inserts a refresh token and sets its generated ID.
+func (r *RefreshTokenRepository) Create(ctx context.Context, t *model.RefreshToken) error {
+	const query = `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, org_id)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+	return r.db.GetContext(ctx, &t.ID, query, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, t.OrgID)
+}
+
+// GetByHash fetches a refresh token by its hash. Returns (nil, nil) if not found.
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var t model.RefreshToken
+	const query = `
+        SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at, org_id
+        FROM refresh_tokens
+        WHERE token_hash = $1
+    `
+	err := r.db.GetContext(ctx, &t, query, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// MarkUsed atomically flags a token as spent. It reports false if the token
+// had already been used or revoked, e.g. by a concurrent refresh.
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	const query = `
+        UPDATE refresh_tokens
+           SET used_at = NOW()
+         WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+    `
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// RevokeFamily revokes every token descended from the same login.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	const query = `
+        UPDATE refresh_tokens
+           SET revoked_at = NOW()
+         WHERE family_id = $1 AND revoked_at IS NULL
+    `
+	_, err := r.db.ExecContext(ctx, query, familyID)
+	return err
+}
+
+// RevokeAllForUser revokes every outstanding refresh token of a user.
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	const query = `
+        UPDATE refresh_tokens
+           SET revoked_at = NOW()
+         WHERE user_id = $1 AND revoked_at IS NULL
+    `
+	_, err := r.db.ExecContext(ctx, query, userID)
+	return err
+}
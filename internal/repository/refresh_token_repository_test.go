@@ -0,0 +1,84 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestRefreshToken_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewRefreshTokenRepository(sqlx.NewDb(db, "sqlmock"))
+
+	rt := &model.RefreshToken{UserID: 7, FamilyID: "fam", TokenHash: "h", ExpiresAt: time.Now()}
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, org_id) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+	)).
+		WithArgs(rt.UserID, rt.FamilyID, rt.TokenHash, rt.ExpiresAt, rt.OrgID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	err = repo.Create(t.Context(), rt)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), rt.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshToken_GetByHash_NotFound(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRefreshTokenRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at, org_id FROM refresh_tokens WHERE token_hash = $1`,
+	)).
+		WithArgs("nope").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	rt, err := repo.GetByHash(t.Context(), "nope")
+	assert.NoError(t, err)
+	assert.Nil(t, rt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshToken_MarkUsed(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRefreshTokenRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(
+		`UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+	)
+	mock.ExpectExec(query).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	claimed, err := repo.MarkUsed(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// second use loses
+	claimed, err = repo.MarkUsed(t.Context(), 1)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshToken_RevokeFamily(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRefreshTokenRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`,
+	)).
+		WithArgs("fam").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.RevokeFamily(t.Context(), "fam"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,307 @@
+//nolint:nilnil
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// grantedRolesCTE resolves the roles a user ($1) holds, globally, within an
+// organization ($2) and through the groups they are a member of, together
+// with the roles they inherit from. Members of a subgroup are members of
+// its groups too. UNION stops at groups and roles already seen, so cycles
+// cannot loop.
+const grantedRolesCTE = `
+        WITH RECURSIVE user_groups AS (
+            SELECT group_id FROM group_members WHERE user_id = $1
+            UNION
+            SELECT s.group_id
+              FROM group_subgroups s
+              JOIN user_groups ug ON s.subgroup_id = ug.group_id
+        ), granted AS (
+            SELECT r.id, r.name, r.parent_id
+              FROM roles r
+             WHERE r.id IN (
+                   SELECT role_id FROM user_roles WHERE user_id = $1
+                   UNION
+                   SELECT role_id FROM memberships WHERE user_id = $1 AND org_id = $2
+                   UNION
+                   SELECT role_id FROM group_roles WHERE group_id IN (SELECT group_id FROM user_groups)
+             )
+            UNION
+            SELECT p.id, p.name, p.parent_id
+              FROM roles p
+              JOIN granted g ON p.id = g.parent_id
+        )
+`
+
+// heldQuery reports whether any user holds the role named $1 outside of
+// organizations: directly, through a role inheriting from it, through a
+// group granted such a role or one of its subgroups, or through the legacy
+// users.role column.
+const heldQuery = `
+        WITH RECURSIVE covering AS (
+            SELECT id FROM roles WHERE name = $1
+            UNION
+            SELECT r.id
+              FROM roles r
+              JOIN covering c ON r.parent_id = c.id
+        ), holding_groups AS (
+            SELECT group_id FROM group_roles WHERE role_id IN (SELECT id FROM covering)
+            UNION
+            SELECT s.subgroup_id
+              FROM group_subgroups s
+              JOIN holding_groups hg ON s.group_id = hg.group_id
+        )
+        SELECT EXISTS (SELECT 1 FROM user_roles WHERE role_id IN (SELECT id FROM covering))
+            OR EXISTS (SELECT 1 FROM group_members WHERE group_id IN (SELECT group_id FROM holding_groups))
+            OR EXISTS (SELECT 1 FROM users WHERE role = $1)
+`
+
+// keepHolder runs change within tx and reports false if it left no user
+// holding the role named role while some did, in which case tx must be
+// rolled back. The role is locked until tx ends, so that concurrent changes
+// cannot leave it without holders either.
+func keepHolder(ctx context.Context, tx *sqlx.Tx, role string, change func() error) (bool, error) {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM roles WHERE name = $1 FOR UPDATE`, role); err != nil {
+		return false, err
+	}
+	var before, after bool
+	if err := tx.GetContext(ctx, &before, heldQuery, role); err != nil {
+		return false, err
+	}
+	if err := change(); err != nil {
+		return false, err
+	}
+	if err := tx.GetContext(ctx, &after, heldQuery, role); err != nil {
+		return false, err
+	}
+	return after || !before, nil
+}
+
+// RoleRepository manages roles, their permissions and who holds them.
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoleRepository constructs a new RoleRepository.
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// EffectiveRoles returns the names of the roles a user holds or inherits,
+// sorted by name. Roles held within organizations only count for orgID,
+// which may be 0 for none.
+func (r *RoleRepository) EffectiveRoles(ctx context.Context, userID, orgID int64) ([]string, error) {
+	roles := []string{}
+	const query = grantedRolesCTE + `
+        SELECT DISTINCT name
+          FROM granted
+         ORDER BY name
+    `
+	if err := r.db.SelectContext(ctx, &roles, query, userID, orgID); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// EffectivePermissions returns the names of the permissions granted to a
+// user through the roles they hold or inherit, sorted by name, with roles
+// held within organizations counting as in EffectiveRoles.
+func (r *RoleRepository) EffectivePermissions(ctx context.Context, userID, orgID int64) ([]string, error) {
+	perms := []string{}
+	const query = grantedRolesCTE + `
+        SELECT DISTINCT p.name
+          FROM granted g
+          JOIN role_permissions rp ON rp.role_id = g.id
+          JOIN permissions p ON p.id = rp.permission_id
+         ORDER BY p.name
+    `
+	if err := r.db.SelectContext(ctx, &perms, query, userID, orgID); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// AssignRole grants the role named role to a user. Granting a role the user
+// already holds is not an error.
+func (r *RoleRepository) AssignRole(ctx context.Context, userID int64, role string) error {
+	const query = `
+        INSERT INTO user_roles (user_id, role_id)
+        SELECT $1, id FROM roles WHERE name = $2
+        ON CONFLICT DO NOTHING
+    `
+	_, err := r.db.ExecContext(ctx, query, userID, role)
+	return err
+}
+
+// ListRoles returns every role with the permissions it grants itself,
+// sorted by name.
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]model.Role, error) {
+	roles := []model.Role{}
+	const query = `
+        SELECT id, name, description, parent_id, created_at
+          FROM roles
+         ORDER BY name
+    `
+	if err := r.db.SelectContext(ctx, &roles, query); err != nil {
+		return nil, err
+	}
+
+	var grants []struct {
+		RoleID     int64  `db:"role_id"`
+		Permission string `db:"name"`
+	}
+	const permissions = `
+        SELECT rp.role_id, p.name
+          FROM role_permissions rp
+          JOIN permissions p ON p.id = rp.permission_id
+         ORDER BY p.name
+    `
+	if err := r.db.SelectContext(ctx, &grants, permissions); err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]*model.Role, len(roles))
+	for i := range roles {
+		roles[i].Permissions = []string{}
+		byID[roles[i].ID] = &roles[i]
+	}
+	for _, g := range grants {
+		if role, ok := byID[g.RoleID]; ok {
+			role.Permissions = append(role.Permissions, g.Permission)
+		}
+	}
+	return roles, nil
+}
+
+// GetRole returns the role named name, without its permissions.
+func (r *RoleRepository) GetRole(ctx context.Context, name string) (*model.Role, error) {
+	var role model.Role
+	const query = `
+        SELECT id, name, description, parent_id, created_at
+          FROM roles
+         WHERE name = $1
+    `
+	if err := r.db.GetContext(ctx, &role, query, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+// ListPermissions returns the names of every permission, sorted by name.
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]string, error) {
+	perms := []string{}
+	if err := r.db.SelectContext(ctx, &perms, `SELECT name FROM permissions ORDER BY name`); err != nil {
+		return nil, err
+	}
+	return perms, nil
+}
+
+// CreateRole inserts role together with the permissions it grants, and sets
+// its ID and CreatedAt. It reports false if a role of that name exists.
+func (r *RoleRepository) CreateRole(ctx context.Context, role *model.Role) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	const insert = `
+        INSERT INTO roles (name, description, parent_id)
+        VALUES ($1, $2, $3)
+        RETURNING id, created_at
+    `
+	err = tx.QueryRowxContext(ctx, insert, role.Name, role.Description, role.ParentID).
+		Scan(&role.ID, &role.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	if len(role.Permissions) > 0 {
+		const grant = `
+            INSERT INTO role_permissions (role_id, permission_id)
+            SELECT $1, id FROM permissions WHERE name = ANY($2)
+        `
+		if _, err = tx.ExecContext(ctx, grant, role.ID, pq.Array(role.Permissions)); err != nil {
+			return false, err
+		}
+	}
+	return true, tx.Commit()
+}
+
+// UserRoles returns the names of the roles granted to a user, without those
+// they inherit, sorted by name.
+func (r *RoleRepository) UserRoles(ctx context.Context, userID int64) ([]string, error) {
+	roles := []string{}
+	const query = `
+        SELECT r.name
+          FROM user_roles ur
+          JOIN roles r ON r.id = ur.role_id
+         WHERE ur.user_id = $1
+         ORDER BY r.name
+    `
+	if err := r.db.SelectContext(ctx, &roles, query, userID); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// RevokeRole takes the role named role from a user. With keepLast, it
+// refuses to leave the role without holders, counting those who hold it
+// through inheritance, groups or users.role as keepHolder does. It reports
+// false if nothing was revoked. A user's legacy users.role falls back to
+// "user" when the role it names is revoked.
+func (r *RoleRepository) RevokeRole(ctx context.Context, userID int64, role string, keepLast bool) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	revoke := func() error {
+		const query = `
+            DELETE FROM user_roles
+             WHERE user_id = $1
+               AND role_id = (SELECT id FROM roles WHERE name = $2)
+        `
+		res, err := tx.ExecContext(ctx, query, userID, role)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		const legacy = `UPDATE users SET role = $3 WHERE id = $1 AND role = $2`
+		_, err = tx.ExecContext(ctx, legacy, userID, role, model.RoleUser)
+		return err
+	}
+	kept := true
+	if keepLast {
+		kept, err = keepHolder(ctx, tx, role, revoke)
+	} else {
+		err = revoke()
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil || !kept {
+		return false, err
+	}
+	return true, tx.Commit()
+}
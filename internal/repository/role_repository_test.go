@@ -0,0 +1,181 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestRoleRepository_EffectiveRoles(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`WITH RECURSIVE user_groups AS \(.*JOIN user_groups ug ON s\.subgroup_id = ug\.group_id \), `+
+		`granted AS \(.*FROM memberships WHERE user_id = \$1 AND org_id = \$2.*`+
+		`FROM group_roles WHERE group_id IN \(SELECT group_id FROM user_groups\).*`+
+		`JOIN granted g ON p\.id = g\.parent_id \) `+
+		regexp.QuoteMeta(`SELECT DISTINCT name FROM granted ORDER BY name`)).
+		WithArgs(int64(7), int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("admin").AddRow("user"))
+
+	roles, err := repo.EffectiveRoles(t.Context(), 7, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "user"}, roles)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_EffectivePermissions(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(`WITH RECURSIVE user_groups AS \(.*\) `+regexp.QuoteMeta(
+		`SELECT DISTINCT p.name FROM granted g JOIN role_permissions rp ON rp.role_id = g.id `+
+			`JOIN permissions p ON p.id = rp.permission_id ORDER BY p.name`,
+	)).
+		WithArgs(int64(7), int64(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("users:delete").AddRow("users:read"))
+
+	perms, err := repo.EffectivePermissions(t.Context(), 7, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"users:delete", "users:read"}, perms)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_AssignRole(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO user_roles (user_id, role_id) SELECT $1, id FROM roles WHERE name = $2 ON CONFLICT DO NOTHING`,
+	)).
+		WithArgs(int64(7), "user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.AssignRole(t.Context(), 7, "user"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_ListRoles(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+	now := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, name, description, parent_id, created_at FROM roles ORDER BY name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "parent_id", "created_at"}).
+			AddRow(2, "admin", "Administers users", 1, now).
+			AddRow(1, "user", "Every registered user", nil, now))
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT rp.role_id, p.name FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id ORDER BY p.name`,
+	)).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "name"}).AddRow(2, "users:delete").AddRow(2, "users:read"))
+
+	roles, err := repo.ListRoles(t.Context())
+	require.NoError(t, err)
+	require.Len(t, roles, 2)
+	assert.Equal(t, []string{"users:delete", "users:read"}, roles[0].Permissions)
+	assert.Equal(t, int64(1), *roles[0].ParentID)
+	assert.Empty(t, roles[1].Permissions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_CreateRole(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+	parent := int64(1)
+	role := &model.Role{Name: "support", ParentID: &parent, Permissions: []string{"users:unlock"}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO roles (name, description, parent_id) VALUES ($1, $2, $3) RETURNING id, created_at`)).
+		WithArgs("support", "", &parent).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`INSERT INTO role_permissions (role_id, permission_id) SELECT $1, id FROM permissions WHERE name = ANY($2)`,
+	)).
+		WithArgs(int64(3), pq.Array([]string{"users:unlock"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateRole(t.Context(), role)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(3), role.ID)
+
+	// the name is taken
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO roles`).WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	created, err = repo.CreateRole(t.Context(), &model.Role{Name: "admin"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_RevokeRole(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT id FROM roles WHERE name = $1 FOR UPDATE`)).
+		WithArgs("admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM user_roles WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)`)).
+		WithArgs(int64(7), "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET role = $3 WHERE id = $1 AND role = $2`)).
+		WithArgs(int64(7), "admin", "user").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectCommit()
+
+	revoked, err := repo.RevokeRole(t.Context(), 7, "admin", true)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_RevokeRole_NotKeepingLast(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM user_roles`).
+		WithArgs(int64(7), "support").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	revoked, err := repo.RevokeRole(t.Context(), 7, "support", false)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoleRepository_RevokeRole_KeepsLast(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewRoleRepository(sqlx.NewDb(db, "sqlmock"))
+
+	// no other user holds the role, directly, through a group or otherwise
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(`DELETE FROM user_roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE users SET role`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, false)
+	mock.ExpectRollback()
+
+	revoked, err := repo.RevokeRole(t.Context(), 7, "admin", true)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
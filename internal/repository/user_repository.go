@@ -5,11 +5,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/enson89/user-service-go/internal/model"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the Postgres error code of unique constraint violations.
+const uniqueViolation = "23505"
+
 // UserRepository wraps a sqlx.DB to manage users.
 type UserRepository struct {
 	db *sqlx.DB
@@ -47,7 +52,7 @@ func (r *UserRepository) Create(ctx context.Context, u *model.User) error {
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var u model.User
 	const query = `
-        SELECT id, email, password_hash, role
+        SELECT id, email, password_hash, role, email_verified_at, locked_until, token_version
         FROM users
         WHERE email = $1
     `
@@ -65,7 +70,7 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.U
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, error) {
 	var u model.User
 	const query = `
-        SELECT id, email, password_hash, role
+        SELECT id, email, password_hash, role, email_verified_at, locked_until, token_version
         FROM users
         WHERE id = $1
     `
@@ -79,20 +84,65 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*model.User, er
 	return &u, nil
 }
 
-// Delete removes a user by ID. Returns an error if no rows were affected.
-func (r *UserRepository) Delete(ctx context.Context, id int64) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
-	if err != nil {
-		return err
-	}
-	count, err := res.RowsAffected()
+// Delete removes a user by ID. It reports false if they are the last
+// holder of the admin role, counted as keepHolder does. Returns
+// sql.ErrNoRows if no such user exists.
+func (r *UserRepository) Delete(ctx context.Context, id int64) (bool, error) {
+	return r.deleteKeepingAdmin(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+		if err != nil {
+			return err
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// DeleteInOrganization deletes a user who is a member of orgID and of no
+// other organization, so that one organization cannot delete the users of
+// another. It reports false if they are the last holder of the admin role,
+// as Delete does. Returns sql.ErrNoRows if no such user exists.
+func (r *UserRepository) DeleteInOrganization(ctx context.Context, orgID, id int64) (bool, error) {
+	return r.deleteKeepingAdmin(ctx, func(tx *sqlx.Tx) error {
+		const query = `
+            DELETE FROM users u
+             WHERE u.id = $1
+               AND EXISTS (SELECT 1 FROM memberships WHERE user_id = u.id AND org_id = $2)
+               AND NOT EXISTS (SELECT 1 FROM memberships WHERE user_id = u.id AND org_id <> $2)
+        `
+		res, err := tx.ExecContext(ctx, query, id, orgID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// deleteKeepingAdmin runs del in a transaction, which it rolls back, and
+// reports false, if del deletes the last holder of the admin role.
+func (r *UserRepository) deleteKeepingAdmin(ctx context.Context, del func(tx *sqlx.Tx) error) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return err
+		return false, err
 	}
-	if count == 0 {
-		return errors.New("no user found to delete")
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	kept, err := keepHolder(ctx, tx, model.RoleAdmin, func() error { return del(tx) })
+	if err != nil || !kept {
+		return false, err
 	}
-	return nil
+	return true, tx.Commit()
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *model.User) error {
@@ -128,3 +178,101 @@ func (r *UserRepository) Update(ctx context.Context, u *model.User) error {
 
 	return tx.Commit()
 }
+
+// UpdatePassword replaces a user's password hash.
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int64, hash string) error {
+	const q = `
+      UPDATE users
+         SET password_hash = $1, updated_at = NOW()
+       WHERE id = $2
+    `
+	res, err := r.db.ExecContext(ctx, q, hash, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkEmailVerified records that the user has verified their email address.
+// An earlier verification time is kept.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	const q = `
+      UPDATE users
+         SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW()
+       WHERE id = $1
+    `
+	res, err := r.db.ExecContext(ctx, q, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UpdateEmail swaps in a verified new email address for a user. It reports
+// false, changing nothing, if another user has the address.
+func (r *UserRepository) UpdateEmail(ctx context.Context, id int64, email string) (bool, error) {
+	const q = `
+      UPDATE users
+         SET email = $1, email_verified_at = NOW(), updated_at = NOW()
+       WHERE id = $2
+    `
+	res, err := r.db.ExecContext(ctx, q, email, id)
+	if err != nil {
+		// the users.email constraint settles concurrent claims on an address
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return false, nil
+		}
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, sql.ErrNoRows
+	}
+	return true, nil
+}
+
+// SetLockedUntil locks a user out of password logins until the given time,
+// or lifts the lock if until is nil.
+func (r *UserRepository) SetLockedUntil(ctx context.Context, id int64, until *time.Time) error {
+	const q = `
+      UPDATE users
+         SET locked_until = $1, updated_at = NOW()
+       WHERE id = $2
+    `
+	res, err := r.db.ExecContext(ctx, q, until, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TokenVersion returns the token version of a user, or sql.ErrNoRows if
+// there is no such user.
+func (r *UserRepository) TokenVersion(ctx context.Context, id int64) (int64, error) {
+	var v int64
+	const q = `SELECT token_version FROM users WHERE id = $1`
+	err := r.db.GetContext(ctx, &v, q, id)
+	return v, err
+}
+
+// BumpTokenVersion raises the token version of a user and returns the new one.
+func (r *UserRepository) BumpTokenVersion(ctx context.Context, id int64) (int64, error) {
+	var v int64
+	const q = `
+      UPDATE users
+         SET token_version = token_version + 1, updated_at = NOW()
+       WHERE id = $1
+   RETURNING token_version
+    `
+	err := r.db.GetContext(ctx, &v, q, id)
+	return v, err
+}
@@ -5,10 +5,13 @@ import (
 	"database/sql"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/enson89/user-service-go/internal/model"
 	"github.com/enson89/user-service-go/internal/repository"
@@ -42,7 +45,7 @@ func TestGetByEmail_NotFound(t *testing.T) {
 	repo := repository.NewUserRepository(sqlxDB)
 
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT id, email, password_hash, role FROM users WHERE email = $1`,
+		`SELECT id, email, password_hash, role, email_verified_at, locked_until, token_version FROM users WHERE email = $1`,
 	)).
 		WithArgs("no@one.com").
 		WillReturnError(sql.ErrNoRows)
@@ -61,7 +64,7 @@ func TestGetByEmail_Found(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
 		AddRow(7, "x@y.com", "hash", "admin")
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT id, email, password_hash, role FROM users WHERE email = $1`,
+		`SELECT id, email, password_hash, role, email_verified_at, locked_until, token_version FROM users WHERE email = $1`,
 	)).
 		WithArgs("x@y.com").
 		WillReturnRows(rows)
@@ -81,7 +84,7 @@ func TestGetByID(t *testing.T) {
 	rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role"}).
 		AddRow(3, "u@v.com", "pwh", "user")
 	mock.ExpectQuery(regexp.QuoteMeta(
-		`SELECT id, email, password_hash, role FROM users WHERE id = $1`,
+		`SELECT id, email, password_hash, role, email_verified_at, locked_until, token_version FROM users WHERE id = $1`,
 	)).
 		WithArgs(int64(3)).
 		WillReturnRows(rows)
@@ -92,19 +95,37 @@ func TestGetByID(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// expectHeld expects the admin role to be locked and its holders counted,
+// answering held.
+func expectHeld(mock sqlmock.Sqlmock, held bool) {
+	mock.ExpectQuery(`WITH RECURSIVE covering AS \(.*JOIN holding_groups hg ON s\.group_id = hg\.group_id \) ` +
+		regexp.QuoteMeta(`SELECT EXISTS (SELECT 1 FROM user_roles WHERE role_id IN (SELECT id FROM covering)) `) +
+		`.*` + regexp.QuoteMeta(`OR EXISTS (SELECT 1 FROM users WHERE role = $1)`)).
+		WithArgs("admin").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(held))
+}
+
 func TestDelete_Success(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	repo := repository.NewUserRepository(sqlxDB)
 
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT id FROM roles WHERE name = $1 FOR UPDATE`)).
+		WithArgs("admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
 	mock.ExpectExec(regexp.QuoteMeta(
 		`DELETE FROM users WHERE id = $1`,
 	)).
 		WithArgs(int64(5)).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectCommit()
 
-	err := repo.Delete(t.Context(), 5)
+	deleted, err := repo.Delete(t.Context(), 5)
 	assert.NoError(t, err)
+	assert.True(t, deleted)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -113,14 +134,70 @@ func TestDelete_NotFound(t *testing.T) {
 	sqlxDB := sqlx.NewDb(db, "sqlmock")
 	repo := repository.NewUserRepository(sqlxDB)
 
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
 	mock.ExpectExec(regexp.QuoteMeta(
 		`DELETE FROM users WHERE id = $1`,
 	)).
 		WithArgs(int64(6)).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err := repo.Delete(t.Context(), 6)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDelete_KeepsLastAdmin(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(`DELETE FROM users`).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, false)
+	mock.ExpectRollback()
+
+	deleted, err := repo.Delete(t.Context(), 5)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteInOrganization(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
 
-	err := repo.Delete(t.Context(), 6)
-	assert.EqualError(t, err, "no user found to delete")
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(regexp.QuoteMeta(
+		`DELETE FROM users u WHERE u.id = $1 `+
+			`AND EXISTS (SELECT 1 FROM memberships WHERE user_id = u.id AND org_id = $2) `+
+			`AND NOT EXISTS (SELECT 1 FROM memberships WHERE user_id = u.id AND org_id <> $2)`,
+	)).
+		WithArgs(int64(5), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectCommit()
+	deleted, err := repo.DeleteInOrganization(t.Context(), 4, 5)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	// a member of another organization, or of none
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT id FROM roles`).WillReturnResult(sqlmock.NewResult(0, 1))
+	expectHeld(mock, true)
+	mock.ExpectExec(`DELETE FROM users u`).
+		WithArgs(int64(6), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	_, err = repo.DeleteInOrganization(t.Context(), 4, 6)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -165,3 +242,103 @@ func TestUpdate_NotFound(t *testing.T) {
 	assert.Equal(t, sql.ErrNoRows, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(`UPDATE users SET password_hash = $1, updated_at = NOW() WHERE id = $2`)
+	mock.ExpectExec(query).WithArgs("newhash", int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WithArgs("newhash", int64(6)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.UpdatePassword(t.Context(), 5, "newhash"))
+	assert.Equal(t, sql.ErrNoRows, repo.UpdatePassword(t.Context(), 6, "newhash"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $1`,
+	)).
+		WithArgs(int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkEmailVerified(t.Context(), 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(`UPDATE users SET email = $1, email_verified_at = NOW(), updated_at = NOW() WHERE id = $2`)
+	mock.ExpectExec(query).WithArgs("new@x.com", int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	// the address was taken in the meantime
+	mock.ExpectExec(query).WithArgs("taken@x.com", int64(5)).
+		WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_key"})
+	mock.ExpectExec(query).WithArgs("new@x.com", int64(6)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	updated, err := repo.UpdateEmail(t.Context(), 5, "new@x.com")
+	assert.NoError(t, err)
+	assert.True(t, updated)
+
+	updated, err = repo.UpdateEmail(t.Context(), 5, "taken@x.com")
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	_, err = repo.UpdateEmail(t.Context(), 6, "new@x.com")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetLockedUntil(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	until := time.Now().Add(time.Minute)
+	query := regexp.QuoteMeta(`UPDATE users SET locked_until = $1, updated_at = NOW() WHERE id = $2`)
+	mock.ExpectExec(query).WithArgs(&until, int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+	// unlock
+	mock.ExpectExec(query).WithArgs(nil, int64(5)).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetLockedUntil(t.Context(), 5, &until))
+	assert.NoError(t, repo.SetLockedUntil(t.Context(), 5, nil))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	repo := repository.NewUserRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT token_version FROM users WHERE id = $1`)).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"token_version"}).AddRow(2))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT token_version FROM users WHERE id = $1`)).
+		WithArgs(int64(6)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(regexp.QuoteMeta(
+		`UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1 RETURNING token_version`,
+	)).
+		WithArgs(int64(5)).
+		WillReturnRows(sqlmock.NewRows([]string{"token_version"}).AddRow(3))
+
+	v, err := repo.TokenVersion(t.Context(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	_, err = repo.TokenVersion(t.Context(), 6)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	v, err = repo.BumpTokenVersion(t.Context(), 5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/jmoiron/sqlx"
+)
+
+// WebAuthnCredentialRepository manages users' registered WebAuthn credentials.
+type WebAuthnCredentialRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebAuthnCredentialRepository constructs a new WebAuthnCredentialRepository.
+func NewWebAuthnCredentialRepository(db *sqlx.DB) *WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{db: db}
+}
+
+// Create inserts a credential and sets its generated ID.
+func (r *WebAuthnCredentialRepository) Create(ctx context.Context, c *model.WebAuthnCredential) error {
+	const query = `
+        INSERT INTO webauthn_credentials (user_id, credential_id, public_key, attestation_type, aaguid,
+                                          sign_count, transports, backup_eligible, backup_state)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        RETURNING id
+    `
+	return r.db.GetContext(ctx, &c.ID, query, c.UserID, c.CredentialID, c.PublicKey, c.AttestationType, c.AAGUID,
+		c.SignCount, c.Transports, c.BackupEligible, c.BackupState)
+}
+
+// ListByUser returns every credential of a user, oldest first.
+func (r *WebAuthnCredentialRepository) ListByUser(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	var creds []model.WebAuthnCredential
+	const query = `
+        SELECT id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports,
+               backup_eligible, backup_state, clone_detected_at, last_used_at, created_at
+        FROM webauthn_credentials
+        WHERE user_id = $1
+        ORDER BY id
+    `
+	if err := r.db.SelectContext(ctx, &creds, query, userID); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// RecordUse stores the signature counter and backup state reported by a
+// successful login with a credential.
+func (r *WebAuthnCredentialRepository) RecordUse(ctx context.Context, id, signCount int64, backupState bool) error {
+	const query = `
+        UPDATE webauthn_credentials
+           SET sign_count = $2, backup_state = $3, last_used_at = NOW()
+         WHERE id = $1
+    `
+	_, err := r.db.ExecContext(ctx, query, id, signCount, backupState)
+	return err
+}
+
+// MarkCloned records that a credential's signature counter went backwards.
+func (r *WebAuthnCredentialRepository) MarkCloned(ctx context.Context, id int64) error {
+	const query = `
+        UPDATE webauthn_credentials
+           SET clone_detected_at = COALESCE(clone_detected_at, NOW())
+         WHERE id = $1
+    `
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Delete removes a credential of a user. It reports false if the user has
+// no credential with that ID.
+func (r *WebAuthnCredentialRepository) Delete(ctx context.Context, userID, id int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DeleteAllForUser removes every credential of a user.
+func (r *WebAuthnCredentialRepository) DeleteAllForUser(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM webauthn_credentials WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,106 @@
+package repository_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/repository"
+)
+
+func TestWebAuthnCredential_Create(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewWebAuthnCredentialRepository(sqlx.NewDb(db, "sqlmock"))
+
+	c := &model.WebAuthnCredential{
+		UserID: 7, CredentialID: []byte("cred"), PublicKey: []byte("pk"), AttestationType: "none",
+		SignCount: 1, Transports: "internal,hybrid", BackupEligible: true,
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO webauthn_credentials`)).
+		WithArgs(c.UserID, c.CredentialID, c.PublicKey, c.AttestationType, c.AAGUID, c.SignCount, c.Transports,
+			c.BackupEligible, c.BackupState).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+
+	assert.NoError(t, repo.Create(t.Context(), c))
+	assert.Equal(t, int64(3), c.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebAuthnCredential_ListByUser(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewWebAuthnCredentialRepository(sqlx.NewDb(db, "sqlmock"))
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM webauthn_credentials WHERE user_id = $1 ORDER BY id`)).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "credential_id", "public_key", "attestation_type", "aaguid", "sign_count", "transports",
+			"backup_eligible", "backup_state", "clone_detected_at", "last_used_at", "created_at",
+		}).
+			AddRow(3, 7, []byte("a"), []byte("pk"), "none", nil, 5, "", false, false, nil, nil, now).
+			AddRow(4, 7, []byte("b"), []byte("pk"), "packed", nil, 0, "usb", false, false, now, now, now))
+
+	creds, err := repo.ListByUser(t.Context(), 7)
+	assert.NoError(t, err)
+	assert.Len(t, creds, 2)
+	assert.Equal(t, int64(5), creds[0].SignCount)
+	assert.Nil(t, creds[0].CloneDetectedAt)
+	assert.NotNil(t, creds[1].CloneDetectedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebAuthnCredential_RecordUseAndMarkCloned(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewWebAuthnCredentialRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE webauthn_credentials SET sign_count = $2, backup_state = $3, last_used_at = NOW() WHERE id = $1`,
+	)).
+		WithArgs(int64(3), int64(6), true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(
+		`UPDATE webauthn_credentials SET clone_detected_at = COALESCE(clone_detected_at, NOW()) WHERE id = $1`,
+	)).
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordUse(t.Context(), 3, 6, true))
+	assert.NoError(t, repo.MarkCloned(t.Context(), 3))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebAuthnCredential_Delete(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewWebAuthnCredentialRepository(sqlx.NewDb(db, "sqlmock"))
+
+	query := regexp.QuoteMeta(`DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`)
+	mock.ExpectExec(query).WithArgs(int64(3), int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	// someone else's credential
+	mock.ExpectExec(query).WithArgs(int64(3), int64(8)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ok, err := repo.Delete(t.Context(), 7, 3)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.Delete(t.Context(), 8, 3)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebAuthnCredential_DeleteAllForUser(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	repo := repository.NewWebAuthnCredentialRepository(sqlx.NewDb(db, "sqlmock"))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM webauthn_credentials WHERE user_id = $1`)).
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	assert.NoError(t, repo.DeleteAllForUser(t.Context(), 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
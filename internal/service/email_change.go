@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrEmailChangeDisabled     = errors.New("email change is not enabled")
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+	ErrEmailUnchanged          = errors.New("new email is the current email")
+	ErrEmailTaken              = errors.New("email already in use")
+)
+
+type EmailChangeRepository interface {
+	Create(ctx context.Context, t *model.EmailChangeToken) error
+	GetByHash(ctx context.Context, hash string) (*model.EmailChangeToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// WithEmailChange lets users change their email address. The new address is
+// confirmed with a token sent to it through notifier, valid for expire.
+func WithEmailChange(repo EmailChangeRepository, notifier Notifier, expire time.Duration) Option {
+	return func(s *UserService) {
+		s.emailChanges = repo
+		s.notifier = notifier
+		s.emailChangeExpire = expire
+	}
+}
+
+// RequestEmailChange sends a confirmation token to newEmail, replacing any
+// pending change, and tells the current address about it. The user confirms
+// the request with their current password, and the address is only changed
+// once the token is confirmed.
+func (s *UserService) RequestEmailChange(ctx context.Context, userID int64, current, newEmail string) error {
+	if s.emailChanges == nil {
+		return ErrEmailChangeDisabled
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return errors.New("user not found")
+	}
+	if err = s.checkCurrentPassword(ctx, u, current); err != nil {
+		return err
+	}
+	if strings.EqualFold(u.Email, newEmail) {
+		return ErrEmailUnchanged
+	}
+	existing, err := s.repo.GetByEmail(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrEmailTaken
+	}
+
+	if err = s.emailChanges.InvalidateForUser(ctx, u.ID); err != nil {
+		return err
+	}
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.emailChanges.Create(ctx, &model.EmailChangeToken{
+		UserID:    u.ID,
+		NewEmail:  newEmail,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.emailChangeExpire),
+	})
+	if err != nil {
+		return err
+	}
+	err = s.notifier.Notify(ctx, model.Notification{
+		To:       newEmail,
+		Template: model.TemplateEmailChange,
+		Data:     map[string]string{"token": raw},
+	})
+	if err != nil {
+		return err
+	}
+	// lets the owner notice a change they did not ask for
+	return s.notifier.Notify(ctx, model.Notification{
+		To:       u.Email,
+		Template: model.TemplateEmailChangeNotice,
+		Data:     map[string]string{"new_email": newEmail},
+	})
+}
+
+// ConfirmEmailChange spends token and swaps in the address it was sent to.
+// If another account took the address in the meantime, ErrEmailTaken is
+// returned and the token is spent all the same.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, token string) error {
+	if s.emailChanges == nil {
+		return ErrEmailChangeDisabled
+	}
+	ec, err := s.emailChanges.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if ec == nil || ec.UsedAt != nil || time.Now().After(ec.ExpiresAt) {
+		return ErrInvalidEmailChangeToken
+	}
+	claimed, err := s.emailChanges.MarkUsed(ctx, ec.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidEmailChangeToken
+	}
+	updated, err := s.repo.UpdateEmail(ctx, ec.UserID, ec.NewEmail)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrEmailTaken
+	}
+	if s.resets != nil {
+		// reset links went to the old address
+		return s.resets.InvalidateForUser(ctx, ec.UserID)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newEmailChangeService(mr *repoMocks.MockUserRepository, er *repoMocks.MockEmailChangeRepository,
+	mn *repoMocks.MockNotifier,
+) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithEmailChange(er, mn, 24*time.Hour))
+}
+
+func TestRequestEmailChange(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	er := new(repoMocks.MockEmailChangeRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newEmailChangeService(mr, er, mn)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "old@x.com", PasswordHash: bcryptHash(t, "secret")}, nil)
+	mr.On("GetByEmail", mock.Anything, "new@x.com").Return(nil, nil)
+	er.On("InvalidateForUser", mock.Anything, int64(7)).Return(nil)
+	var stored *model.EmailChangeToken
+	er.On("Create", mock.Anything, mock.AnythingOfType("*model.EmailChangeToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.EmailChangeToken) }).
+		Return(nil)
+	var sent []model.Notification
+	mn.On("Notify", mock.Anything, mock.AnythingOfType("model.Notification")).
+		Run(func(args mock.Arguments) { sent = append(sent, args.Get(1).(model.Notification)) }).
+		Return(nil)
+
+	require.NoError(t, svc.RequestEmailChange(t.Context(), 7, "secret", "new@x.com"))
+	assert.Equal(t, "new@x.com", stored.NewEmail)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+	require.Len(t, sent, 2)
+	assert.Equal(t, "new@x.com", sent[0].To)
+	assert.Equal(t, model.TemplateEmailChange, sent[0].Template)
+	assert.Equal(t, sha256Hex(sent[0].Data["token"]), stored.TokenHash)
+	assert.Equal(t, "old@x.com", sent[1].To)
+	assert.Equal(t, model.TemplateEmailChangeNotice, sent[1].Template)
+	assert.Equal(t, "new@x.com", sent[1].Data["new_email"])
+	er.AssertExpectations(t)
+}
+
+func TestRequestEmailChange_Refused(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	er := new(repoMocks.MockEmailChangeRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newEmailChangeService(mr, er, mn)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "old@x.com", PasswordHash: bcryptHash(t, "secret")}, nil)
+	mr.On("GetByEmail", mock.Anything, "other@x.com").Return(&model.User{ID: 8, Email: "other@x.com"}, nil)
+
+	assert.ErrorIs(t, svc.RequestEmailChange(t.Context(), 7, "wrong", "new@x.com"), service.ErrInvalidCurrentPassword)
+	assert.ErrorIs(t, svc.RequestEmailChange(t.Context(), 7, "secret", "Old@x.com"), service.ErrEmailUnchanged)
+	assert.ErrorIs(t, svc.RequestEmailChange(t.Context(), 7, "secret", "other@x.com"), service.ErrEmailTaken)
+	er.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mn.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+
+	plain := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+	assert.ErrorIs(t, plain.RequestEmailChange(t.Context(), 7, "secret", "new@x.com"), service.ErrEmailChangeDisabled)
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	er := new(repoMocks.MockEmailChangeRepository)
+	svc := newEmailChangeService(mr, er, new(repoMocks.MockNotifier))
+
+	er.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.EmailChangeToken{ID: 2, UserID: 7, NewEmail: "new@x.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	er.On("MarkUsed", mock.Anything, int64(2)).Return(true, nil)
+	mr.On("UpdateEmail", mock.Anything, int64(7), "new@x.com").Return(true, nil)
+	er.On("GetByHash", mock.Anything, sha256Hex("old")).
+		Return(&model.EmailChangeToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+	er.On("GetByHash", mock.Anything, sha256Hex("nope")).Return(nil, nil)
+
+	assert.NoError(t, svc.ConfirmEmailChange(t.Context(), "tok"))
+	assert.ErrorIs(t, svc.ConfirmEmailChange(t.Context(), "old"), service.ErrInvalidEmailChangeToken)
+	assert.ErrorIs(t, svc.ConfirmEmailChange(t.Context(), "nope"), service.ErrInvalidEmailChangeToken)
+	mr.AssertExpectations(t)
+	er.AssertExpectations(t)
+}
+
+func TestConfirmEmailChange_Taken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	er := new(repoMocks.MockEmailChangeRepository)
+	svc := newEmailChangeService(mr, er, new(repoMocks.MockNotifier))
+
+	// another account signed up with the address after the change was requested
+	er.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.EmailChangeToken{ID: 2, UserID: 7, NewEmail: "new@x.com", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	er.On("MarkUsed", mock.Anything, int64(2)).Return(true, nil)
+	mr.On("UpdateEmail", mock.Anything, int64(7), "new@x.com").Return(false, nil)
+
+	assert.ErrorIs(t, svc.ConfirmEmailChange(t.Context(), "tok"), service.ErrEmailTaken)
+}
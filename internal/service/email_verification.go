@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrEmailVerificationDisabled = errors.New("email verification is not enabled")
+	ErrInvalidVerificationToken  = errors.New("invalid or expired verification token")
+	ErrEmailNotVerified          = errors.New("email address not verified")
+)
+
+// UnverifiedLoginPolicy decides what Login does for users who have not
+// verified their email address.
+type UnverifiedLoginPolicy string
+
+const (
+	UnverifiedLoginAllow    UnverifiedLoginPolicy = "allow"
+	UnverifiedLoginDeny     UnverifiedLoginPolicy = "deny"
+	UnverifiedLoginRestrict UnverifiedLoginPolicy = "restrict"
+)
+
+type EmailVerificationRepository interface {
+	Create(ctx context.Context, t *model.EmailVerificationToken) error
+	GetByHash(ctx context.Context, hash string) (*model.EmailVerificationToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// WithEmailVerification sends a verification token on signup through
+// notifier, valid for expire, and applies policy to unverified logins.
+func WithEmailVerification(repo EmailVerificationRepository, notifier Notifier, expire time.Duration,
+	policy UnverifiedLoginPolicy,
+) Option {
+	return func(s *UserService) {
+		s.verifications = repo
+		s.notifier = notifier
+		s.verifyExpire = expire
+		s.unverifiedLogin = policy
+	}
+}
+
+// VerifyEmail spends token and marks the address it was sent to as verified.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) error {
+	if s.verifications == nil {
+		return ErrEmailVerificationDisabled
+	}
+	ev, err := s.verifications.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if ev == nil || ev.UsedAt != nil || time.Now().After(ev.ExpiresAt) {
+		return ErrInvalidVerificationToken
+	}
+	claimed, err := s.verifications.MarkUsed(ctx, ev.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrInvalidVerificationToken
+	}
+	return s.repo.MarkEmailVerified(ctx, ev.UserID)
+}
+
+// ResendVerification sends a fresh verification token to email. Unknown and
+// already verified addresses are ignored so callers cannot probe for accounts.
+func (s *UserService) ResendVerification(ctx context.Context, email string) error {
+	if s.verifications == nil {
+		return ErrEmailVerificationDisabled
+	}
+	u, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if u == nil || u.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.sendVerification(ctx, u)
+}
+
+// sendVerification replaces any outstanding verification token of u with a new one.
+func (s *UserService) sendVerification(ctx context.Context, u *model.User) error {
+	if err := s.verifications.InvalidateForUser(ctx, u.ID); err != nil {
+		return err
+	}
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.verifications.Create(ctx, &model.EmailVerificationToken{
+		UserID:    u.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.verifyExpire),
+	})
+	if err != nil {
+		return err
+	}
+	return s.notifier.Notify(ctx, model.Notification{
+		To:       u.Email,
+		Template: model.TemplateEmailVerification,
+		Data:     map[string]string{"token": raw},
+	})
+}
+
+// unverified reports whether u has yet to verify their email while
+// verification is enforced.
+func (s *UserService) unverified(u *model.User) bool {
+	return s.verifications != nil && u.EmailVerifiedAt == nil
+}
@@ -0,0 +1,134 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newVerifyService(mr *repoMocks.MockUserRepository, vr *repoMocks.MockEmailVerificationRepository,
+	mn *repoMocks.MockNotifier, policy service.UnverifiedLoginPolicy,
+) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithEmailVerification(vr, mn, 48*time.Hour, policy))
+}
+
+func TestSignUp_SendsVerification(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	vr := new(repoMocks.MockEmailVerificationRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newVerifyService(mr, vr, mn, service.UnverifiedLoginAllow)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.User).ID = 7 }).
+		Return(nil)
+	vr.On("InvalidateForUser", mock.Anything, int64(7)).Return(nil)
+	var stored *model.EmailVerificationToken
+	vr.On("Create", mock.Anything, mock.AnythingOfType("*model.EmailVerificationToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.EmailVerificationToken) }).
+		Return(nil)
+	var sent model.Notification
+	mn.On("Notify", mock.Anything, mock.AnythingOfType("model.Notification")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(model.Notification) }).
+		Return(nil)
+
+	_, err := svc.SignUp(t.Context(), "user@x.com", "pwd1234")
+	require.NoError(t, err)
+	assert.Equal(t, "user@x.com", sent.To)
+	assert.Equal(t, model.TemplateEmailVerification, sent.Template)
+	assert.Equal(t, sha256Hex(sent.Data["token"]), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), stored.ExpiresAt, time.Minute)
+	vr.AssertExpectations(t)
+	mn.AssertExpectations(t)
+}
+
+func TestVerifyEmail(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	vr := new(repoMocks.MockEmailVerificationRepository)
+	svc := newVerifyService(mr, vr, new(repoMocks.MockNotifier), service.UnverifiedLoginAllow)
+
+	vr.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.EmailVerificationToken{ID: 2, UserID: 7, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	vr.On("MarkUsed", mock.Anything, int64(2)).Return(true, nil)
+	mr.On("MarkEmailVerified", mock.Anything, int64(7)).Return(nil)
+	vr.On("GetByHash", mock.Anything, sha256Hex("old")).
+		Return(&model.EmailVerificationToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+	vr.On("GetByHash", mock.Anything, sha256Hex("nope")).Return(nil, nil)
+
+	assert.NoError(t, svc.VerifyEmail(t.Context(), "tok"))
+	assert.ErrorIs(t, svc.VerifyEmail(t.Context(), "old"), service.ErrInvalidVerificationToken)
+	assert.ErrorIs(t, svc.VerifyEmail(t.Context(), "nope"), service.ErrInvalidVerificationToken)
+	mr.AssertExpectations(t)
+	vr.AssertExpectations(t)
+}
+
+func TestResendVerification_AlreadyVerified(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	vr := new(repoMocks.MockEmailVerificationRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newVerifyService(mr, vr, mn, service.UnverifiedLoginAllow)
+
+	verified := time.Now()
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", EmailVerifiedAt: &verified}, nil)
+	mr.On("GetByEmail", mock.Anything, "nobody@x.com").Return(nil, nil)
+
+	assert.NoError(t, svc.ResendVerification(t.Context(), "user@x.com"))
+	assert.NoError(t, svc.ResendVerification(t.Context(), "nobody@x.com"))
+	mn.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestLogin_UnverifiedPolicy(t *testing.T) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	unverified := &model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}
+
+	t.Run("deny", func(t *testing.T) {
+		mr := new(repoMocks.MockUserRepository)
+		svc := newVerifyService(mr, new(repoMocks.MockEmailVerificationRepository), new(repoMocks.MockNotifier),
+			service.UnverifiedLoginDeny)
+		mr.On("GetByEmail", mock.Anything, "user@x.com").Return(unverified, nil)
+
+		_, err := svc.Login(t.Context(), "user@x.com", "correct")
+		assert.ErrorIs(t, err, service.ErrEmailNotVerified)
+	})
+
+	t.Run("restrict", func(t *testing.T) {
+		mr := new(repoMocks.MockUserRepository)
+		svc := newVerifyService(mr, new(repoMocks.MockEmailVerificationRepository), new(repoMocks.MockNotifier),
+			service.UnverifiedLoginRestrict)
+		mr.On("GetByEmail", mock.Anything, "user@x.com").Return(unverified, nil)
+
+		tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+		require.NoError(t, err)
+		claims, err := auth.ParseToken(tokens.AccessToken, svc.Keys, auth.TokenOptions{})
+		require.NoError(t, err)
+		assert.True(t, claims.Restricted)
+	})
+
+	t.Run("restrict, verified", func(t *testing.T) {
+		mr := new(repoMocks.MockUserRepository)
+		svc := newVerifyService(mr, new(repoMocks.MockEmailVerificationRepository), new(repoMocks.MockNotifier),
+			service.UnverifiedLoginRestrict)
+		verified := *unverified
+		verified.EmailVerifiedAt = new(time.Time)
+		mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&verified, nil)
+
+		tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+		require.NoError(t, err)
+		claims, err := auth.ParseToken(tokens.AccessToken, svc.Keys, auth.TokenOptions{})
+		require.NoError(t, err)
+		assert.False(t, claims.Restricted)
+	})
+}
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrGroupsDisabled   = errors.New("groups are not enabled")
+	ErrGroupNotFound    = errors.New("group not found")
+	ErrGroupExists      = errors.New("group already exists")
+	ErrGroupCycle       = errors.New("a group cannot be its own subgroup")
+	ErrNotGroupMember   = errors.New("not a member of the group")
+	ErrGroupRoleNotHeld = errors.New("group does not hold the role")
+)
+
+// GroupRepository manages groups, their members, nested subgroups and the
+// roles granted to them.
+type GroupRepository interface {
+	Create(ctx context.Context, g *model.Group) (bool, error)
+	Get(ctx context.Context, id int64) (*model.Group, error)
+	List(ctx context.Context) ([]model.Group, error)
+	Rename(ctx context.Context, id int64, name string) (bool, error)
+	Delete(ctx context.Context, id int64) ([]int64, bool, error)
+	Members(ctx context.Context, id int64) ([]model.GroupMember, error)
+	AddMember(ctx context.Context, groupID, userID int64) error
+	RemoveMember(ctx context.Context, groupID, userID int64) (bool, error)
+	Subgroups(ctx context.Context, id int64) ([]model.Group, error)
+	AddSubgroup(ctx context.Context, groupID, subgroupID int64) (bool, error)
+	RemoveSubgroup(ctx context.Context, groupID, subgroupID int64) ([]int64, bool, error)
+	Roles(ctx context.Context, id int64) ([]string, error)
+	GrantRole(ctx context.Context, groupID int64, role string) error
+	RevokeRole(ctx context.Context, groupID int64, role string) ([]int64, bool, error)
+}
+
+// WithGroups lets admins manage groups of users. The roles granted to a
+// group are held by its members, and by the members of its subgroups, as
+// resolved by the RoleRepository of WithRBAC. It takes WithRBAC to have an
+// effect.
+func WithGroups(repo GroupRepository) Option {
+	return func(s *UserService) {
+		s.groups = repo
+	}
+}
+
+// ListGroups returns every group, without members, subgroups and roles.
+func (s *UserService) ListGroups(ctx context.Context) ([]model.Group, error) {
+	if !s.groupsEnabled() {
+		return nil, ErrGroupsDisabled
+	}
+	return s.groups.List(ctx)
+}
+
+// GetGroup returns a group with its direct members and subgroups and the
+// roles granted to it.
+func (s *UserService) GetGroup(ctx context.Context, id int64) (*model.Group, error) {
+	g, err := s.group(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g.Members, err = s.groups.Members(ctx, id); err != nil {
+		return nil, err
+	}
+	if g.Subgroups, err = s.groups.Subgroups(ctx, id); err != nil {
+		return nil, err
+	}
+	if g.Roles, err = s.groups.Roles(ctx, id); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// CreateGroup creates an empty group.
+func (s *UserService) CreateGroup(ctx context.Context, name, description string) (*model.Group, error) {
+	if !s.groupsEnabled() {
+		return nil, ErrGroupsDisabled
+	}
+	g := &model.Group{Name: name, Description: description}
+	created, err := s.groups.Create(ctx, g)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrGroupExists
+	}
+	return g, nil
+}
+
+// RenameGroup renames a group.
+func (s *UserService) RenameGroup(ctx context.Context, id int64, name string) (*model.Group, error) {
+	g, err := s.group(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := s.groups.Rename(ctx, id, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !renamed {
+		return nil, ErrGroupExists
+	}
+	g.Name = name
+	return g, nil
+}
+
+// DeleteGroup deletes a group. Its members lose the roles granted to it, so
+// their access tokens are revoked. A group holding the last admins cannot
+// be deleted.
+func (s *UserService) DeleteGroup(ctx context.Context, id int64) error {
+	if !s.groupsEnabled() {
+		return ErrGroupsDisabled
+	}
+	members, deleted, err := s.groups.Delete(ctx, id)
+	if err = groupChanged(deleted, err, ErrGroupNotFound); err != nil {
+		return err
+	}
+	return s.revokeMemberTokens(ctx, members)
+}
+
+// AddGroupMember makes a user a member of a group. They hold the roles of
+// the group from the next tokens issued to them.
+func (s *UserService) AddGroupMember(ctx context.Context, groupID, userID int64) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	if err := s.userExists(ctx, userID); err != nil {
+		return err
+	}
+	return s.groups.AddMember(ctx, groupID, userID)
+}
+
+// RemoveGroupMember removes a user from a group and revokes their access
+// tokens. The last admin cannot be removed from the group making them one.
+func (s *UserService) RemoveGroupMember(ctx context.Context, groupID, userID int64) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	removed, err := s.groups.RemoveMember(ctx, groupID, userID)
+	if err = groupChanged(removed, err, ErrNotGroupMember); err != nil {
+		return err
+	}
+	return s.revokeUserTokens(ctx, userID)
+}
+
+// AddSubgroup nests a group in another, making its members members of the
+// other too. A group cannot end up a subgroup of itself.
+func (s *UserService) AddSubgroup(ctx context.Context, groupID, subgroupID int64) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	if _, err := s.group(ctx, subgroupID); err != nil {
+		return err
+	}
+	if groupID == subgroupID {
+		return ErrGroupCycle
+	}
+	added, err := s.groups.AddSubgroup(ctx, groupID, subgroupID)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrGroupCycle
+	}
+	return nil
+}
+
+// RemoveSubgroup takes a subgroup out of a group and revokes the access
+// tokens of the subgroup's members, unless that would leave no admin.
+func (s *UserService) RemoveSubgroup(ctx context.Context, groupID, subgroupID int64) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	members, removed, err := s.groups.RemoveSubgroup(ctx, groupID, subgroupID)
+	if err = groupChanged(removed, err, ErrNotGroupMember); err != nil {
+		return err
+	}
+	return s.revokeMemberTokens(ctx, members)
+}
+
+// GrantGroupRole grants a role to a group. Its members, and those of its
+// subgroups, hold the role from the next tokens issued to them.
+func (s *UserService) GrantGroupRole(ctx context.Context, groupID int64, role string) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	r, err := s.roles.GetRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return ErrRoleNotFound
+	}
+	return s.groups.GrantRole(ctx, groupID, role)
+}
+
+// RevokeGroupRole takes a role from a group and revokes the access tokens
+// of its members, and those of its subgroups, unless that would leave no
+// admin.
+func (s *UserService) RevokeGroupRole(ctx context.Context, groupID int64, role string) error {
+	if _, err := s.group(ctx, groupID); err != nil {
+		return err
+	}
+	members, revoked, err := s.groups.RevokeRole(ctx, groupID, role)
+	if err = groupChanged(revoked, err, ErrGroupRoleNotHeld); err != nil {
+		return err
+	}
+	return s.revokeMemberTokens(ctx, members)
+}
+
+// groupsEnabled reports whether groups have an effect, see WithGroups.
+func (s *UserService) groupsEnabled() bool {
+	return s.groups != nil && s.roles != nil
+}
+
+// groupChanged maps the result of a change of a group that must leave an
+// admin: missing if there was nothing to change, ErrLastAdmin if the change
+// was refused.
+func groupChanged(changed bool, err, missing error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return missing
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// group returns the group with id, or ErrGroupNotFound.
+func (s *UserService) group(ctx context.Context, id int64) (*model.Group, error) {
+	if !s.groupsEnabled() {
+		return nil, ErrGroupsDisabled
+	}
+	g, err := s.groups.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGroupNotFound
+	}
+	return g, nil
+}
+
+// revokeMemberTokens revokes the access tokens of the members of a group.
+func (s *UserService) revokeMemberTokens(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		if err := s.revokeUserTokens(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newGroupService(
+	mr *repoMocks.MockUserRepository,
+	rr *repoMocks.MockRoleRepository,
+	gr *repoMocks.MockGroupRepository,
+	opts ...service.Option,
+) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		append([]service.Option{service.WithRBAC(rr), service.WithGroups(gr),
+			service.WithTokenVersions(new(repoMocks.MockTokenVersionCache))}, opts...)...)
+}
+
+func TestCreateGroup(t *testing.T) {
+	gr := new(repoMocks.MockGroupRepository)
+	svc := newGroupService(new(repoMocks.MockUserRepository), new(repoMocks.MockRoleRepository), gr)
+
+	gr.On("Create", mock.Anything, mock.AnythingOfType("*model.Group")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.Group).ID = 3 }).
+		Return(true, nil).Once()
+	g, err := svc.CreateGroup(t.Context(), "editors", "Editorial team")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), g.ID)
+
+	gr.On("Create", mock.Anything, mock.AnythingOfType("*model.Group")).Return(false, nil)
+	_, err = svc.CreateGroup(t.Context(), "editors", "")
+	assert.ErrorIs(t, err, service.ErrGroupExists)
+}
+
+func TestRenameGroup(t *testing.T) {
+	gr := new(repoMocks.MockGroupRepository)
+	svc := newGroupService(new(repoMocks.MockUserRepository), new(repoMocks.MockRoleRepository), gr)
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3, Name: "editors"}, nil)
+	gr.On("Get", mock.Anything, int64(4)).Return(nil, nil)
+	gr.On("Rename", mock.Anything, int64(3), "desk").Return(true, nil)
+	gr.On("Rename", mock.Anything, int64(3), "reporters").Return(false, nil)
+
+	g, err := svc.RenameGroup(t.Context(), 3, "desk")
+	require.NoError(t, err)
+	assert.Equal(t, "desk", g.Name)
+
+	_, err = svc.RenameGroup(t.Context(), 3, "reporters")
+	assert.ErrorIs(t, err, service.ErrGroupExists)
+	_, err = svc.RenameGroup(t.Context(), 4, "desk")
+	assert.ErrorIs(t, err, service.ErrGroupNotFound)
+}
+
+func TestAddSubgroup_RefusesCycles(t *testing.T) {
+	gr := new(repoMocks.MockGroupRepository)
+	svc := newGroupService(new(repoMocks.MockUserRepository), new(repoMocks.MockRoleRepository), gr)
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	gr.On("Get", mock.Anything, int64(5)).Return(&model.Group{ID: 5}, nil)
+	gr.On("AddSubgroup", mock.Anything, int64(3), int64(5)).Return(true, nil)
+	gr.On("AddSubgroup", mock.Anything, int64(5), int64(3)).Return(false, nil)
+
+	require.NoError(t, svc.AddSubgroup(t.Context(), 3, 5))
+	assert.ErrorIs(t, svc.AddSubgroup(t.Context(), 5, 3), service.ErrGroupCycle)
+	assert.ErrorIs(t, svc.AddSubgroup(t.Context(), 3, 3), service.ErrGroupCycle)
+	gr.AssertNotCalled(t, "AddSubgroup", mock.Anything, int64(3), int64(3))
+}
+
+func TestAddGroupMember(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(mr, new(repoMocks.MockRoleRepository), gr, service.WithTokenVersions(tv))
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7}, nil)
+	mr.On("GetByID", mock.Anything, int64(8)).Return(nil, nil)
+	gr.On("AddMember", mock.Anything, int64(3), int64(7)).Return(nil)
+
+	// the roles of the group come with the next tokens
+	require.NoError(t, svc.AddGroupMember(t.Context(), 3, 7))
+	tv.AssertNotCalled(t, "SetTokenVersion", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.ErrorIs(t, svc.AddGroupMember(t.Context(), 3, 8), sql.ErrNoRows)
+}
+
+func TestGrantGroupRole(t *testing.T) {
+	rr := new(repoMocks.MockRoleRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(new(repoMocks.MockUserRepository), rr, gr, service.WithTokenVersions(tv))
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	rr.On("GetRole", mock.Anything, "editor").Return(&model.Role{ID: 9, Name: "editor"}, nil)
+	rr.On("GetRole", mock.Anything, "nobody").Return(nil, nil)
+	gr.On("GrantRole", mock.Anything, int64(3), "editor").Return(nil)
+
+	require.NoError(t, svc.GrantGroupRole(t.Context(), 3, "editor"))
+	tv.AssertNotCalled(t, "SetTokenVersion", mock.Anything, mock.Anything, mock.Anything)
+
+	assert.ErrorIs(t, svc.GrantGroupRole(t.Context(), 3, "nobody"), service.ErrRoleNotFound)
+}
+
+func TestRevokeGroupRole_RevokesMemberTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(mr, new(repoMocks.MockRoleRepository), gr, service.WithTokenVersions(tv))
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	// 8 is a member through a subgroup
+	gr.On("RevokeRole", mock.Anything, int64(3), "editor").Return([]int64{7, 8}, true, nil)
+	gr.On("RevokeRole", mock.Anything, int64(3), "admin").Return(nil, false, sql.ErrNoRows)
+	for _, id := range []int64{7, 8} {
+		tv.On("DeleteTokenVersion", mock.Anything, id).Return(nil)
+		mr.On("BumpTokenVersion", mock.Anything, id).Return(int64(2), nil)
+		tv.On("SetTokenVersion", mock.Anything, id, int64(2)).Return(nil)
+	}
+
+	require.NoError(t, svc.RevokeGroupRole(t.Context(), 3, "editor"))
+	tv.AssertExpectations(t)
+
+	assert.ErrorIs(t, svc.RevokeGroupRole(t.Context(), 3, "admin"), service.ErrGroupRoleNotHeld)
+}
+
+func TestRemoveGroupMember(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(mr, new(repoMocks.MockRoleRepository), gr, service.WithTokenVersions(tv))
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	gr.On("RemoveMember", mock.Anything, int64(3), int64(7)).Return(true, nil)
+	gr.On("RemoveMember", mock.Anything, int64(3), int64(8)).Return(false, sql.ErrNoRows)
+	// 9 is the last admin, through the group
+	gr.On("RemoveMember", mock.Anything, int64(3), int64(9)).Return(false, nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(2), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(2)).Return(nil)
+
+	require.NoError(t, svc.RemoveGroupMember(t.Context(), 3, 7))
+	tv.AssertExpectations(t)
+
+	assert.ErrorIs(t, svc.RemoveGroupMember(t.Context(), 3, 8), service.ErrNotGroupMember)
+	assert.ErrorIs(t, svc.RemoveGroupMember(t.Context(), 3, 9), service.ErrLastAdmin)
+	mr.AssertNotCalled(t, "BumpTokenVersion", mock.Anything, int64(9))
+}
+
+func TestDeleteGroup(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(mr, new(repoMocks.MockRoleRepository), gr, service.WithTokenVersions(tv))
+
+	gr.On("Delete", mock.Anything, int64(3)).Return([]int64{7}, true, nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(2), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(2)).Return(nil)
+	require.NoError(t, svc.DeleteGroup(t.Context(), 3))
+	tv.AssertExpectations(t)
+
+	gr.On("Delete", mock.Anything, int64(4)).Return(nil, false, sql.ErrNoRows)
+	assert.ErrorIs(t, svc.DeleteGroup(t.Context(), 4), service.ErrGroupNotFound)
+	// the group makes the last admins admins
+	gr.On("Delete", mock.Anything, int64(5)).Return(nil, false, nil)
+	assert.ErrorIs(t, svc.DeleteGroup(t.Context(), 5), service.ErrLastAdmin)
+}
+
+func TestGroups_Disabled(t *testing.T) {
+	// groups grant roles, so they take RBAC
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithGroups(new(repoMocks.MockGroupRepository)))
+
+	_, err := svc.ListGroups(t.Context())
+	assert.ErrorIs(t, err, service.ErrGroupsDisabled)
+	assert.ErrorIs(t, svc.AddGroupMember(t.Context(), 3, 7), service.ErrGroupsDisabled)
+}
+
+func TestRemoveSubgroup_RevokesMemberTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	gr := new(repoMocks.MockGroupRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newGroupService(mr, new(repoMocks.MockRoleRepository), gr, service.WithTokenVersions(tv))
+
+	gr.On("Get", mock.Anything, int64(3)).Return(&model.Group{ID: 3}, nil)
+	// the members of the subgroup as of its removal lose the roles of 3
+	gr.On("RemoveSubgroup", mock.Anything, int64(3), int64(4)).Return([]int64{7}, true, nil)
+	gr.On("RemoveSubgroup", mock.Anything, int64(3), int64(5)).Return(nil, false, sql.ErrNoRows)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(2), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(2)).Return(nil)
+
+	require.NoError(t, svc.RemoveSubgroup(t.Context(), 3, 4))
+	tv.AssertExpectations(t)
+
+	assert.ErrorIs(t, svc.RemoveSubgroup(t.Context(), 3, 5), service.ErrNotGroupMember)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrInvitationsDisabled = errors.New("organization invitations are not enabled")
+	ErrInvalidInvitation   = errors.New("invalid or expired invitation")
+	ErrInvitationNotFound  = errors.New("invitation not found")
+	ErrAlreadyMember       = errors.New("already a member of the organization")
+	ErrPasswordRequired    = errors.New("a password is required to create the invited account")
+	ErrInvitationLogin     = errors.New("log in to accept the invitation with the invited account")
+	ErrInvitationForOther  = errors.New("the invitation is for another email address")
+)
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *model.Invitation) error
+	GetByHash(ctx context.Context, hash string) (*model.Invitation, error)
+	ListPending(ctx context.Context, orgID int64) ([]model.Invitation, error)
+	Revoke(ctx context.Context, orgID, id int64) (bool, error)
+	MarkAccepted(ctx context.Context, id int64) (bool, error)
+	Release(ctx context.Context, id int64) error
+}
+
+// WithInvitations lets organization admins invite people by email. The
+// invitation token is sent through notifier and is valid for expire. It
+// takes WithOrganizations to have an effect.
+func WithInvitations(repo InvitationRepository, notifier Notifier, expire time.Duration) Option {
+	return func(s *UserService) {
+		s.invitations = repo
+		s.notifier = notifier
+		s.invitationExpire = expire
+	}
+}
+
+// InviteMember invites email to an organization, to hold role within it
+// once they accept. The invitation is sent to email; its token is not
+// returned.
+func (s *UserService) InviteMember(ctx context.Context, orgID, inviterID int64, email, role string) (*model.Invitation, error) {
+	if s.invitations == nil || s.orgs == nil {
+		return nil, ErrInvitationsDisabled
+	}
+	if role != model.RoleOrgAdmin && role != model.RoleOrgMember {
+		return nil, ErrInvalidOrgRole
+	}
+	org, err := s.orgs.Get(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrNotMember
+	}
+	existing, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		m, err := s.orgs.Membership(ctx, orgID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if m != nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	inv := &model.Invitation{
+		OrgID:     orgID,
+		Email:     email,
+		Role:      role,
+		TokenHash: hash,
+		InvitedBy: &inviterID,
+		ExpiresAt: time.Now().Add(s.invitationExpire),
+	}
+	if err = s.invitations.Create(ctx, inv); err != nil {
+		return nil, err
+	}
+	err = s.notifier.Notify(ctx, model.Notification{
+		To:       email,
+		Template: model.TemplateOrgInvitation,
+		Data:     map[string]string{"token": raw, "org": org.Name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// ListInvitations returns the invitations of an organization that can
+// still be accepted.
+func (s *UserService) ListInvitations(ctx context.Context, orgID int64) ([]model.Invitation, error) {
+	if s.invitations == nil || s.orgs == nil {
+		return nil, ErrInvitationsDisabled
+	}
+	return s.invitations.ListPending(ctx, orgID)
+}
+
+// RevokeInvitation withdraws a pending invitation of an organization.
+func (s *UserService) RevokeInvitation(ctx context.Context, orgID, id int64) error {
+	if s.invitations == nil || s.orgs == nil {
+		return ErrInvitationsDisabled
+	}
+	revoked, err := s.invitations.Revoke(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// AcceptInvitation makes the invitee a member of the organization they were
+// invited to, holding the role of the invitation. An existing account with
+// the invited address joins only through its owner, logged in as userID;
+// with userID 0, an account is signed up with password. Having received the
+// token, the invitee owns the address, so a new account's email counts as
+// verified. The invitation is spent first and restored if joining fails, so
+// that it cannot be accepted twice nor lost.
+func (s *UserService) AcceptInvitation(ctx context.Context, userID int64, token, password string) (*model.Membership, error) {
+	if s.invitations == nil || s.orgs == nil {
+		return nil, ErrInvitationsDisabled
+	}
+	inv, err := s.invitations.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil || inv.AcceptedAt != nil || inv.RevokedAt != nil || time.Now().After(inv.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+
+	var u *model.User
+	if userID != 0 {
+		if u, err = s.repo.GetByID(ctx, userID); err != nil {
+			return nil, err
+		}
+		if u == nil || !strings.EqualFold(u.Email, inv.Email) {
+			return nil, ErrInvitationForOther
+		}
+	} else {
+		existing, err := s.repo.GetByEmail(ctx, inv.Email)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return nil, ErrInvitationLogin
+		}
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+	}
+
+	claimed, err := s.invitations.MarkAccepted(ctx, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// a concurrent request accepted it first
+		return nil, ErrInvalidInvitation
+	}
+	m, err := s.joinInvited(ctx, inv, u, password)
+	if err != nil {
+		return nil, errors.Join(err, s.invitations.Release(ctx, inv.ID))
+	}
+	return m, nil
+}
+
+// joinInvited adds u, or a new account signed up with password if u is nil,
+// to the organization of a spent invitation. A new account is deleted again
+// if it cannot join.
+func (s *UserService) joinInvited(ctx context.Context, inv *model.Invitation, u *model.User, password string) (*model.Membership, error) {
+	if u != nil {
+		if err := s.orgs.AddMember(ctx, inv.OrgID, u.ID, inv.Role); err != nil {
+			return nil, err
+		}
+		return s.orgs.Membership(ctx, inv.OrgID, u.ID)
+	}
+	u, err := s.createUser(ctx, inv.Email, password)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.repo.MarkEmailVerified(ctx, u.ID); err == nil {
+		err = s.orgs.AddMember(ctx, inv.OrgID, u.ID, inv.Role)
+	}
+	if err != nil {
+		_, derr := s.repo.Delete(ctx, u.ID)
+		return nil, errors.Join(err, derr)
+	}
+	return s.orgs.Membership(ctx, inv.OrgID, u.ID)
+}
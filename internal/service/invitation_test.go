@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newInvitationService(
+	mr *repoMocks.MockUserRepository,
+	or *repoMocks.MockOrganizationRepository,
+	ir *repoMocks.MockInvitationRepository,
+	mn *repoMocks.MockNotifier,
+) *service.UserService {
+	return newOrgService(mr, or, service.WithInvitations(ir, mn, 24*time.Hour))
+}
+
+func TestInviteMember(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newInvitationService(mr, or, ir, mn)
+
+	or.On("Get", mock.Anything, int64(4)).Return(&model.Organization{ID: 4, Name: "Acme"}, nil)
+	mr.On("GetByEmail", mock.Anything, "new@x.com").Return(nil, nil)
+	var stored *model.Invitation
+	ir.On("Create", mock.Anything, mock.AnythingOfType("*model.Invitation")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.Invitation) }).
+		Return(nil)
+	var sent model.Notification
+	mn.On("Notify", mock.Anything, mock.AnythingOfType("model.Notification")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(model.Notification) }).
+		Return(nil)
+
+	inv, err := svc.InviteMember(t.Context(), 4, 7, "new@x.com", model.RoleOrgMember)
+	require.NoError(t, err)
+	assert.Same(t, stored, inv)
+	assert.Equal(t, model.RoleOrgMember, inv.Role)
+	assert.Equal(t, int64(7), *inv.InvitedBy)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), inv.ExpiresAt, 5*time.Second)
+
+	// only the hash of the mailed token is stored
+	assert.Equal(t, "new@x.com", sent.To)
+	assert.Equal(t, model.TemplateOrgInvitation, sent.Template)
+	assert.Equal(t, "Acme", sent.Data["org"])
+	assert.NotEqual(t, sent.Data["token"], inv.TokenHash)
+
+	_, err = svc.InviteMember(t.Context(), 4, 7, "new@x.com", model.RoleAdmin)
+	assert.ErrorIs(t, err, service.ErrInvalidOrgRole)
+}
+
+func TestInviteMember_AlreadyMember(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newInvitationService(mr, or, ir, mn)
+
+	or.On("Get", mock.Anything, int64(4)).Return(&model.Organization{ID: 4, Name: "Acme"}, nil)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 8, Email: "user@x.com"}, nil)
+	or.On("Membership", mock.Anything, int64(4), int64(8)).
+		Return(&model.Membership{OrgID: 4, UserID: 8, Role: model.RoleOrgMember}, nil)
+
+	_, err := svc.InviteMember(t.Context(), 4, 7, "user@x.com", model.RoleOrgAdmin)
+	assert.ErrorIs(t, err, service.ErrAlreadyMember)
+	ir.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mn.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestAcceptInvitation_ExistingUser(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	svc := newInvitationService(mr, or, ir, new(repoMocks.MockNotifier))
+
+	inv := &model.Invitation{ID: 9, OrgID: 4, Email: "user@x.com", Role: model.RoleOrgAdmin, ExpiresAt: time.Now().Add(time.Hour)}
+	ir.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).Return(inv, nil)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 8, Email: "user@x.com"}, nil)
+	mr.On("GetByID", mock.Anything, int64(8)).Return(&model.User{ID: 8, Email: "User@x.com"}, nil)
+	mr.On("GetByID", mock.Anything, int64(5)).Return(&model.User{ID: 5, Email: "other@x.com"}, nil)
+	ir.On("MarkAccepted", mock.Anything, int64(9)).Return(true, nil).Once()
+	or.On("AddMember", mock.Anything, int64(4), int64(8), model.RoleOrgAdmin).Return(nil)
+	or.On("Membership", mock.Anything, int64(4), int64(8)).
+		Return(&model.Membership{OrgID: 4, UserID: 8, Role: model.RoleOrgAdmin}, nil)
+
+	// whoever holds the token cannot join an existing account without logging in as it
+	_, err := svc.AcceptInvitation(t.Context(), 0, "token", "pwd12345")
+	assert.ErrorIs(t, err, service.ErrInvitationLogin)
+	_, err = svc.AcceptInvitation(t.Context(), 5, "token", "")
+	assert.ErrorIs(t, err, service.ErrInvitationForOther)
+	ir.AssertNotCalled(t, "MarkAccepted", mock.Anything, mock.Anything)
+
+	// no password is needed by the account's owner
+	m, err := svc.AcceptInvitation(t.Context(), 8, "token", "")
+	require.NoError(t, err)
+	assert.Equal(t, model.RoleOrgAdmin, m.Role)
+	mr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// it cannot be accepted twice
+	ir.On("MarkAccepted", mock.Anything, int64(9)).Return(false, nil)
+	_, err = svc.AcceptInvitation(t.Context(), 8, "token", "")
+	assert.ErrorIs(t, err, service.ErrInvalidInvitation)
+	or.AssertNumberOfCalls(t, "AddMember", 1)
+}
+
+func TestAcceptInvitation_SignsUp(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	svc := newInvitationService(mr, or, ir, new(repoMocks.MockNotifier))
+
+	inv := &model.Invitation{ID: 9, OrgID: 4, Email: "new@x.com", Role: model.RoleOrgMember, ExpiresAt: time.Now().Add(time.Hour)}
+	ir.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).Return(inv, nil)
+	mr.On("GetByEmail", mock.Anything, "new@x.com").Return(nil, nil)
+
+	_, err := svc.AcceptInvitation(t.Context(), 0, "token", "")
+	assert.ErrorIs(t, err, service.ErrPasswordRequired)
+
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.User).ID = 8 }).
+		Return(nil)
+	mr.On("MarkEmailVerified", mock.Anything, int64(8)).Return(nil)
+	ir.On("MarkAccepted", mock.Anything, int64(9)).Return(true, nil)
+	or.On("AddMember", mock.Anything, int64(4), int64(8), model.RoleOrgMember).Return(nil)
+	or.On("Membership", mock.Anything, int64(4), int64(8)).
+		Return(&model.Membership{OrgID: 4, UserID: 8, Role: model.RoleOrgMember}, nil)
+
+	m, err := svc.AcceptInvitation(t.Context(), 0, "token", "pwd12345")
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), m.UserID)
+	mr.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_RestoresOnFailure(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	svc := newInvitationService(mr, or, ir, new(repoMocks.MockNotifier))
+
+	inv := &model.Invitation{ID: 9, OrgID: 4, Email: "new@x.com", Role: model.RoleOrgMember, ExpiresAt: time.Now().Add(time.Hour)}
+	ir.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).Return(inv, nil)
+	mr.On("GetByEmail", mock.Anything, "new@x.com").Return(nil, nil)
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.User).ID = 8 }).
+		Return(nil)
+	mr.On("MarkEmailVerified", mock.Anything, int64(8)).Return(nil)
+	ir.On("MarkAccepted", mock.Anything, int64(9)).Return(true, nil)
+	or.On("AddMember", mock.Anything, int64(4), int64(8), model.RoleOrgMember).Return(errors.New("db down"))
+	// the new account and the spent invitation are undone, so the invitee can try again
+	mr.On("Delete", mock.Anything, int64(8)).Return(true, nil)
+	ir.On("Release", mock.Anything, int64(9)).Return(nil)
+
+	_, err := svc.AcceptInvitation(t.Context(), 0, "token", "pwd12345")
+	assert.EqualError(t, err, "db down")
+	mr.AssertExpectations(t)
+	ir.AssertExpectations(t)
+}
+
+func TestAcceptInvitation_Invalid(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ir := new(repoMocks.MockInvitationRepository)
+	svc := newInvitationService(mr, or, ir, new(repoMocks.MockNotifier))
+
+	now := time.Now()
+	tests := map[string]*model.Invitation{
+		"unknown":  nil,
+		"expired":  {ID: 1, ExpiresAt: now.Add(-time.Minute)},
+		"revoked":  {ID: 2, ExpiresAt: now.Add(time.Hour), RevokedAt: &now},
+		"accepted": {ID: 3, ExpiresAt: now.Add(time.Hour), AcceptedAt: &now},
+	}
+	for name, inv := range tests {
+		t.Run(name, func(t *testing.T) {
+			ir.On("GetByHash", mock.Anything, mock.AnythingOfType("string")).Return(inv, nil).Once()
+			_, err := svc.AcceptInvitation(t.Context(), 0, "token", "pwd12345")
+			assert.ErrorIs(t, err, service.ErrInvalidInvitation)
+		})
+	}
+	ir.AssertNotCalled(t, "MarkAccepted", mock.Anything, mock.Anything)
+}
+
+func TestInvitations_Disabled(t *testing.T) {
+	svc := newOrgService(new(repoMocks.MockUserRepository), new(repoMocks.MockOrganizationRepository))
+
+	_, err := svc.InviteMember(t.Context(), 4, 7, "new@x.com", model.RoleOrgMember)
+	assert.ErrorIs(t, err, service.ErrInvitationsDisabled)
+	_, err = svc.AcceptInvitation(t.Context(), 0, "token", "")
+	assert.ErrorIs(t, err, service.ErrInvitationsDisabled)
+	assert.ErrorIs(t, svc.RevokeInvitation(t.Context(), 4, 9), service.ErrInvitationsDisabled)
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrLockoutDisabled = errors.New("login lockout is not enabled")
+	ErrLoginLocked     = errors.New("too many failed logins, try again later")
+)
+
+// LoginLockedError refuses a login while its account or client IP is
+// locked out. It matches ErrLoginLocked.
+type LoginLockedError struct {
+	// RetryAfter is how long the lockout lasts.
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked.Error()
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginAttemptStore counts failed logins per key, e.g. per account or per
+// client IP, and keeps lockouts of keys that failed too often.
+type LoginAttemptStore interface {
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	Strike(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, d time.Duration) error
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// LockoutPolicy decides when failed logins lock an account or a client IP
+// out, and for how long.
+type LockoutPolicy struct {
+	// AccountThreshold and IPThreshold are the failures within Window that
+	// lock an account or a client IP out.
+	AccountThreshold int64
+	IPThreshold      int64
+	Window           time.Duration
+	// The first lockout lasts BaseLockout; each further one within
+	// StrikeReset of the previous lasts twice as long, up to MaxLockout.
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	StrikeReset time.Duration
+}
+
+// lockoutDuration returns how long the given lockout in a row lasts.
+func (p LockoutPolicy) lockoutDuration(strikes int64) time.Duration {
+	d := p.BaseLockout
+	for i := int64(1); i < strikes && d < p.MaxLockout; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxLockout)
+}
+
+type clientIPKey struct{}
+
+// ContextWithClientIP returns a context for requests made from ip, which
+// failed logins are counted against.
+func ContextWithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFrom returns the IP set by ContextWithClientIP, or "".
+func ClientIPFrom(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
+
+// WithLockout locks accounts and client IPs out of logins after repeated
+// failures, of passwords and second factors alike, as described by policy. Account lockouts are recorded
+// on the user so that admins can see and lift them.
+func WithLockout(store LoginAttemptStore, policy LockoutPolicy) Option {
+	return func(s *UserService) {
+		s.attempts = store
+		s.lockout = policy
+	}
+}
+
+// UnlockUser lifts the lockout of a user and forgets their failed logins.
+// Within an organization, only its members can be unlocked.
+func (s *UserService) UnlockUser(ctx context.Context, id int64) error {
+	if s.attempts == nil {
+		return ErrLockoutDisabled
+	}
+	if err := s.checkTenant(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.SetLockedUntil(ctx, id, nil); err != nil {
+		return err
+	}
+	return s.attempts.Reset(ctx, accountAttemptKey(id))
+}
+
+// checkIPLockout refuses logins from a client IP that is locked out.
+func (s *UserService) checkIPLockout(ctx context.Context) error {
+	ip := ClientIPFrom(ctx)
+	if s.attempts == nil || ip == "" {
+		return nil
+	}
+	d, err := s.attempts.LockedFor(ctx, ipAttemptKey(ip))
+	if err != nil {
+		return err
+	}
+	if d > 0 {
+		return &LoginLockedError{RetryAfter: d}
+	}
+	return nil
+}
+
+// checkAccountLockout refuses logins to u while it is locked out.
+func (s *UserService) checkAccountLockout(u *model.User) error {
+	if s.attempts == nil || u.LockedUntil == nil {
+		return nil
+	}
+	if d := time.Until(*u.LockedUntil); d > 0 {
+		return &LoginLockedError{RetryAfter: d}
+	}
+	return nil
+}
+
+// loginFailed counts a failed login against the client IP and, if the
+// account exists, against u. It returns the error to answer the login with.
+func (s *UserService) loginFailed(ctx context.Context, u *model.User) error {
+	if err := s.recordLoginFailure(ctx, u); err != nil {
+		return err
+	}
+	return errors.New("invalid credentials")
+}
+
+// recordLoginFailure counts a failed password or second factor against the
+// client IP and, if the account exists, against u. It returns a
+// *LoginLockedError if either is locked out as a result.
+func (s *UserService) recordLoginFailure(ctx context.Context, u *model.User) error {
+	if s.attempts == nil {
+		return nil
+	}
+	var locked time.Duration
+	if ip := ClientIPFrom(ctx); ip != "" {
+		key := ipAttemptKey(ip)
+		failures, err := s.attempts.RecordFailure(ctx, key, s.lockout.Window)
+		if err != nil {
+			return err
+		}
+		if failures >= s.lockout.IPThreshold {
+			strikes, err := s.attempts.Strike(ctx, key, s.lockout.StrikeReset)
+			if err != nil {
+				return err
+			}
+			d := s.lockout.lockoutDuration(strikes)
+			if err = s.attempts.Lock(ctx, key, d); err != nil {
+				return err
+			}
+			locked = d
+		}
+	}
+	if u != nil {
+		key := accountAttemptKey(u.ID)
+		failures, err := s.attempts.RecordFailure(ctx, key, s.lockout.Window)
+		if err != nil {
+			return err
+		}
+		if failures >= s.lockout.AccountThreshold {
+			strikes, err := s.attempts.Strike(ctx, key, s.lockout.StrikeReset)
+			if err != nil {
+				return err
+			}
+			d := s.lockout.lockoutDuration(strikes)
+			until := time.Now().Add(d)
+			if err = s.repo.SetLockedUntil(ctx, u.ID, &until); err != nil {
+				return err
+			}
+			locked = max(locked, d)
+		}
+	}
+	if locked > 0 {
+		return &LoginLockedError{RetryAfter: locked}
+	}
+	return nil
+}
+
+// loginSucceeded forgets the failed logins of u. Those of the client IP are
+// kept, or logging in to one's own account would reset them.
+func (s *UserService) loginSucceeded(ctx context.Context, u *model.User) error {
+	if s.attempts == nil {
+		return nil
+	}
+	return s.attempts.Reset(ctx, accountAttemptKey(u.ID))
+}
+
+func accountAttemptKey(userID int64) string {
+	return "account:" + strconv.FormatInt(userID, 10)
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,290 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+var testLockoutPolicy = service.LockoutPolicy{
+	AccountThreshold: 5,
+	IPThreshold:      20,
+	Window:           15 * time.Minute,
+	BaseLockout:      time.Minute,
+	MaxLockout:       time.Hour,
+	StrikeReset:      24 * time.Hour,
+}
+
+func newLockoutService(mr *repoMocks.MockUserRepository, as *repoMocks.MockLoginAttemptStore) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithLockout(as, testLockoutPolicy))
+}
+
+func lockoutUser(t *testing.T) *model.User {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	require.NoError(t, err)
+	return &model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}
+}
+
+func TestLogin_CountsFailures(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+	ctx := service.ContextWithClientIP(t.Context(), "10.0.0.1")
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(lockoutUser(t), nil)
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil)
+	as.On("RecordFailure", mock.Anything, "ip:10.0.0.1", 15*time.Minute).Return(int64(3), nil)
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(2), nil)
+
+	_, err := svc.Login(ctx, "user@x.com", "wrong")
+	assert.EqualError(t, err, "invalid credentials")
+	as.AssertExpectations(t)
+	mr.AssertNotCalled(t, "SetLockedUntil", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogin_LocksAccountExponentially(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(lockoutUser(t), nil)
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(5), nil)
+	// the third lockout in a row
+	as.On("Strike", mock.Anything, "account:7", 24*time.Hour).Return(int64(3), nil)
+	var until *time.Time
+	mr.On("SetLockedUntil", mock.Anything, int64(7), mock.AnythingOfType("*time.Time")).
+		Run(func(args mock.Arguments) { until = args.Get(2).(*time.Time) }).
+		Return(nil)
+
+	_, err := svc.Login(t.Context(), "user@x.com", "wrong")
+	var le *service.LoginLockedError
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, 4*time.Minute, le.RetryAfter)
+	require.NotNil(t, until)
+	assert.WithinDuration(t, time.Now().Add(4*time.Minute), *until, 5*time.Second)
+}
+
+func TestLogin_LockedAccount(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+
+	u := lockoutUser(t)
+	until := time.Now().Add(time.Minute)
+	u.LockedUntil = &until
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(u, nil)
+
+	// even the right password is refused
+	_, err := svc.Login(t.Context(), "user@x.com", "correct")
+	var le *service.LoginLockedError
+	require.ErrorAs(t, err, &le)
+	assert.InDelta(t, time.Minute, le.RetryAfter, float64(5*time.Second))
+
+	// an expired lockout is ignored
+	past := time.Now().Add(-time.Minute)
+	u.LockedUntil = &past
+	as.On("Reset", mock.Anything, "account:7").Return(nil).Once()
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	as.AssertExpectations(t)
+}
+
+func TestLogin_LocksIP(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+	ctx := service.ContextWithClientIP(t.Context(), "10.0.0.1")
+
+	// spraying unknown accounts
+	mr.On("GetByEmail", mock.Anything, "nobody@x.com").Return(nil, nil)
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil).Once()
+	as.On("RecordFailure", mock.Anything, "ip:10.0.0.1", 15*time.Minute).Return(int64(20), nil)
+	as.On("Strike", mock.Anything, "ip:10.0.0.1", 24*time.Hour).Return(int64(1), nil)
+	as.On("Lock", mock.Anything, "ip:10.0.0.1", time.Minute).Return(nil)
+
+	_, err := svc.Login(ctx, "nobody@x.com", "guess")
+	var le *service.LoginLockedError
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, time.Minute, le.RetryAfter)
+
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(50*time.Second, nil).Once()
+	_, err = svc.Login(ctx, "user@x.com", "correct")
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, 50*time.Second, le.RetryAfter)
+	as.AssertExpectations(t)
+	mr.AssertNotCalled(t, "GetByEmail", mock.Anything, "user@x.com")
+}
+
+func TestLoginMFA_LocksAccount(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithLockout(as, testLockoutPolicy), service.WithMFA(fr, cs, cipher, "User Service", 5*time.Minute))
+	ctx := service.ContextWithClientIP(t.Context(), "10.0.0.1")
+	now := time.Now()
+
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil)
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("mfa")).Return(int64(7), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(&model.UserMFA{UserID: 7, EnabledAt: &now}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(lockoutUser(t), nil)
+	fr.On("UseRecoveryCode", mock.Anything, int64(7), mock.Anything).Return(false, nil)
+	cs.On("FailChallenge", mock.Anything, sha256Hex("mfa")).Return(int64(1), nil)
+	// wrong codes count like wrong passwords, across challenges
+	as.On("RecordFailure", mock.Anything, "ip:10.0.0.1", 15*time.Minute).Return(int64(5), nil)
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(5), nil)
+	as.On("Strike", mock.Anything, "account:7", 24*time.Hour).Return(int64(1), nil)
+	mr.On("SetLockedUntil", mock.Anything, int64(7), mock.AnythingOfType("*time.Time")).Return(nil)
+
+	_, err = svc.LoginMFA(ctx, "mfa", "wrong-guess")
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+	mr.AssertExpectations(t)
+}
+
+func TestDisableMFA_LocksAccount(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	fr := new(repoMocks.MockMFARepository)
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithLockout(as, testLockoutPolicy),
+		service.WithMFA(fr, new(repoMocks.MockMFAChallengeStore), cipher, "User Service", 5*time.Minute))
+	ctx := service.ContextWithClientIP(t.Context(), "10.0.0.1")
+	now := time.Now()
+
+	u := lockoutUser(t)
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(&model.UserMFA{UserID: 7, EnabledAt: &now}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(u, nil)
+	fr.On("UseRecoveryCode", mock.Anything, int64(7), mock.Anything).Return(false, nil)
+	as.On("RecordFailure", mock.Anything, "ip:10.0.0.1", 15*time.Minute).Return(int64(1), nil)
+	for i := int64(1); i < 5; i++ {
+		as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(i, nil).Once()
+	}
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(5), nil).Once()
+	as.On("Strike", mock.Anything, "account:7", 24*time.Hour).Return(int64(1), nil)
+	mr.On("SetLockedUntil", mock.Anything, int64(7), mock.AnythingOfType("*time.Time")).
+		Run(func(args mock.Arguments) { u.LockedUntil = args.Get(2).(*time.Time) }).
+		Return(nil)
+
+	for i := 0; i < 4; i++ {
+		assert.ErrorIs(t, svc.DisableMFA(ctx, 7, "correct", "wrong-guess"), service.ErrInvalidMFACode)
+	}
+	assert.ErrorIs(t, svc.DisableMFA(ctx, 7, "correct", "wrong-guess"), service.ErrLoginLocked)
+
+	// no more guesses while locked out
+	assert.ErrorIs(t, svc.DisableMFA(ctx, 7, "correct", "wrong-guess"), service.ErrLoginLocked)
+	fr.AssertNumberOfCalls(t, "UseRecoveryCode", 5)
+	fr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	as.AssertExpectations(t)
+}
+
+func TestLogin_MFAKeepsFailures(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithLockout(as, testLockoutPolicy), service.WithMFA(fr, cs, cipher, "User Service", 5*time.Minute))
+	now := time.Now()
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(lockoutUser(t), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(&model.UserMFA{UserID: 7, EnabledAt: &now}, nil)
+	cs.On("CreateChallenge", mock.Anything, mock.AnythingOfType("string"), int64(7), 5*time.Minute).Return(nil)
+
+	// the right password alone does not forget the wrong codes before it
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.MFAToken)
+	as.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_CountsFailures(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+	ctx := service.ContextWithClientIP(t.Context(), "10.0.0.1")
+
+	u := lockoutUser(t)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(u, nil)
+	as.On("LockedFor", mock.Anything, "ip:10.0.0.1").Return(time.Duration(0), nil)
+	as.On("RecordFailure", mock.Anything, "ip:10.0.0.1", 15*time.Minute).Return(int64(1), nil)
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(4), nil).Once()
+
+	_, err := svc.ChangePassword(ctx, 7, "wrong", "new-secret")
+	assert.ErrorIs(t, err, service.ErrInvalidCurrentPassword)
+
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(5), nil).Once()
+	as.On("Strike", mock.Anything, "account:7", 24*time.Hour).Return(int64(1), nil)
+	mr.On("SetLockedUntil", mock.Anything, int64(7), mock.AnythingOfType("*time.Time")).Return(nil)
+	_, err = svc.ChangePassword(ctx, 7, "wrong", "new-secret")
+	var le *service.LoginLockedError
+	require.ErrorAs(t, err, &le)
+	assert.Equal(t, time.Minute, le.RetryAfter)
+
+	// even the right password is refused while locked out
+	until := time.Now().Add(time.Minute)
+	u.LockedUntil = &until
+	_, err = svc.ChangePassword(ctx, 7, "correct", "new-secret")
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+	mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	as.AssertExpectations(t)
+}
+
+func TestLockoutPolicy_Capped(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(lockoutUser(t), nil)
+	as.On("RecordFailure", mock.Anything, "account:7", 15*time.Minute).Return(int64(5), nil)
+	as.On("Strike", mock.Anything, "account:7", 24*time.Hour).Return(int64(40), nil)
+	var until *time.Time
+	mr.On("SetLockedUntil", mock.Anything, int64(7), mock.AnythingOfType("*time.Time")).
+		Run(func(args mock.Arguments) { until = args.Get(2).(*time.Time) }).
+		Return(nil)
+
+	_, err := svc.Login(t.Context(), "user@x.com", "wrong")
+	assert.ErrorIs(t, err, service.ErrLoginLocked)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *until, 5*time.Second)
+}
+
+func TestUnlockUser(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	as := new(repoMocks.MockLoginAttemptStore)
+	svc := newLockoutService(mr, as)
+
+	mr.On("SetLockedUntil", mock.Anything, int64(7), (*time.Time)(nil)).Return(nil)
+	as.On("Reset", mock.Anything, "account:7").Return(nil)
+
+	require.NoError(t, svc.UnlockUser(t.Context(), 7))
+	mr.AssertExpectations(t)
+	as.AssertExpectations(t)
+
+	plain := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+	assert.ErrorIs(t, plain.UnlockUser(t.Context(), 7), service.ErrLockoutDisabled)
+}
@@ -0,0 +1,118 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newMagicLinkService(mr *repoMocks.MockUserRepository, ls *repoMocks.MockMagicLinkStore, mn *repoMocks.MockNotifier,
+	opts ...service.Option,
+) *service.UserService {
+	opts = append(opts, service.WithMagicLink(ls, mn, 15*time.Minute))
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour, opts...)
+}
+
+func TestRequestMagicLink_SendsToken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ls := new(repoMocks.MockMagicLinkStore)
+	mn := new(repoMocks.MockNotifier)
+	svc := newMagicLinkService(mr, ls, mn)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 7, Email: "user@x.com"}, nil)
+	var stored string
+	ls.On("SaveMagicLink", mock.Anything, mock.AnythingOfType("string"), int64(7), 15*time.Minute).
+		Run(func(args mock.Arguments) { stored = args.String(1) }).
+		Return(nil)
+	var sent model.Notification
+	mn.On("Notify", mock.Anything, mock.AnythingOfType("model.Notification")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(model.Notification) }).
+		Return(nil)
+
+	require.NoError(t, svc.RequestMagicLink(t.Context(), "user@x.com"))
+
+	assert.Equal(t, "user@x.com", sent.To)
+	assert.Equal(t, model.TemplateMagicLink, sent.Template)
+	assert.Equal(t, sha256Hex(sent.Data["token"]), stored)
+}
+
+func TestRequestMagicLink_UnknownEmail(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ls := new(repoMocks.MockMagicLinkStore)
+	mn := new(repoMocks.MockNotifier)
+	svc := newMagicLinkService(mr, ls, mn)
+
+	mr.On("GetByEmail", mock.Anything, "nobody@x.com").Return(nil, nil)
+
+	require.NoError(t, svc.RequestMagicLink(t.Context(), "nobody@x.com"))
+	mn.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestLoginMagicLink(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ls := new(repoMocks.MockMagicLinkStore)
+	mn := new(repoMocks.MockNotifier)
+	svc := newMagicLinkService(mr, ls, mn)
+
+	ls.On("ConsumeMagicLink", mock.Anything, sha256Hex("link")).Return(int64(7), nil).Once()
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Email: "user@x.com", Role: "user"}, nil)
+	// the link was delivered to the address
+	mr.On("MarkEmailVerified", mock.Anything, int64(7)).Return(nil).Once()
+
+	tokens, err := svc.LoginMagicLink(t.Context(), "link")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+
+	// replayed
+	ls.On("ConsumeMagicLink", mock.Anything, sha256Hex("link")).Return(int64(0), nil).Once()
+	_, err = svc.LoginMagicLink(t.Context(), "link")
+	assert.ErrorIs(t, err, service.ErrInvalidMagicLink)
+	mr.AssertExpectations(t)
+}
+
+func TestLoginMagicLink_MFAChallenge(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ls := new(repoMocks.MockMagicLinkStore)
+	mn := new(repoMocks.MockNotifier)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	svc := newMagicLinkService(mr, ls, mn, service.WithMFA(fr, cs, cipher, "User Service", 5*time.Minute))
+	m, _ := enabledMFA(t, cipher)
+
+	now := time.Now()
+	ls.On("ConsumeMagicLink", mock.Anything, sha256Hex("link")).Return(int64(7), nil)
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", Role: "user", EmailVerifiedAt: &now}, nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	cs.On("CreateChallenge", mock.Anything, mock.AnythingOfType("string"), int64(7), 5*time.Minute).Return(nil)
+
+	// the link replaces the password, not the second factor
+	tokens, err := svc.LoginMagicLink(t.Context(), "link")
+	require.NoError(t, err)
+	assert.Empty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.MFAToken)
+	mr.AssertNotCalled(t, "MarkEmailVerified", mock.Anything, mock.Anything)
+}
+
+func TestMagicLink_NotConfigured(t *testing.T) {
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	assert.ErrorIs(t, svc.RequestMagicLink(t.Context(), "user@x.com"), service.ErrMagicLinkDisabled)
+	_, err := svc.LoginMagicLink(t.Context(), "link")
+	assert.ErrorIs(t, err, service.ErrMagicLinkDisabled)
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrMFADisabled         = errors.New("multi-factor authentication is not enabled")
+	ErrMFAAlreadyEnabled   = errors.New("multi-factor authentication is already set up")
+	ErrMFANotEnrolled      = errors.New("multi-factor authentication is not set up")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa token")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+)
+
+// maxMFAAttempts is how many wrong codes a login challenge survives.
+const maxMFAAttempts = 5
+
+type MFARepository interface {
+	Get(ctx context.Context, userID int64) (*model.UserMFA, error)
+	SavePending(ctx context.Context, userID int64, secret string) error
+	Enable(ctx context.Context, userID, step int64, codeHashes []string) (bool, error)
+	ClaimTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	Delete(ctx context.Context, userID int64) error
+}
+
+// MFAChallengeStore keeps the pending second steps of logins, keyed by the
+// hash of the challenge token handed to the client.
+type MFAChallengeStore interface {
+	CreateChallenge(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error
+	ChallengeUser(ctx context.Context, tokenHash string) (int64, error)
+	FailChallenge(ctx context.Context, tokenHash string) (int64, error)
+	DeleteChallenge(ctx context.Context, tokenHash string) (bool, error)
+}
+
+// WithMFA lets users enroll TOTP authenticators. Login then answers users
+// who have one with a challenge that lives for challengeExpire. Secrets are
+// encrypted with cipher and shown in authenticator apps under issuer.
+func WithMFA(repo MFARepository, challenges MFAChallengeStore, cipher *mfa.Cipher, issuer string,
+	challengeExpire time.Duration,
+) Option {
+	return func(s *UserService) {
+		s.mfa = repo
+		s.mfaChallenges = challenges
+		s.mfaCipher = cipher
+		s.mfaIssuer = issuer
+		s.mfaExpire = challengeExpire
+	}
+}
+
+// EnrollTOTP starts enrolling a new authenticator for a user, who confirms
+// it with their current password. It stays inactive until confirmed with
+// ConfirmTOTP.
+func (s *UserService) EnrollTOTP(ctx context.Context, userID int64, password string) (*model.TOTPEnrollment, error) {
+	if s.mfa == nil {
+		return nil, ErrMFADisabled
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, errors.New("user not found")
+	}
+	if err = s.checkCurrentPassword(ctx, u, password); err != nil {
+		return nil, err
+	}
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m != nil && m.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := mfa.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.mfaCipher.Encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.mfa.SavePending(ctx, userID, encrypted); err != nil {
+		return nil, err
+	}
+	return &model.TOTPEnrollment{Secret: secret, URI: mfa.TOTPURI(s.mfaIssuer, u.Email, secret)}, nil
+}
+
+// ConfirmTOTP enables the pending authenticator of a user once they prove
+// it works with a code, and returns their new recovery codes. The codes are
+// only stored hashed, so this is the only time they can be shown.
+func (s *UserService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	if s.mfa == nil {
+		return nil, ErrMFADisabled
+	}
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMFANotEnrolled
+	}
+	if m.EnabledAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := s.mfaCipher.Decrypt(m.TOTPSecret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := mfa.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+	codes, err := mfa.NewRecoveryCodes(mfa.RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = hashToken(c)
+	}
+	enabled, err := s.mfa.Enable(ctx, userID, step, hashes)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		// confirmed by a concurrent request
+		return nil, ErrMFAAlreadyEnabled
+	}
+	return codes, nil
+}
+
+// DisableMFA removes the authenticator and recovery codes of a user, who
+// must present their current password and a current code or an unused
+// recovery code. Wrong passwords and codes count as failed logins, as in
+// LoginMFA, so that a stolen session cannot guess its way to turning MFA
+// off.
+func (s *UserService) DisableMFA(ctx context.Context, userID int64, password, code string) error {
+	if s.mfa == nil {
+		return ErrMFADisabled
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return errors.New("user not found")
+	}
+	if err = s.checkCurrentPassword(ctx, u, password); err != nil {
+		return err
+	}
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.EnabledAt == nil {
+		return ErrMFANotEnrolled
+	}
+	if err = s.checkMFACode(ctx, u, m, code); err != nil {
+		return err
+	}
+	return s.mfa.Delete(ctx, userID)
+}
+
+// LoginMFA completes a login that Login answered with an MFA token. A
+// challenge is spent by success or by too many wrong codes.
+func (s *UserService) LoginMFA(ctx context.Context, mfaToken, code string) (*model.TokenPair, error) {
+	if s.mfa == nil {
+		return nil, ErrMFADisabled
+	}
+	if err := s.checkIPLockout(ctx); err != nil {
+		return nil, err
+	}
+	hash := hashToken(mfaToken)
+	userID, err := s.mfaChallenges.ChallengeUser(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		return nil, ErrInvalidMFAChallenge
+	}
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.EnabledAt == nil {
+		// disabled since the challenge was issued
+		return nil, ErrInvalidMFAChallenge
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+	if err = s.checkAccountLockout(u); err != nil {
+		return nil, err
+	}
+	ok, err := s.checkSecondFactor(ctx, m, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		failures, err := s.mfaChallenges.FailChallenge(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if failures >= maxMFAAttempts {
+			if _, err = s.mfaChallenges.DeleteChallenge(ctx, hash); err != nil {
+				return nil, err
+			}
+		}
+		// wrong codes count as failed logins, or new challenges would
+		// allow guessing them without end
+		if err = s.recordLoginFailure(ctx, u); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidMFACode
+	}
+	claimed, err := s.mfaChallenges.DeleteChallenge(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// completed by a concurrent request
+		return nil, ErrInvalidMFAChallenge
+	}
+	if err = s.loginSucceeded(ctx, u); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, u, "")
+}
+
+// mfaChallenge returns the response to a correct password of a user with
+// MFA enabled: a token to present along with the second factor.
+func (s *UserService) mfaChallenge(ctx context.Context, userID int64) (*model.TokenPair, error) {
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.mfaChallenges.CreateChallenge(ctx, hash, userID, s.mfaExpire); err != nil {
+		return nil, err
+	}
+	return &model.TokenPair{
+		MFAToken:  raw,
+		TokenType: "mfa",
+		ExpiresIn: int64(s.mfaExpire.Seconds()),
+	}, nil
+}
+
+// mfaEnabled reports whether the user has to present a second factor to log in.
+func (s *UserService) mfaEnabled(ctx context.Context, userID int64) (bool, error) {
+	if s.mfa == nil {
+		return false, nil
+	}
+	m, err := s.mfa.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return m != nil && m.EnabledAt != nil, nil
+}
+
+// checkSecondFactor spends code, which is either a TOTP code or a recovery
+// code, against the enabled second factor m.
+func (s *UserService) checkSecondFactor(ctx context.Context, m *model.UserMFA, code string) (bool, error) {
+	if !isTOTPCode(code) {
+		return s.mfa.UseRecoveryCode(ctx, m.UserID, hashToken(mfa.NormalizeRecoveryCode(code)))
+	}
+	secret, err := s.mfaCipher.Decrypt(m.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := mfa.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	// a code seen before may have been observed by someone else
+	return s.mfa.ClaimTOTPStep(ctx, m.UserID, step)
+}
+
+// checkMFACode returns ErrInvalidMFACode unless code is a current code or
+// an unused recovery code of m, counting wrong ones as failed logins of u.
+// The lockout itself is checked by checkCurrentPassword.
+func (s *UserService) checkMFACode(ctx context.Context, u *model.User, m *model.UserMFA, code string) error {
+	ok, err := s.checkSecondFactor(ctx, m, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err = s.recordLoginFailure(ctx, u); err != nil {
+			return err
+		}
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+// reauthenticate confirms a change that could take over the logins of u,
+// such as adding a passkey, with their current password and, if they have
+// MFA enabled, a second factor, so that a stolen session is not enough to
+// make it.
+func (s *UserService) reauthenticate(ctx context.Context, u *model.User, password, code string) error {
+	if err := s.checkCurrentPassword(ctx, u, password); err != nil {
+		return err
+	}
+	if s.mfa == nil {
+		return nil
+	}
+	m, err := s.mfa.Get(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	if m == nil || m.EnabledAt == nil {
+		return nil
+	}
+	return s.checkMFACode(ctx, u, m, code)
+}
+
+// isTOTPCode tells authenticator codes apart from recovery codes.
+func isTOTPCode(code string) bool {
+	if len(code) != 6 { //nolint:mnd
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
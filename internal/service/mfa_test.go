@@ -0,0 +1,273 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newMFAService(t *testing.T, mr *repoMocks.MockUserRepository, fr *repoMocks.MockMFARepository,
+	cs *repoMocks.MockMFAChallengeStore,
+) (*service.UserService, *mfa.Cipher) {
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithMFA(fr, cs, cipher, "User Service", 5*time.Minute))
+	return svc, cipher
+}
+
+// enabledMFA returns the enabled second factor of user 7 and its plain secret.
+func enabledMFA(t *testing.T, cipher *mfa.Cipher) (*model.UserMFA, string) {
+	secret, err := mfa.NewTOTPSecret()
+	require.NoError(t, err)
+	enc, err := cipher.Encrypt(secret)
+	require.NoError(t, err)
+	now := time.Now()
+	return &model.UserMFA{UserID: 7, TOTPSecret: enc, EnabledAt: &now}, secret
+}
+
+func TestLogin_MFAChallenge(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+	m, _ := enabledMFA(t, cipher)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "admin@x.com").
+		Return(&model.User{ID: 7, Email: "admin@x.com", PasswordHash: string(hash), Role: "admin"}, nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	var stored string
+	cs.On("CreateChallenge", mock.Anything, mock.AnythingOfType("string"), int64(7), 5*time.Minute).
+		Run(func(args mock.Arguments) { stored = args.String(1) }).
+		Return(nil)
+
+	tokens, err := svc.Login(t.Context(), "admin@x.com", "correct")
+	require.NoError(t, err)
+	// no access token until the second factor is presented
+	assert.Empty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.RefreshToken)
+	assert.NotEmpty(t, tokens.MFAToken)
+	assert.Equal(t, sha256Hex(tokens.MFAToken), stored)
+	assert.Equal(t, int64(300), tokens.ExpiresIn)
+	cs.AssertExpectations(t)
+}
+
+func TestLogin_MFANotEnrolled(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, _ := newMFAService(t, mr, fr, cs)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}, nil)
+	// a pending enrollment does not count
+	fr.On("Get", mock.Anything, int64(7)).Return(&model.UserMFA{UserID: 7, TOTPSecret: "enc"}, nil)
+
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.MFAToken)
+	cs.AssertNotCalled(t, "CreateChallenge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginMFA_TOTP(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+	m, secret := enabledMFA(t, cipher)
+	code, err := mfa.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("mfa")).Return(int64(7), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	fr.On("ClaimTOTPStep", mock.Anything, int64(7), mock.AnythingOfType("int64")).Return(true, nil)
+	cs.On("DeleteChallenge", mock.Anything, sha256Hex("mfa")).Return(true, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "admin"}, nil)
+
+	tokens, err := svc.LoginMFA(t.Context(), "mfa", code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.MFAToken)
+	fr.AssertExpectations(t)
+	cs.AssertExpectations(t)
+}
+
+func TestLoginMFA_ReplayedTOTP(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+	m, secret := enabledMFA(t, cipher)
+	code, err := mfa.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("mfa")).Return(int64(7), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	fr.On("ClaimTOTPStep", mock.Anything, int64(7), mock.AnythingOfType("int64")).Return(false, nil)
+	cs.On("FailChallenge", mock.Anything, sha256Hex("mfa")).Return(int64(1), nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "admin"}, nil)
+
+	_, err = svc.LoginMFA(t.Context(), "mfa", code)
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+	cs.AssertNotCalled(t, "DeleteChallenge", mock.Anything, mock.Anything)
+}
+
+func TestLoginMFA_RecoveryCode(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+	m, _ := enabledMFA(t, cipher)
+
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("mfa")).Return(int64(7), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	// typed without the dash and in upper case
+	fr.On("UseRecoveryCode", mock.Anything, int64(7), sha256Hex("abcde-fghij")).Return(true, nil)
+	cs.On("DeleteChallenge", mock.Anything, sha256Hex("mfa")).Return(true, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "admin"}, nil)
+
+	tokens, err := svc.LoginMFA(t.Context(), "mfa", "ABCDEFGHIJ")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	fr.AssertExpectations(t)
+}
+
+func TestLoginMFA_TooManyFailures(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+	m, _ := enabledMFA(t, cipher)
+
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("mfa")).Return(int64(7), nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	fr.On("UseRecoveryCode", mock.Anything, int64(7), mock.Anything).Return(false, nil)
+	cs.On("FailChallenge", mock.Anything, sha256Hex("mfa")).Return(int64(5), nil)
+	cs.On("DeleteChallenge", mock.Anything, sha256Hex("mfa")).Return(true, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "admin"}, nil)
+
+	_, err := svc.LoginMFA(t.Context(), "mfa", "wrong-guess")
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+	cs.AssertExpectations(t)
+}
+
+func TestLoginMFA_UnknownChallenge(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, _ := newMFAService(t, mr, fr, cs)
+
+	cs.On("ChallengeUser", mock.Anything, sha256Hex("expired")).Return(int64(0), nil)
+
+	_, err := svc.LoginMFA(t.Context(), "expired", "123456")
+	assert.ErrorIs(t, err, service.ErrInvalidMFAChallenge)
+}
+
+func TestEnrollAndConfirmTOTP(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	cs := new(repoMocks.MockMFAChallengeStore)
+	svc, cipher := newMFAService(t, mr, fr, cs)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "admin@x.com", PasswordHash: bcryptHash(t, "secret")}, nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(nil, nil).Once()
+	var stored string
+	fr.On("SavePending", mock.Anything, int64(7), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { stored = args.String(2) }).
+		Return(nil)
+
+	_, err := svc.EnrollTOTP(t.Context(), 7, "wrong")
+	assert.ErrorIs(t, err, service.ErrInvalidCurrentPassword)
+
+	enrollment, err := svc.EnrollTOTP(t.Context(), 7, "secret")
+	require.NoError(t, err)
+	assert.Contains(t, enrollment.URI, "otpauth://totp/")
+	assert.Contains(t, enrollment.URI, "secret="+enrollment.Secret)
+	// the secret is only stored encrypted
+	assert.NotEqual(t, enrollment.Secret, stored)
+	plain, err := cipher.Decrypt(stored)
+	require.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, plain)
+
+	fr.On("Get", mock.Anything, int64(7)).Return(&model.UserMFA{UserID: 7, TOTPSecret: stored}, nil)
+	var hashes []string
+	fr.On("Enable", mock.Anything, int64(7), mock.AnythingOfType("int64"), mock.AnythingOfType("[]string")).
+		Run(func(args mock.Arguments) { hashes = args.Get(3).([]string) }).
+		Return(true, nil)
+
+	code, err := mfa.TOTPCode(enrollment.Secret, time.Now())
+	require.NoError(t, err)
+	_, err = svc.ConfirmTOTP(t.Context(), 7, "abcdef")
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+
+	codes, err := svc.ConfirmTOTP(t.Context(), 7, code)
+	require.NoError(t, err)
+	assert.Len(t, codes, mfa.RecoveryCodeCount)
+	// only hashes of the recovery codes are persisted
+	require.Len(t, hashes, len(codes))
+	for i, c := range codes {
+		assert.Equal(t, sha256Hex(c), hashes[i])
+	}
+	fr.AssertExpectations(t)
+}
+
+func TestEnrollTOTP_AlreadyEnabled(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	svc, cipher := newMFAService(t, mr, fr, new(repoMocks.MockMFAChallengeStore))
+	m, _ := enabledMFA(t, cipher)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "admin@x.com", PasswordHash: bcryptHash(t, "secret")}, nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+
+	_, err := svc.EnrollTOTP(t.Context(), 7, "secret")
+	assert.ErrorIs(t, err, service.ErrMFAAlreadyEnabled)
+	fr.AssertNotCalled(t, "SavePending", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDisableMFA(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	fr := new(repoMocks.MockMFARepository)
+	svc, cipher := newMFAService(t, mr, fr, new(repoMocks.MockMFAChallengeStore))
+	m, secret := enabledMFA(t, cipher)
+	code, err := mfa.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Role: "user", PasswordHash: bcryptHash(t, "secret")}, nil)
+	fr.On("ClaimTOTPStep", mock.Anything, int64(7), mock.AnythingOfType("int64")).Return(true, nil)
+	fr.On("Delete", mock.Anything, int64(7)).Return(nil)
+
+	// the code alone does not do
+	assert.ErrorIs(t, svc.DisableMFA(t.Context(), 7, "wrong", code), service.ErrInvalidCurrentPassword)
+	require.NoError(t, svc.DisableMFA(t.Context(), 7, "secret", code))
+	fr.AssertExpectations(t)
+}
+
+func TestMFA_NotConfigured(t *testing.T) {
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	_, err := svc.EnrollTOTP(t.Context(), 7, "secret")
+	assert.ErrorIs(t, err, service.ErrMFADisabled)
+	_, err = svc.LoginMFA(t.Context(), "mfa", "123456")
+	assert.ErrorIs(t, err, service.ErrMFADisabled)
+}
@@ -6,6 +6,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/enson89/user-service-go/internal/model"
 	mock "github.com/stretchr/testify/mock"
@@ -38,6 +39,61 @@ func (_m *MockUserRepository) EXPECT() *MockUserRepository_Expecter {
 	return &MockUserRepository_Expecter{mock: &_m.Mock}
 }
 
+// BumpTokenVersion provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) BumpTokenVersion(ctx context.Context, id int64) (int64, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BumpTokenVersion")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_BumpTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BumpTokenVersion'
+type MockUserRepository_BumpTokenVersion_Call struct {
+	*mock.Call
+}
+
+// BumpTokenVersion is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserRepository_Expecter) BumpTokenVersion(ctx interface{}, id interface{}) *MockUserRepository_BumpTokenVersion_Call {
+	return &MockUserRepository_BumpTokenVersion_Call{Call: _e.mock.On("BumpTokenVersion", ctx, id)}
+}
+
+func (_c *MockUserRepository_BumpTokenVersion_Call) Run(run func(ctx context.Context, id int64)) *MockUserRepository_BumpTokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_BumpTokenVersion_Call) Return(n int64, err error) *MockUserRepository_BumpTokenVersion_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockUserRepository_BumpTokenVersion_Call) RunAndReturn(run func(ctx context.Context, id int64) (int64, error)) *MockUserRepository_BumpTokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function for the type MockUserRepository
 func (_mock *MockUserRepository) Create(ctx context.Context, u *model.User) error {
 	ret := _mock.Called(ctx, u)
@@ -85,20 +141,29 @@ func (_c *MockUserRepository_Create_Call) RunAndReturn(run func(ctx context.Cont
 }
 
 // Delete provides a mock function for the type MockUserRepository
-func (_mock *MockUserRepository) Delete(ctx context.Context, id int64) error {
+func (_mock *MockUserRepository) Delete(ctx context.Context, id int64) (bool, error) {
 	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
 		r0 = returnFunc(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(bool)
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
 // MockUserRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
@@ -120,12 +185,68 @@ func (_c *MockUserRepository_Delete_Call) Run(run func(ctx context.Context, id i
 	return _c
 }
 
-func (_c *MockUserRepository_Delete_Call) Return(err error) *MockUserRepository_Delete_Call {
-	_c.Call.Return(err)
+func (_c *MockUserRepository_Delete_Call) Return(b bool, err error) *MockUserRepository_Delete_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockUserRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockUserRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteInOrganization provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) DeleteInOrganization(ctx context.Context, orgID int64, id int64) (bool, error) {
+	ret := _mock.Called(ctx, orgID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteInOrganization")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, orgID, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, orgID, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_DeleteInOrganization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteInOrganization'
+type MockUserRepository_DeleteInOrganization_Call struct {
+	*mock.Call
+}
+
+// DeleteInOrganization is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - id
+func (_e *MockUserRepository_Expecter) DeleteInOrganization(ctx interface{}, orgID interface{}, id interface{}) *MockUserRepository_DeleteInOrganization_Call {
+	return &MockUserRepository_DeleteInOrganization_Call{Call: _e.mock.On("DeleteInOrganization", ctx, orgID, id)}
+}
+
+func (_c *MockUserRepository_DeleteInOrganization_Call) Run(run func(ctx context.Context, orgID int64, id int64)) *MockUserRepository_DeleteInOrganization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_DeleteInOrganization_Call) Return(b bool, err error) *MockUserRepository_DeleteInOrganization_Call {
+	_c.Call.Return(b, err)
 	return _c
 }
 
-func (_c *MockUserRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserRepository_Delete_Call {
+func (_c *MockUserRepository_DeleteInOrganization_Call) RunAndReturn(run func(ctx context.Context, orgID int64, id int64) (bool, error)) *MockUserRepository_DeleteInOrganization_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -244,6 +365,99 @@ func (_c *MockUserRepository_GetByID_Call) RunAndReturn(run func(ctx context.Con
 	return _c
 }
 
+// MarkEmailVerified provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) MarkEmailVerified(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkEmailVerified")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_MarkEmailVerified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkEmailVerified'
+type MockUserRepository_MarkEmailVerified_Call struct {
+	*mock.Call
+}
+
+// MarkEmailVerified is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserRepository_Expecter) MarkEmailVerified(ctx interface{}, id interface{}) *MockUserRepository_MarkEmailVerified_Call {
+	return &MockUserRepository_MarkEmailVerified_Call{Call: _e.mock.On("MarkEmailVerified", ctx, id)}
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) Run(run func(ctx context.Context, id int64)) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) Return(err error) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_MarkEmailVerified_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserRepository_MarkEmailVerified_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetLockedUntil provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) SetLockedUntil(ctx context.Context, id int64, until *time.Time) error {
+	ret := _mock.Called(ctx, id, until)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetLockedUntil")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, *time.Time) error); ok {
+		r0 = returnFunc(ctx, id, until)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_SetLockedUntil_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetLockedUntil'
+type MockUserRepository_SetLockedUntil_Call struct {
+	*mock.Call
+}
+
+// SetLockedUntil is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - until
+func (_e *MockUserRepository_Expecter) SetLockedUntil(ctx interface{}, id interface{}, until interface{}) *MockUserRepository_SetLockedUntil_Call {
+	return &MockUserRepository_SetLockedUntil_Call{Call: _e.mock.On("SetLockedUntil", ctx, id, until)}
+}
+
+func (_c *MockUserRepository_SetLockedUntil_Call) Run(run func(ctx context.Context, id int64, until *time.Time)) *MockUserRepository_SetLockedUntil_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(*time.Time))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_SetLockedUntil_Call) Return(err error) *MockUserRepository_SetLockedUntil_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_SetLockedUntil_Call) RunAndReturn(run func(ctx context.Context, id int64, until *time.Time) error) *MockUserRepository_SetLockedUntil_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Update provides a mock function for the type MockUserRepository
 func (_mock *MockUserRepository) Update(ctx context.Context, u *model.User) error {
 	ret := _mock.Called(ctx, u)
@@ -289,3 +503,5307 @@ func (_c *MockUserRepository_Update_Call) RunAndReturn(run func(ctx context.Cont
 	_c.Call.Return(run)
 	return _c
 }
+
+// UpdateEmail provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) UpdateEmail(ctx context.Context, id int64, email string) (bool, error) {
+	ret := _mock.Called(ctx, id, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEmail")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, id, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = returnFunc(ctx, id, email)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, id, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserRepository_UpdateEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateEmail'
+type MockUserRepository_UpdateEmail_Call struct {
+	*mock.Call
+}
+
+// UpdateEmail is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - email
+func (_e *MockUserRepository_Expecter) UpdateEmail(ctx interface{}, id interface{}, email interface{}) *MockUserRepository_UpdateEmail_Call {
+	return &MockUserRepository_UpdateEmail_Call{Call: _e.mock.On("UpdateEmail", ctx, id, email)}
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) Run(run func(ctx context.Context, id int64, email string)) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) Return(b bool, err error) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdateEmail_Call) RunAndReturn(run func(ctx context.Context, id int64, email string) (bool, error)) *MockUserRepository_UpdateEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePassword provides a mock function for the type MockUserRepository
+func (_mock *MockUserRepository) UpdatePassword(ctx context.Context, id int64, hash string) error {
+	ret := _mock.Called(ctx, id, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, id, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserRepository_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type MockUserRepository_UpdatePassword_Call struct {
+	*mock.Call
+}
+
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - hash
+func (_e *MockUserRepository_Expecter) UpdatePassword(ctx interface{}, id interface{}, hash interface{}) *MockUserRepository_UpdatePassword_Call {
+	return &MockUserRepository_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, id, hash)}
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Run(run func(ctx context.Context, id int64, hash string)) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) Return(err error) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserRepository_UpdatePassword_Call) RunAndReturn(run func(ctx context.Context, id int64, hash string) error) *MockUserRepository_UpdatePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRefreshTokenRepository creates a new instance of MockRefreshTokenRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRefreshTokenRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRefreshTokenRepository {
+	mock := &MockRefreshTokenRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRefreshTokenRepository is an autogenerated mock type for the RefreshTokenRepository type
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+type MockRefreshTokenRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRefreshTokenRepository) EXPECT() *MockRefreshTokenRepository_Expecter {
+	return &MockRefreshTokenRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockRefreshTokenRepository
+func (_mock *MockRefreshTokenRepository) Create(ctx context.Context, t *model.RefreshToken) error {
+	ret := _mock.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.RefreshToken) error); ok {
+		r0 = returnFunc(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokenRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockRefreshTokenRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - t
+func (_e *MockRefreshTokenRepository_Expecter) Create(ctx interface{}, t interface{}) *MockRefreshTokenRepository_Create_Call {
+	return &MockRefreshTokenRepository_Create_Call{Call: _e.mock.On("Create", ctx, t)}
+}
+
+func (_c *MockRefreshTokenRepository_Create_Call) Run(run func(ctx context.Context, t *model.RefreshToken)) *MockRefreshTokenRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.RefreshToken))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_Create_Call) Return(err error) *MockRefreshTokenRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_Create_Call) RunAndReturn(run func(ctx context.Context, t *model.RefreshToken) error) *MockRefreshTokenRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockRefreshTokenRepository
+func (_mock *MockRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.RefreshToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.RefreshToken, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.RefreshToken); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.RefreshToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRefreshTokenRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockRefreshTokenRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx
+//   - hash
+func (_e *MockRefreshTokenRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockRefreshTokenRepository_GetByHash_Call {
+	return &MockRefreshTokenRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockRefreshTokenRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockRefreshTokenRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_GetByHash_Call) Return(refreshToken *model.RefreshToken, err error) *MockRefreshTokenRepository_GetByHash_Call {
+	_c.Call.Return(refreshToken, err)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*model.RefreshToken, error)) *MockRefreshTokenRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkUsed provides a mock function for the type MockRefreshTokenRepository
+func (_mock *MockRefreshTokenRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRefreshTokenRepository_MarkUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkUsed'
+type MockRefreshTokenRepository_MarkUsed_Call struct {
+	*mock.Call
+}
+
+// MarkUsed is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockRefreshTokenRepository_Expecter) MarkUsed(ctx interface{}, id interface{}) *MockRefreshTokenRepository_MarkUsed_Call {
+	return &MockRefreshTokenRepository_MarkUsed_Call{Call: _e.mock.On("MarkUsed", ctx, id)}
+}
+
+func (_c *MockRefreshTokenRepository_MarkUsed_Call) Run(run func(ctx context.Context, id int64)) *MockRefreshTokenRepository_MarkUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_MarkUsed_Call) Return(b bool, err error) *MockRefreshTokenRepository_MarkUsed_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_MarkUsed_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockRefreshTokenRepository_MarkUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAllForUser provides a mock function for the type MockRefreshTokenRepository
+func (_mock *MockRefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAllForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokenRepository_RevokeAllForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAllForUser'
+type MockRefreshTokenRepository_RevokeAllForUser_Call struct {
+	*mock.Call
+}
+
+// RevokeAllForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockRefreshTokenRepository_Expecter) RevokeAllForUser(ctx interface{}, userID interface{}) *MockRefreshTokenRepository_RevokeAllForUser_Call {
+	return &MockRefreshTokenRepository_RevokeAllForUser_Call{Call: _e.mock.On("RevokeAllForUser", ctx, userID)}
+}
+
+func (_c *MockRefreshTokenRepository_RevokeAllForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockRefreshTokenRepository_RevokeAllForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeAllForUser_Call) Return(err error) *MockRefreshTokenRepository_RevokeAllForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeAllForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockRefreshTokenRepository_RevokeAllForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeFamily provides a mock function for the type MockRefreshTokenRepository
+func (_mock *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	ret := _mock.Called(ctx, familyID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeFamily")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRefreshTokenRepository_RevokeFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeFamily'
+type MockRefreshTokenRepository_RevokeFamily_Call struct {
+	*mock.Call
+}
+
+// RevokeFamily is a helper method to define mock.On call
+//   - ctx
+//   - familyID
+func (_e *MockRefreshTokenRepository_Expecter) RevokeFamily(ctx interface{}, familyID interface{}) *MockRefreshTokenRepository_RevokeFamily_Call {
+	return &MockRefreshTokenRepository_RevokeFamily_Call{Call: _e.mock.On("RevokeFamily", ctx, familyID)}
+}
+
+func (_c *MockRefreshTokenRepository_RevokeFamily_Call) Run(run func(ctx context.Context, familyID string)) *MockRefreshTokenRepository_RevokeFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeFamily_Call) Return(err error) *MockRefreshTokenRepository_RevokeFamily_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRefreshTokenRepository_RevokeFamily_Call) RunAndReturn(run func(ctx context.Context, familyID string) error) *MockRefreshTokenRepository_RevokeFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPasswordResetRepository creates a new instance of MockPasswordResetRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPasswordResetRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPasswordResetRepository {
+	mock := &MockPasswordResetRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPasswordResetRepository is an autogenerated mock type for the PasswordResetRepository type
+type MockPasswordResetRepository struct {
+	mock.Mock
+}
+
+type MockPasswordResetRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPasswordResetRepository) EXPECT() *MockPasswordResetRepository_Expecter {
+	return &MockPasswordResetRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockPasswordResetRepository
+func (_mock *MockPasswordResetRepository) Create(ctx context.Context, t *model.PasswordResetToken) error {
+	ret := _mock.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.PasswordResetToken) error); ok {
+		r0 = returnFunc(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPasswordResetRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockPasswordResetRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - t
+func (_e *MockPasswordResetRepository_Expecter) Create(ctx interface{}, t interface{}) *MockPasswordResetRepository_Create_Call {
+	return &MockPasswordResetRepository_Create_Call{Call: _e.mock.On("Create", ctx, t)}
+}
+
+func (_c *MockPasswordResetRepository_Create_Call) Run(run func(ctx context.Context, t *model.PasswordResetToken)) *MockPasswordResetRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.PasswordResetToken))
+	})
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_Create_Call) Return(err error) *MockPasswordResetRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_Create_Call) RunAndReturn(run func(ctx context.Context, t *model.PasswordResetToken) error) *MockPasswordResetRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockPasswordResetRepository
+func (_mock *MockPasswordResetRepository) GetByHash(ctx context.Context, hash string) (*model.PasswordResetToken, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.PasswordResetToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.PasswordResetToken, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.PasswordResetToken); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PasswordResetToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPasswordResetRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockPasswordResetRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx
+//   - hash
+func (_e *MockPasswordResetRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockPasswordResetRepository_GetByHash_Call {
+	return &MockPasswordResetRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockPasswordResetRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockPasswordResetRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_GetByHash_Call) Return(passwordResetToken *model.PasswordResetToken, err error) *MockPasswordResetRepository_GetByHash_Call {
+	_c.Call.Return(passwordResetToken, err)
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*model.PasswordResetToken, error)) *MockPasswordResetRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InvalidateForUser provides a mock function for the type MockPasswordResetRepository
+func (_mock *MockPasswordResetRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPasswordResetRepository_InvalidateForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InvalidateForUser'
+type MockPasswordResetRepository_InvalidateForUser_Call struct {
+	*mock.Call
+}
+
+// InvalidateForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockPasswordResetRepository_Expecter) InvalidateForUser(ctx interface{}, userID interface{}) *MockPasswordResetRepository_InvalidateForUser_Call {
+	return &MockPasswordResetRepository_InvalidateForUser_Call{Call: _e.mock.On("InvalidateForUser", ctx, userID)}
+}
+
+func (_c *MockPasswordResetRepository_InvalidateForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockPasswordResetRepository_InvalidateForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_InvalidateForUser_Call) Return(err error) *MockPasswordResetRepository_InvalidateForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_InvalidateForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockPasswordResetRepository_InvalidateForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkUsed provides a mock function for the type MockPasswordResetRepository
+func (_mock *MockPasswordResetRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPasswordResetRepository_MarkUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkUsed'
+type MockPasswordResetRepository_MarkUsed_Call struct {
+	*mock.Call
+}
+
+// MarkUsed is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockPasswordResetRepository_Expecter) MarkUsed(ctx interface{}, id interface{}) *MockPasswordResetRepository_MarkUsed_Call {
+	return &MockPasswordResetRepository_MarkUsed_Call{Call: _e.mock.On("MarkUsed", ctx, id)}
+}
+
+func (_c *MockPasswordResetRepository_MarkUsed_Call) Run(run func(ctx context.Context, id int64)) *MockPasswordResetRepository_MarkUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_MarkUsed_Call) Return(b bool, err error) *MockPasswordResetRepository_MarkUsed_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockPasswordResetRepository_MarkUsed_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockPasswordResetRepository_MarkUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockEmailVerificationRepository creates a new instance of MockEmailVerificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockEmailVerificationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockEmailVerificationRepository {
+	mock := &MockEmailVerificationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockEmailVerificationRepository is an autogenerated mock type for the EmailVerificationRepository type
+type MockEmailVerificationRepository struct {
+	mock.Mock
+}
+
+type MockEmailVerificationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockEmailVerificationRepository) EXPECT() *MockEmailVerificationRepository_Expecter {
+	return &MockEmailVerificationRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockEmailVerificationRepository
+func (_mock *MockEmailVerificationRepository) Create(ctx context.Context, t *model.EmailVerificationToken) error {
+	ret := _mock.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.EmailVerificationToken) error); ok {
+		r0 = returnFunc(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEmailVerificationRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockEmailVerificationRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - t
+func (_e *MockEmailVerificationRepository_Expecter) Create(ctx interface{}, t interface{}) *MockEmailVerificationRepository_Create_Call {
+	return &MockEmailVerificationRepository_Create_Call{Call: _e.mock.On("Create", ctx, t)}
+}
+
+func (_c *MockEmailVerificationRepository_Create_Call) Run(run func(ctx context.Context, t *model.EmailVerificationToken)) *MockEmailVerificationRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.EmailVerificationToken))
+	})
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_Create_Call) Return(err error) *MockEmailVerificationRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_Create_Call) RunAndReturn(run func(ctx context.Context, t *model.EmailVerificationToken) error) *MockEmailVerificationRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockEmailVerificationRepository
+func (_mock *MockEmailVerificationRepository) GetByHash(ctx context.Context, hash string) (*model.EmailVerificationToken, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.EmailVerificationToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.EmailVerificationToken, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.EmailVerificationToken); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EmailVerificationToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEmailVerificationRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockEmailVerificationRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx
+//   - hash
+func (_e *MockEmailVerificationRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockEmailVerificationRepository_GetByHash_Call {
+	return &MockEmailVerificationRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockEmailVerificationRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockEmailVerificationRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_GetByHash_Call) Return(emailVerificationToken *model.EmailVerificationToken, err error) *MockEmailVerificationRepository_GetByHash_Call {
+	_c.Call.Return(emailVerificationToken, err)
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*model.EmailVerificationToken, error)) *MockEmailVerificationRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InvalidateForUser provides a mock function for the type MockEmailVerificationRepository
+func (_mock *MockEmailVerificationRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEmailVerificationRepository_InvalidateForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InvalidateForUser'
+type MockEmailVerificationRepository_InvalidateForUser_Call struct {
+	*mock.Call
+}
+
+// InvalidateForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockEmailVerificationRepository_Expecter) InvalidateForUser(ctx interface{}, userID interface{}) *MockEmailVerificationRepository_InvalidateForUser_Call {
+	return &MockEmailVerificationRepository_InvalidateForUser_Call{Call: _e.mock.On("InvalidateForUser", ctx, userID)}
+}
+
+func (_c *MockEmailVerificationRepository_InvalidateForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockEmailVerificationRepository_InvalidateForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_InvalidateForUser_Call) Return(err error) *MockEmailVerificationRepository_InvalidateForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_InvalidateForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockEmailVerificationRepository_InvalidateForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkUsed provides a mock function for the type MockEmailVerificationRepository
+func (_mock *MockEmailVerificationRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEmailVerificationRepository_MarkUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkUsed'
+type MockEmailVerificationRepository_MarkUsed_Call struct {
+	*mock.Call
+}
+
+// MarkUsed is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockEmailVerificationRepository_Expecter) MarkUsed(ctx interface{}, id interface{}) *MockEmailVerificationRepository_MarkUsed_Call {
+	return &MockEmailVerificationRepository_MarkUsed_Call{Call: _e.mock.On("MarkUsed", ctx, id)}
+}
+
+func (_c *MockEmailVerificationRepository_MarkUsed_Call) Run(run func(ctx context.Context, id int64)) *MockEmailVerificationRepository_MarkUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_MarkUsed_Call) Return(b bool, err error) *MockEmailVerificationRepository_MarkUsed_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockEmailVerificationRepository_MarkUsed_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockEmailVerificationRepository_MarkUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockNotifier creates a new instance of MockNotifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockNotifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockNotifier {
+	mock := &MockNotifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockNotifier is an autogenerated mock type for the Notifier type
+type MockNotifier struct {
+	mock.Mock
+}
+
+type MockNotifier_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockNotifier) EXPECT() *MockNotifier_Expecter {
+	return &MockNotifier_Expecter{mock: &_m.Mock}
+}
+
+// Notify provides a mock function for the type MockNotifier
+func (_mock *MockNotifier) Notify(ctx context.Context, n model.Notification) error {
+	ret := _mock.Called(ctx, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Notify")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, model.Notification) error); ok {
+		r0 = returnFunc(ctx, n)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockNotifier_Notify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Notify'
+type MockNotifier_Notify_Call struct {
+	*mock.Call
+}
+
+// Notify is a helper method to define mock.On call
+//   - ctx
+//   - n
+func (_e *MockNotifier_Expecter) Notify(ctx interface{}, n interface{}) *MockNotifier_Notify_Call {
+	return &MockNotifier_Notify_Call{Call: _e.mock.On("Notify", ctx, n)}
+}
+
+func (_c *MockNotifier_Notify_Call) Run(run func(ctx context.Context, n model.Notification)) *MockNotifier_Notify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(model.Notification))
+	})
+	return _c
+}
+
+func (_c *MockNotifier_Notify_Call) Return(err error) *MockNotifier_Notify_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockNotifier_Notify_Call) RunAndReturn(run func(ctx context.Context, n model.Notification) error) *MockNotifier_Notify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMFARepository creates a new instance of MockMFARepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMFARepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMFARepository {
+	mock := &MockMFARepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMFARepository is an autogenerated mock type for the MFARepository type
+type MockMFARepository struct {
+	mock.Mock
+}
+
+type MockMFARepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMFARepository) EXPECT() *MockMFARepository_Expecter {
+	return &MockMFARepository_Expecter{mock: &_m.Mock}
+}
+
+// ClaimTOTPStep provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) ClaimTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	ret := _mock.Called(ctx, userID, step)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimTOTPStep")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, userID, step)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, userID, step)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, userID, step)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFARepository_ClaimTOTPStep_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClaimTOTPStep'
+type MockMFARepository_ClaimTOTPStep_Call struct {
+	*mock.Call
+}
+
+// ClaimTOTPStep is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - step
+func (_e *MockMFARepository_Expecter) ClaimTOTPStep(ctx interface{}, userID interface{}, step interface{}) *MockMFARepository_ClaimTOTPStep_Call {
+	return &MockMFARepository_ClaimTOTPStep_Call{Call: _e.mock.On("ClaimTOTPStep", ctx, userID, step)}
+}
+
+func (_c *MockMFARepository_ClaimTOTPStep_Call) Run(run func(ctx context.Context, userID int64, step int64)) *MockMFARepository_ClaimTOTPStep_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_ClaimTOTPStep_Call) Return(b bool, err error) *MockMFARepository_ClaimTOTPStep_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMFARepository_ClaimTOTPStep_Call) RunAndReturn(run func(ctx context.Context, userID int64, step int64) (bool, error)) *MockMFARepository_ClaimTOTPStep_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) Delete(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMFARepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockMFARepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockMFARepository_Expecter) Delete(ctx interface{}, userID interface{}) *MockMFARepository_Delete_Call {
+	return &MockMFARepository_Delete_Call{Call: _e.mock.On("Delete", ctx, userID)}
+}
+
+func (_c *MockMFARepository_Delete_Call) Run(run func(ctx context.Context, userID int64)) *MockMFARepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_Delete_Call) Return(err error) *MockMFARepository_Delete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMFARepository_Delete_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockMFARepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Enable provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) Enable(ctx context.Context, userID int64, step int64, codeHashes []string) (bool, error) {
+	ret := _mock.Called(ctx, userID, step, codeHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Enable")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, []string) (bool, error)); ok {
+		return returnFunc(ctx, userID, step, codeHashes)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, []string) bool); ok {
+		r0 = returnFunc(ctx, userID, step, codeHashes)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, []string) error); ok {
+		r1 = returnFunc(ctx, userID, step, codeHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFARepository_Enable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Enable'
+type MockMFARepository_Enable_Call struct {
+	*mock.Call
+}
+
+// Enable is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - step
+//   - codeHashes
+func (_e *MockMFARepository_Expecter) Enable(ctx interface{}, userID interface{}, step interface{}, codeHashes interface{}) *MockMFARepository_Enable_Call {
+	return &MockMFARepository_Enable_Call{Call: _e.mock.On("Enable", ctx, userID, step, codeHashes)}
+}
+
+func (_c *MockMFARepository_Enable_Call) Run(run func(ctx context.Context, userID int64, step int64, codeHashes []string)) *MockMFARepository_Enable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_Enable_Call) Return(b bool, err error) *MockMFARepository_Enable_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMFARepository_Enable_Call) RunAndReturn(run func(ctx context.Context, userID int64, step int64, codeHashes []string) (bool, error)) *MockMFARepository_Enable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) Get(ctx context.Context, userID int64) (*model.UserMFA, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.UserMFA
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.UserMFA, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.UserMFA); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.UserMFA)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFARepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockMFARepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockMFARepository_Expecter) Get(ctx interface{}, userID interface{}) *MockMFARepository_Get_Call {
+	return &MockMFARepository_Get_Call{Call: _e.mock.On("Get", ctx, userID)}
+}
+
+func (_c *MockMFARepository_Get_Call) Run(run func(ctx context.Context, userID int64)) *MockMFARepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_Get_Call) Return(userMFA *model.UserMFA, err error) *MockMFARepository_Get_Call {
+	_c.Call.Return(userMFA, err)
+	return _c
+}
+
+func (_c *MockMFARepository_Get_Call) RunAndReturn(run func(ctx context.Context, userID int64) (*model.UserMFA, error)) *MockMFARepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SavePending provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) SavePending(ctx context.Context, userID int64, secret string) error {
+	ret := _mock.Called(ctx, userID, secret)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePending")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, secret)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMFARepository_SavePending_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePending'
+type MockMFARepository_SavePending_Call struct {
+	*mock.Call
+}
+
+// SavePending is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - secret
+func (_e *MockMFARepository_Expecter) SavePending(ctx interface{}, userID interface{}, secret interface{}) *MockMFARepository_SavePending_Call {
+	return &MockMFARepository_SavePending_Call{Call: _e.mock.On("SavePending", ctx, userID, secret)}
+}
+
+func (_c *MockMFARepository_SavePending_Call) Run(run func(ctx context.Context, userID int64, secret string)) *MockMFARepository_SavePending_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_SavePending_Call) Return(err error) *MockMFARepository_SavePending_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMFARepository_SavePending_Call) RunAndReturn(run func(ctx context.Context, userID int64, secret string) error) *MockMFARepository_SavePending_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UseRecoveryCode provides a mock function for the type MockMFARepository
+func (_mock *MockMFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	ret := _mock.Called(ctx, userID, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UseRecoveryCode")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, userID, codeHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = returnFunc(ctx, userID, codeHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, userID, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFARepository_UseRecoveryCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseRecoveryCode'
+type MockMFARepository_UseRecoveryCode_Call struct {
+	*mock.Call
+}
+
+// UseRecoveryCode is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - codeHash
+func (_e *MockMFARepository_Expecter) UseRecoveryCode(ctx interface{}, userID interface{}, codeHash interface{}) *MockMFARepository_UseRecoveryCode_Call {
+	return &MockMFARepository_UseRecoveryCode_Call{Call: _e.mock.On("UseRecoveryCode", ctx, userID, codeHash)}
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) Run(run func(ctx context.Context, userID int64, codeHash string)) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) Return(b bool, err error) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMFARepository_UseRecoveryCode_Call) RunAndReturn(run func(ctx context.Context, userID int64, codeHash string) (bool, error)) *MockMFARepository_UseRecoveryCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMFAChallengeStore creates a new instance of MockMFAChallengeStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMFAChallengeStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMFAChallengeStore {
+	mock := &MockMFAChallengeStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMFAChallengeStore is an autogenerated mock type for the MFAChallengeStore type
+type MockMFAChallengeStore struct {
+	mock.Mock
+}
+
+type MockMFAChallengeStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMFAChallengeStore) EXPECT() *MockMFAChallengeStore_Expecter {
+	return &MockMFAChallengeStore_Expecter{mock: &_m.Mock}
+}
+
+// ChallengeUser provides a mock function for the type MockMFAChallengeStore
+func (_mock *MockMFAChallengeStore) ChallengeUser(ctx context.Context, tokenHash string) (int64, error) {
+	ret := _mock.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChallengeUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return returnFunc(ctx, tokenHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = returnFunc(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFAChallengeStore_ChallengeUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChallengeUser'
+type MockMFAChallengeStore_ChallengeUser_Call struct {
+	*mock.Call
+}
+
+// ChallengeUser is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+func (_e *MockMFAChallengeStore_Expecter) ChallengeUser(ctx interface{}, tokenHash interface{}) *MockMFAChallengeStore_ChallengeUser_Call {
+	return &MockMFAChallengeStore_ChallengeUser_Call{Call: _e.mock.On("ChallengeUser", ctx, tokenHash)}
+}
+
+func (_c *MockMFAChallengeStore_ChallengeUser_Call) Run(run func(ctx context.Context, tokenHash string)) *MockMFAChallengeStore_ChallengeUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_ChallengeUser_Call) Return(n int64, err error) *MockMFAChallengeStore_ChallengeUser_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_ChallengeUser_Call) RunAndReturn(run func(ctx context.Context, tokenHash string) (int64, error)) *MockMFAChallengeStore_ChallengeUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateChallenge provides a mock function for the type MockMFAChallengeStore
+func (_mock *MockMFAChallengeStore) CreateChallenge(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error {
+	ret := _mock.Called(ctx, tokenHash, userID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateChallenge")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) error); ok {
+		r0 = returnFunc(ctx, tokenHash, userID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMFAChallengeStore_CreateChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateChallenge'
+type MockMFAChallengeStore_CreateChallenge_Call struct {
+	*mock.Call
+}
+
+// CreateChallenge is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+//   - userID
+//   - ttl
+func (_e *MockMFAChallengeStore_Expecter) CreateChallenge(ctx interface{}, tokenHash interface{}, userID interface{}, ttl interface{}) *MockMFAChallengeStore_CreateChallenge_Call {
+	return &MockMFAChallengeStore_CreateChallenge_Call{Call: _e.mock.On("CreateChallenge", ctx, tokenHash, userID, ttl)}
+}
+
+func (_c *MockMFAChallengeStore_CreateChallenge_Call) Run(run func(ctx context.Context, tokenHash string, userID int64, ttl time.Duration)) *MockMFAChallengeStore_CreateChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_CreateChallenge_Call) Return(err error) *MockMFAChallengeStore_CreateChallenge_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_CreateChallenge_Call) RunAndReturn(run func(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error) *MockMFAChallengeStore_CreateChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteChallenge provides a mock function for the type MockMFAChallengeStore
+func (_mock *MockMFAChallengeStore) DeleteChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	ret := _mock.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteChallenge")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return returnFunc(ctx, tokenHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = returnFunc(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFAChallengeStore_DeleteChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteChallenge'
+type MockMFAChallengeStore_DeleteChallenge_Call struct {
+	*mock.Call
+}
+
+// DeleteChallenge is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+func (_e *MockMFAChallengeStore_Expecter) DeleteChallenge(ctx interface{}, tokenHash interface{}) *MockMFAChallengeStore_DeleteChallenge_Call {
+	return &MockMFAChallengeStore_DeleteChallenge_Call{Call: _e.mock.On("DeleteChallenge", ctx, tokenHash)}
+}
+
+func (_c *MockMFAChallengeStore_DeleteChallenge_Call) Run(run func(ctx context.Context, tokenHash string)) *MockMFAChallengeStore_DeleteChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_DeleteChallenge_Call) Return(b bool, err error) *MockMFAChallengeStore_DeleteChallenge_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_DeleteChallenge_Call) RunAndReturn(run func(ctx context.Context, tokenHash string) (bool, error)) *MockMFAChallengeStore_DeleteChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FailChallenge provides a mock function for the type MockMFAChallengeStore
+func (_mock *MockMFAChallengeStore) FailChallenge(ctx context.Context, tokenHash string) (int64, error) {
+	ret := _mock.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FailChallenge")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return returnFunc(ctx, tokenHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = returnFunc(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMFAChallengeStore_FailChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FailChallenge'
+type MockMFAChallengeStore_FailChallenge_Call struct {
+	*mock.Call
+}
+
+// FailChallenge is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+func (_e *MockMFAChallengeStore_Expecter) FailChallenge(ctx interface{}, tokenHash interface{}) *MockMFAChallengeStore_FailChallenge_Call {
+	return &MockMFAChallengeStore_FailChallenge_Call{Call: _e.mock.On("FailChallenge", ctx, tokenHash)}
+}
+
+func (_c *MockMFAChallengeStore_FailChallenge_Call) Run(run func(ctx context.Context, tokenHash string)) *MockMFAChallengeStore_FailChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_FailChallenge_Call) Return(n int64, err error) *MockMFAChallengeStore_FailChallenge_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockMFAChallengeStore_FailChallenge_Call) RunAndReturn(run func(ctx context.Context, tokenHash string) (int64, error)) *MockMFAChallengeStore_FailChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockWebAuthnCredentialRepository creates a new instance of MockWebAuthnCredentialRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebAuthnCredentialRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebAuthnCredentialRepository {
+	mock := &MockWebAuthnCredentialRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockWebAuthnCredentialRepository is an autogenerated mock type for the WebAuthnCredentialRepository type
+type MockWebAuthnCredentialRepository struct {
+	mock.Mock
+}
+
+type MockWebAuthnCredentialRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockWebAuthnCredentialRepository) EXPECT() *MockWebAuthnCredentialRepository_Expecter {
+	return &MockWebAuthnCredentialRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) Create(ctx context.Context, c *model.WebAuthnCredential) error {
+	ret := _mock.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.WebAuthnCredential) error); ok {
+		r0 = returnFunc(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebAuthnCredentialRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockWebAuthnCredentialRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - c
+func (_e *MockWebAuthnCredentialRepository_Expecter) Create(ctx interface{}, c interface{}) *MockWebAuthnCredentialRepository_Create_Call {
+	return &MockWebAuthnCredentialRepository_Create_Call{Call: _e.mock.On("Create", ctx, c)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_Create_Call) Run(run func(ctx context.Context, c *model.WebAuthnCredential)) *MockWebAuthnCredentialRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.WebAuthnCredential))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_Create_Call) Return(err error) *MockWebAuthnCredentialRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_Create_Call) RunAndReturn(run func(ctx context.Context, c *model.WebAuthnCredential) error) *MockWebAuthnCredentialRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) Delete(ctx context.Context, userID int64, id int64) (bool, error) {
+	ret := _mock.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, userID, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, userID, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebAuthnCredentialRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockWebAuthnCredentialRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - id
+func (_e *MockWebAuthnCredentialRepository_Expecter) Delete(ctx interface{}, userID interface{}, id interface{}) *MockWebAuthnCredentialRepository_Delete_Call {
+	return &MockWebAuthnCredentialRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, userID, id)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_Delete_Call) Run(run func(ctx context.Context, userID int64, id int64)) *MockWebAuthnCredentialRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_Delete_Call) Return(b bool, err error) *MockWebAuthnCredentialRepository_Delete_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, userID int64, id int64) (bool, error)) *MockWebAuthnCredentialRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAllForUser provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) DeleteAllForUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebAuthnCredentialRepository_DeleteAllForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAllForUser'
+type MockWebAuthnCredentialRepository_DeleteAllForUser_Call struct {
+	*mock.Call
+}
+
+// DeleteAllForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockWebAuthnCredentialRepository_Expecter) DeleteAllForUser(ctx interface{}, userID interface{}) *MockWebAuthnCredentialRepository_DeleteAllForUser_Call {
+	return &MockWebAuthnCredentialRepository_DeleteAllForUser_Call{Call: _e.mock.On("DeleteAllForUser", ctx, userID)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_DeleteAllForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockWebAuthnCredentialRepository_DeleteAllForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_DeleteAllForUser_Call) Return(err error) *MockWebAuthnCredentialRepository_DeleteAllForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_DeleteAllForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockWebAuthnCredentialRepository_DeleteAllForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByUser provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) ListByUser(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByUser")
+	}
+
+	var r0 []model.WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebAuthnCredentialRepository_ListByUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByUser'
+type MockWebAuthnCredentialRepository_ListByUser_Call struct {
+	*mock.Call
+}
+
+// ListByUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockWebAuthnCredentialRepository_Expecter) ListByUser(ctx interface{}, userID interface{}) *MockWebAuthnCredentialRepository_ListByUser_Call {
+	return &MockWebAuthnCredentialRepository_ListByUser_Call{Call: _e.mock.On("ListByUser", ctx, userID)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_ListByUser_Call) Run(run func(ctx context.Context, userID int64)) *MockWebAuthnCredentialRepository_ListByUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_ListByUser_Call) Return(webAuthnCredentials []model.WebAuthnCredential, err error) *MockWebAuthnCredentialRepository_ListByUser_Call {
+	_c.Call.Return(webAuthnCredentials, err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_ListByUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)) *MockWebAuthnCredentialRepository_ListByUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkCloned provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) MarkCloned(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkCloned")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebAuthnCredentialRepository_MarkCloned_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkCloned'
+type MockWebAuthnCredentialRepository_MarkCloned_Call struct {
+	*mock.Call
+}
+
+// MarkCloned is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockWebAuthnCredentialRepository_Expecter) MarkCloned(ctx interface{}, id interface{}) *MockWebAuthnCredentialRepository_MarkCloned_Call {
+	return &MockWebAuthnCredentialRepository_MarkCloned_Call{Call: _e.mock.On("MarkCloned", ctx, id)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_MarkCloned_Call) Run(run func(ctx context.Context, id int64)) *MockWebAuthnCredentialRepository_MarkCloned_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_MarkCloned_Call) Return(err error) *MockWebAuthnCredentialRepository_MarkCloned_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_MarkCloned_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockWebAuthnCredentialRepository_MarkCloned_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordUse provides a mock function for the type MockWebAuthnCredentialRepository
+func (_mock *MockWebAuthnCredentialRepository) RecordUse(ctx context.Context, id int64, signCount int64, backupState bool) error {
+	ret := _mock.Called(ctx, id, signCount, backupState)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordUse")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, bool) error); ok {
+		r0 = returnFunc(ctx, id, signCount, backupState)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebAuthnCredentialRepository_RecordUse_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordUse'
+type MockWebAuthnCredentialRepository_RecordUse_Call struct {
+	*mock.Call
+}
+
+// RecordUse is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - signCount
+//   - backupState
+func (_e *MockWebAuthnCredentialRepository_Expecter) RecordUse(ctx interface{}, id interface{}, signCount interface{}, backupState interface{}) *MockWebAuthnCredentialRepository_RecordUse_Call {
+	return &MockWebAuthnCredentialRepository_RecordUse_Call{Call: _e.mock.On("RecordUse", ctx, id, signCount, backupState)}
+}
+
+func (_c *MockWebAuthnCredentialRepository_RecordUse_Call) Run(run func(ctx context.Context, id int64, signCount int64, backupState bool)) *MockWebAuthnCredentialRepository_RecordUse_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_RecordUse_Call) Return(err error) *MockWebAuthnCredentialRepository_RecordUse_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebAuthnCredentialRepository_RecordUse_Call) RunAndReturn(run func(ctx context.Context, id int64, signCount int64, backupState bool) error) *MockWebAuthnCredentialRepository_RecordUse_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockWebAuthnSessionStore creates a new instance of MockWebAuthnSessionStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockWebAuthnSessionStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockWebAuthnSessionStore {
+	mock := &MockWebAuthnSessionStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockWebAuthnSessionStore is an autogenerated mock type for the WebAuthnSessionStore type
+type MockWebAuthnSessionStore struct {
+	mock.Mock
+}
+
+type MockWebAuthnSessionStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockWebAuthnSessionStore) EXPECT() *MockWebAuthnSessionStore_Expecter {
+	return &MockWebAuthnSessionStore_Expecter{mock: &_m.Mock}
+}
+
+// SaveSession provides a mock function for the type MockWebAuthnSessionStore
+func (_mock *MockWebAuthnSessionStore) SaveSession(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	ret := _mock.Called(ctx, key, data, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []byte, time.Duration) error); ok {
+		r0 = returnFunc(ctx, key, data, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWebAuthnSessionStore_SaveSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSession'
+type MockWebAuthnSessionStore_SaveSession_Call struct {
+	*mock.Call
+}
+
+// SaveSession is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - data
+//   - ttl
+func (_e *MockWebAuthnSessionStore_Expecter) SaveSession(ctx interface{}, key interface{}, data interface{}, ttl interface{}) *MockWebAuthnSessionStore_SaveSession_Call {
+	return &MockWebAuthnSessionStore_SaveSession_Call{Call: _e.mock.On("SaveSession", ctx, key, data, ttl)}
+}
+
+func (_c *MockWebAuthnSessionStore_SaveSession_Call) Run(run func(ctx context.Context, key string, data []byte, ttl time.Duration)) *MockWebAuthnSessionStore_SaveSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnSessionStore_SaveSession_Call) Return(err error) *MockWebAuthnSessionStore_SaveSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWebAuthnSessionStore_SaveSession_Call) RunAndReturn(run func(ctx context.Context, key string, data []byte, ttl time.Duration) error) *MockWebAuthnSessionStore_SaveSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TakeSession provides a mock function for the type MockWebAuthnSessionStore
+func (_mock *MockWebAuthnSessionStore) TakeSession(ctx context.Context, key string) ([]byte, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TakeSession")
+	}
+
+	var r0 []byte
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) ([]byte, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) []byte); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWebAuthnSessionStore_TakeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TakeSession'
+type MockWebAuthnSessionStore_TakeSession_Call struct {
+	*mock.Call
+}
+
+// TakeSession is a helper method to define mock.On call
+//   - ctx
+//   - key
+func (_e *MockWebAuthnSessionStore_Expecter) TakeSession(ctx interface{}, key interface{}) *MockWebAuthnSessionStore_TakeSession_Call {
+	return &MockWebAuthnSessionStore_TakeSession_Call{Call: _e.mock.On("TakeSession", ctx, key)}
+}
+
+func (_c *MockWebAuthnSessionStore_TakeSession_Call) Run(run func(ctx context.Context, key string)) *MockWebAuthnSessionStore_TakeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockWebAuthnSessionStore_TakeSession_Call) Return(bytes []byte, err error) *MockWebAuthnSessionStore_TakeSession_Call {
+	_c.Call.Return(bytes, err)
+	return _c
+}
+
+func (_c *MockWebAuthnSessionStore_TakeSession_Call) RunAndReturn(run func(ctx context.Context, key string) ([]byte, error)) *MockWebAuthnSessionStore_TakeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMagicLinkStore creates a new instance of MockMagicLinkStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMagicLinkStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMagicLinkStore {
+	mock := &MockMagicLinkStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMagicLinkStore is an autogenerated mock type for the MagicLinkStore type
+type MockMagicLinkStore struct {
+	mock.Mock
+}
+
+type MockMagicLinkStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMagicLinkStore) EXPECT() *MockMagicLinkStore_Expecter {
+	return &MockMagicLinkStore_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeMagicLink provides a mock function for the type MockMagicLinkStore
+func (_mock *MockMagicLinkStore) ConsumeMagicLink(ctx context.Context, tokenHash string) (int64, error) {
+	ret := _mock.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeMagicLink")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return returnFunc(ctx, tokenHash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = returnFunc(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMagicLinkStore_ConsumeMagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeMagicLink'
+type MockMagicLinkStore_ConsumeMagicLink_Call struct {
+	*mock.Call
+}
+
+// ConsumeMagicLink is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+func (_e *MockMagicLinkStore_Expecter) ConsumeMagicLink(ctx interface{}, tokenHash interface{}) *MockMagicLinkStore_ConsumeMagicLink_Call {
+	return &MockMagicLinkStore_ConsumeMagicLink_Call{Call: _e.mock.On("ConsumeMagicLink", ctx, tokenHash)}
+}
+
+func (_c *MockMagicLinkStore_ConsumeMagicLink_Call) Run(run func(ctx context.Context, tokenHash string)) *MockMagicLinkStore_ConsumeMagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockMagicLinkStore_ConsumeMagicLink_Call) Return(n int64, err error) *MockMagicLinkStore_ConsumeMagicLink_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockMagicLinkStore_ConsumeMagicLink_Call) RunAndReturn(run func(ctx context.Context, tokenHash string) (int64, error)) *MockMagicLinkStore_ConsumeMagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveMagicLink provides a mock function for the type MockMagicLinkStore
+func (_mock *MockMagicLinkStore) SaveMagicLink(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error {
+	ret := _mock.Called(ctx, tokenHash, userID, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveMagicLink")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, int64, time.Duration) error); ok {
+		r0 = returnFunc(ctx, tokenHash, userID, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMagicLinkStore_SaveMagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveMagicLink'
+type MockMagicLinkStore_SaveMagicLink_Call struct {
+	*mock.Call
+}
+
+// SaveMagicLink is a helper method to define mock.On call
+//   - ctx
+//   - tokenHash
+//   - userID
+//   - ttl
+func (_e *MockMagicLinkStore_Expecter) SaveMagicLink(ctx interface{}, tokenHash interface{}, userID interface{}, ttl interface{}) *MockMagicLinkStore_SaveMagicLink_Call {
+	return &MockMagicLinkStore_SaveMagicLink_Call{Call: _e.mock.On("SaveMagicLink", ctx, tokenHash, userID, ttl)}
+}
+
+func (_c *MockMagicLinkStore_SaveMagicLink_Call) Run(run func(ctx context.Context, tokenHash string, userID int64, ttl time.Duration)) *MockMagicLinkStore_SaveMagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockMagicLinkStore_SaveMagicLink_Call) Return(err error) *MockMagicLinkStore_SaveMagicLink_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMagicLinkStore_SaveMagicLink_Call) RunAndReturn(run func(ctx context.Context, tokenHash string, userID int64, ttl time.Duration) error) *MockMagicLinkStore_SaveMagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLoginAttemptStore creates a new instance of MockLoginAttemptStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLoginAttemptStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLoginAttemptStore {
+	mock := &MockLoginAttemptStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockLoginAttemptStore is an autogenerated mock type for the LoginAttemptStore type
+type MockLoginAttemptStore struct {
+	mock.Mock
+}
+
+type MockLoginAttemptStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLoginAttemptStore) EXPECT() *MockLoginAttemptStore_Expecter {
+	return &MockLoginAttemptStore_Expecter{mock: &_m.Mock}
+}
+
+// Lock provides a mock function for the type MockLoginAttemptStore
+func (_mock *MockLoginAttemptStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	ret := _mock.Called(ctx, key, d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Lock")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) error); ok {
+		r0 = returnFunc(ctx, key, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLoginAttemptStore_Lock_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Lock'
+type MockLoginAttemptStore_Lock_Call struct {
+	*mock.Call
+}
+
+// Lock is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - d
+func (_e *MockLoginAttemptStore_Expecter) Lock(ctx interface{}, key interface{}, d interface{}) *MockLoginAttemptStore_Lock_Call {
+	return &MockLoginAttemptStore_Lock_Call{Call: _e.mock.On("Lock", ctx, key, d)}
+}
+
+func (_c *MockLoginAttemptStore_Lock_Call) Run(run func(ctx context.Context, key string, d time.Duration)) *MockLoginAttemptStore_Lock_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Lock_Call) Return(err error) *MockLoginAttemptStore_Lock_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Lock_Call) RunAndReturn(run func(ctx context.Context, key string, d time.Duration) error) *MockLoginAttemptStore_Lock_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LockedFor provides a mock function for the type MockLoginAttemptStore
+func (_mock *MockLoginAttemptStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LockedFor")
+	}
+
+	var r0 time.Duration
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (time.Duration, error)); ok {
+		return returnFunc(ctx, key)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) time.Duration); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Get(0).(time.Duration)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLoginAttemptStore_LockedFor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LockedFor'
+type MockLoginAttemptStore_LockedFor_Call struct {
+	*mock.Call
+}
+
+// LockedFor is a helper method to define mock.On call
+//   - ctx
+//   - key
+func (_e *MockLoginAttemptStore_Expecter) LockedFor(ctx interface{}, key interface{}) *MockLoginAttemptStore_LockedFor_Call {
+	return &MockLoginAttemptStore_LockedFor_Call{Call: _e.mock.On("LockedFor", ctx, key)}
+}
+
+func (_c *MockLoginAttemptStore_LockedFor_Call) Run(run func(ctx context.Context, key string)) *MockLoginAttemptStore_LockedFor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_LockedFor_Call) Return(duration time.Duration, err error) *MockLoginAttemptStore_LockedFor_Call {
+	_c.Call.Return(duration, err)
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_LockedFor_Call) RunAndReturn(run func(ctx context.Context, key string) (time.Duration, error)) *MockLoginAttemptStore_LockedFor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordFailure provides a mock function for the type MockLoginAttemptStore
+func (_mock *MockLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	ret := _mock.Called(ctx, key, window)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordFailure")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) (int64, error)); ok {
+		return returnFunc(ctx, key, window)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) int64); ok {
+		r0 = returnFunc(ctx, key, window)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = returnFunc(ctx, key, window)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLoginAttemptStore_RecordFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordFailure'
+type MockLoginAttemptStore_RecordFailure_Call struct {
+	*mock.Call
+}
+
+// RecordFailure is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - window
+func (_e *MockLoginAttemptStore_Expecter) RecordFailure(ctx interface{}, key interface{}, window interface{}) *MockLoginAttemptStore_RecordFailure_Call {
+	return &MockLoginAttemptStore_RecordFailure_Call{Call: _e.mock.On("RecordFailure", ctx, key, window)}
+}
+
+func (_c *MockLoginAttemptStore_RecordFailure_Call) Run(run func(ctx context.Context, key string, window time.Duration)) *MockLoginAttemptStore_RecordFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_RecordFailure_Call) Return(n int64, err error) *MockLoginAttemptStore_RecordFailure_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_RecordFailure_Call) RunAndReturn(run func(ctx context.Context, key string, window time.Duration) (int64, error)) *MockLoginAttemptStore_RecordFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reset provides a mock function for the type MockLoginAttemptStore
+func (_mock *MockLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	ret := _mock.Called(ctx, key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reset")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockLoginAttemptStore_Reset_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reset'
+type MockLoginAttemptStore_Reset_Call struct {
+	*mock.Call
+}
+
+// Reset is a helper method to define mock.On call
+//   - ctx
+//   - key
+func (_e *MockLoginAttemptStore_Expecter) Reset(ctx interface{}, key interface{}) *MockLoginAttemptStore_Reset_Call {
+	return &MockLoginAttemptStore_Reset_Call{Call: _e.mock.On("Reset", ctx, key)}
+}
+
+func (_c *MockLoginAttemptStore_Reset_Call) Run(run func(ctx context.Context, key string)) *MockLoginAttemptStore_Reset_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Reset_Call) Return(err error) *MockLoginAttemptStore_Reset_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Reset_Call) RunAndReturn(run func(ctx context.Context, key string) error) *MockLoginAttemptStore_Reset_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Strike provides a mock function for the type MockLoginAttemptStore
+func (_mock *MockLoginAttemptStore) Strike(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	ret := _mock.Called(ctx, key, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Strike")
+	}
+
+	var r0 int64
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) (int64, error)); ok {
+		return returnFunc(ctx, key, ttl)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, time.Duration) int64); ok {
+		r0 = returnFunc(ctx, key, ttl)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, time.Duration) error); ok {
+		r1 = returnFunc(ctx, key, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockLoginAttemptStore_Strike_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Strike'
+type MockLoginAttemptStore_Strike_Call struct {
+	*mock.Call
+}
+
+// Strike is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - ttl
+func (_e *MockLoginAttemptStore_Expecter) Strike(ctx interface{}, key interface{}, ttl interface{}) *MockLoginAttemptStore_Strike_Call {
+	return &MockLoginAttemptStore_Strike_Call{Call: _e.mock.On("Strike", ctx, key, ttl)}
+}
+
+func (_c *MockLoginAttemptStore_Strike_Call) Run(run func(ctx context.Context, key string, ttl time.Duration)) *MockLoginAttemptStore_Strike_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Strike_Call) Return(n int64, err error) *MockLoginAttemptStore_Strike_Call {
+	_c.Call.Return(n, err)
+	return _c
+}
+
+func (_c *MockLoginAttemptStore_Strike_Call) RunAndReturn(run func(ctx context.Context, key string, ttl time.Duration) (int64, error)) *MockLoginAttemptStore_Strike_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockPasswordHistoryRepository creates a new instance of MockPasswordHistoryRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPasswordHistoryRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPasswordHistoryRepository {
+	mock := &MockPasswordHistoryRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPasswordHistoryRepository is an autogenerated mock type for the PasswordHistoryRepository type
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+type MockPasswordHistoryRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPasswordHistoryRepository) EXPECT() *MockPasswordHistoryRepository_Expecter {
+	return &MockPasswordHistoryRepository_Expecter{mock: &_m.Mock}
+}
+
+// Add provides a mock function for the type MockPasswordHistoryRepository
+func (_mock *MockPasswordHistoryRepository) Add(ctx context.Context, userID int64, hash string) error {
+	ret := _mock.Called(ctx, userID, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Add")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPasswordHistoryRepository_Add_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Add'
+type MockPasswordHistoryRepository_Add_Call struct {
+	*mock.Call
+}
+
+// Add is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - hash
+func (_e *MockPasswordHistoryRepository_Expecter) Add(ctx interface{}, userID interface{}, hash interface{}) *MockPasswordHistoryRepository_Add_Call {
+	return &MockPasswordHistoryRepository_Add_Call{Call: _e.mock.On("Add", ctx, userID, hash)}
+}
+
+func (_c *MockPasswordHistoryRepository_Add_Call) Run(run func(ctx context.Context, userID int64, hash string)) *MockPasswordHistoryRepository_Add_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Add_Call) Return(err error) *MockPasswordHistoryRepository_Add_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Add_Call) RunAndReturn(run func(ctx context.Context, userID int64, hash string) error) *MockPasswordHistoryRepository_Add_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Recent provides a mock function for the type MockPasswordHistoryRepository
+func (_mock *MockPasswordHistoryRepository) Recent(ctx context.Context, userID int64, n int) ([]string, error) {
+	ret := _mock.Called(ctx, userID, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Recent")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) ([]string, error)); ok {
+		return returnFunc(ctx, userID, n)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) []string); ok {
+		r0 = returnFunc(ctx, userID, n)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = returnFunc(ctx, userID, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPasswordHistoryRepository_Recent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Recent'
+type MockPasswordHistoryRepository_Recent_Call struct {
+	*mock.Call
+}
+
+// Recent is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - n
+func (_e *MockPasswordHistoryRepository_Expecter) Recent(ctx interface{}, userID interface{}, n interface{}) *MockPasswordHistoryRepository_Recent_Call {
+	return &MockPasswordHistoryRepository_Recent_Call{Call: _e.mock.On("Recent", ctx, userID, n)}
+}
+
+func (_c *MockPasswordHistoryRepository_Recent_Call) Run(run func(ctx context.Context, userID int64, n int)) *MockPasswordHistoryRepository_Recent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Recent_Call) Return(ss []string, err error) *MockPasswordHistoryRepository_Recent_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Recent_Call) RunAndReturn(run func(ctx context.Context, userID int64, n int) ([]string, error)) *MockPasswordHistoryRepository_Recent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Trim provides a mock function for the type MockPasswordHistoryRepository
+func (_mock *MockPasswordHistoryRepository) Trim(ctx context.Context, userID int64, keep int) error {
+	ret := _mock.Called(ctx, userID, keep)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Trim")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int) error); ok {
+		r0 = returnFunc(ctx, userID, keep)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockPasswordHistoryRepository_Trim_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Trim'
+type MockPasswordHistoryRepository_Trim_Call struct {
+	*mock.Call
+}
+
+// Trim is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - keep
+func (_e *MockPasswordHistoryRepository_Expecter) Trim(ctx interface{}, userID interface{}, keep interface{}) *MockPasswordHistoryRepository_Trim_Call {
+	return &MockPasswordHistoryRepository_Trim_Call{Call: _e.mock.On("Trim", ctx, userID, keep)}
+}
+
+func (_c *MockPasswordHistoryRepository_Trim_Call) Run(run func(ctx context.Context, userID int64, keep int)) *MockPasswordHistoryRepository_Trim_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Trim_Call) Return(err error) *MockPasswordHistoryRepository_Trim_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockPasswordHistoryRepository_Trim_Call) RunAndReturn(run func(ctx context.Context, userID int64, keep int) error) *MockPasswordHistoryRepository_Trim_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockEmailChangeRepository creates a new instance of MockEmailChangeRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockEmailChangeRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockEmailChangeRepository {
+	mock := &MockEmailChangeRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockEmailChangeRepository is an autogenerated mock type for the EmailChangeRepository type
+type MockEmailChangeRepository struct {
+	mock.Mock
+}
+
+type MockEmailChangeRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockEmailChangeRepository) EXPECT() *MockEmailChangeRepository_Expecter {
+	return &MockEmailChangeRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockEmailChangeRepository
+func (_mock *MockEmailChangeRepository) Create(ctx context.Context, t *model.EmailChangeToken) error {
+	ret := _mock.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.EmailChangeToken) error); ok {
+		r0 = returnFunc(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEmailChangeRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockEmailChangeRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - t
+func (_e *MockEmailChangeRepository_Expecter) Create(ctx interface{}, t interface{}) *MockEmailChangeRepository_Create_Call {
+	return &MockEmailChangeRepository_Create_Call{Call: _e.mock.On("Create", ctx, t)}
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) Run(run func(ctx context.Context, t *model.EmailChangeToken)) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.EmailChangeToken))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) Return(err error) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_Create_Call) RunAndReturn(run func(ctx context.Context, t *model.EmailChangeToken) error) *MockEmailChangeRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockEmailChangeRepository
+func (_mock *MockEmailChangeRepository) GetByHash(ctx context.Context, hash string) (*model.EmailChangeToken, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.EmailChangeToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.EmailChangeToken, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.EmailChangeToken); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.EmailChangeToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEmailChangeRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockEmailChangeRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx
+//   - hash
+func (_e *MockEmailChangeRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockEmailChangeRepository_GetByHash_Call {
+	return &MockEmailChangeRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockEmailChangeRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockEmailChangeRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByHash_Call) Return(emailChangeToken *model.EmailChangeToken, err error) *MockEmailChangeRepository_GetByHash_Call {
+	_c.Call.Return(emailChangeToken, err)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*model.EmailChangeToken, error)) *MockEmailChangeRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InvalidateForUser provides a mock function for the type MockEmailChangeRepository
+func (_mock *MockEmailChangeRepository) InvalidateForUser(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvalidateForUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockEmailChangeRepository_InvalidateForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InvalidateForUser'
+type MockEmailChangeRepository_InvalidateForUser_Call struct {
+	*mock.Call
+}
+
+// InvalidateForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockEmailChangeRepository_Expecter) InvalidateForUser(ctx interface{}, userID interface{}) *MockEmailChangeRepository_InvalidateForUser_Call {
+	return &MockEmailChangeRepository_InvalidateForUser_Call{Call: _e.mock.On("InvalidateForUser", ctx, userID)}
+}
+
+func (_c *MockEmailChangeRepository_InvalidateForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockEmailChangeRepository_InvalidateForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_InvalidateForUser_Call) Return(err error) *MockEmailChangeRepository_InvalidateForUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_InvalidateForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockEmailChangeRepository_InvalidateForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkUsed provides a mock function for the type MockEmailChangeRepository
+func (_mock *MockEmailChangeRepository) MarkUsed(ctx context.Context, id int64) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkUsed")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockEmailChangeRepository_MarkUsed_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkUsed'
+type MockEmailChangeRepository_MarkUsed_Call struct {
+	*mock.Call
+}
+
+// MarkUsed is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockEmailChangeRepository_Expecter) MarkUsed(ctx interface{}, id interface{}) *MockEmailChangeRepository_MarkUsed_Call {
+	return &MockEmailChangeRepository_MarkUsed_Call{Call: _e.mock.On("MarkUsed", ctx, id)}
+}
+
+func (_c *MockEmailChangeRepository_MarkUsed_Call) Run(run func(ctx context.Context, id int64)) *MockEmailChangeRepository_MarkUsed_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_MarkUsed_Call) Return(b bool, err error) *MockEmailChangeRepository_MarkUsed_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockEmailChangeRepository_MarkUsed_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockEmailChangeRepository_MarkUsed_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockSessionRegistry creates a new instance of MockSessionRegistry. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSessionRegistry(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSessionRegistry {
+	mock := &MockSessionRegistry{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSessionRegistry is an autogenerated mock type for the SessionRegistry type
+type MockSessionRegistry struct {
+	mock.Mock
+}
+
+type MockSessionRegistry_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSessionRegistry) EXPECT() *MockSessionRegistry_Expecter {
+	return &MockSessionRegistry_Expecter{mock: &_m.Mock}
+}
+
+// DeleteAllSessions provides a mock function for the type MockSessionRegistry
+func (_mock *MockSessionRegistry) DeleteAllSessions(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAllSessions")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSessionRegistry_DeleteAllSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAllSessions'
+type MockSessionRegistry_DeleteAllSessions_Call struct {
+	*mock.Call
+}
+
+// DeleteAllSessions is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockSessionRegistry_Expecter) DeleteAllSessions(ctx interface{}, userID interface{}) *MockSessionRegistry_DeleteAllSessions_Call {
+	return &MockSessionRegistry_DeleteAllSessions_Call{Call: _e.mock.On("DeleteAllSessions", ctx, userID)}
+}
+
+func (_c *MockSessionRegistry_DeleteAllSessions_Call) Run(run func(ctx context.Context, userID int64)) *MockSessionRegistry_DeleteAllSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSessionRegistry_DeleteAllSessions_Call) Return(err error) *MockSessionRegistry_DeleteAllSessions_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSessionRegistry_DeleteAllSessions_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockSessionRegistry_DeleteAllSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSession provides a mock function for the type MockSessionRegistry
+func (_mock *MockSessionRegistry) DeleteSession(ctx context.Context, userID int64, id string) (bool, error) {
+	ret := _mock.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSession")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, userID, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = returnFunc(ctx, userID, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSessionRegistry_DeleteSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSession'
+type MockSessionRegistry_DeleteSession_Call struct {
+	*mock.Call
+}
+
+// DeleteSession is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - id
+func (_e *MockSessionRegistry_Expecter) DeleteSession(ctx interface{}, userID interface{}, id interface{}) *MockSessionRegistry_DeleteSession_Call {
+	return &MockSessionRegistry_DeleteSession_Call{Call: _e.mock.On("DeleteSession", ctx, userID, id)}
+}
+
+func (_c *MockSessionRegistry_DeleteSession_Call) Run(run func(ctx context.Context, userID int64, id string)) *MockSessionRegistry_DeleteSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockSessionRegistry_DeleteSession_Call) Return(b bool, err error) *MockSessionRegistry_DeleteSession_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockSessionRegistry_DeleteSession_Call) RunAndReturn(run func(ctx context.Context, userID int64, id string) (bool, error)) *MockSessionRegistry_DeleteSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function for the type MockSessionRegistry
+func (_mock *MockSessionRegistry) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Session, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Session); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSessionRegistry_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockSessionRegistry_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockSessionRegistry_Expecter) ListSessions(ctx interface{}, userID interface{}) *MockSessionRegistry_ListSessions_Call {
+	return &MockSessionRegistry_ListSessions_Call{Call: _e.mock.On("ListSessions", ctx, userID)}
+}
+
+func (_c *MockSessionRegistry_ListSessions_Call) Run(run func(ctx context.Context, userID int64)) *MockSessionRegistry_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockSessionRegistry_ListSessions_Call) Return(sessions []model.Session, err error) *MockSessionRegistry_ListSessions_Call {
+	_c.Call.Return(sessions, err)
+	return _c
+}
+
+func (_c *MockSessionRegistry_ListSessions_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.Session, error)) *MockSessionRegistry_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSession provides a mock function for the type MockSessionRegistry
+func (_mock *MockSessionRegistry) SaveSession(ctx context.Context, s *model.Session, ttl time.Duration) error {
+	ret := _mock.Called(ctx, s, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSession")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Session, time.Duration) error); ok {
+		r0 = returnFunc(ctx, s, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSessionRegistry_SaveSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSession'
+type MockSessionRegistry_SaveSession_Call struct {
+	*mock.Call
+}
+
+// SaveSession is a helper method to define mock.On call
+//   - ctx
+//   - s
+//   - ttl
+func (_e *MockSessionRegistry_Expecter) SaveSession(ctx interface{}, s interface{}, ttl interface{}) *MockSessionRegistry_SaveSession_Call {
+	return &MockSessionRegistry_SaveSession_Call{Call: _e.mock.On("SaveSession", ctx, s, ttl)}
+}
+
+func (_c *MockSessionRegistry_SaveSession_Call) Run(run func(ctx context.Context, s *model.Session, ttl time.Duration)) *MockSessionRegistry_SaveSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Session), args[2].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockSessionRegistry_SaveSession_Call) Return(err error) *MockSessionRegistry_SaveSession_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSessionRegistry_SaveSession_Call) RunAndReturn(run func(ctx context.Context, s *model.Session, ttl time.Duration) error) *MockSessionRegistry_SaveSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockTokenVersionCache creates a new instance of MockTokenVersionCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTokenVersionCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTokenVersionCache {
+	mock := &MockTokenVersionCache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTokenVersionCache is an autogenerated mock type for the TokenVersionCache type
+type MockTokenVersionCache struct {
+	mock.Mock
+}
+
+type MockTokenVersionCache_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTokenVersionCache) EXPECT() *MockTokenVersionCache_Expecter {
+	return &MockTokenVersionCache_Expecter{mock: &_m.Mock}
+}
+
+// DeleteTokenVersion provides a mock function for the type MockTokenVersionCache
+func (_mock *MockTokenVersionCache) DeleteTokenVersion(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTokenVersion")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTokenVersionCache_DeleteTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteTokenVersion'
+type MockTokenVersionCache_DeleteTokenVersion_Call struct {
+	*mock.Call
+}
+
+// DeleteTokenVersion is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockTokenVersionCache_Expecter) DeleteTokenVersion(ctx interface{}, userID interface{}) *MockTokenVersionCache_DeleteTokenVersion_Call {
+	return &MockTokenVersionCache_DeleteTokenVersion_Call{Call: _e.mock.On("DeleteTokenVersion", ctx, userID)}
+}
+
+func (_c *MockTokenVersionCache_DeleteTokenVersion_Call) Run(run func(ctx context.Context, userID int64)) *MockTokenVersionCache_DeleteTokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockTokenVersionCache_DeleteTokenVersion_Call) Return(err error) *MockTokenVersionCache_DeleteTokenVersion_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTokenVersionCache_DeleteTokenVersion_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockTokenVersionCache_DeleteTokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetTokenVersion provides a mock function for the type MockTokenVersionCache
+func (_mock *MockTokenVersionCache) SetTokenVersion(ctx context.Context, userID int64, version int64) error {
+	ret := _mock.Called(ctx, userID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTokenVersion")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, userID, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTokenVersionCache_SetTokenVersion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetTokenVersion'
+type MockTokenVersionCache_SetTokenVersion_Call struct {
+	*mock.Call
+}
+
+// SetTokenVersion is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - version
+func (_e *MockTokenVersionCache_Expecter) SetTokenVersion(ctx interface{}, userID interface{}, version interface{}) *MockTokenVersionCache_SetTokenVersion_Call {
+	return &MockTokenVersionCache_SetTokenVersion_Call{Call: _e.mock.On("SetTokenVersion", ctx, userID, version)}
+}
+
+func (_c *MockTokenVersionCache_SetTokenVersion_Call) Run(run func(ctx context.Context, userID int64, version int64)) *MockTokenVersionCache_SetTokenVersion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockTokenVersionCache_SetTokenVersion_Call) Return(err error) *MockTokenVersionCache_SetTokenVersion_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTokenVersionCache_SetTokenVersion_Call) RunAndReturn(run func(ctx context.Context, userID int64, version int64) error) *MockTokenVersionCache_SetTokenVersion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRoleRepository creates a new instance of MockRoleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRoleRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRoleRepository {
+	mock := &MockRoleRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRoleRepository is an autogenerated mock type for the RoleRepository type
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+type MockRoleRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRoleRepository) EXPECT() *MockRoleRepository_Expecter {
+	return &MockRoleRepository_Expecter{mock: &_m.Mock}
+}
+
+// AssignRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) AssignRole(ctx context.Context, userID int64, role string) error {
+	ret := _mock.Called(ctx, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AssignRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockRoleRepository_AssignRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AssignRole'
+type MockRoleRepository_AssignRole_Call struct {
+	*mock.Call
+}
+
+// AssignRole is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - role
+func (_e *MockRoleRepository_Expecter) AssignRole(ctx interface{}, userID interface{}, role interface{}) *MockRoleRepository_AssignRole_Call {
+	return &MockRoleRepository_AssignRole_Call{Call: _e.mock.On("AssignRole", ctx, userID, role)}
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) Run(run func(ctx context.Context, userID int64, role string)) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) Return(err error) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockRoleRepository_AssignRole_Call) RunAndReturn(run func(ctx context.Context, userID int64, role string) error) *MockRoleRepository_AssignRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) CreateRole(ctx context.Context, role *model.Role) (bool, error) {
+	ret := _mock.Called(ctx, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRole")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Role) (bool, error)); ok {
+		return returnFunc(ctx, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Role) bool); ok {
+		r0 = returnFunc(ctx, role)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *model.Role) error); ok {
+		r1 = returnFunc(ctx, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_CreateRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRole'
+type MockRoleRepository_CreateRole_Call struct {
+	*mock.Call
+}
+
+// CreateRole is a helper method to define mock.On call
+//   - ctx
+//   - role
+func (_e *MockRoleRepository_Expecter) CreateRole(ctx interface{}, role interface{}) *MockRoleRepository_CreateRole_Call {
+	return &MockRoleRepository_CreateRole_Call{Call: _e.mock.On("CreateRole", ctx, role)}
+}
+
+func (_c *MockRoleRepository_CreateRole_Call) Run(run func(ctx context.Context, role *model.Role)) *MockRoleRepository_CreateRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Role))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_CreateRole_Call) Return(b bool, err error) *MockRoleRepository_CreateRole_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_CreateRole_Call) RunAndReturn(run func(ctx context.Context, role *model.Role) (bool, error)) *MockRoleRepository_CreateRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EffectivePermissions provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) EffectivePermissions(ctx context.Context, userID int64, orgID int64) ([]string, error) {
+	ret := _mock.Called(ctx, userID, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EffectivePermissions")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) ([]string, error)); ok {
+		return returnFunc(ctx, userID, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) []string); ok {
+		r0 = returnFunc(ctx, userID, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, userID, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_EffectivePermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EffectivePermissions'
+type MockRoleRepository_EffectivePermissions_Call struct {
+	*mock.Call
+}
+
+// EffectivePermissions is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - orgID
+func (_e *MockRoleRepository_Expecter) EffectivePermissions(ctx interface{}, userID interface{}, orgID interface{}) *MockRoleRepository_EffectivePermissions_Call {
+	return &MockRoleRepository_EffectivePermissions_Call{Call: _e.mock.On("EffectivePermissions", ctx, userID, orgID)}
+}
+
+func (_c *MockRoleRepository_EffectivePermissions_Call) Run(run func(ctx context.Context, userID int64, orgID int64)) *MockRoleRepository_EffectivePermissions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_EffectivePermissions_Call) Return(ss []string, err error) *MockRoleRepository_EffectivePermissions_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_EffectivePermissions_Call) RunAndReturn(run func(ctx context.Context, userID int64, orgID int64) ([]string, error)) *MockRoleRepository_EffectivePermissions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EffectiveRoles provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) EffectiveRoles(ctx context.Context, userID int64, orgID int64) ([]string, error) {
+	ret := _mock.Called(ctx, userID, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EffectiveRoles")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) ([]string, error)); ok {
+		return returnFunc(ctx, userID, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) []string); ok {
+		r0 = returnFunc(ctx, userID, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, userID, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_EffectiveRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EffectiveRoles'
+type MockRoleRepository_EffectiveRoles_Call struct {
+	*mock.Call
+}
+
+// EffectiveRoles is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - orgID
+func (_e *MockRoleRepository_Expecter) EffectiveRoles(ctx interface{}, userID interface{}, orgID interface{}) *MockRoleRepository_EffectiveRoles_Call {
+	return &MockRoleRepository_EffectiveRoles_Call{Call: _e.mock.On("EffectiveRoles", ctx, userID, orgID)}
+}
+
+func (_c *MockRoleRepository_EffectiveRoles_Call) Run(run func(ctx context.Context, userID int64, orgID int64)) *MockRoleRepository_EffectiveRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_EffectiveRoles_Call) Return(ss []string, err error) *MockRoleRepository_EffectiveRoles_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_EffectiveRoles_Call) RunAndReturn(run func(ctx context.Context, userID int64, orgID int64) ([]string, error)) *MockRoleRepository_EffectiveRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) GetRole(ctx context.Context, name string) (*model.Role, error) {
+	ret := _mock.Called(ctx, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRole")
+	}
+
+	var r0 *model.Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Role, error)); ok {
+		return returnFunc(ctx, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Role); ok {
+		r0 = returnFunc(ctx, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_GetRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRole'
+type MockRoleRepository_GetRole_Call struct {
+	*mock.Call
+}
+
+// GetRole is a helper method to define mock.On call
+//   - ctx
+//   - name
+func (_e *MockRoleRepository_Expecter) GetRole(ctx interface{}, name interface{}) *MockRoleRepository_GetRole_Call {
+	return &MockRoleRepository_GetRole_Call{Call: _e.mock.On("GetRole", ctx, name)}
+}
+
+func (_c *MockRoleRepository_GetRole_Call) Run(run func(ctx context.Context, name string)) *MockRoleRepository_GetRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_GetRole_Call) Return(role *model.Role, err error) *MockRoleRepository_GetRole_Call {
+	_c.Call.Return(role, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_GetRole_Call) RunAndReturn(run func(ctx context.Context, name string) (*model.Role, error)) *MockRoleRepository_GetRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPermissions provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) ListPermissions(ctx context.Context) ([]string, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPermissions")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_ListPermissions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPermissions'
+type MockRoleRepository_ListPermissions_Call struct {
+	*mock.Call
+}
+
+// ListPermissions is a helper method to define mock.On call
+//   - ctx
+func (_e *MockRoleRepository_Expecter) ListPermissions(ctx interface{}) *MockRoleRepository_ListPermissions_Call {
+	return &MockRoleRepository_ListPermissions_Call{Call: _e.mock.On("ListPermissions", ctx)}
+}
+
+func (_c *MockRoleRepository_ListPermissions_Call) Run(run func(ctx context.Context)) *MockRoleRepository_ListPermissions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_ListPermissions_Call) Return(ss []string, err error) *MockRoleRepository_ListPermissions_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_ListPermissions_Call) RunAndReturn(run func(ctx context.Context) ([]string, error)) *MockRoleRepository_ListPermissions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRoles provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) ListRoles(ctx context.Context) ([]model.Role, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRoles")
+	}
+
+	var r0 []model.Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Role, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Role); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_ListRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRoles'
+type MockRoleRepository_ListRoles_Call struct {
+	*mock.Call
+}
+
+// ListRoles is a helper method to define mock.On call
+//   - ctx
+func (_e *MockRoleRepository_Expecter) ListRoles(ctx interface{}) *MockRoleRepository_ListRoles_Call {
+	return &MockRoleRepository_ListRoles_Call{Call: _e.mock.On("ListRoles", ctx)}
+}
+
+func (_c *MockRoleRepository_ListRoles_Call) Run(run func(ctx context.Context)) *MockRoleRepository_ListRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_ListRoles_Call) Return(roles []model.Role, err error) *MockRoleRepository_ListRoles_Call {
+	_c.Call.Return(roles, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_ListRoles_Call) RunAndReturn(run func(ctx context.Context) ([]model.Role, error)) *MockRoleRepository_ListRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRole provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) RevokeRole(ctx context.Context, userID int64, role string, keepLast bool) (bool, error) {
+	ret := _mock.Called(ctx, userID, role, keepLast)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRole")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, bool) (bool, error)); ok {
+		return returnFunc(ctx, userID, role, keepLast)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, bool) bool); ok {
+		r0 = returnFunc(ctx, userID, role, keepLast)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, bool) error); ok {
+		r1 = returnFunc(ctx, userID, role, keepLast)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_RevokeRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRole'
+type MockRoleRepository_RevokeRole_Call struct {
+	*mock.Call
+}
+
+// RevokeRole is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - role
+//   - keepLast
+func (_e *MockRoleRepository_Expecter) RevokeRole(ctx interface{}, userID interface{}, role interface{}, keepLast interface{}) *MockRoleRepository_RevokeRole_Call {
+	return &MockRoleRepository_RevokeRole_Call{Call: _e.mock.On("RevokeRole", ctx, userID, role, keepLast)}
+}
+
+func (_c *MockRoleRepository_RevokeRole_Call) Run(run func(ctx context.Context, userID int64, role string, keepLast bool)) *MockRoleRepository_RevokeRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_RevokeRole_Call) Return(b bool, err error) *MockRoleRepository_RevokeRole_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_RevokeRole_Call) RunAndReturn(run func(ctx context.Context, userID int64, role string, keepLast bool) (bool, error)) *MockRoleRepository_RevokeRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UserRoles provides a mock function for the type MockRoleRepository
+func (_mock *MockRoleRepository) UserRoles(ctx context.Context, userID int64) ([]string, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserRoles")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]string, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRoleRepository_UserRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserRoles'
+type MockRoleRepository_UserRoles_Call struct {
+	*mock.Call
+}
+
+// UserRoles is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockRoleRepository_Expecter) UserRoles(ctx interface{}, userID interface{}) *MockRoleRepository_UserRoles_Call {
+	return &MockRoleRepository_UserRoles_Call{Call: _e.mock.On("UserRoles", ctx, userID)}
+}
+
+func (_c *MockRoleRepository_UserRoles_Call) Run(run func(ctx context.Context, userID int64)) *MockRoleRepository_UserRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockRoleRepository_UserRoles_Call) Return(ss []string, err error) *MockRoleRepository_UserRoles_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockRoleRepository_UserRoles_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]string, error)) *MockRoleRepository_UserRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockOrganizationRepository creates a new instance of MockOrganizationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockOrganizationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockOrganizationRepository is an autogenerated mock type for the OrganizationRepository type
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+type MockOrganizationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockOrganizationRepository) EXPECT() *MockOrganizationRepository_Expecter {
+	return &MockOrganizationRepository_Expecter{mock: &_m.Mock}
+}
+
+// AddMember provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) AddMember(ctx context.Context, orgID int64, userID int64, role string) error {
+	ret := _mock.Called(ctx, orgID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string) error); ok {
+		r0 = returnFunc(ctx, orgID, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockOrganizationRepository_AddMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMember'
+type MockOrganizationRepository_AddMember_Call struct {
+	*mock.Call
+}
+
+// AddMember is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - userID
+//   - role
+func (_e *MockOrganizationRepository_Expecter) AddMember(ctx interface{}, orgID interface{}, userID interface{}, role interface{}) *MockOrganizationRepository_AddMember_Call {
+	return &MockOrganizationRepository_AddMember_Call{Call: _e.mock.On("AddMember", ctx, orgID, userID, role)}
+}
+
+func (_c *MockOrganizationRepository_AddMember_Call) Run(run func(ctx context.Context, orgID int64, userID int64, role string)) *MockOrganizationRepository_AddMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_AddMember_Call) Return(err error) *MockOrganizationRepository_AddMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_AddMember_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64, role string) error) *MockOrganizationRepository_AddMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) Create(ctx context.Context, org *model.Organization, ownerID int64, role string) (bool, error) {
+	ret := _mock.Called(ctx, org, ownerID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Organization, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, org, ownerID, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Organization, int64, string) bool); ok {
+		r0 = returnFunc(ctx, org, ownerID, role)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *model.Organization, int64, string) error); ok {
+		r1 = returnFunc(ctx, org, ownerID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockOrganizationRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - org
+//   - ownerID
+//   - role
+func (_e *MockOrganizationRepository_Expecter) Create(ctx interface{}, org interface{}, ownerID interface{}, role interface{}) *MockOrganizationRepository_Create_Call {
+	return &MockOrganizationRepository_Create_Call{Call: _e.mock.On("Create", ctx, org, ownerID, role)}
+}
+
+func (_c *MockOrganizationRepository_Create_Call) Run(run func(ctx context.Context, org *model.Organization, ownerID int64, role string)) *MockOrganizationRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Organization), args[2].(int64), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Create_Call) Return(b bool, err error) *MockOrganizationRepository_Create_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Create_Call) RunAndReturn(run func(ctx context.Context, org *model.Organization, ownerID int64, role string) (bool, error)) *MockOrganizationRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) Get(ctx context.Context, id int64) (*model.Organization, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Organization, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Organization); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockOrganizationRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockOrganizationRepository_Expecter) Get(ctx interface{}, id interface{}) *MockOrganizationRepository_Get_Call {
+	return &MockOrganizationRepository_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockOrganizationRepository_Get_Call) Run(run func(ctx context.Context, id int64)) *MockOrganizationRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Get_Call) Return(organization *model.Organization, err error) *MockOrganizationRepository_Get_Call {
+	_c.Call.Return(organization, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Get_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.Organization, error)) *MockOrganizationRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListForUser provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) ListForUser(ctx context.Context, userID int64) ([]model.Organization, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListForUser")
+	}
+
+	var r0 []model.Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Organization, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Organization); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_ListForUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListForUser'
+type MockOrganizationRepository_ListForUser_Call struct {
+	*mock.Call
+}
+
+// ListForUser is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockOrganizationRepository_Expecter) ListForUser(ctx interface{}, userID interface{}) *MockOrganizationRepository_ListForUser_Call {
+	return &MockOrganizationRepository_ListForUser_Call{Call: _e.mock.On("ListForUser", ctx, userID)}
+}
+
+func (_c *MockOrganizationRepository_ListForUser_Call) Run(run func(ctx context.Context, userID int64)) *MockOrganizationRepository_ListForUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListForUser_Call) Return(organizations []model.Organization, err error) *MockOrganizationRepository_ListForUser_Call {
+	_c.Call.Return(organizations, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListForUser_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.Organization, error)) *MockOrganizationRepository_ListForUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error) {
+	ret := _mock.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []model.Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Membership, error)); ok {
+		return returnFunc(ctx, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Membership); ok {
+		r0 = returnFunc(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockOrganizationRepository_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+func (_e *MockOrganizationRepository_Expecter) ListMembers(ctx interface{}, orgID interface{}) *MockOrganizationRepository_ListMembers_Call {
+	return &MockOrganizationRepository_ListMembers_Call{Call: _e.mock.On("ListMembers", ctx, orgID)}
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) Run(run func(ctx context.Context, orgID int64)) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) Return(memberships []model.Membership, err error) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Return(memberships, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_ListMembers_Call) RunAndReturn(run func(ctx context.Context, orgID int64) ([]model.Membership, error)) *MockOrganizationRepository_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Membership provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) Membership(ctx context.Context, orgID int64, userID int64) (*model.Membership, error) {
+	ret := _mock.Called(ctx, orgID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Membership")
+	}
+
+	var r0 *model.Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (*model.Membership, error)); ok {
+		return returnFunc(ctx, orgID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) *model.Membership); ok {
+		r0 = returnFunc(ctx, orgID, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, orgID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_Membership_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Membership'
+type MockOrganizationRepository_Membership_Call struct {
+	*mock.Call
+}
+
+// Membership is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - userID
+func (_e *MockOrganizationRepository_Expecter) Membership(ctx interface{}, orgID interface{}, userID interface{}) *MockOrganizationRepository_Membership_Call {
+	return &MockOrganizationRepository_Membership_Call{Call: _e.mock.On("Membership", ctx, orgID, userID)}
+}
+
+func (_c *MockOrganizationRepository_Membership_Call) Run(run func(ctx context.Context, orgID int64, userID int64)) *MockOrganizationRepository_Membership_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Membership_Call) Return(membership *model.Membership, err error) *MockOrganizationRepository_Membership_Call {
+	_c.Call.Return(membership, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_Membership_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64) (*model.Membership, error)) *MockOrganizationRepository_Membership_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) RemoveMember(ctx context.Context, orgID int64, userID int64) (bool, error) {
+	ret := _mock.Called(ctx, orgID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, orgID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, orgID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, orgID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockOrganizationRepository_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - userID
+func (_e *MockOrganizationRepository_Expecter) RemoveMember(ctx interface{}, orgID interface{}, userID interface{}) *MockOrganizationRepository_RemoveMember_Call {
+	return &MockOrganizationRepository_RemoveMember_Call{Call: _e.mock.On("RemoveMember", ctx, orgID, userID)}
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) Run(run func(ctx context.Context, orgID int64, userID int64)) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) Return(b bool, err error) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_RemoveMember_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64) (bool, error)) *MockOrganizationRepository_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetMemberRole provides a mock function for the type MockOrganizationRepository
+func (_mock *MockOrganizationRepository) SetMemberRole(ctx context.Context, orgID int64, userID int64, role string) (bool, error) {
+	ret := _mock.Called(ctx, orgID, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMemberRole")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, orgID, userID, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string) bool); ok {
+		r0 = returnFunc(ctx, orgID, userID, role)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, string) error); ok {
+		r1 = returnFunc(ctx, orgID, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockOrganizationRepository_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockOrganizationRepository_SetMemberRole_Call struct {
+	*mock.Call
+}
+
+// SetMemberRole is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - userID
+//   - role
+func (_e *MockOrganizationRepository_Expecter) SetMemberRole(ctx interface{}, orgID interface{}, userID interface{}, role interface{}) *MockOrganizationRepository_SetMemberRole_Call {
+	return &MockOrganizationRepository_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", ctx, orgID, userID, role)}
+}
+
+func (_c *MockOrganizationRepository_SetMemberRole_Call) Run(run func(ctx context.Context, orgID int64, userID int64, role string)) *MockOrganizationRepository_SetMemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockOrganizationRepository_SetMemberRole_Call) Return(b bool, err error) *MockOrganizationRepository_SetMemberRole_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockOrganizationRepository_SetMemberRole_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64, role string) (bool, error)) *MockOrganizationRepository_SetMemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockInvitationRepository creates a new instance of MockInvitationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockInvitationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockInvitationRepository {
+	mock := &MockInvitationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockInvitationRepository is an autogenerated mock type for the InvitationRepository type
+type MockInvitationRepository struct {
+	mock.Mock
+}
+
+type MockInvitationRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockInvitationRepository) EXPECT() *MockInvitationRepository_Expecter {
+	return &MockInvitationRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) Create(ctx context.Context, inv *model.Invitation) error {
+	ret := _mock.Called(ctx, inv)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Invitation) error); ok {
+		r0 = returnFunc(ctx, inv)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockInvitationRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockInvitationRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - inv
+func (_e *MockInvitationRepository_Expecter) Create(ctx interface{}, inv interface{}) *MockInvitationRepository_Create_Call {
+	return &MockInvitationRepository_Create_Call{Call: _e.mock.On("Create", ctx, inv)}
+}
+
+func (_c *MockInvitationRepository_Create_Call) Run(run func(ctx context.Context, inv *model.Invitation)) *MockInvitationRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Invitation))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_Create_Call) Return(err error) *MockInvitationRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_Create_Call) RunAndReturn(run func(ctx context.Context, inv *model.Invitation) error) *MockInvitationRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByHash provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) GetByHash(ctx context.Context, hash string) (*model.Invitation, error) {
+	ret := _mock.Called(ctx, hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 *model.Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.Invitation, error)); ok {
+		return returnFunc(ctx, hash)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.Invitation); ok {
+		r0 = returnFunc(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockInvitationRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type MockInvitationRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - ctx
+//   - hash
+func (_e *MockInvitationRepository_Expecter) GetByHash(ctx interface{}, hash interface{}) *MockInvitationRepository_GetByHash_Call {
+	return &MockInvitationRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", ctx, hash)}
+}
+
+func (_c *MockInvitationRepository_GetByHash_Call) Run(run func(ctx context.Context, hash string)) *MockInvitationRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_GetByHash_Call) Return(invitation *model.Invitation, err error) *MockInvitationRepository_GetByHash_Call {
+	_c.Call.Return(invitation, err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_GetByHash_Call) RunAndReturn(run func(ctx context.Context, hash string) (*model.Invitation, error)) *MockInvitationRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListPending provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) ListPending(ctx context.Context, orgID int64) ([]model.Invitation, error) {
+	ret := _mock.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListPending")
+	}
+
+	var r0 []model.Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Invitation, error)); ok {
+		return returnFunc(ctx, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Invitation); ok {
+		r0 = returnFunc(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockInvitationRepository_ListPending_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListPending'
+type MockInvitationRepository_ListPending_Call struct {
+	*mock.Call
+}
+
+// ListPending is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+func (_e *MockInvitationRepository_Expecter) ListPending(ctx interface{}, orgID interface{}) *MockInvitationRepository_ListPending_Call {
+	return &MockInvitationRepository_ListPending_Call{Call: _e.mock.On("ListPending", ctx, orgID)}
+}
+
+func (_c *MockInvitationRepository_ListPending_Call) Run(run func(ctx context.Context, orgID int64)) *MockInvitationRepository_ListPending_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_ListPending_Call) Return(invitations []model.Invitation, err error) *MockInvitationRepository_ListPending_Call {
+	_c.Call.Return(invitations, err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_ListPending_Call) RunAndReturn(run func(ctx context.Context, orgID int64) ([]model.Invitation, error)) *MockInvitationRepository_ListPending_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkAccepted provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) MarkAccepted(ctx context.Context, id int64) (bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkAccepted")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockInvitationRepository_MarkAccepted_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkAccepted'
+type MockInvitationRepository_MarkAccepted_Call struct {
+	*mock.Call
+}
+
+// MarkAccepted is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockInvitationRepository_Expecter) MarkAccepted(ctx interface{}, id interface{}) *MockInvitationRepository_MarkAccepted_Call {
+	return &MockInvitationRepository_MarkAccepted_Call{Call: _e.mock.On("MarkAccepted", ctx, id)}
+}
+
+func (_c *MockInvitationRepository_MarkAccepted_Call) Run(run func(ctx context.Context, id int64)) *MockInvitationRepository_MarkAccepted_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_MarkAccepted_Call) Return(b bool, err error) *MockInvitationRepository_MarkAccepted_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_MarkAccepted_Call) RunAndReturn(run func(ctx context.Context, id int64) (bool, error)) *MockInvitationRepository_MarkAccepted_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Release provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) Release(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Release")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockInvitationRepository_Release_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Release'
+type MockInvitationRepository_Release_Call struct {
+	*mock.Call
+}
+
+// Release is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockInvitationRepository_Expecter) Release(ctx interface{}, id interface{}) *MockInvitationRepository_Release_Call {
+	return &MockInvitationRepository_Release_Call{Call: _e.mock.On("Release", ctx, id)}
+}
+
+func (_c *MockInvitationRepository_Release_Call) Run(run func(ctx context.Context, id int64)) *MockInvitationRepository_Release_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_Release_Call) Return(err error) *MockInvitationRepository_Release_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_Release_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockInvitationRepository_Release_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Revoke provides a mock function for the type MockInvitationRepository
+func (_mock *MockInvitationRepository) Revoke(ctx context.Context, orgID int64, id int64) (bool, error) {
+	ret := _mock.Called(ctx, orgID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, orgID, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, orgID, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, orgID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockInvitationRepository_Revoke_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Revoke'
+type MockInvitationRepository_Revoke_Call struct {
+	*mock.Call
+}
+
+// Revoke is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - id
+func (_e *MockInvitationRepository_Expecter) Revoke(ctx interface{}, orgID interface{}, id interface{}) *MockInvitationRepository_Revoke_Call {
+	return &MockInvitationRepository_Revoke_Call{Call: _e.mock.On("Revoke", ctx, orgID, id)}
+}
+
+func (_c *MockInvitationRepository_Revoke_Call) Run(run func(ctx context.Context, orgID int64, id int64)) *MockInvitationRepository_Revoke_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockInvitationRepository_Revoke_Call) Return(b bool, err error) *MockInvitationRepository_Revoke_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockInvitationRepository_Revoke_Call) RunAndReturn(run func(ctx context.Context, orgID int64, id int64) (bool, error)) *MockInvitationRepository_Revoke_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockGroupRepository creates a new instance of MockGroupRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGroupRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGroupRepository {
+	mock := &MockGroupRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockGroupRepository is an autogenerated mock type for the GroupRepository type
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+type MockGroupRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockGroupRepository) EXPECT() *MockGroupRepository_Expecter {
+	return &MockGroupRepository_Expecter{mock: &_m.Mock}
+}
+
+// AddMember provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) AddMember(ctx context.Context, groupID int64, userID int64) error {
+	ret := _mock.Called(ctx, groupID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, groupID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockGroupRepository_AddMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddMember'
+type MockGroupRepository_AddMember_Call struct {
+	*mock.Call
+}
+
+// AddMember is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - userID
+func (_e *MockGroupRepository_Expecter) AddMember(ctx interface{}, groupID interface{}, userID interface{}) *MockGroupRepository_AddMember_Call {
+	return &MockGroupRepository_AddMember_Call{Call: _e.mock.On("AddMember", ctx, groupID, userID)}
+}
+
+func (_c *MockGroupRepository_AddMember_Call) Run(run func(ctx context.Context, groupID int64, userID int64)) *MockGroupRepository_AddMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_AddMember_Call) Return(err error) *MockGroupRepository_AddMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockGroupRepository_AddMember_Call) RunAndReturn(run func(ctx context.Context, groupID int64, userID int64) error) *MockGroupRepository_AddMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddSubgroup provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) AddSubgroup(ctx context.Context, groupID int64, subgroupID int64) (bool, error) {
+	ret := _mock.Called(ctx, groupID, subgroupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSubgroup")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, groupID, subgroupID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_AddSubgroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddSubgroup'
+type MockGroupRepository_AddSubgroup_Call struct {
+	*mock.Call
+}
+
+// AddSubgroup is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - subgroupID
+func (_e *MockGroupRepository_Expecter) AddSubgroup(ctx interface{}, groupID interface{}, subgroupID interface{}) *MockGroupRepository_AddSubgroup_Call {
+	return &MockGroupRepository_AddSubgroup_Call{Call: _e.mock.On("AddSubgroup", ctx, groupID, subgroupID)}
+}
+
+func (_c *MockGroupRepository_AddSubgroup_Call) Run(run func(ctx context.Context, groupID int64, subgroupID int64)) *MockGroupRepository_AddSubgroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_AddSubgroup_Call) Return(b bool, err error) *MockGroupRepository_AddSubgroup_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_AddSubgroup_Call) RunAndReturn(run func(ctx context.Context, groupID int64, subgroupID int64) (bool, error)) *MockGroupRepository_AddSubgroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Create(ctx context.Context, g *model.Group) (bool, error) {
+	ret := _mock.Called(ctx, g)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Group) (bool, error)); ok {
+		return returnFunc(ctx, g)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, *model.Group) bool); ok {
+		r0 = returnFunc(ctx, g)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, *model.Group) error); ok {
+		r1 = returnFunc(ctx, g)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockGroupRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - ctx
+//   - g
+func (_e *MockGroupRepository_Expecter) Create(ctx interface{}, g interface{}) *MockGroupRepository_Create_Call {
+	return &MockGroupRepository_Create_Call{Call: _e.mock.On("Create", ctx, g)}
+}
+
+func (_c *MockGroupRepository_Create_Call) Run(run func(ctx context.Context, g *model.Group)) *MockGroupRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*model.Group))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Create_Call) Return(b bool, err error) *MockGroupRepository_Create_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Create_Call) RunAndReturn(run func(ctx context.Context, g *model.Group) (bool, error)) *MockGroupRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Delete(ctx context.Context, id int64) ([]int64, bool, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 []int64
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]int64, bool, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []int64); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) bool); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, int64) error); ok {
+		r2 = returnFunc(ctx, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockGroupRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockGroupRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockGroupRepository_Expecter) Delete(ctx interface{}, id interface{}) *MockGroupRepository_Delete_Call {
+	return &MockGroupRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
+}
+
+func (_c *MockGroupRepository_Delete_Call) Run(run func(ctx context.Context, id int64)) *MockGroupRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Delete_Call) Return(ns []int64, b bool, err error) *MockGroupRepository_Delete_Call {
+	_c.Call.Return(ns, b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Delete_Call) RunAndReturn(run func(ctx context.Context, id int64) ([]int64, bool, error)) *MockGroupRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Get(ctx context.Context, id int64) (*model.Group, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Group, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Group); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockGroupRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockGroupRepository_Expecter) Get(ctx interface{}, id interface{}) *MockGroupRepository_Get_Call {
+	return &MockGroupRepository_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockGroupRepository_Get_Call) Run(run func(ctx context.Context, id int64)) *MockGroupRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Get_Call) Return(group *model.Group, err error) *MockGroupRepository_Get_Call {
+	_c.Call.Return(group, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Get_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.Group, error)) *MockGroupRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GrantRole provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) GrantRole(ctx context.Context, groupID int64, role string) error {
+	ret := _mock.Called(ctx, groupID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, groupID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockGroupRepository_GrantRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GrantRole'
+type MockGroupRepository_GrantRole_Call struct {
+	*mock.Call
+}
+
+// GrantRole is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - role
+func (_e *MockGroupRepository_Expecter) GrantRole(ctx interface{}, groupID interface{}, role interface{}) *MockGroupRepository_GrantRole_Call {
+	return &MockGroupRepository_GrantRole_Call{Call: _e.mock.On("GrantRole", ctx, groupID, role)}
+}
+
+func (_c *MockGroupRepository_GrantRole_Call) Run(run func(ctx context.Context, groupID int64, role string)) *MockGroupRepository_GrantRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_GrantRole_Call) Return(err error) *MockGroupRepository_GrantRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockGroupRepository_GrantRole_Call) RunAndReturn(run func(ctx context.Context, groupID int64, role string) error) *MockGroupRepository_GrantRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) List(ctx context.Context) ([]model.Group, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Group, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Group); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockGroupRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx
+func (_e *MockGroupRepository_Expecter) List(ctx interface{}) *MockGroupRepository_List_Call {
+	return &MockGroupRepository_List_Call{Call: _e.mock.On("List", ctx)}
+}
+
+func (_c *MockGroupRepository_List_Call) Run(run func(ctx context.Context)) *MockGroupRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_List_Call) Return(groups []model.Group, err error) *MockGroupRepository_List_Call {
+	_c.Call.Return(groups, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_List_Call) RunAndReturn(run func(ctx context.Context) ([]model.Group, error)) *MockGroupRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Members provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Members(ctx context.Context, id int64) ([]model.GroupMember, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Members")
+	}
+
+	var r0 []model.GroupMember
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.GroupMember, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.GroupMember); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.GroupMember)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Members_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Members'
+type MockGroupRepository_Members_Call struct {
+	*mock.Call
+}
+
+// Members is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockGroupRepository_Expecter) Members(ctx interface{}, id interface{}) *MockGroupRepository_Members_Call {
+	return &MockGroupRepository_Members_Call{Call: _e.mock.On("Members", ctx, id)}
+}
+
+func (_c *MockGroupRepository_Members_Call) Run(run func(ctx context.Context, id int64)) *MockGroupRepository_Members_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Members_Call) Return(groupMembers []model.GroupMember, err error) *MockGroupRepository_Members_Call {
+	_c.Call.Return(groupMembers, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Members_Call) RunAndReturn(run func(ctx context.Context, id int64) ([]model.GroupMember, error)) *MockGroupRepository_Members_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) RemoveMember(ctx context.Context, groupID int64, userID int64) (bool, error) {
+	ret := _mock.Called(ctx, groupID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (bool, error)); ok {
+		return returnFunc(ctx, groupID, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) bool); ok {
+		r0 = returnFunc(ctx, groupID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, groupID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockGroupRepository_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - userID
+func (_e *MockGroupRepository_Expecter) RemoveMember(ctx interface{}, groupID interface{}, userID interface{}) *MockGroupRepository_RemoveMember_Call {
+	return &MockGroupRepository_RemoveMember_Call{Call: _e.mock.On("RemoveMember", ctx, groupID, userID)}
+}
+
+func (_c *MockGroupRepository_RemoveMember_Call) Run(run func(ctx context.Context, groupID int64, userID int64)) *MockGroupRepository_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_RemoveMember_Call) Return(b bool, err error) *MockGroupRepository_RemoveMember_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_RemoveMember_Call) RunAndReturn(run func(ctx context.Context, groupID int64, userID int64) (bool, error)) *MockGroupRepository_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveSubgroup provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) RemoveSubgroup(ctx context.Context, groupID int64, subgroupID int64) ([]int64, bool, error) {
+	ret := _mock.Called(ctx, groupID, subgroupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveSubgroup")
+	}
+
+	var r0 []int64
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) ([]int64, bool, error)); ok {
+		return returnFunc(ctx, groupID, subgroupID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) []int64); ok {
+		r0 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) bool); ok {
+		r1 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, int64, int64) error); ok {
+		r2 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockGroupRepository_RemoveSubgroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveSubgroup'
+type MockGroupRepository_RemoveSubgroup_Call struct {
+	*mock.Call
+}
+
+// RemoveSubgroup is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - subgroupID
+func (_e *MockGroupRepository_Expecter) RemoveSubgroup(ctx interface{}, groupID interface{}, subgroupID interface{}) *MockGroupRepository_RemoveSubgroup_Call {
+	return &MockGroupRepository_RemoveSubgroup_Call{Call: _e.mock.On("RemoveSubgroup", ctx, groupID, subgroupID)}
+}
+
+func (_c *MockGroupRepository_RemoveSubgroup_Call) Run(run func(ctx context.Context, groupID int64, subgroupID int64)) *MockGroupRepository_RemoveSubgroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_RemoveSubgroup_Call) Return(ns []int64, b bool, err error) *MockGroupRepository_RemoveSubgroup_Call {
+	_c.Call.Return(ns, b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_RemoveSubgroup_Call) RunAndReturn(run func(ctx context.Context, groupID int64, subgroupID int64) ([]int64, bool, error)) *MockGroupRepository_RemoveSubgroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Rename provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Rename(ctx context.Context, id int64, name string) (bool, error) {
+	ret := _mock.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rename")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (bool, error)); ok {
+		return returnFunc(ctx, id, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) bool); ok {
+		r0 = returnFunc(ctx, id, name)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, id, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Rename_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rename'
+type MockGroupRepository_Rename_Call struct {
+	*mock.Call
+}
+
+// Rename is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - name
+func (_e *MockGroupRepository_Expecter) Rename(ctx interface{}, id interface{}, name interface{}) *MockGroupRepository_Rename_Call {
+	return &MockGroupRepository_Rename_Call{Call: _e.mock.On("Rename", ctx, id, name)}
+}
+
+func (_c *MockGroupRepository_Rename_Call) Run(run func(ctx context.Context, id int64, name string)) *MockGroupRepository_Rename_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Rename_Call) Return(b bool, err error) *MockGroupRepository_Rename_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Rename_Call) RunAndReturn(run func(ctx context.Context, id int64, name string) (bool, error)) *MockGroupRepository_Rename_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRole provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) RevokeRole(ctx context.Context, groupID int64, role string) ([]int64, bool, error) {
+	ret := _mock.Called(ctx, groupID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRole")
+	}
+
+	var r0 []int64
+	var r1 bool
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) ([]int64, bool, error)); ok {
+		return returnFunc(ctx, groupID, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) []int64); ok {
+		r0 = returnFunc(ctx, groupID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) bool); ok {
+		r1 = returnFunc(ctx, groupID, role)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, int64, string) error); ok {
+		r2 = returnFunc(ctx, groupID, role)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockGroupRepository_RevokeRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRole'
+type MockGroupRepository_RevokeRole_Call struct {
+	*mock.Call
+}
+
+// RevokeRole is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - role
+func (_e *MockGroupRepository_Expecter) RevokeRole(ctx interface{}, groupID interface{}, role interface{}) *MockGroupRepository_RevokeRole_Call {
+	return &MockGroupRepository_RevokeRole_Call{Call: _e.mock.On("RevokeRole", ctx, groupID, role)}
+}
+
+func (_c *MockGroupRepository_RevokeRole_Call) Run(run func(ctx context.Context, groupID int64, role string)) *MockGroupRepository_RevokeRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_RevokeRole_Call) Return(ns []int64, b bool, err error) *MockGroupRepository_RevokeRole_Call {
+	_c.Call.Return(ns, b, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_RevokeRole_Call) RunAndReturn(run func(ctx context.Context, groupID int64, role string) ([]int64, bool, error)) *MockGroupRepository_RevokeRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Roles provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Roles(ctx context.Context, id int64) ([]string, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Roles")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]string, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Roles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Roles'
+type MockGroupRepository_Roles_Call struct {
+	*mock.Call
+}
+
+// Roles is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockGroupRepository_Expecter) Roles(ctx interface{}, id interface{}) *MockGroupRepository_Roles_Call {
+	return &MockGroupRepository_Roles_Call{Call: _e.mock.On("Roles", ctx, id)}
+}
+
+func (_c *MockGroupRepository_Roles_Call) Run(run func(ctx context.Context, id int64)) *MockGroupRepository_Roles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Roles_Call) Return(ss []string, err error) *MockGroupRepository_Roles_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Roles_Call) RunAndReturn(run func(ctx context.Context, id int64) ([]string, error)) *MockGroupRepository_Roles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Subgroups provides a mock function for the type MockGroupRepository
+func (_mock *MockGroupRepository) Subgroups(ctx context.Context, id int64) ([]model.Group, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subgroups")
+	}
+
+	var r0 []model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Group, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Group); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockGroupRepository_Subgroups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Subgroups'
+type MockGroupRepository_Subgroups_Call struct {
+	*mock.Call
+}
+
+// Subgroups is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockGroupRepository_Expecter) Subgroups(ctx interface{}, id interface{}) *MockGroupRepository_Subgroups_Call {
+	return &MockGroupRepository_Subgroups_Call{Call: _e.mock.On("Subgroups", ctx, id)}
+}
+
+func (_c *MockGroupRepository_Subgroups_Call) Run(run func(ctx context.Context, id int64)) *MockGroupRepository_Subgroups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockGroupRepository_Subgroups_Call) Return(groups []model.Group, err error) *MockGroupRepository_Subgroups_Call {
+	_c.Call.Return(groups, err)
+	return _c
+}
+
+func (_c *MockGroupRepository_Subgroups_Call) RunAndReturn(run func(ctx context.Context, id int64) ([]model.Group, error)) *MockGroupRepository_Subgroups_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrOrganizationsDisabled = errors.New("organizations are not enabled")
+	ErrInvalidSlug           = errors.New("slug must be lowercase letters and digits, separated by single dashes")
+	ErrSlugTaken             = errors.New("organization slug already in use")
+	ErrNotMember             = errors.New("not a member of the organization")
+	ErrInvalidOrgRole        = errors.New("invalid organization role")
+	ErrLastOrgAdmin          = errors.New("cannot demote or remove the last admin of the organization")
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// OrganizationRepository keeps organizations and the memberships of their
+// users, each holding a role within the organization.
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *model.Organization, ownerID int64, role string) (bool, error)
+	Get(ctx context.Context, id int64) (*model.Organization, error)
+	ListForUser(ctx context.Context, userID int64) ([]model.Organization, error)
+	Membership(ctx context.Context, orgID, userID int64) (*model.Membership, error)
+	ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error)
+	AddMember(ctx context.Context, orgID, userID int64, role string) error
+	SetMemberRole(ctx context.Context, orgID, userID int64, role string) (bool, error)
+	RemoveMember(ctx context.Context, orgID, userID int64) (bool, error)
+}
+
+type organizationKey struct{}
+
+// ContextWithOrganization returns a context for requests made within the
+// organization orgID: tokens issued while handling the request are scoped
+// to it, and only its members can be administered.
+func ContextWithOrganization(ctx context.Context, orgID int64) context.Context {
+	return context.WithValue(ctx, organizationKey{}, orgID)
+}
+
+// OrganizationFrom returns the organization set by ContextWithOrganization,
+// or 0.
+func OrganizationFrom(ctx context.Context) int64 {
+	id, _ := ctx.Value(organizationKey{}).(int64)
+	return id
+}
+
+// WithOrganizations lets users create organizations and get tokens scoped to
+// the ones they are members of. Roles held within an organization are
+// resolved through WithRBAC.
+func WithOrganizations(repo OrganizationRepository) Option {
+	return func(s *UserService) {
+		s.orgs = repo
+	}
+}
+
+// CreateOrganization creates an organization administered by its creator.
+func (s *UserService) CreateOrganization(ctx context.Context, userID int64, slug, name string) (*model.Organization, error) {
+	if s.orgs == nil {
+		return nil, ErrOrganizationsDisabled
+	}
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidSlug
+	}
+	org := &model.Organization{Slug: slug, Name: name}
+	created, err := s.orgs.Create(ctx, org, userID, model.RoleOrgAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrSlugTaken
+	}
+	return org, nil
+}
+
+// ListOrganizations returns the organizations a user is a member of.
+func (s *UserService) ListOrganizations(ctx context.Context, userID int64) ([]model.Organization, error) {
+	if s.orgs == nil {
+		return nil, ErrOrganizationsDisabled
+	}
+	return s.orgs.ListForUser(ctx, userID)
+}
+
+// SwitchOrganization issues a user tokens scoped to one of their
+// organizations, in a new session.
+func (s *UserService) SwitchOrganization(ctx context.Context, userID, orgID int64) (*model.TokenPair, error) {
+	if s.orgs == nil {
+		return nil, ErrOrganizationsDisabled
+	}
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, errors.New("user not found")
+	}
+	return s.issueTokens(ContextWithOrganization(ctx, orgID), u, "")
+}
+
+// ListMembers returns the members of an organization.
+func (s *UserService) ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error) {
+	if s.orgs == nil {
+		return nil, ErrOrganizationsDisabled
+	}
+	return s.orgs.ListMembers(ctx, orgID)
+}
+
+// SetMemberRole changes the role a member holds within an organization and
+// revokes their access tokens. The last admin cannot be demoted.
+func (s *UserService) SetMemberRole(ctx context.Context, orgID, userID int64, role string) error {
+	if s.orgs == nil {
+		return ErrOrganizationsDisabled
+	}
+	if role != model.RoleOrgAdmin && role != model.RoleOrgMember {
+		return ErrInvalidOrgRole
+	}
+	updated, err := s.orgs.SetMemberRole(ctx, orgID, userID, role)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return s.membershipUnchanged(ctx, orgID, userID)
+	}
+	return s.revokeUserTokens(ctx, userID)
+}
+
+// RemoveMember removes a user from an organization and revokes their access
+// tokens. The last admin cannot be removed.
+func (s *UserService) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	if s.orgs == nil {
+		return ErrOrganizationsDisabled
+	}
+	removed, err := s.orgs.RemoveMember(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return s.membershipUnchanged(ctx, orgID, userID)
+	}
+	return s.revokeUserTokens(ctx, userID)
+}
+
+// membershipUnchanged explains why the membership of a user was left as
+// is: either there is none, or the user is the organization's last admin.
+func (s *UserService) membershipUnchanged(ctx context.Context, orgID, userID int64) error {
+	m, err := s.orgs.Membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return ErrNotMember
+	}
+	return ErrLastOrgAdmin
+}
+
+// checkMember refuses to scope tokens of a user to an organization they are
+// not a member of.
+func (s *UserService) checkMember(ctx context.Context, orgID, userID int64) error {
+	if s.orgs == nil {
+		return ErrOrganizationsDisabled
+	}
+	m, err := s.orgs.Membership(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// checkTenant returns sql.ErrNoRows if the request is made within an
+// organization the user is not a member of, as if the user did not exist.
+func (s *UserService) checkTenant(ctx context.Context, userID int64) error {
+	orgID := OrganizationFrom(ctx)
+	if orgID == 0 {
+		return nil
+	}
+	err := s.checkMember(ctx, orgID, userID)
+	if errors.Is(err, ErrNotMember) {
+		return sql.ErrNoRows
+	}
+	return err
+}
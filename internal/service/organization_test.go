@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+var testOrgKeys = auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec")))
+
+func newOrgService(mr *repoMocks.MockUserRepository, or *repoMocks.MockOrganizationRepository, opts ...service.Option) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore), testOrgKeys, time.Hour,
+		append([]service.Option{service.WithOrganizations(or)}, opts...)...)
+}
+
+func TestCreateOrganization(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	svc := newOrgService(mr, or)
+
+	or.On("Create", mock.Anything, mock.AnythingOfType("*model.Organization"), int64(7), model.RoleOrgAdmin).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.Organization).ID = 4 }).
+		Return(true, nil).Once()
+	org, err := svc.CreateOrganization(t.Context(), 7, "acme-sites", "Acme")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), org.ID)
+
+	_, err = svc.CreateOrganization(t.Context(), 7, "Acme Sites", "Acme")
+	assert.ErrorIs(t, err, service.ErrInvalidSlug)
+
+	or.On("Create", mock.Anything, mock.Anything, int64(7), model.RoleOrgAdmin).Return(false, nil)
+	_, err = svc.CreateOrganization(t.Context(), 7, "acme", "Acme")
+	assert.ErrorIs(t, err, service.ErrSlugTaken)
+}
+
+func TestSwitchOrganization_ScopesTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	ft := new(repoMocks.MockRefreshTokenRepository)
+	svc := newOrgService(mr, or, service.WithRBAC(rr), service.WithRefreshTokens(ft, time.Hour))
+
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "user"}, nil)
+	or.On("Membership", mock.Anything, int64(4), int64(7)).
+		Return(&model.Membership{OrgID: 4, UserID: 7, Role: model.RoleOrgAdmin}, nil)
+	or.On("Membership", mock.Anything, int64(5), int64(7)).Return(nil, nil)
+	rr.On("EffectiveRoles", mock.Anything, int64(7), int64(4)).
+		Return([]string{model.RoleOrgAdmin, model.RoleOrgMember, "user"}, nil)
+	rr.On("EffectivePermissions", mock.Anything, int64(7), int64(4)).
+		Return([]string{model.PermissionMembersManage, model.PermissionUsersDelete}, nil)
+	var stored *model.RefreshToken
+	ft.On("Create", mock.Anything, mock.AnythingOfType("*model.RefreshToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.RefreshToken) }).
+		Return(nil)
+
+	tokens, err := svc.SwitchOrganization(t.Context(), 7, 4)
+	require.NoError(t, err)
+	claims, err := auth.ParseToken(tokens.AccessToken, testOrgKeys, auth.TokenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), claims.OrgID)
+	assert.Contains(t, claims.Roles, model.RoleOrgAdmin)
+	assert.Contains(t, claims.Permissions, model.PermissionUsersDelete)
+	// refreshed tokens stay scoped to the organization
+	require.NotNil(t, stored.OrgID)
+	assert.Equal(t, int64(4), *stored.OrgID)
+
+	_, err = svc.SwitchOrganization(t.Context(), 7, 5)
+	assert.ErrorIs(t, err, service.ErrNotMember)
+}
+
+func TestRefresh_AfterLeavingOrganization(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	ft := new(repoMocks.MockRefreshTokenRepository)
+	svc := newOrgService(mr, or, service.WithRefreshTokens(ft, time.Hour))
+
+	orgID := int64(4)
+	ft.On("GetByHash", mock.Anything, sha256Hex("old")).Return(&model.RefreshToken{
+		ID: 1, UserID: 7, FamilyID: "fam", OrgID: &orgID, ExpiresAt: time.Now().Add(time.Hour),
+	}, nil)
+	ft.On("MarkUsed", mock.Anything, int64(1)).Return(true, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "user"}, nil)
+	or.On("Membership", mock.Anything, int64(4), int64(7)).Return(nil, nil)
+
+	_, err := svc.Refresh(t.Context(), "old")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	ft.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestSetMemberRole(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newOrgService(mr, or, service.WithTokenVersions(tv))
+
+	// the member's tokens carry their old role and are revoked
+	or.On("SetMemberRole", mock.Anything, int64(4), int64(8), model.RoleOrgAdmin).Return(true, nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(8)).Return(nil).Once()
+	mr.On("BumpTokenVersion", mock.Anything, int64(8)).Return(int64(2), nil).Once()
+	tv.On("SetTokenVersion", mock.Anything, int64(8), int64(2)).Return(nil).Once()
+	require.NoError(t, svc.SetMemberRole(t.Context(), 4, 8, model.RoleOrgAdmin))
+
+	assert.ErrorIs(t, svc.SetMemberRole(t.Context(), 4, 8, model.RoleAdmin), service.ErrInvalidOrgRole)
+
+	// the last admin cannot demote themselves
+	or.On("SetMemberRole", mock.Anything, int64(4), int64(7), model.RoleOrgMember).Return(false, nil)
+	or.On("Membership", mock.Anything, int64(4), int64(7)).
+		Return(&model.Membership{OrgID: 4, UserID: 7, Role: model.RoleOrgAdmin}, nil)
+	assert.ErrorIs(t, svc.SetMemberRole(t.Context(), 4, 7, model.RoleOrgMember), service.ErrLastOrgAdmin)
+
+	or.On("RemoveMember", mock.Anything, int64(4), int64(9)).Return(false, nil)
+	or.On("Membership", mock.Anything, int64(4), int64(9)).Return(nil, nil)
+	assert.ErrorIs(t, svc.RemoveMember(t.Context(), 4, 9), service.ErrNotMember)
+	mr.AssertExpectations(t)
+	tv.AssertExpectations(t)
+}
+
+func TestDeleteUser_WithinOrganization(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newOrgService(mr, or, service.WithTokenVersions(tv))
+	ctx := service.ContextWithOrganization(t.Context(), 4)
+
+	mr.On("DeleteInOrganization", mock.Anything, int64(4), int64(8)).Return(true, nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(8), int64(auth.RevokedTokenVersion)).Return(nil).Once()
+	require.NoError(t, svc.DeleteUser(ctx, 8))
+	mr.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	// users of other organizations are out of reach
+	mr.On("DeleteInOrganization", mock.Anything, int64(4), int64(9)).Return(false, sql.ErrNoRows)
+	assert.ErrorIs(t, svc.DeleteUser(ctx, 9), sql.ErrNoRows)
+}
+
+func TestGetUser_WithinOrganization(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	or := new(repoMocks.MockOrganizationRepository)
+	svc := newOrgService(mr, or)
+	ctx := service.ContextWithOrganization(t.Context(), 4)
+
+	or.On("Membership", mock.Anything, int64(4), int64(9)).Return(nil, nil)
+	_, err := svc.GetUser(ctx, 9)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	mr.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+
+	// outside of organizations, any user is found
+	mr.On("GetByID", mock.Anything, int64(9)).Return(&model.User{ID: 9}, nil)
+	u, err := svc.GetUser(t.Context(), 9)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), u.ID)
+}
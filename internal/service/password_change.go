@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// ErrInvalidCurrentPassword is returned when a password or email change is
+// not confirmed with the current password.
+var ErrInvalidCurrentPassword = errors.New("current password is incorrect")
+
+// ViolationReused is reported for passwords that were used recently.
+const ViolationReused = "reused"
+
+// PasswordHistoryRepository remembers the previous password hashes of users.
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, userID int64, hash string) error
+	Recent(ctx context.Context, userID int64, n int) ([]string, error)
+	Trim(ctx context.Context, userID int64, keep int) error
+}
+
+// WithPasswordHistory refuses new passwords that match one of the latest
+// depth passwords of the user, the current one included.
+func WithPasswordHistory(repo PasswordHistoryRepository, depth int) Option {
+	return func(s *UserService) {
+		s.passwordHistory = repo
+		s.historyDepth = depth
+	}
+}
+
+// ChangePassword replaces the password of a user who confirms the current
+// one, see checkCurrentPassword. Every session is revoked; the caller's own
+// continues with the returned token pair.
+func (s *UserService) ChangePassword(ctx context.Context, userID int64, current, newPassword string) (*model.TokenPair, error) {
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, errors.New("user not found")
+	}
+	if err = s.checkCurrentPassword(ctx, u, current); err != nil {
+		return nil, err
+	}
+	if err = s.checkNewPassword(ctx, u, newPassword); err != nil {
+		return nil, err
+	}
+	if err = s.setPassword(ctx, u, newPassword); err != nil {
+		return nil, err
+	}
+	if s.resets != nil {
+		// a reset link sent before must not undo the change
+		if err = s.resets.InvalidateForUser(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	// setPassword bumped the token version, which revokes every access
+	// token but those issued below
+	if err = s.endSessions(ctx, u.ID); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(ctx, u, "")
+}
+
+// checkCurrentPassword returns ErrInvalidCurrentPassword unless current is
+// the password of u. Wrong passwords count as failed logins, so that a
+// stolen session cannot be used to guess the password past the lockout, and
+// a *LoginLockedError is returned while u or the client IP is locked out.
+func (s *UserService) checkCurrentPassword(ctx context.Context, u *model.User, current string) error {
+	if err := s.checkIPLockout(ctx); err != nil {
+		return err
+	}
+	if err := s.checkAccountLockout(u); err != nil {
+		return err
+	}
+	if ok, _ := s.passwordHasher().Verify(u.PasswordHash, current); !ok {
+		if err := s.recordLoginFailure(ctx, u); err != nil {
+			return err
+		}
+		return ErrInvalidCurrentPassword
+	}
+	return nil
+}
+
+// checkNewPassword returns a *PasswordPolicyError if newPassword breaks the
+// password policy or was used by u recently.
+func (s *UserService) checkNewPassword(ctx context.Context, u *model.User, newPassword string) error {
+	err := s.checkPassword(newPassword, u.Email)
+	var pe *PasswordPolicyError
+	if err != nil && !errors.As(err, &pe) {
+		return err
+	}
+	reused, rerr := s.passwordReused(ctx, u, newPassword)
+	if rerr != nil {
+		return rerr
+	}
+	if reused {
+		if pe == nil {
+			pe = &PasswordPolicyError{}
+		}
+		pe.Violations = append(pe.Violations, ViolationReused)
+	}
+	if pe != nil {
+		return pe
+	}
+	return nil
+}
+
+// passwordReused reports whether password is the current password of u or,
+// with WithPasswordHistory, one of the previous ones.
+func (s *UserService) passwordReused(ctx context.Context, u *model.User, password string) (bool, error) {
+	hashes := []string{u.PasswordHash}
+	if s.passwordHistory != nil && s.historyDepth > 1 {
+		previous, err := s.passwordHistory.Recent(ctx, u.ID, s.historyDepth-1)
+		if err != nil {
+			return false, err
+		}
+		hashes = append(hashes, previous...)
+	}
+	for _, hash := range hashes {
+		if ok, _ := s.passwordHasher().Verify(hash, password); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// setPassword stores a new password for u, keeping the old hash in the
+// password history, and revokes the access tokens of u.
+func (s *UserService) setPassword(ctx context.Context, u *model.User, password string) error {
+	hash, err := s.passwordHasher().Hash(password)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return err
+	}
+	if s.passwordHistory != nil && s.historyDepth > 1 {
+		if err = s.passwordHistory.Add(ctx, u.ID, u.PasswordHash); err != nil {
+			return err
+		}
+		if err = s.passwordHistory.Trim(ctx, u.ID, s.historyDepth-1); err != nil {
+			return err
+		}
+	}
+	u.PasswordHash = hash
+	return s.revokeTokens(ctx, u)
+}
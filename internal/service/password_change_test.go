@@ -0,0 +1,148 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newPasswordChangeService(mr *repoMocks.MockUserRepository, ms *authMocks.MockSessionStore,
+	hr *repoMocks.MockPasswordHistoryRepository, tv *repoMocks.MockTokenVersionCache,
+) *service.UserService {
+	return service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithPasswordPolicy(service.PasswordPolicy{MinLength: 8}, nil),
+		service.WithPasswordHistory(hr, 3), service.WithTokenVersions(tv))
+}
+
+func bcryptHash(t *testing.T, password string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+	return string(hash)
+}
+
+func TestChangePassword(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	hr := new(repoMocks.MockPasswordHistoryRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newPasswordChangeService(mr, ms, hr, tv)
+
+	current := bcryptHash(t, "current-secret")
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: current, Role: "user"}, nil)
+	hr.On("Recent", mock.Anything, int64(7), 2).Return([]string{bcryptHash(t, "older-secret")}, nil)
+	var newHash string
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { newHash = args.String(2) }).
+		Return(nil)
+	// the old hash joins the history, which keeps the two before the current
+	hr.On("Add", mock.Anything, int64(7), current).Return(nil)
+	hr.On("Trim", mock.Anything, int64(7), 2).Return(nil)
+	// the tokens issued so far are revoked
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(3), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(3)).Return(nil)
+
+	tokens, err := svc.ChangePassword(t.Context(), 7, "current-secret", "brand-new-secret")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("brand-new-secret")))
+	mr.AssertExpectations(t)
+	hr.AssertExpectations(t)
+	tv.AssertExpectations(t)
+	ms.AssertNotCalled(t, "RevokeAllForUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_TokenSurvivesRevocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	hr := new(repoMocks.MockPasswordHistoryRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newPasswordChangeService(mr, ms, hr, tv)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: bcryptHash(t, "current-secret"), Role: "user"}, nil)
+	hr.On("Recent", mock.Anything, int64(7), 2).Return(nil, nil)
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).Return(nil)
+	hr.On("Add", mock.Anything, int64(7), mock.AnythingOfType("string")).Return(nil)
+	hr.On("Trim", mock.Anything, int64(7), 2).Return(nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(3), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(3)).Return(nil)
+
+	tokens, err := svc.ChangePassword(t.Context(), 7, "current-secret", "brand-new-secret")
+	require.NoError(t, err)
+
+	// the token issued by the change carries the bumped version and is accepted
+	ms.On("IsBlacklisted", mock.Anything, tokens.AccessToken).Return(false, nil)
+	ms.On("RevokedBefore", mock.Anything, int64(7)).Return(time.Time{}, nil)
+	versions := new(authMocks.MockTokenVersions)
+	versions.On("TokenVersion", mock.Anything, int64(7)).Return(int64(3), nil)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	m := auth.AuthenticationMiddleware(auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))),
+		auth.TokenOptions{}, ms, versions)
+	m(c)
+
+	assert.False(t, c.IsAborted())
+	versions.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	svc := newPasswordChangeService(mr, new(authMocks.MockSessionStore), new(repoMocks.MockPasswordHistoryRepository),
+		new(repoMocks.MockTokenVersionCache))
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: bcryptHash(t, "current-secret")}, nil)
+
+	_, err := svc.ChangePassword(t.Context(), 7, "guess", "brand-new-secret")
+	assert.ErrorIs(t, err, service.ErrInvalidCurrentPassword)
+	mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_Reused(t *testing.T) {
+	tests := []struct {
+		name       string
+		password   string
+		violations []string
+	}{
+		{"current", "current-secret", []string{service.ViolationReused}},
+		{"previous", "older-secret", []string{service.ViolationReused}},
+		{"with policy violations", "older", []string{service.ViolationTooShort, service.ViolationReused}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := new(repoMocks.MockUserRepository)
+			hr := new(repoMocks.MockPasswordHistoryRepository)
+			svc := newPasswordChangeService(mr, new(authMocks.MockSessionStore), hr, new(repoMocks.MockTokenVersionCache))
+
+			mr.On("GetByID", mock.Anything, int64(7)).
+				Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: bcryptHash(t, "current-secret")}, nil)
+			hr.On("Recent", mock.Anything, int64(7), 2).
+				Return([]string{bcryptHash(t, "older-secret"), bcryptHash(t, "older")}, nil)
+
+			_, err := svc.ChangePassword(t.Context(), 7, "current-secret", tc.password)
+			var pe *service.PasswordPolicyError
+			require.ErrorAs(t, err, &pe)
+			assert.Equal(t, tc.violations, pe.Violations)
+			mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/passhash"
+)
+
+// PasswordHasher hashes passwords and verifies them against stored hashes,
+// which may have been made with other algorithms or parameters.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether hash should be replaced by a new one.
+	NeedsRehash(hash string) bool
+	// MaxBytes is the longest password that is hashed in full, or 0.
+	MaxBytes() int
+}
+
+// defaultPasswordHasher is used without WithPasswordHasher.
+var defaultPasswordHasher PasswordHasher = passhash.New(passhash.Bcrypt{})
+
+// WithPasswordHasher hashes new passwords with hasher. Hashes it reports as
+// outdated are replaced on the next successful login, which moves users to
+// the configured algorithm and cost without a reset.
+func WithPasswordHasher(hasher PasswordHasher) Option {
+	return func(s *UserService) {
+		s.hasher = hasher
+	}
+}
+
+func (s *UserService) passwordHasher() PasswordHasher {
+	if s.hasher != nil {
+		return s.hasher
+	}
+	return defaultPasswordHasher
+}
+
+// rehashPassword replaces the hash of u made with outdated parameters now
+// that the password is known. Only hashers set with WithPasswordHasher
+// upgrade hashes.
+func (s *UserService) rehashPassword(ctx context.Context, u *model.User, password string) error {
+	if s.hasher == nil || !s.hasher.NeedsRehash(u.PasswordHash) {
+		return nil
+	}
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	if err = s.repo.UpdatePassword(ctx, u.ID, hash); err != nil {
+		return err
+	}
+	u.PasswordHash = hash
+	return nil
+}
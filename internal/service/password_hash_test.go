@@ -0,0 +1,93 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/passhash"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+var testArgon2id = passhash.New(passhash.Argon2id{Memory: 1024, Iterations: 1, Parallelism: 1})
+
+func newHasherService(mr *repoMocks.MockUserRepository) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithPasswordHasher(testArgon2id))
+}
+
+func TestSignUp_HashesWithConfiguredHasher(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	svc := newHasherService(mr)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+
+	u, err := svc.SignUp(t.Context(), "user@x.com", "pwd1234")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u.PasswordHash, "$argon2id$"))
+	ok, err := testArgon2id.Verify(u.PasswordHash, "pwd1234")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLogin_RehashesLegacyHash(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	svc := newHasherService(mr)
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	u := &model.User{ID: 7, Email: "user@x.com", PasswordHash: string(legacy), Role: "user"}
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(u, nil)
+	var upgraded string
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { upgraded = args.String(2) }).
+		Return(nil).Once()
+
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	ok, err := testArgon2id.Verify(upgraded, "correct")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// the upgraded hash is current
+	u.PasswordHash = upgraded
+	_, err = svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	mr.AssertExpectations(t)
+}
+
+func TestLogin_WrongPasswordNotRehashed(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	svc := newHasherService(mr)
+
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(legacy), Role: "user"}, nil)
+
+	_, err := svc.Login(t.Context(), "user@x.com", "wrong")
+	assert.EqualError(t, err, "invalid credentials")
+	mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPasswordPolicy_NoLimitWithArgon2id(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	svc := newHasherService(mr)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+
+	// Argon2id hashes passwords past bcrypt's 72 bytes in full
+	_, err := svc.SignUp(t.Context(), "user@x.com", strings.Repeat("x", 100))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrWeakPassword is matched by every *PasswordPolicyError.
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// Codes of password policy violations, as reported to clients.
+const (
+	ViolationTooShort         = "too_short"
+	ViolationTooLong          = "too_long"
+	ViolationMissingUppercase = "missing_uppercase"
+	ViolationMissingLowercase = "missing_lowercase"
+	ViolationMissingDigit     = "missing_digit"
+	ViolationMissingSymbol    = "missing_symbol"
+	ViolationSimilarToEmail   = "similar_to_email"
+	ViolationBreached         = "breached"
+)
+
+// PasswordPolicyError lists the rules a password breaks.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Violations, ", ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// BreachedPasswordChecker reports whether a password is known to have
+// been breached.
+type BreachedPasswordChecker interface {
+	Breached(password string) bool
+}
+
+// PasswordPolicy describes the passwords users may choose.
+type PasswordPolicy struct {
+	// MinLength counts characters, MaxBytes bytes; MaxBytes is capped at
+	// what the password hasher can hash, e.g. 72 bytes for bcrypt.
+	MinLength int
+	MaxBytes  int
+
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// RejectEmailSimilar refuses passwords that contain the local part of
+	// the user's email address, or are contained in it.
+	RejectEmailSimilar bool
+}
+
+// WithPasswordPolicy enforces policy on new passwords and, unless breached
+// is nil, refuses passwords it reports as breached.
+func WithPasswordPolicy(policy PasswordPolicy, breached BreachedPasswordChecker) Option {
+	return func(s *UserService) {
+		s.passwordPolicy = policy
+		s.breached = breached
+	}
+}
+
+// checkPassword returns a *PasswordPolicyError if password, chosen by the
+// owner of email, breaks the password policy.
+func (s *UserService) checkPassword(password, email string) error {
+	violations := s.passwordPolicy.violations(password, email, s.passwordHasher().MaxBytes())
+	if s.breached != nil && s.breached.Breached(password) {
+		violations = append(violations, ViolationBreached)
+	}
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// violations lists the rules password breaks; hashable is the longest
+// password the hasher takes, or 0.
+func (p PasswordPolicy) violations(password, email string, hashable int) []string {
+	var v []string
+	if len([]rune(password)) < p.MinLength {
+		v = append(v, ViolationTooShort)
+	}
+	maxBytes := p.MaxBytes
+	if hashable > 0 && (maxBytes <= 0 || maxBytes > hashable) {
+		maxBytes = hashable
+	}
+	if maxBytes > 0 && len(password) > maxBytes {
+		v = append(v, ViolationTooLong)
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUppercase && !upper {
+		v = append(v, ViolationMissingUppercase)
+	}
+	if p.RequireLowercase && !lower {
+		v = append(v, ViolationMissingLowercase)
+	}
+	if p.RequireDigit && !digit {
+		v = append(v, ViolationMissingDigit)
+	}
+	if p.RequireSymbol && !symbol {
+		v = append(v, ViolationMissingSymbol)
+	}
+
+	if p.RejectEmailSimilar && similarToEmail(password, email) {
+		v = append(v, ViolationSimilarToEmail)
+	}
+	return v
+}
+
+// similarToEmail reports whether password contains the local part of email
+// or is part of it, ignoring case and anything but letters and digits.
+// Local parts that short would match too much are skipped.
+func similarToEmail(password, email string) bool {
+	local, _, _ := strings.Cut(email, "@")
+	local, password = alphanumeric(local), alphanumeric(password)
+	if len(local) < 3 || password == "" {
+		return false
+	}
+	return strings.Contains(password, local) || strings.Contains(local, password)
+}
+
+func alphanumeric(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
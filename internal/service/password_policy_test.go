@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+type breachedList map[string]bool
+
+func (l breachedList) Breached(password string) bool { return l[password] }
+
+func TestSignUp_PasswordPolicy(t *testing.T) {
+	policy := service.PasswordPolicy{
+		MinLength:          10,
+		RequireUppercase:   true,
+		RequireLowercase:   true,
+		RequireDigit:       true,
+		RequireSymbol:      true,
+		RejectEmailSimilar: true,
+	}
+	tests := []struct {
+		name       string
+		email      string
+		password   string
+		violations []string
+	}{
+		{"valid", "user@x.com", "Tr0ub4dor&3x", nil},
+		{"short", "user@x.com", "Ab1!", []string{service.ViolationTooShort}},
+		{"over bcrypt limit", "user@x.com", "Aa1!" + strings.Repeat("x", 69), []string{service.ViolationTooLong}},
+		{"classes", "user@x.com", "correcthorsebattery", []string{
+			service.ViolationMissingUppercase, service.ViolationMissingDigit, service.ViolationMissingSymbol,
+		}},
+		{"contains email", "john.smith@x.com", "JohnSmith!2025", []string{service.ViolationSimilarToEmail}},
+		{"part of email", "Ze9!.longaddress@x.com", "Ze9!.long", []string{service.ViolationTooShort, service.ViolationSimilarToEmail}},
+		{"short local part", "jo@x.com", "Jo-Jo-1234!", nil},
+		{"breached", "user@x.com", "P@ssw0rd2024", []string{service.ViolationBreached}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := new(repoMocks.MockUserRepository)
+			svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+				auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+				service.WithPasswordPolicy(policy, breachedList{"P@ssw0rd2024": true}))
+			mr.On("GetByEmail", mock.Anything, tc.email).Return(nil, nil).Maybe()
+			mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil).Maybe()
+
+			_, err := svc.SignUp(t.Context(), tc.email, tc.password)
+			if tc.violations == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, service.ErrWeakPassword)
+			var pe *service.PasswordPolicyError
+			require.True(t, errors.As(err, &pe))
+			assert.Equal(t, tc.violations, pe.Violations)
+			mr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSignUp_DefaultPolicy(t *testing.T) {
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	// bcrypt would hash only the first 72 bytes
+	_, err := svc.SignUp(t.Context(), "user@x.com", strings.Repeat("x", 73))
+	assert.EqualError(t, err, "password does not meet the password policy: too_long")
+}
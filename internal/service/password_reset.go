@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrPasswordResetDisabled = errors.New("password reset is not enabled")
+	ErrInvalidResetToken     = errors.New("invalid or expired reset token")
+)
+
+type PasswordResetRepository interface {
+	Create(ctx context.Context, t *model.PasswordResetToken) error
+	GetByHash(ctx context.Context, hash string) (*model.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	InvalidateForUser(ctx context.Context, userID int64) error
+}
+
+// Notifier delivers notifications to users.
+type Notifier interface {
+	Notify(ctx context.Context, n model.Notification) error
+}
+
+// WithPasswordReset enables the forgot/reset password flow. Reset tokens are
+// delivered through notifier and live for expire.
+func WithPasswordReset(repo PasswordResetRepository, notifier Notifier, expire time.Duration) Option {
+	return func(s *UserService) {
+		s.resets = repo
+		s.notifier = notifier
+		s.resetExpire = expire
+	}
+}
+
+// ForgotPassword sends a reset token to email, replacing any token sent
+// before. Unknown addresses are ignored so callers cannot probe for accounts.
+func (s *UserService) ForgotPassword(ctx context.Context, email string) error {
+	if s.resets == nil {
+		return ErrPasswordResetDisabled
+	}
+	u, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return nil
+	}
+	if err = s.resets.InvalidateForUser(ctx, u.ID); err != nil {
+		return err
+	}
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	err = s.resets.Create(ctx, &model.PasswordResetToken{
+		UserID:    u.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.resetExpire),
+	})
+	if err != nil {
+		return err
+	}
+	return s.notifier.Notify(ctx, model.Notification{
+		To:       u.Email,
+		Template: model.TemplatePasswordReset,
+		Data:     map[string]string{"token": raw},
+	})
+}
+
+// ResetPassword spends token to set a new password, then logs the user out
+// everywhere and removes their passkeys so that whoever knew the old
+// password, or held one of their sessions, loses access. A password
+// breaking the password policy, or used recently, is refused with a
+// *PasswordPolicyError and leaves the token unspent.
+func (s *UserService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if s.resets == nil {
+		return ErrPasswordResetDisabled
+	}
+	pr, err := s.resets.GetByHash(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if pr == nil || pr.UsedAt != nil || time.Now().After(pr.ExpiresAt) {
+		return ErrInvalidResetToken
+	}
+	u, err := s.repo.GetByID(ctx, pr.UserID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrInvalidResetToken
+	}
+	if err = s.checkNewPassword(ctx, u, newPassword); err != nil {
+		return err
+	}
+	claimed, err := s.resets.MarkUsed(ctx, pr.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		// spent by a concurrent reset
+		return ErrInvalidResetToken
+	}
+	if err = s.setPassword(ctx, u, newPassword); err != nil {
+		return err
+	}
+	if s.webAuthnCreds != nil {
+		// a passkey added by whoever the account is recovered from would
+		// outlive the new password
+		if err = s.webAuthnCreds.DeleteAllForUser(ctx, u.ID); err != nil {
+			return err
+		}
+	}
+	return s.LogoutAll(ctx, pr.UserID)
+}
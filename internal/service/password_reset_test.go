@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func newResetService(mr *repoMocks.MockUserRepository, ms *authMocks.MockSessionStore,
+	pr *repoMocks.MockPasswordResetRepository, mn *repoMocks.MockNotifier, opts ...service.Option,
+) *service.UserService {
+	opts = append(opts, service.WithPasswordReset(pr, mn, 30*time.Minute))
+	return service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		opts...)
+}
+
+func TestForgotPassword_SendsToken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	pr := new(repoMocks.MockPasswordResetRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newResetService(mr, ms, pr, mn)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 7, Email: "user@x.com"}, nil)
+	pr.On("InvalidateForUser", mock.Anything, int64(7)).Return(nil)
+	var stored *model.PasswordResetToken
+	pr.On("Create", mock.Anything, mock.AnythingOfType("*model.PasswordResetToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.PasswordResetToken) }).
+		Return(nil)
+	var sent model.Notification
+	mn.On("Notify", mock.Anything, mock.AnythingOfType("model.Notification")).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(model.Notification) }).
+		Return(nil)
+
+	require.NoError(t, svc.ForgotPassword(t.Context(), "user@x.com"))
+
+	// the raw token goes to the user, only its hash is persisted
+	assert.Equal(t, "user@x.com", sent.To)
+	assert.Equal(t, model.TemplatePasswordReset, sent.Template)
+	assert.NotEmpty(t, sent.Data["token"])
+	assert.Equal(t, sha256Hex(sent.Data["token"]), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), stored.ExpiresAt, time.Minute)
+	mr.AssertExpectations(t)
+	pr.AssertExpectations(t)
+	mn.AssertExpectations(t)
+}
+
+func TestForgotPassword_UnknownEmail(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	pr := new(repoMocks.MockPasswordResetRepository)
+	mn := new(repoMocks.MockNotifier)
+	svc := newResetService(mr, ms, pr, mn)
+
+	mr.On("GetByEmail", mock.Anything, "nobody@x.com").Return(nil, nil)
+
+	assert.NoError(t, svc.ForgotPassword(t.Context(), "nobody@x.com"))
+	pr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mn.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything)
+}
+
+func TestResetPassword_Success(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	pr := new(repoMocks.MockPasswordResetRepository)
+	mn := new(repoMocks.MockNotifier)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newResetService(mr, ms, pr, mn, service.WithTokenVersions(tv))
+
+	pr.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Email: "user@x.com"}, nil)
+	pr.On("MarkUsed", mock.Anything, int64(3)).Return(true, nil)
+	var newHash string
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).
+		Run(func(args mock.Arguments) { newHash = args.String(2) }).
+		Return(nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(2), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(2)).Return(nil)
+	ms.On("RevokeAllForUser", mock.Anything, int64(7), time.Hour).Return(nil)
+
+	require.NoError(t, svc.ResetPassword(t.Context(), "tok", "n3wpassword"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte("n3wpassword")))
+	mr.AssertExpectations(t)
+	pr.AssertExpectations(t)
+	ms.AssertExpectations(t)
+	tv.AssertExpectations(t)
+}
+
+func TestResetPassword_InvalidToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *model.PasswordResetToken
+	}{
+		{"unknown", nil},
+		{"expired", &model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(-time.Minute)}},
+		{"used", &model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Minute), UsedAt: new(time.Time)}},
+		{"spent concurrently", &model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Minute)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := new(repoMocks.MockUserRepository)
+			ms := new(authMocks.MockSessionStore)
+			pr := new(repoMocks.MockPasswordResetRepository)
+			svc := newResetService(mr, ms, pr, new(repoMocks.MockNotifier))
+
+			pr.On("GetByHash", mock.Anything, sha256Hex("tok")).Return(tc.token, nil)
+			mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Email: "user@x.com"}, nil).Maybe()
+			pr.On("MarkUsed", mock.Anything, int64(3)).Return(false, nil).Maybe()
+
+			err := svc.ResetPassword(t.Context(), "tok", "n3wpassword")
+			assert.ErrorIs(t, err, service.ErrInvalidResetToken)
+			mr.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestResetPassword_WeakPassword(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	pr := new(repoMocks.MockPasswordResetRepository)
+	svc := newResetService(mr, new(authMocks.MockSessionStore), pr, new(repoMocks.MockNotifier),
+		service.WithPasswordPolicy(service.PasswordPolicy{MinLength: 12}, nil))
+
+	pr.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Email: "user@x.com"}, nil)
+
+	err := svc.ResetPassword(t.Context(), "tok", "n3wpassword")
+	assert.ErrorIs(t, err, service.ErrWeakPassword)
+	// the token can still be used with a better password
+	pr.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrRBACDisabled      = errors.New("role-based access control is not enabled")
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrRoleNotHeld       = errors.New("user does not hold the role")
+	ErrLastAdmin         = errors.New("cannot remove the last admin")
+)
+
+// RoleRepository resolves the roles users hold, with the roles they inherit,
+// and the permissions those grant, and manages roles and their holders.
+type RoleRepository interface {
+	EffectiveRoles(ctx context.Context, userID, orgID int64) ([]string, error)
+	EffectivePermissions(ctx context.Context, userID, orgID int64) ([]string, error)
+	AssignRole(ctx context.Context, userID int64, role string) error
+	ListRoles(ctx context.Context) ([]model.Role, error)
+	GetRole(ctx context.Context, name string) (*model.Role, error)
+	ListPermissions(ctx context.Context) ([]string, error)
+	CreateRole(ctx context.Context, role *model.Role) (bool, error)
+	UserRoles(ctx context.Context, userID int64) ([]string, error)
+	RevokeRole(ctx context.Context, userID int64, role string, keepLast bool) (bool, error)
+}
+
+// WithRBAC issues access tokens carrying the roles and permissions of their
+// user as resolved by repo, and grants new users their role in repo. Role
+// changes revoke those tokens by their token version.
+func WithRBAC(repo RoleRepository) Option {
+	return func(s *UserService) {
+		s.roles = repo
+	}
+}
+
+// grantClaims adds the roles and permissions of a user to claims, including
+// those held within the organization claims are scoped to.
+func (s *UserService) grantClaims(ctx context.Context, userID int64, claims *auth.Claims) error {
+	if s.roles == nil {
+		return nil
+	}
+	roles, err := s.roles.EffectiveRoles(ctx, userID, claims.OrgID)
+	if err != nil {
+		return err
+	}
+	perms, err := s.roles.EffectivePermissions(ctx, userID, claims.OrgID)
+	if err != nil {
+		return err
+	}
+	claims.Roles, claims.Permissions = roles, perms
+	return nil
+}
+
+// ListRoles returns every role with the permissions it grants itself.
+func (s *UserService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	if s.roles == nil {
+		return nil, ErrRBACDisabled
+	}
+	return s.roles.ListRoles(ctx)
+}
+
+// CreateRole creates a role granting permissions, which inherits those of
+// the role named parent unless parent is "".
+func (s *UserService) CreateRole(ctx context.Context, name, description, parent string, permissions []string) (*model.Role, error) {
+	if s.roles == nil {
+		return nil, ErrRBACDisabled
+	}
+	known, err := s.roles.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range permissions {
+		if !slices.Contains(known, p) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+	}
+	role := &model.Role{Name: name, Description: description, Permissions: permissions}
+	if role.Permissions == nil {
+		role.Permissions = []string{}
+	}
+	if parent != "" {
+		p, err := s.roles.GetRole(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, ErrRoleNotFound
+		}
+		role.ParentID = &p.ID
+	}
+	created, err := s.roles.CreateRole(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, ErrRoleExists
+	}
+	return role, nil
+}
+
+// UserRoles returns the roles granted to a user, without those they inherit.
+func (s *UserService) UserRoles(ctx context.Context, userID int64) ([]string, error) {
+	if s.roles == nil {
+		return nil, ErrRBACDisabled
+	}
+	if err := s.userExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.roles.UserRoles(ctx, userID)
+}
+
+// GrantRole grants a role to a user. The user's access tokens are revoked,
+// so that the next ones carry the role.
+func (s *UserService) GrantRole(ctx context.Context, userID int64, role string) error {
+	if s.roles == nil {
+		return ErrRBACDisabled
+	}
+	r, err := s.roles.GetRole(ctx, role)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		return ErrRoleNotFound
+	}
+	if err = s.userExists(ctx, userID); err != nil {
+		return err
+	}
+	if err = s.roles.AssignRole(ctx, userID, role); err != nil {
+		return err
+	}
+	return s.revokeUserTokens(ctx, userID)
+}
+
+// RevokeRole takes a role from a user and revokes their access tokens. The
+// admin role is never taken from its last holder, counting those who hold
+// it through inheritance or groups, so that there is always someone left to
+// manage roles.
+func (s *UserService) RevokeRole(ctx context.Context, userID int64, role string) error {
+	if s.roles == nil {
+		return ErrRBACDisabled
+	}
+	revoked, err := s.roles.RevokeRole(ctx, userID, role, role == model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		held, err := s.roles.UserRoles(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(held, role) {
+			return ErrLastAdmin
+		}
+		return ErrRoleNotHeld
+	}
+	return s.revokeUserTokens(ctx, userID)
+}
+
+// userExists returns sql.ErrNoRows unless there is a user with id.
+func (s *UserService) userExists(ctx context.Context, id int64) error {
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func TestLogin_GrantsRolesAndPermissions(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec")))
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore), keys, time.Hour, service.WithRBAC(rr))
+
+	mr.On("GetByEmail", mock.Anything, "admin@x.com").
+		Return(&model.User{ID: 7, Email: "admin@x.com", PasswordHash: bcryptHash(t, "correct"), Role: "admin"}, nil)
+	rr.On("EffectiveRoles", mock.Anything, int64(7), int64(0)).Return([]string{"admin", "user"}, nil)
+	rr.On("EffectivePermissions", mock.Anything, int64(7), int64(0)).
+		Return([]string{model.PermissionUsersDelete, model.PermissionUsersRead}, nil)
+
+	tokens, err := svc.Login(t.Context(), "admin@x.com", "correct")
+	require.NoError(t, err)
+	claims, err := auth.ParseToken(tokens.AccessToken, keys, auth.TokenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Equal(t, []string{"admin", "user"}, claims.Roles)
+	assert.Equal(t, []string{model.PermissionUsersDelete, model.PermissionUsersRead}, claims.Permissions)
+}
+
+func TestSignUp_AssignsRole(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour, service.WithRBAC(rr))
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
+	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).
+		Run(func(args mock.Arguments) { args.Get(1).(*model.User).ID = 7 }).
+		Return(nil)
+	rr.On("AssignRole", mock.Anything, int64(7), "user").Return(nil)
+
+	_, err := svc.SignUp(t.Context(), "user@x.com", "pwd1234")
+	require.NoError(t, err)
+	rr.AssertExpectations(t)
+}
+
+func newRBACService(mr *repoMocks.MockUserRepository, rr *repoMocks.MockRoleRepository, opts ...service.Option) *service.UserService {
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		append([]service.Option{service.WithRBAC(rr)}, opts...)...)
+}
+
+func TestCreateRole(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	svc := newRBACService(mr, rr)
+
+	rr.On("ListPermissions", mock.Anything).Return([]string{model.PermissionUsersRead, model.PermissionUsersUnlock}, nil)
+	rr.On("GetRole", mock.Anything, "user").Return(&model.Role{ID: 1, Name: "user"}, nil)
+	rr.On("GetRole", mock.Anything, "nobody").Return(nil, nil)
+	rr.On("CreateRole", mock.Anything, mock.MatchedBy(func(r *model.Role) bool {
+		return r.Name == "support" && *r.ParentID == 1
+	})).Run(func(args mock.Arguments) { args.Get(1).(*model.Role).ID = 3 }).Return(true, nil).Once()
+
+	role, err := svc.CreateRole(t.Context(), "support", "Helps users", "user", []string{model.PermissionUsersUnlock})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), role.ID)
+	assert.Equal(t, []string{model.PermissionUsersUnlock}, role.Permissions)
+
+	_, err = svc.CreateRole(t.Context(), "support", "", "", []string{"users:fly"})
+	assert.ErrorIs(t, err, service.ErrUnknownPermission)
+	_, err = svc.CreateRole(t.Context(), "support", "", "nobody", nil)
+	assert.ErrorIs(t, err, service.ErrRoleNotFound)
+
+	rr.On("CreateRole", mock.Anything, mock.AnythingOfType("*model.Role")).Return(false, nil)
+	_, err = svc.CreateRole(t.Context(), "support", "", "", nil)
+	assert.ErrorIs(t, err, service.ErrRoleExists)
+}
+
+func TestGrantRole_RevokesTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newRBACService(mr, rr, service.WithTokenVersions(tv))
+
+	rr.On("GetRole", mock.Anything, "admin").Return(&model.Role{ID: 2, Name: "admin"}, nil)
+	rr.On("GetRole", mock.Anything, "nobody").Return(nil, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7}, nil)
+	mr.On("GetByID", mock.Anything, int64(8)).Return(nil, nil)
+	rr.On("AssignRole", mock.Anything, int64(7), "admin").Return(nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(4), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(4)).Return(nil)
+
+	require.NoError(t, svc.GrantRole(t.Context(), 7, "admin"))
+	tv.AssertExpectations(t)
+
+	assert.ErrorIs(t, svc.GrantRole(t.Context(), 7, "nobody"), service.ErrRoleNotFound)
+	assert.ErrorIs(t, svc.GrantRole(t.Context(), 8, "admin"), sql.ErrNoRows)
+}
+
+func TestRevokeRole(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := newRBACService(mr, rr, service.WithTokenVersions(tv))
+
+	rr.On("RevokeRole", mock.Anything, int64(7), "support", false).Return(true, nil).Once()
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil).Once()
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(5), nil).Once()
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(5)).Return(nil).Once()
+	require.NoError(t, svc.RevokeRole(t.Context(), 7, "support"))
+	tv.AssertExpectations(t)
+
+	rr.On("RevokeRole", mock.Anything, int64(7), "support", false).Return(false, nil)
+	rr.On("UserRoles", mock.Anything, int64(7)).Return([]string{"admin", "user"}, nil)
+	assert.ErrorIs(t, svc.RevokeRole(t.Context(), 7, "support"), service.ErrRoleNotHeld)
+
+	// the last admin cannot demote themselves
+	rr.On("RevokeRole", mock.Anything, int64(7), "admin", true).Return(false, nil)
+	assert.ErrorIs(t, svc.RevokeRole(t.Context(), 7, "admin"), service.ErrLastAdmin)
+}
+
+func TestRoles_RBACDisabled(t *testing.T) {
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	_, err := svc.ListRoles(t.Context())
+	assert.ErrorIs(t, err, service.ErrRBACDisabled)
+	assert.ErrorIs(t, svc.GrantRole(t.Context(), 7, "admin"), service.ErrRBACDisabled)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, t *model.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	MarkUsed(ctx context.Context, id int64) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID int64) error
+}
+
+// WithRefreshTokens enables opaque refresh tokens that live for expire.
+func WithRefreshTokens(repo RefreshTokenRepository, expire time.Duration) Option {
+	return func(s *UserService) {
+		s.refresh = repo
+		s.refreshExpire = expire
+	}
+}
+
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is spent in the process; presenting it again revokes its whole family.
+func (s *UserService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	if s.refresh == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	rt, err := s.refresh.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil || time.Now().After(rt.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if rt.UsedAt != nil || rt.RevokedAt != nil {
+		return nil, s.revokeReusedFamily(ctx, rt.FamilyID)
+	}
+	claimed, err := s.refresh.MarkUsed(ctx, rt.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// a concurrent request spent it first
+		return nil, s.revokeReusedFamily(ctx, rt.FamilyID)
+	}
+	u, err := s.repo.GetByID(ctx, rt.UserID)
+	if err != nil || u == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if rt.OrgID != nil {
+		ctx = ContextWithOrganization(ctx, *rt.OrgID)
+	}
+	pair, err := s.issueTokens(ctx, u, rt.FamilyID)
+	if errors.Is(err, ErrNotMember) {
+		// the user has left the organization since
+		return nil, ErrInvalidRefreshToken
+	}
+	return pair, err
+}
+
+// issueTokens mints an access token and, when enabled, a refresh token in
+// familyID. An empty familyID starts a new family, and a new session. The
+// tokens are scoped to the organization of ctx, if any.
+func (s *UserService) issueTokens(ctx context.Context, u *model.User, familyID string) (*model.TokenPair, error) {
+	key, err := s.Keys.Primary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if familyID == "" {
+		if familyID, err = newID(); err != nil {
+			return nil, err
+		}
+	}
+	claims := auth.NewClaims(u, s.tokenOpts, s.jwtExpire)
+	claims.Restricted = s.unverified(u) && s.unverifiedLogin == UnverifiedLoginRestrict
+	if claims.OrgID = OrganizationFrom(ctx); claims.OrgID != 0 {
+		if err = s.checkMember(ctx, claims.OrgID, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err = s.grantClaims(ctx, u.ID, claims); err != nil {
+		return nil, err
+	}
+	if s.sessions != nil {
+		// the family is the session, so it survives refreshes
+		claims.SessionID = familyID
+		if err = s.saveSession(ctx, u, familyID, claims.ID); err != nil {
+			return nil, err
+		}
+	}
+	access, err := auth.SignToken(claims, key)
+	if err != nil {
+		return nil, err
+	}
+	pair := &model.TokenPair{
+		AccessToken: access,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.jwtExpire.Seconds()),
+	}
+	if s.refresh == nil {
+		return pair, nil
+	}
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	rt := &model.RefreshToken{
+		UserID:    u.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(s.refreshExpire),
+	}
+	if claims.OrgID != 0 {
+		rt.OrgID = &claims.OrgID
+	}
+	if err = s.refresh.Create(ctx, rt); err != nil {
+		return nil, err
+	}
+	pair.RefreshToken = raw
+	return pair, nil
+}
+
+// revokeReusedFamily revokes a family after one of its spent tokens was
+// presented again, which means it has most likely been stolen.
+func (s *UserService) revokeReusedFamily(ctx context.Context, familyID string) error {
+	if err := s.refresh.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// revokeRefreshFamily revokes the family of refreshToken, or returns
+// ErrInvalidRefreshToken if it is not a refresh token of userID.
+func (s *UserService) revokeRefreshFamily(ctx context.Context, userID int64, refreshToken string) error {
+	if s.refresh == nil {
+		return nil
+	}
+	rt, err := s.refresh.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if rt == nil || rt.UserID != userID {
+		return ErrInvalidRefreshToken
+	}
+	return s.refresh.RevokeFamily(ctx, rt.FamilyID)
+}
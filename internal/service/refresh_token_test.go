@@ -0,0 +1,193 @@
+package service_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLogin_IssuesRefreshToken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), 15*time.Minute,
+		service.WithRefreshTokens(rr, 24*time.Hour))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}, nil)
+
+	var stored *model.RefreshToken
+	rr.On("Create", mock.Anything, mock.AnythingOfType("*model.RefreshToken")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.RefreshToken) }).
+		Return(nil)
+
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	assert.Equal(t, int64(900), tokens.ExpiresIn)
+
+	// only the hash is persisted
+	assert.Equal(t, int64(7), stored.UserID)
+	assert.NotEmpty(t, stored.FamilyID)
+	assert.Equal(t, sha256Hex(tokens.RefreshToken), stored.TokenHash)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), stored.ExpiresAt, time.Minute)
+
+	mr.AssertExpectations(t)
+	rr.AssertExpectations(t)
+}
+
+func TestRefresh_Rotates(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	current := &model.RefreshToken{ID: 1, UserID: 7, FamilyID: "fam", ExpiresAt: time.Now().Add(time.Hour)}
+	rr.On("GetByHash", mock.Anything, sha256Hex("old")).Return(current, nil)
+	rr.On("MarkUsed", mock.Anything, int64(1)).Return(true, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7, Role: "user"}, nil)
+	rr.On("Create", mock.Anything, mock.MatchedBy(func(rt *model.RefreshToken) bool {
+		return rt.FamilyID == "fam" && rt.UserID == 7
+	})).Return(nil)
+
+	tokens, err := svc.Refresh(t.Context(), "old")
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.NotEqual(t, "old", tokens.RefreshToken)
+
+	mr.AssertExpectations(t)
+	rr.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	used := time.Now().Add(-time.Minute)
+	spent := &model.RefreshToken{ID: 1, UserID: 7, FamilyID: "fam", ExpiresAt: time.Now().Add(time.Hour), UsedAt: &used}
+	rr.On("GetByHash", mock.Anything, sha256Hex("old")).Return(spent, nil)
+	rr.On("RevokeFamily", mock.Anything, "fam").Return(nil)
+
+	tokens, err := svc.Refresh(t.Context(), "old")
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	assert.Nil(t, tokens)
+
+	rr.AssertExpectations(t)
+	rr.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRefresh_LostRaceRevokesFamily(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	current := &model.RefreshToken{ID: 1, UserID: 7, FamilyID: "fam", ExpiresAt: time.Now().Add(time.Hour)}
+	rr.On("GetByHash", mock.Anything, sha256Hex("old")).Return(current, nil)
+	rr.On("MarkUsed", mock.Anything, int64(1)).Return(false, nil)
+	rr.On("RevokeFamily", mock.Anything, "fam").Return(nil)
+
+	_, err := svc.Refresh(t.Context(), "old")
+	assert.ErrorIs(t, err, service.ErrRefreshTokenReused)
+	rr.AssertExpectations(t)
+}
+
+func TestRefresh_Invalid(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	// unknown token
+	rr.On("GetByHash", mock.Anything, sha256Hex("unknown")).Return(nil, nil)
+	_, err := svc.Refresh(t.Context(), "unknown")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// expired token
+	expired := &model.RefreshToken{ID: 2, UserID: 7, FamilyID: "fam", ExpiresAt: time.Now().Add(-time.Minute)}
+	rr.On("GetByHash", mock.Anything, sha256Hex("expired")).Return(expired, nil)
+	_, err = svc.Refresh(t.Context(), "expired")
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	rr.AssertExpectations(t)
+}
+
+func TestLogout_RevokesRefreshFamily(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	key := auth.NewHMACKey("test", []byte("sec"))
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(key), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	tok, _ := auth.GenerateToken(&model.User{ID: 7, Role: "user"}, key, auth.TokenOptions{}, time.Minute)
+	rr.On("GetByHash", mock.Anything, sha256Hex("rt")).
+		Return(&model.RefreshToken{ID: 1, UserID: 7, FamilyID: "fam"}, nil)
+	rr.On("RevokeFamily", mock.Anything, "fam").Return(nil)
+	ms.On("BlacklistToken", mock.Anything, tok, mock.AnythingOfType("time.Duration")).Return(nil)
+
+	err := svc.Logout(t.Context(), tok, "rt")
+	assert.NoError(t, err)
+	rr.AssertExpectations(t)
+	ms.AssertExpectations(t)
+}
+
+func TestLogout_RejectsForeignRefreshToken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	key := auth.NewHMACKey("test", []byte("sec"))
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(key), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	tok, _ := auth.GenerateToken(&model.User{ID: 7, Role: "user"}, key, auth.TokenOptions{}, time.Minute)
+	rr.On("GetByHash", mock.Anything, sha256Hex("unknown")).Return(nil, nil)
+	rr.On("GetByHash", mock.Anything, sha256Hex("theirs")).
+		Return(&model.RefreshToken{ID: 2, UserID: 8, FamilyID: "fam"}, nil)
+
+	assert.ErrorIs(t, svc.Logout(t.Context(), tok, "unknown"), service.ErrInvalidRefreshToken)
+	assert.ErrorIs(t, svc.Logout(t.Context(), tok, "theirs"), service.ErrInvalidRefreshToken)
+	rr.AssertNotCalled(t, "RevokeFamily", mock.Anything, mock.Anything)
+	ms.AssertNotCalled(t, "BlacklistToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogoutAll_RevokesRefreshTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Minute,
+		service.WithRefreshTokens(rr, time.Hour))
+
+	rr.On("RevokeAllForUser", mock.Anything, int64(7)).Return(nil)
+	ms.On("RevokeAllForUser", mock.Anything, int64(7), time.Minute).Return(nil)
+
+	err := svc.LogoutAll(t.Context(), 7)
+	assert.NoError(t, err)
+	rr.AssertExpectations(t)
+	ms.AssertExpectations(t)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrSessionsDisabled = errors.New("session tracking is not enabled")
+	ErrSessionNotFound  = errors.New("session not found")
+)
+
+// SessionRegistry keeps the sessions of users, so that they can see where
+// they are logged in and end sessions remotely.
+type SessionRegistry interface {
+	SaveSession(ctx context.Context, s *model.Session, ttl time.Duration) error
+	ListSessions(ctx context.Context, userID int64) ([]model.Session, error)
+	DeleteSession(ctx context.Context, userID int64, id string) (bool, error)
+	DeleteAllSessions(ctx context.Context, userID int64) error
+}
+
+type userAgentKey struct{}
+
+// ContextWithUserAgent returns a context for requests made by userAgent,
+// which sessions started while handling the request are recorded with.
+func ContextWithUserAgent(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, userAgentKey{}, userAgent)
+}
+
+// UserAgentFrom returns the user agent set by ContextWithUserAgent, or "".
+func UserAgentFrom(ctx context.Context) string {
+	ua, _ := ctx.Value(userAgentKey{}).(string)
+	return ua
+}
+
+// WithSessionRegistry records every login as a session in registry. Access
+// tokens carry their session ID, and a session lasts as long as its refresh
+// token family.
+func WithSessionRegistry(registry SessionRegistry) Option {
+	return func(s *UserService) {
+		s.sessions = registry
+	}
+}
+
+// ListSessions returns the live sessions of a user.
+func (s *UserService) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	if s.sessions == nil {
+		return nil, ErrSessionsDisabled
+	}
+	return s.sessions.ListSessions(ctx, userID)
+}
+
+// RevokeSession ends a session of a user: its access tokens are refused from
+// now on and its refresh token can no longer be used.
+func (s *UserService) RevokeSession(ctx context.Context, userID int64, id string) error {
+	if s.sessions == nil {
+		return ErrSessionsDisabled
+	}
+	deleted, err := s.sessions.DeleteSession(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSessionNotFound
+	}
+	if s.refresh != nil {
+		return s.refresh.RevokeFamily(ctx, id)
+	}
+	return nil
+}
+
+// saveSession records the session familyID of u, to which the access token
+// with claims jti was just issued.
+func (s *UserService) saveSession(ctx context.Context, u *model.User, familyID, jti string) error {
+	now := time.Now()
+	return s.sessions.SaveSession(ctx, &model.Session{
+		ID:         familyID,
+		UserID:     u.ID,
+		JTI:        jti,
+		UserAgent:  UserAgentFrom(ctx),
+		IP:         ClientIPFrom(ctx),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}, max(s.jwtExpire, s.refreshExpire))
+}
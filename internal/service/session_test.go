@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func TestLogin_RecordsSession(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	sr := new(repoMocks.MockSessionRegistry)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec")))
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore), keys, time.Hour,
+		service.WithRefreshTokens(rr, 24*time.Hour), service.WithSessionRegistry(sr))
+	ctx := service.ContextWithUserAgent(service.ContextWithClientIP(t.Context(), "10.0.0.1"), "curl/8.0")
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: bcryptHash(t, "correct"), Role: "user"}, nil)
+	var session *model.Session
+	// the session lasts as long as its refresh tokens
+	sr.On("SaveSession", mock.Anything, mock.AnythingOfType("*model.Session"), 24*time.Hour).
+		Run(func(args mock.Arguments) { session = args.Get(1).(*model.Session) }).
+		Return(nil)
+	var rt *model.RefreshToken
+	rr.On("Create", mock.Anything, mock.AnythingOfType("*model.RefreshToken")).
+		Run(func(args mock.Arguments) { rt = args.Get(1).(*model.RefreshToken) }).
+		Return(nil)
+
+	tokens, err := svc.Login(ctx, "user@x.com", "correct")
+	require.NoError(t, err)
+	claims, err := auth.ParseToken(tokens.AccessToken, keys, auth.TokenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, rt.FamilyID, claims.SessionID)
+	assert.Equal(t, claims.SessionID, session.ID)
+	assert.Equal(t, claims.ID, session.JTI)
+	assert.Equal(t, int64(7), session.UserID)
+	assert.Equal(t, "curl/8.0", session.UserAgent)
+	assert.Equal(t, "10.0.0.1", session.IP)
+}
+
+func TestRevokeSession(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRefreshTokenRepository)
+	sr := new(repoMocks.MockSessionRegistry)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithRefreshTokens(rr, 24*time.Hour), service.WithSessionRegistry(sr))
+
+	sr.On("DeleteSession", mock.Anything, int64(7), "s1").Return(true, nil)
+	rr.On("RevokeFamily", mock.Anything, "s1").Return(nil)
+	sr.On("DeleteSession", mock.Anything, int64(7), "other").Return(false, nil)
+
+	require.NoError(t, svc.RevokeSession(t.Context(), 7, "s1"))
+	assert.ErrorIs(t, svc.RevokeSession(t.Context(), 7, "other"), service.ErrSessionNotFound)
+	rr.AssertNotCalled(t, "RevokeFamily", mock.Anything, "other")
+	rr.AssertExpectations(t)
+
+	plain := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+	assert.ErrorIs(t, plain.RevokeSession(t.Context(), 7, "s1"), service.ErrSessionsDisabled)
+	_, err := plain.ListSessions(t.Context(), 7)
+	assert.ErrorIs(t, err, service.ErrSessionsDisabled)
+}
+
+func TestLogout_EndsSession(t *testing.T) {
+	ms := new(authMocks.MockSessionStore)
+	sr := new(repoMocks.MockSessionRegistry)
+	key := auth.NewHMACKey("test", []byte("sec"))
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), ms, auth.NewStaticKeyring(key), time.Hour,
+		service.WithSessionRegistry(sr))
+
+	claims := auth.NewClaims(&model.User{ID: 7, Role: "user"}, auth.TokenOptions{}, 10*time.Minute)
+	claims.SessionID = "s1"
+	tok, err := auth.SignToken(claims, key)
+	require.NoError(t, err)
+	sr.On("DeleteSession", mock.Anything, int64(7), "s1").Return(true, nil)
+	ms.On("BlacklistToken", mock.Anything, tok, mock.Anything).Return(nil)
+
+	require.NoError(t, svc.Logout(t.Context(), tok, ""))
+	sr.AssertExpectations(t)
+
+	sr.On("DeleteAllSessions", mock.Anything, int64(7)).Return(nil)
+	ms.On("RevokeAllForUser", mock.Anything, int64(7), time.Hour).Return(nil)
+	require.NoError(t, svc.LogoutAll(t.Context(), 7))
+	sr.AssertExpectations(t)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+// TokenVersionCache keeps the token versions the authentication middleware
+// checks access tokens against. Versions it does not hold are loaded from
+// the database.
+type TokenVersionCache interface {
+	SetTokenVersion(ctx context.Context, userID, version int64) error
+	DeleteTokenVersion(ctx context.Context, userID int64) error
+}
+
+// WithTokenVersions revokes every access token of a user at once on role
+// changes, password changes and deletion, by bumping their token version and
+// publishing it to cache. It is required: access tokens carry roles and
+// permissions, which nothing else revokes when they change.
+func WithTokenVersions(cache TokenVersionCache) Option {
+	return func(s *UserService) {
+		s.tokenVersions = cache
+	}
+}
+
+// revokeTokens bumps the token version of u, which revokes every access
+// token issued to them so far. Tokens issued to u afterwards are valid.
+func (s *UserService) revokeTokens(ctx context.Context, u *model.User) error {
+	// a cache that cannot be written now would keep the old version after
+	// the bump, so nothing is bumped unless the old version is gone
+	if err := s.tokenVersions.DeleteTokenVersion(ctx, u.ID); err != nil {
+		return err
+	}
+	v, err := s.repo.BumpTokenVersion(ctx, u.ID)
+	if err != nil {
+		return err
+	}
+	u.TokenVersion = v
+	return s.publishTokenVersion(ctx, u.ID, v)
+}
+
+// revokeUserTokens is revokeTokens for a user that has not been loaded.
+func (s *UserService) revokeUserTokens(ctx context.Context, id int64) error {
+	return s.revokeTokens(ctx, &model.User{ID: id})
+}
+
+// revokeDeletedUser revokes the tokens of a user who has been deleted.
+func (s *UserService) revokeDeletedUser(ctx context.Context, id int64) error {
+	return s.publishTokenVersion(ctx, id, auth.RevokedTokenVersion)
+}
+
+// publishTokenVersion caches the new token version of a user. If that fails,
+// the cached version is dropped instead, as the old one may have been cached
+// again since the bump.
+func (s *UserService) publishTokenVersion(ctx context.Context, id, version int64) error {
+	err := s.tokenVersions.SetTokenVersion(ctx, id, version)
+	if err == nil {
+		return nil
+	}
+	return s.tokenVersions.DeleteTokenVersion(ctx, id)
+}
@@ -0,0 +1,104 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+func TestChangePassword_BumpsTokenVersion(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	vc := new(repoMocks.MockTokenVersionCache)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec")))
+	svc := service.NewUserService(mr, ms, keys, time.Hour, service.WithTokenVersions(vc))
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: bcryptHash(t, "current-secret"), Role: "user",
+			TokenVersion: 4}, nil)
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).Return(nil)
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(5), nil)
+	vc.On("SetTokenVersion", mock.Anything, int64(7), int64(5)).Return(nil)
+
+	tokens, err := svc.ChangePassword(t.Context(), 7, "current-secret", "brand-new-secret")
+	require.NoError(t, err)
+	// the caller's new tokens carry the new version, which revokes the
+	// others without revoking these as a "log out everywhere" would
+	claims, err := auth.ParseToken(tokens.AccessToken, keys, auth.TokenOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), claims.TokenVersion)
+	vc.AssertExpectations(t)
+	ms.AssertNotCalled(t, "RevokeAllForUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteUser_RevokesTokens(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	vc := new(repoMocks.MockTokenVersionCache)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour, service.WithTokenVersions(vc))
+
+	mr.On("Delete", mock.Anything, int64(7)).Return(true, nil)
+	vc.On("SetTokenVersion", mock.Anything, int64(7), int64(auth.RevokedTokenVersion)).Return(nil)
+
+	require.NoError(t, svc.DeleteUser(t.Context(), 7))
+	vc.AssertExpectations(t)
+}
+
+func TestRevokeTokens_CacheFailure(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	rr := new(repoMocks.MockRoleRepository)
+	vc := new(repoMocks.MockTokenVersionCache)
+	svc := newRBACService(mr, rr, service.WithTokenVersions(vc))
+	down := errors.New("redis down")
+
+	rr.On("GetRole", mock.Anything, "admin").Return(&model.Role{ID: 2, Name: "admin"}, nil)
+	mr.On("GetByID", mock.Anything, int64(7)).Return(&model.User{ID: 7}, nil)
+	rr.On("AssignRole", mock.Anything, int64(7), "admin").Return(nil)
+
+	// a cache that cannot drop the old version fails the change before the bump
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(down).Once()
+	assert.ErrorIs(t, svc.GrantRole(t.Context(), 7, "admin"), down)
+	mr.AssertNotCalled(t, "BumpTokenVersion", mock.Anything, mock.Anything)
+
+	// a new version that cannot be stored is dropped, so that it is loaded
+	// from the database rather than an old one cached in the meantime
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil).Twice()
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(5), nil).Once()
+	vc.On("SetTokenVersion", mock.Anything, int64(7), int64(5)).Return(down).Once()
+	require.NoError(t, svc.GrantRole(t.Context(), 7, "admin"))
+	vc.AssertExpectations(t)
+
+	// and the change fails if neither works
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil).Once()
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(6), nil).Once()
+	vc.On("SetTokenVersion", mock.Anything, int64(7), int64(6)).Return(down).Once()
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(down).Once()
+	assert.ErrorIs(t, svc.GrantRole(t.Context(), 7, "admin"), down)
+	vc.AssertExpectations(t)
+}
+
+func TestDeleteUser_CacheFailure(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	vc := new(repoMocks.MockTokenVersionCache)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour, service.WithTokenVersions(vc))
+
+	// a deleted user that cannot be cached as such is loaded as deleted
+	mr.On("Delete", mock.Anything, int64(7)).Return(true, nil)
+	vc.On("SetTokenVersion", mock.Anything, int64(7), int64(auth.RevokedTokenVersion)).Return(errors.New("redis down"))
+	vc.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+
+	require.NoError(t, svc.DeleteUser(t.Context(), 7))
+	vc.AssertExpectations(t)
+}
@@ -6,70 +6,278 @@ import (
 	"time"
 
 	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/mfa"
 	"github.com/enson89/user-service-go/internal/model"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
+// ErrInvalidToken refuses a logout with an access token that does not parse.
+var ErrInvalidToken = errors.New("invalid token")
+
 type UserRepository interface {
 	Create(ctx context.Context, u *model.User) error
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	GetByID(ctx context.Context, id int64) (*model.User, error)
-	Delete(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) (bool, error)
+	DeleteInOrganization(ctx context.Context, orgID, id int64) (bool, error)
 	Update(ctx context.Context, u *model.User) error
+	UpdatePassword(ctx context.Context, id int64, hash string) error
+	MarkEmailVerified(ctx context.Context, id int64) error
+	SetLockedUntil(ctx context.Context, id int64, until *time.Time) error
+	UpdateEmail(ctx context.Context, id int64, email string) (bool, error)
+	BumpTokenVersion(ctx context.Context, id int64) (int64, error)
 }
 
 type SessionStore interface {
-	BlacklistToken(ctx context.Context, token string) error
+	BlacklistToken(ctx context.Context, token string, ttl time.Duration) error
 	IsBlacklisted(ctx context.Context, token string) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID int64, ttl time.Duration) error
+	RevokedBefore(ctx context.Context, userID int64) (time.Time, error)
 }
 
 type UserService struct {
-	repo      UserRepository
-	Store     SessionStore  // exported for middleware
-	Secret    []byte        // exported for middleware
-	jwtExpire time.Duration // used internally for token expiry
+	repo          UserRepository
+	Store         SessionStore  // exported for middleware
+	Keys          *auth.Keyring // exported for middleware
+	jwtExpire     time.Duration // used internally for token expiry
+	tokenOpts     auth.TokenOptions
+	refresh       RefreshTokenRepository
+	refreshExpire time.Duration
+	resets        PasswordResetRepository
+	notifier      Notifier
+	resetExpire   time.Duration
+
+	verifications   EmailVerificationRepository
+	verifyExpire    time.Duration
+	unverifiedLogin UnverifiedLoginPolicy
+
+	mfa           MFARepository
+	mfaChallenges MFAChallengeStore
+	mfaCipher     *mfa.Cipher
+	mfaIssuer     string
+	mfaExpire     time.Duration
+
+	webAuthnCreds    WebAuthnCredentialRepository
+	webAuthnSessions WebAuthnSessionStore
+	webAuthn         *webauthn.WebAuthn
+	webAuthnExpire   time.Duration
+
+	magicLinks      MagicLinkStore
+	magicLinkExpire time.Duration
+
+	attempts LoginAttemptStore
+	lockout  LockoutPolicy
+
+	hasher          PasswordHasher
+	passwordPolicy  PasswordPolicy
+	breached        BreachedPasswordChecker
+	passwordHistory PasswordHistoryRepository
+	historyDepth    int
+
+	emailChanges      EmailChangeRepository
+	emailChangeExpire time.Duration
+
+	sessions      SessionRegistry
+	tokenVersions TokenVersionCache
+	roles         RoleRepository
+	orgs          OrganizationRepository
+
+	invitations      InvitationRepository
+	invitationExpire time.Duration
+
+	groups GroupRepository
+}
+
+// Option configures optional UserService features.
+type Option func(*UserService)
+
+// WithTokenOptions sets the issuer, audience and validation rules of access tokens.
+func WithTokenOptions(opts auth.TokenOptions) Option {
+	return func(s *UserService) {
+		s.tokenOpts = opts
+	}
 }
 
-func NewUserService(repo UserRepository, store SessionStore, secret []byte, expire time.Duration) *UserService {
-	return &UserService{repo: repo, Store: store, Secret: secret, jwtExpire: expire}
+func NewUserService(repo UserRepository, store SessionStore, keys *auth.Keyring, expire time.Duration, opts ...Option) *UserService {
+	s := &UserService{repo: repo, Store: store, Keys: keys, jwtExpire: expire}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// SignUp creates a user. A password breaking the password policy is
+// refused with a *PasswordPolicyError.
 func (s *UserService) SignUp(ctx context.Context, email, password string) (*model.User, error) {
+	u, err := s.createUser(ctx, email, password)
+	if err != nil {
+		return nil, err
+	}
+	if s.verifications != nil {
+		// the account exists either way; a failed send can be retried through ResendVerification
+		_ = s.sendVerification(ctx, u)
+	}
+	return u, nil
+}
+
+// createUser creates a user holding the user role, as SignUp does, without
+// asking them to verify their email.
+func (s *UserService) createUser(ctx context.Context, email, password string) (*model.User, error) {
+	if err := s.checkPassword(password, email); err != nil {
+		return nil, err
+	}
 	if existing, _ := s.repo.GetByEmail(ctx, email); existing != nil {
 		return nil, errors.New("email already in use")
 	}
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := s.passwordHasher().Hash(password)
 	if err != nil {
 		return nil, err
 	}
-	u := &model.User{Email: email, PasswordHash: string(hash), Role: "user"}
+	u := &model.User{Email: email, PasswordHash: hash, Role: "user"}
 	if err = s.repo.Create(ctx, u); err != nil {
 		return nil, err
 	}
+	if s.roles != nil {
+		if err = s.roles.AssignRole(ctx, u.ID, u.Role); err != nil {
+			return nil, err
+		}
+	}
 	return u, nil
 }
 
-func (s *UserService) Login(ctx context.Context, email, password string) (string, error) {
+// Login checks a user's password. Users with MFA enabled get a pair holding
+// only an MFAToken, to be completed with LoginMFA. With WithLockout, failures
+// are counted against the account and the client IP of ctx. Outdated
+// password hashes are upgraded on the way.
+func (s *UserService) Login(ctx context.Context, email, password string) (*model.TokenPair, error) {
+	if err := s.checkIPLockout(ctx); err != nil {
+		return nil, err
+	}
 	u, err := s.repo.GetByEmail(ctx, email)
-	if err != nil || u == nil {
-		return "", errors.New("invalid credentials")
+	if err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+	if u == nil {
+		return nil, s.loginFailed(ctx, nil)
+	}
+	if err = s.checkAccountLockout(u); err != nil {
+		return nil, err
+	}
+	if ok, _ := s.passwordHasher().Verify(u.PasswordHash, password); !ok {
+		return nil, s.loginFailed(ctx, u)
+	}
+	enrolled, err := s.mfaEnabled(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		// with a second factor, the failures are forgotten once it is presented
+		if err = s.loginSucceeded(ctx, u); err != nil {
+			return nil, err
+		}
+	}
+	// the login stands even if the upgrade fails; it is retried next time
+	_ = s.rehashPassword(ctx, u, password)
+	if s.unverified(u) && s.unverifiedLogin == UnverifiedLoginDeny {
+		return nil, ErrEmailNotVerified
+	}
+	if enrolled {
+		return s.mfaChallenge(ctx, u.ID)
+	}
+	return s.issueTokens(ctx, u, "")
+}
+
+// Logout revokes the presented access token for exactly its remaining lifetime,
+// along with the refresh token family of refreshToken if one is given.
+func (s *UserService) Logout(ctx context.Context, token, refreshToken string) error {
+	claims, err := auth.ParseToken(token, s.Keys, s.tokenOpts)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if refreshToken != "" {
+		if err = s.revokeRefreshFamily(ctx, claims.UserID(), refreshToken); err != nil {
+			return err
+		}
+	}
+	if s.sessions != nil && claims.SessionID != "" {
+		if _, err = s.sessions.DeleteSession(ctx, claims.UserID(), claims.SessionID); err != nil {
+			return err
+		}
+	}
+	ttl := time.Until(claims.ExpiresAt.Time)
+	if ttl <= 0 {
+		// already expired, nothing left to revoke
+		return nil
+	}
+	return s.Store.BlacklistToken(ctx, token, ttl)
+}
+
+// LogoutAll revokes every token issued to the user so far.
+func (s *UserService) LogoutAll(ctx context.Context, userID int64) error {
+	if err := s.endSessions(ctx, userID); err != nil {
+		return err
+	}
+	return s.Store.RevokeAllForUser(ctx, userID, s.jwtExpire)
+}
+
+// endSessions revokes the refresh tokens and sessions of a user, but not
+// their access tokens.
+func (s *UserService) endSessions(ctx context.Context, userID int64) error {
+	if s.refresh != nil {
+		if err := s.refresh.RevokeAllForUser(ctx, userID); err != nil {
+			return err
+		}
 	}
-	if err = bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("invalid credentials")
+	if s.sessions != nil {
+		if err := s.sessions.DeleteAllSessions(ctx, userID); err != nil {
+			return err
+		}
 	}
-	token, err := auth.GenerateToken(u, s.Secret, s.jwtExpire)
+	return nil
+}
+
+// RotateSigningKey switches token signing to the next configured key and returns its ID.
+func (s *UserService) RotateSigningKey(ctx context.Context) (string, error) {
+	key, err := s.Keys.Rotate(ctx)
 	if err != nil {
 		return "", err
 	}
-	return token, nil
+	return key.ID, nil
 }
 
 func (s *UserService) GetProfile(ctx context.Context, id int64) (*model.User, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// GetUser returns a user for administration. Within an organization, only
+// its members are found.
+func (s *UserService) GetUser(ctx context.Context, id int64) (*model.User, error) {
+	if err := s.checkTenant(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// DeleteUser deletes a user. Within an organization, only users who are
+// members of it and of no other organization can be deleted. The last admin
+// is never deleted. Their tokens stop working immediately.
 func (s *UserService) DeleteUser(ctx context.Context, id int64) error {
-	return s.repo.Delete(ctx, id)
+	var (
+		deleted bool
+		err     error
+	)
+	if orgID := OrganizationFrom(ctx); orgID != 0 {
+		deleted, err = s.repo.DeleteInOrganization(ctx, orgID, id)
+	} else {
+		deleted, err = s.repo.Delete(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrLastAdmin
+	}
+	return s.revokeDeletedUser(ctx, id)
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, id int64, newName string) (*model.User, error) {
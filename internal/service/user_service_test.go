@@ -5,10 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/enson89/user-service-go/internal/auth"
 	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
 	"github.com/enson89/user-service-go/internal/model"
 	"github.com/enson89/user-service-go/internal/service"
@@ -18,7 +20,7 @@ import (
 func TestSignUp_Success(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
 
 	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
 	mr.On("Create", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
@@ -33,7 +35,7 @@ func TestSignUp_Success(t *testing.T) {
 func TestSignUp_Duplicate(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
 
 	mr.On("GetByEmail", mock.Anything, "user@x.com").
 		Return(&model.User{Email: "user@x.com"}, nil)
@@ -47,36 +49,120 @@ func TestSignUp_Duplicate(t *testing.T) {
 func TestLogin_Success(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
 
 	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
 	mr.On("GetByEmail", mock.Anything, "user@x.com").
 		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}, nil)
 
-	token, err := svc.Login(t.Context(), "user@x.com", "correct")
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.RefreshToken)
 	mr.AssertExpectations(t)
 }
 
+func TestLogin_TokenOptions(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec")))
+	opts := auth.TokenOptions{Issuer: "user-service", Audience: "cms"}
+	svc := service.NewUserService(mr, ms, keys, time.Hour, service.WithTokenOptions(opts))
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}, nil)
+
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	assert.NoError(t, err)
+	claims, err := auth.ParseToken(tokens.AccessToken, keys, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-service", claims.Issuer)
+	assert.Equal(t, int64(7), claims.UserID())
+
+	// a verifier expecting another audience rejects it
+	_, err = auth.ParseToken(tokens.AccessToken, keys, auth.TokenOptions{Audience: "billing"})
+	assert.Error(t, err)
+}
+
 func TestLogin_Invalid(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
 
 	mr.On("GetByEmail", mock.Anything, "user@x.com").
 		Return(nil, errors.New("not found"))
 
-	token, err := svc.Login(t.Context(), "user@x.com", "pwd")
+	tokens, err := svc.Login(t.Context(), "user@x.com", "pwd")
 	assert.Error(t, err)
-	assert.Empty(t, token)
+	assert.Nil(t, tokens)
 	mr.AssertExpectations(t)
 }
 
+func TestLogout(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	key := auth.NewHMACKey("test", []byte("sec"))
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(key), time.Hour)
+
+	tok, _ := auth.GenerateToken(&model.User{ID: 7, Role: "user"}, key, auth.TokenOptions{}, 10*time.Minute)
+	// the blacklist entry must live exactly as long as the token does
+	ms.On("BlacklistToken", mock.Anything, tok, mock.MatchedBy(func(ttl time.Duration) bool {
+		return ttl > 9*time.Minute && ttl <= 10*time.Minute
+	})).Return(nil)
+
+	err := svc.Logout(t.Context(), tok, "")
+	assert.NoError(t, err)
+	ms.AssertExpectations(t)
+}
+
+func TestLogout_InvalidToken(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	err := svc.Logout(t.Context(), "not.a.token", "")
+	assert.ErrorIs(t, err, service.ErrInvalidToken)
+	ms.AssertNotCalled(t, "BlacklistToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogoutAll(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	ms.On("RevokeAllForUser", mock.Anything, int64(7), time.Hour).Return(nil)
+
+	err := svc.LogoutAll(t.Context(), 7)
+	assert.NoError(t, err)
+	ms.AssertExpectations(t)
+}
+
+func TestRotateSigningKey(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("k1", []byte("a")), auth.NewHMACKey("k2", []byte("b")))
+	svc := service.NewUserService(mr, ms, keys, time.Hour)
+
+	kid, err := svc.RotateSigningKey(t.Context())
+	assert.NoError(t, err)
+	assert.Equal(t, "k2", kid)
+
+	// new tokens are signed with the rotated-to key
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct"), bcrypt.MinCost)
+	mr.On("GetByEmail", mock.Anything, "user@x.com").
+		Return(&model.User{ID: 7, Email: "user@x.com", PasswordHash: string(hash), Role: "user"}, nil)
+	tokens, err := svc.Login(t.Context(), "user@x.com", "correct")
+	assert.NoError(t, err)
+	tok, _, err := jwt.NewParser().ParseUnverified(tokens.AccessToken, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, "k2", tok.Header["kid"])
+}
+
 func TestGetProfile(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
 
 	expected := &model.User{ID: 3, Email: "a@b.com", Role: "admin"}
 	mr.On("GetByID", mock.Anything, int64(3)).Return(expected, nil)
@@ -90,19 +176,34 @@ func TestGetProfile(t *testing.T) {
 func TestDeleteUser(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("sec"), time.Hour)
+	tv := new(repoMocks.MockTokenVersionCache)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithTokenVersions(tv))
 
-	mr.On("Delete", mock.Anything, int64(5)).Return(nil)
+	mr.On("Delete", mock.Anything, int64(5)).Return(true, nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(5), int64(auth.RevokedTokenVersion)).Return(nil)
 
 	err := svc.DeleteUser(t.Context(), 5)
 	assert.NoError(t, err)
 	mr.AssertExpectations(t)
+	tv.AssertExpectations(t)
+}
+
+func TestDeleteUser_LastAdmin(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	mr.On("Delete", mock.Anything, int64(1)).Return(false, nil)
+
+	assert.ErrorIs(t, svc.DeleteUser(t.Context(), 1), service.ErrLastAdmin)
+	mr.AssertExpectations(t)
 }
 
 func TestUpdateUser_Success(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("secret"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("secret"))), time.Hour)
 
 	existing := &model.User{ID: 1, Name: "Old"}
 	mr.On("GetByID", mock.Anything, int64(1)).Return(existing, nil)
@@ -118,7 +219,7 @@ func TestUpdateUser_Success(t *testing.T) {
 func TestUpdateUser_NotFound(t *testing.T) {
 	mr := new(repoMocks.MockUserRepository)
 	ms := new(authMocks.MockSessionStore)
-	svc := service.NewUserService(mr, ms, []byte("secret"), time.Hour)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("secret"))), time.Hour)
 
 	mr.On("GetByID", mock.Anything, int64(2)).Return(nil, errors.New("not found"))
 
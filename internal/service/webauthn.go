@@ -0,0 +1,408 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	"github.com/enson89/user-service-go/internal/model"
+)
+
+var (
+	ErrWebAuthnDisabled           = errors.New("webauthn is not enabled")
+	ErrInvalidWebAuthnSession     = errors.New("invalid or expired webauthn session")
+	ErrInvalidWebAuthnResponse    = errors.New("invalid webauthn response")
+	ErrWebAuthnCloneDetected      = errors.New("webauthn credential may have been cloned")
+	ErrWebAuthnCredentialNotFound = errors.New("webauthn credential not found")
+)
+
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, c *model.WebAuthnCredential) error
+	ListByUser(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)
+	RecordUse(ctx context.Context, id, signCount int64, backupState bool) error
+	MarkCloned(ctx context.Context, id int64) error
+	Delete(ctx context.Context, userID, id int64) (bool, error)
+	DeleteAllForUser(ctx context.Context, userID int64) error
+}
+
+// WebAuthnSessionStore keeps the session data of WebAuthn ceremonies between
+// their begin and finish steps. TakeSession returns nil for unknown keys.
+type WebAuthnSessionStore interface {
+	SaveSession(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	TakeSession(ctx context.Context, key string) ([]byte, error)
+}
+
+// WithWebAuthn lets users register passkeys and security keys and log in
+// with them. A ceremony has to be finished within sessionExpire of being
+// begun. Authenticators have to verify their user, e.g. with a PIN or
+// biometrics, as a credential stands in for both password and second factor.
+func WithWebAuthn(repo WebAuthnCredentialRepository, sessions WebAuthnSessionStore, wa *webauthn.WebAuthn,
+	sessionExpire time.Duration,
+) Option {
+	return func(s *UserService) {
+		s.webAuthnCreds = repo
+		s.webAuthnSessions = sessions
+		s.webAuthn = wa
+		s.webAuthnExpire = sessionExpire
+	}
+}
+
+// BeginWebAuthnRegistration starts registering a new credential for a user
+// and returns the options to pass to navigator.credentials.create. The user
+// confirms it with their current password and, if they have MFA enabled, a
+// second factor, see reauthenticate.
+func (s *UserService) BeginWebAuthnRegistration(ctx context.Context, userID int64, password, code string,
+) (*protocol.CredentialCreation, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	user, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.reauthenticate(ctx, user.u, password, code); err != nil {
+		return nil, err
+	}
+	exclusions := make([]protocol.CredentialDescriptor, len(user.creds))
+	for i, c := range user.creds {
+		exclusions[i] = toWebAuthnCredential(c).Descriptor()
+	}
+	creation, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+		func(opts *protocol.PublicKeyCredentialCreationOptions) {
+			opts.AuthenticatorSelection.UserVerification = protocol.VerificationRequired
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.saveWebAuthnSession(ctx, registrationSessionKey(userID), session); err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// FinishWebAuthnRegistration verifies the attestation response to the
+// user's pending registration and stores the new credential. The user is
+// told about it by email, if notifications are set up.
+func (s *UserService) FinishWebAuthnRegistration(ctx context.Context, userID int64, response []byte,
+) (*model.WebAuthnCredential, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	session, err := s.takeWebAuthnSession(ctx, registrationSessionKey(userID))
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebAuthnResponse, err)
+	}
+	user, err := s.loadWebAuthnUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebAuthnResponse, err)
+	}
+	transports := make([]string, len(credential.Transport))
+	for i, t := range credential.Transport {
+		transports[i] = string(t)
+	}
+	c := &model.WebAuthnCredential{
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Transports:      strings.Join(transports, ","),
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err = s.webAuthnCreds.Create(ctx, c); err != nil {
+		return nil, err
+	}
+	if s.notifier != nil {
+		// lets the owner notice a passkey they did not add
+		err = s.notifier.Notify(ctx, model.Notification{
+			To:       user.u.Email,
+			Template: model.TemplatePasskeyAdded,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// BeginWebAuthnLogin starts a login and returns the options to pass to
+// navigator.credentials.get, along with the session ID to finish it with.
+// Without an email, or for an email with nothing registered, any
+// discoverable credential (passkey) is accepted, so the response does not
+// reveal whether an account exists.
+func (s *UserService) BeginWebAuthnLogin(ctx context.Context, email string,
+) (string, *protocol.CredentialAssertion, error) {
+	if s.webAuthn == nil {
+		return "", nil, ErrWebAuthnDisabled
+	}
+	var user *webAuthnUser
+	if email != "" {
+		u, err := s.repo.GetByEmail(ctx, email)
+		if err != nil {
+			return "", nil, err
+		}
+		if u != nil {
+			if user, err = s.webAuthnUserFor(ctx, u); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		err       error
+	)
+	uv := webauthn.WithUserVerification(protocol.VerificationRequired)
+	if user != nil && len(user.WebAuthnCredentials()) > 0 {
+		assertion, session, err = s.webAuthn.BeginLogin(user, uv)
+	} else {
+		assertion, session, err = s.webAuthn.BeginDiscoverableLogin(uv)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	raw, hash, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	if err = s.saveWebAuthnSession(ctx, loginSessionKey(hash), session); err != nil {
+		return "", nil, err
+	}
+	return raw, assertion, nil
+}
+
+// FinishWebAuthnLogin verifies the assertion response to the login begun
+// under sessionID and issues tokens. A credential whose signature counter did
+// not advance may have been cloned: it is disabled and the login refused.
+// The credential itself stands in for both password and second factor.
+func (s *UserService) FinishWebAuthnLogin(ctx context.Context, sessionID string, response []byte,
+) (*model.TokenPair, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	session, err := s.takeWebAuthnSession(ctx, loginSessionKey(hashToken(sessionID)))
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebAuthnResponse, err)
+	}
+
+	var user *webAuthnUser
+	if len(session.UserID) == 0 {
+		handler := func(_, userHandle []byte) (webauthn.User, error) {
+			userID, ok := webAuthnUserID(userHandle)
+			if !ok {
+				return nil, errors.New("malformed user handle")
+			}
+			found, lookupErr := s.loadWebAuthnUser(ctx, userID)
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			user = found
+			return found, nil
+		}
+		_, _, err = s.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	} else {
+		userID, ok := webAuthnUserID(session.UserID)
+		if !ok {
+			return nil, ErrInvalidWebAuthnSession
+		}
+		if user, err = s.loadWebAuthnUser(ctx, userID); err != nil {
+			return nil, err
+		}
+		_, err = s.webAuthn.ValidateLogin(user, *session, parsed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWebAuthnResponse, err)
+	}
+	return s.completeWebAuthnLogin(ctx, user, parsed)
+}
+
+// ListWebAuthnCredentials returns the credentials a user has registered.
+func (s *UserService) ListWebAuthnCredentials(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	if s.webAuthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+	return s.webAuthnCreds.ListByUser(ctx, userID)
+}
+
+// DeleteWebAuthnCredential removes one of a user's credentials.
+func (s *UserService) DeleteWebAuthnCredential(ctx context.Context, userID, id int64) error {
+	if s.webAuthn == nil {
+		return ErrWebAuthnDisabled
+	}
+	deleted, err := s.webAuthnCreds.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrWebAuthnCredentialNotFound
+	}
+	return nil
+}
+
+// completeWebAuthnLogin records the use of the credential that signed a
+// verified assertion and issues tokens to its owner.
+func (s *UserService) completeWebAuthnLogin(ctx context.Context, user *webAuthnUser,
+	parsed *protocol.ParsedCredentialAssertionData,
+) (*model.TokenPair, error) {
+	var stored *model.WebAuthnCredential
+	for i := range user.creds {
+		if bytes.Equal(user.creds[i].CredentialID, parsed.RawID) {
+			stored = &user.creds[i]
+			break
+		}
+	}
+	if stored == nil {
+		return nil, ErrInvalidWebAuthnResponse
+	}
+	authenticator := toWebAuthnCredential(*stored).Authenticator
+	authenticator.UpdateCounter(parsed.Response.AuthenticatorData.Counter)
+	if authenticator.CloneWarning {
+		if err := s.webAuthnCreds.MarkCloned(ctx, stored.ID); err != nil {
+			return nil, err
+		}
+		return nil, ErrWebAuthnCloneDetected
+	}
+	backupState := parsed.Response.AuthenticatorData.Flags.HasBackupState()
+	if err := s.webAuthnCreds.RecordUse(ctx, stored.ID, int64(authenticator.SignCount), backupState); err != nil {
+		return nil, err
+	}
+	if s.unverified(user.u) && s.unverifiedLogin == UnverifiedLoginDeny {
+		return nil, ErrEmailNotVerified
+	}
+	return s.issueTokens(ctx, user.u, "")
+}
+
+func (s *UserService) loadWebAuthnUser(ctx context.Context, userID int64) (*webAuthnUser, error) {
+	u, err := s.repo.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil, errors.New("user not found")
+	}
+	return s.webAuthnUserFor(ctx, u)
+}
+
+func (s *UserService) webAuthnUserFor(ctx context.Context, u *model.User) (*webAuthnUser, error) {
+	creds, err := s.webAuthnCreds.ListByUser(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{u: u, creds: creds}, nil
+}
+
+func (s *UserService) saveWebAuthnSession(ctx context.Context, key string, session *webauthn.SessionData) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.webAuthnSessions.SaveSession(ctx, key, data, s.webAuthnExpire)
+}
+
+func (s *UserService) takeWebAuthnSession(ctx context.Context, key string) (*webauthn.SessionData, error) {
+	data, err := s.webAuthnSessions.TakeSession(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrInvalidWebAuthnSession
+	}
+	var session webauthn.SessionData
+	if err = json.Unmarshal(data, &session); err != nil {
+		return nil, ErrInvalidWebAuthnSession
+	}
+	return &session, nil
+}
+
+// registrationSessionKey allows one pending registration per user.
+func registrationSessionKey(userID int64) string {
+	return "register:" + strconv.FormatInt(userID, 10)
+}
+
+func loginSessionKey(sessionHash string) string {
+	return "login:" + sessionHash
+}
+
+// webAuthnUser adapts a user and their stored credentials to webauthn.User.
+// The user handle is the big-endian user ID.
+type webAuthnUser struct {
+	u     *model.User
+	creds []model.WebAuthnCredential
+}
+
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(w.u.ID)) //nolint:gosec
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.u.Email
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.u.Email
+}
+
+// WebAuthnCredentials returns the credentials the user can log in with,
+// leaving out those suspected of having been cloned.
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(w.creds))
+	for _, c := range w.creds {
+		if c.CloneDetectedAt == nil {
+			creds = append(creds, toWebAuthnCredential(c))
+		}
+	}
+	return creds
+}
+
+func webAuthnUserID(handle []byte) (int64, bool) {
+	if len(handle) != 8 { //nolint:mnd
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(handle)), true //nolint:gosec
+}
+
+func toWebAuthnCredential(c model.WebAuthnCredential) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if c.Transports != "" {
+		for _, t := range strings.Split(c.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(t))
+		}
+	}
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    c.AAGUID,
+			SignCount: uint32(c.SignCount), //nolint:gosec
+		},
+	}
+}
@@ -0,0 +1,380 @@
+package service_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/mfa"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	repoMocks "github.com/enson89/user-service-go/internal/service/mocks"
+)
+
+const (
+	testRPID     = "example.com"
+	testRPOrigin = "https://example.com"
+)
+
+// softAuthenticator is a software WebAuthn authenticator holding a single
+// P-256 credential, producing "none" attestations.
+type softAuthenticator struct {
+	t      *testing.T
+	key    *ecdsa.PrivateKey
+	credID []byte
+	userID []byte
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credID := make([]byte, 16)
+	_, err = rand.Read(credID)
+	require.NoError(t, err)
+	return &softAuthenticator{t: t, key: key, credID: credID}
+}
+
+// create answers navigator.credentials.create options.
+func (a *softAuthenticator) create(opts *protocol.CredentialCreation) []byte {
+	a.userID = opts.Response.User.ID.(protocol.URLEncodedBase64)
+	clientData := a.clientData("webauthn.create", opts.Response.Challenge)
+
+	ecdh, err := a.key.PublicKey.ECDH()
+	require.NoError(a.t, err)
+	point := ecdh.Bytes() // 0x04 | x | y
+	coseKey, err := webauthncbor.Marshal(map[int]any{1: 2, 3: -7, -1: 1, -2: point[1:33], -3: point[33:]})
+	require.NoError(a.t, err)
+
+	authData := a.authData(0x45, 0) // UP | UV | AT
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credID)))
+	authData = append(authData, a.credID...)
+	authData = append(authData, coseKey...)
+	attestation, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	require.NoError(a.t, err)
+
+	return a.response(map[string]any{
+		"clientDataJSON":    b64(clientData),
+		"attestationObject": b64(attestation),
+	})
+}
+
+// get answers navigator.credentials.get options with the given signature counter.
+func (a *softAuthenticator) get(opts *protocol.CredentialAssertion, counter uint32) []byte {
+	clientData := a.clientData("webauthn.get", opts.Response.Challenge)
+	authData := a.authData(0x05, counter) // UP | UV
+	digest := sha256.Sum256(clientData)
+	signed := sha256.Sum256(append(authData, digest[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, signed[:])
+	require.NoError(a.t, err)
+
+	return a.response(map[string]any{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(sig),
+		"userHandle":        b64(a.userID),
+	})
+}
+
+func (a *softAuthenticator) clientData(typ string, challenge protocol.URLEncodedBase64) []byte {
+	data, err := json.Marshal(map[string]string{"type": typ, "challenge": challenge.String(), "origin": testRPOrigin})
+	require.NoError(a.t, err)
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte, counter uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, counter)
+}
+
+func (a *softAuthenticator) response(resp map[string]any) []byte {
+	data, err := json.Marshal(map[string]any{
+		"id": b64(a.credID), "rawId": b64(a.credID), "type": "public-key", "response": resp,
+	})
+	require.NoError(a.t, err)
+	return data
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newWebAuthnService(t *testing.T, mr *repoMocks.MockUserRepository, cr *repoMocks.MockWebAuthnCredentialRepository,
+	ss *repoMocks.MockWebAuthnSessionStore,
+) *service.UserService {
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "User Service",
+		RPOrigins:     []string{testRPOrigin},
+	})
+	require.NoError(t, err)
+	return service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithWebAuthn(cr, ss, wa, 5*time.Minute))
+}
+
+// registerSoftAuthenticator runs a registration ceremony for user 7 and
+// returns the authenticator and the credential as stored.
+func registerSoftAuthenticator(t *testing.T, svc *service.UserService, mr *repoMocks.MockUserRepository,
+	cr *repoMocks.MockWebAuthnCredentialRepository, ss *repoMocks.MockWebAuthnSessionStore,
+) (*softAuthenticator, model.WebAuthnCredential) {
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", Role: "user", PasswordHash: bcryptHash(t, "secret")}, nil)
+	cr.On("ListByUser", mock.Anything, int64(7)).Return(nil, nil).Twice()
+	var session []byte
+	ss.On("SaveSession", mock.Anything, "register:7", mock.Anything, 5*time.Minute).
+		Run(func(args mock.Arguments) { session = args.Get(2).([]byte) }).
+		Return(nil).Once()
+
+	creation, err := svc.BeginWebAuthnRegistration(t.Context(), 7, "secret", "")
+	require.NoError(t, err)
+	assert.Equal(t, protocol.VerificationRequired, creation.Response.AuthenticatorSelection.UserVerification)
+	authn := newSoftAuthenticator(t)
+	response := authn.create(creation)
+
+	ss.On("TakeSession", mock.Anything, "register:7").Return(session, nil).Once()
+	var stored model.WebAuthnCredential
+	cr.On("Create", mock.Anything, mock.AnythingOfType("*model.WebAuthnCredential")).
+		Run(func(args mock.Arguments) {
+			c := args.Get(1).(*model.WebAuthnCredential)
+			c.ID = 3
+			stored = *c
+		}).
+		Return(nil).Once()
+
+	c, err := svc.FinishWebAuthnRegistration(t.Context(), 7, response)
+	require.NoError(t, err)
+	assert.Equal(t, authn.credID, c.CredentialID)
+	assert.Equal(t, "none", c.AttestationType)
+	return authn, stored
+}
+
+// beginLogin begins a login for email and returns its session ID and options,
+// with the session data ready to be taken by the finish step.
+func beginLogin(t *testing.T, svc *service.UserService, ss *repoMocks.MockWebAuthnSessionStore, email string,
+) (string, *protocol.CredentialAssertion) {
+	var (
+		key     string
+		session []byte
+	)
+	ss.On("SaveSession", mock.Anything, mock.AnythingOfType("string"), mock.Anything, 5*time.Minute).
+		Run(func(args mock.Arguments) {
+			key = args.String(1)
+			session = args.Get(2).([]byte)
+		}).
+		Return(nil).Once()
+
+	sessionID, assertion, err := svc.BeginWebAuthnLogin(t.Context(), email)
+	require.NoError(t, err)
+	assert.Equal(t, "login:"+sha256Hex(sessionID), key)
+	ss.On("TakeSession", mock.Anything, key).Return(session, nil).Once()
+	return sessionID, assertion
+}
+
+func TestBeginWebAuthnRegistration_Reauthenticates(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	fr := new(repoMocks.MockMFARepository)
+	cipher, err := mfa.NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+	wa, err := webauthn.New(&webauthn.Config{RPID: testRPID, RPDisplayName: "User Service", RPOrigins: []string{testRPOrigin}})
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithWebAuthn(cr, ss, wa, 5*time.Minute),
+		service.WithMFA(fr, new(repoMocks.MockMFAChallengeStore), cipher, "User Service", 5*time.Minute))
+	m, secret := enabledMFA(t, cipher)
+
+	mr.On("GetByID", mock.Anything, int64(7)).
+		Return(&model.User{ID: 7, Email: "user@x.com", Role: "user", PasswordHash: bcryptHash(t, "secret")}, nil)
+	cr.On("ListByUser", mock.Anything, int64(7)).Return(nil, nil)
+	fr.On("Get", mock.Anything, int64(7)).Return(m, nil)
+	fr.On("UseRecoveryCode", mock.Anything, int64(7), mock.Anything).Return(false, nil)
+
+	// a session alone is not enough to add a passkey
+	_, err = svc.BeginWebAuthnRegistration(t.Context(), 7, "wrong", "")
+	assert.ErrorIs(t, err, service.ErrInvalidCurrentPassword)
+	// nor is the password, as the passkey would stand in for the second factor too
+	_, err = svc.BeginWebAuthnRegistration(t.Context(), 7, "secret", "")
+	assert.ErrorIs(t, err, service.ErrInvalidMFACode)
+	ss.AssertNotCalled(t, "SaveSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	code, err := mfa.TOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	fr.On("ClaimTOTPStep", mock.Anything, int64(7), mock.AnythingOfType("int64")).Return(true, nil)
+	ss.On("SaveSession", mock.Anything, "register:7", mock.Anything, 5*time.Minute).Return(nil)
+	_, err = svc.BeginWebAuthnRegistration(t.Context(), 7, "secret", code)
+	require.NoError(t, err)
+}
+
+func TestWebAuthn_NoticeAndReset(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	ms := new(authMocks.MockSessionStore)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	pr := new(repoMocks.MockPasswordResetRepository)
+	mn := new(repoMocks.MockNotifier)
+	tv := new(repoMocks.MockTokenVersionCache)
+	wa, err := webauthn.New(&webauthn.Config{RPID: testRPID, RPDisplayName: "User Service", RPOrigins: []string{testRPOrigin}})
+	require.NoError(t, err)
+	svc := service.NewUserService(mr, ms, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour,
+		service.WithWebAuthn(cr, ss, wa, 5*time.Minute), service.WithPasswordReset(pr, mn, 30*time.Minute),
+		service.WithTokenVersions(tv))
+
+	// the owner hears of every passkey added
+	mn.On("Notify", mock.Anything, model.Notification{To: "user@x.com", Template: model.TemplatePasskeyAdded}).
+		Return(nil).Once()
+	registerSoftAuthenticator(t, svc, mr, cr, ss)
+	mn.AssertExpectations(t)
+
+	// and a password reset removes them all
+	pr.On("GetByHash", mock.Anything, sha256Hex("tok")).
+		Return(&model.PasswordResetToken{ID: 3, UserID: 7, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	pr.On("MarkUsed", mock.Anything, int64(3)).Return(true, nil)
+	mr.On("UpdatePassword", mock.Anything, int64(7), mock.AnythingOfType("string")).Return(nil)
+	tv.On("DeleteTokenVersion", mock.Anything, int64(7)).Return(nil)
+	mr.On("BumpTokenVersion", mock.Anything, int64(7)).Return(int64(2), nil)
+	tv.On("SetTokenVersion", mock.Anything, int64(7), int64(2)).Return(nil)
+	ms.On("RevokeAllForUser", mock.Anything, int64(7), time.Hour).Return(nil)
+	cr.On("DeleteAllForUser", mock.Anything, int64(7)).Return(nil).Once()
+
+	require.NoError(t, svc.ResetPassword(t.Context(), "tok", "n3wpassword"))
+	cr.AssertExpectations(t)
+}
+
+func TestWebAuthn_RegisterAndLogin(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+	authn, stored := registerSoftAuthenticator(t, svc, mr, cr, ss)
+
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 7, Email: "user@x.com", Role: "user"}, nil)
+	cr.On("ListByUser", mock.Anything, int64(7)).Return([]model.WebAuthnCredential{stored}, nil)
+	sessionID, assertion := beginLogin(t, svc, ss, "user@x.com")
+	require.Len(t, assertion.Response.AllowedCredentials, 1)
+	assert.Equal(t, protocol.VerificationRequired, assertion.Response.UserVerification)
+
+	cr.On("RecordUse", mock.Anything, int64(3), int64(1), false).Return(nil).Once()
+	tokens, err := svc.FinishWebAuthnLogin(t.Context(), sessionID, authn.get(assertion, 1))
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	cr.AssertExpectations(t)
+	ss.AssertExpectations(t)
+}
+
+func TestWebAuthn_DiscoverableLogin(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+	authn, stored := registerSoftAuthenticator(t, svc, mr, cr, ss)
+
+	// an unknown email gets the same options as no email at all
+	mr.On("GetByEmail", mock.Anything, "nobody@x.com").Return(nil, nil)
+	sessionID, assertion := beginLogin(t, svc, ss, "nobody@x.com")
+	assert.Empty(t, assertion.Response.AllowedCredentials)
+
+	cr.On("ListByUser", mock.Anything, int64(7)).Return([]model.WebAuthnCredential{stored}, nil)
+	cr.On("RecordUse", mock.Anything, int64(3), int64(0), false).Return(nil).Once()
+	tokens, err := svc.FinishWebAuthnLogin(t.Context(), sessionID, authn.get(assertion, 0))
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	cr.AssertExpectations(t)
+}
+
+func TestWebAuthn_CloneDetected(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+	authn, stored := registerSoftAuthenticator(t, svc, mr, cr, ss)
+
+	// the genuine authenticator already got to 5
+	stored.SignCount = 5
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(&model.User{ID: 7, Email: "user@x.com", Role: "user"}, nil)
+	cr.On("ListByUser", mock.Anything, int64(7)).Return([]model.WebAuthnCredential{stored}, nil)
+	sessionID, assertion := beginLogin(t, svc, ss, "user@x.com")
+
+	cr.On("MarkCloned", mock.Anything, int64(3)).Return(nil).Once()
+	_, err := svc.FinishWebAuthnLogin(t.Context(), sessionID, authn.get(assertion, 3))
+	assert.ErrorIs(t, err, service.ErrWebAuthnCloneDetected)
+	cr.AssertExpectations(t)
+	cr.AssertNotCalled(t, "RecordUse", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebAuthn_ClonedCredentialRejected(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+	authn, stored := registerSoftAuthenticator(t, svc, mr, cr, ss)
+
+	now := time.Now()
+	stored.CloneDetectedAt = &now
+	mr.On("GetByEmail", mock.Anything, "user@x.com").Return(nil, nil)
+	cr.On("ListByUser", mock.Anything, int64(7)).Return([]model.WebAuthnCredential{stored}, nil)
+	sessionID, assertion := beginLogin(t, svc, ss, "user@x.com")
+
+	_, err := svc.FinishWebAuthnLogin(t.Context(), sessionID, authn.get(assertion, 9))
+	assert.ErrorIs(t, err, service.ErrInvalidWebAuthnResponse)
+}
+
+func TestWebAuthn_SessionGone(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+
+	ss.On("TakeSession", mock.Anything, "login:"+sha256Hex("stale")).Return(nil, nil)
+	_, err := svc.FinishWebAuthnLogin(t.Context(), "stale", []byte(`{}`))
+	assert.ErrorIs(t, err, service.ErrInvalidWebAuthnSession)
+
+	ss.On("TakeSession", mock.Anything, "register:7").Return(nil, nil)
+	_, err = svc.FinishWebAuthnRegistration(t.Context(), 7, []byte(`{}`))
+	assert.ErrorIs(t, err, service.ErrInvalidWebAuthnSession)
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	mr := new(repoMocks.MockUserRepository)
+	cr := new(repoMocks.MockWebAuthnCredentialRepository)
+	ss := new(repoMocks.MockWebAuthnSessionStore)
+	svc := newWebAuthnService(t, mr, cr, ss)
+
+	cr.On("Delete", mock.Anything, int64(7), int64(3)).Return(true, nil)
+	cr.On("Delete", mock.Anything, int64(7), int64(4)).Return(false, nil)
+
+	assert.NoError(t, svc.DeleteWebAuthnCredential(t.Context(), 7, 3))
+	assert.ErrorIs(t, svc.DeleteWebAuthnCredential(t.Context(), 7, 4), service.ErrWebAuthnCredentialNotFound)
+}
+
+func TestWebAuthn_NotConfigured(t *testing.T) {
+	svc := service.NewUserService(new(repoMocks.MockUserRepository), new(authMocks.MockSessionStore),
+		auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("sec"))), time.Hour)
+
+	_, err := svc.BeginWebAuthnRegistration(t.Context(), 7, "secret", "")
+	assert.ErrorIs(t, err, service.ErrWebAuthnDisabled)
+	_, _, err = svc.BeginWebAuthnLogin(t.Context(), "user@x.com")
+	assert.ErrorIs(t, err, service.ErrWebAuthnDisabled)
+	_, err = svc.FinishWebAuthnLogin(t.Context(), "s", nil)
+	assert.ErrorIs(t, err, service.ErrWebAuthnDisabled)
+	_, err = svc.ListWebAuthnCredentials(t.Context(), 7)
+	assert.ErrorIs(t, err, service.ErrWebAuthnDisabled)
+}
@@ -0,0 +1,71 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUser godoc
+// @Summary      Get a user
+// @Description  Fetch a user, including whether they are locked out of password logins (requires users:read). With a token scoped to an organization, only its members are found.
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {object}  model.User
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Router       /admin/users/{id} [get]
+// @Security     ApiKeyAuth
+func (h *Handler) GetUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	user, err := h.svc.GetUser(getContext(c), id)
+	if err != nil || user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UnlockUser godoc
+// @Summary      Unlock a user
+// @Description  Lift the lockout of a user after failed logins and forget those failures (requires users:unlock). With a token scoped to an organization, only its members can be unlocked.
+// @Tags         admin
+// @Param        id   path      int  true  "User ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/users/{id}/unlock [post]
+// @Security     ApiKeyAuth
+func (h *Handler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if err = h.svc.UnlockUser(getContext(c), id); err != nil {
+		switch {
+		case errors.Is(err, service.ErrLockoutDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package http_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_GetUser(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	until := time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	mockSvc.On("GetUser", mock.Anything, int64(10)).
+		Return(&model.User{ID: 10, Email: "u@x.com", Role: "user", LockedUntil: &until}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "10"}}
+
+	handler.GetUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp model.User
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, int64(10), resp.ID)
+	assert.True(t, until.Equal(*resp.LockedUntil))
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"no such user", sql.ErrNoRows, http.StatusNotFound},
+		{"feature off", service.ErrLockoutDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("UnlockUser", mock.Anything, int64(10)).Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "10"}}
+
+			handler.UnlockUser(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
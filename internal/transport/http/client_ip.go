@@ -0,0 +1,42 @@
+package http
+
+import (
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIP stores the client's IP in the request context, so that failed
+// logins made while handling the request are counted against it. The IP is
+// only taken from X-Forwarded-For behind trusted proxies, see
+// WithTrustedProxies.
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ip := c.ClientIP(); ip != "" {
+			c.Request = c.Request.WithContext(service.ContextWithClientIP(c.Request.Context(), ip))
+		}
+		c.Next()
+	}
+}
+
+// UserAgent stores the client's user agent in the request context, so that
+// sessions started while handling the request can be told apart.
+func UserAgent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ua := c.Request.UserAgent(); ua != "" {
+			c.Request = c.Request.WithContext(service.ContextWithUserAgent(c.Request.Context(), ua))
+		}
+		c.Next()
+	}
+}
+
+// Tenant stores the organization the caller's token is scoped to in the
+// request context, so that the request only reaches that organization's
+// users. It runs after authentication.
+func Tenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if orgID := c.GetInt64("orgID"); orgID != 0 {
+			c.Request = c.Request.WithContext(service.ContextWithOrganization(c.Request.Context(), orgID))
+		}
+		c.Next()
+	}
+}
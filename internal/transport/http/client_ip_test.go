@@ -0,0 +1,98 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestClientIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(httptransport.ClientIP())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, service.ClientIPFrom(c.Request.Context()))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:52100"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "10.0.0.1", w.Body.String())
+}
+
+func TestClientIP_TrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys := auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("test-secret")))
+	login := func(r http.Handler) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/login", bytes.NewBufferString(`{"email":"ok@x.com","password":"pw"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", "203.0.113.9")
+		req.RemoteAddr = "10.0.0.1:52100"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	fromIP := func(ip string) any {
+		return mock.MatchedBy(func(ctx context.Context) bool { return service.ClientIPFrom(ctx) == ip })
+	}
+
+	// by default, a forged header does not change the client IP
+	mockSvc := new(httphandlermocks.MockUserService)
+	mockSvc.On("Login", fromIP("10.0.0.1"), "ok@x.com", "pw").Return(nil, service.ErrLoginLocked)
+	r, err := httptransport.NewRouter(mockSvc, keys, auth.TokenOptions{}, nil)
+	require.NoError(t, err)
+	login(r)
+	mockSvc.AssertExpectations(t)
+
+	// behind a trusted proxy, its header is believed
+	mockSvc = new(httphandlermocks.MockUserService)
+	mockSvc.On("Login", fromIP("203.0.113.9"), "ok@x.com", "pw").Return(nil, service.ErrLoginLocked)
+	r, err = httptransport.NewRouter(mockSvc, keys, auth.TokenOptions{}, nil,
+		httptransport.WithTrustedProxies([]string{"10.0.0.0/8"}))
+	require.NoError(t, err)
+	login(r)
+	mockSvc.AssertExpectations(t)
+
+	_, err = httptransport.NewRouter(mockSvc, keys, auth.TokenOptions{}, nil,
+		httptransport.WithTrustedProxies([]string{"not-an-ip"}))
+	assert.Error(t, err)
+}
+
+func TestUserAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(httptransport.UserAgent())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, service.UserAgentFrom(c.Request.Context()))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "curl/8.0", w.Body.String())
+}
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("orgID", int64(4)) }, httptransport.Tenant())
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, service.OrganizationFrom(c.Request.Context()))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "4", w.Body.String())
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Confirm ownership of the account's email address with the token sent to it
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.VerifyEmailRequest  true  "Verification token"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /email/verify [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.VerifyEmail(getContext(c), req.Token); err != nil {
+		if errors.Is(err, service.ErrInvalidVerificationToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ResendVerification godoc
+// @Summary      Resend verification email
+// @Description  Send a new verification token to an unverified address. The response is the same whether or not such an account exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ResendVerificationRequest  true  "Account email"
+// @Success      202      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /email/verify/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.ResendVerification(getContext(c), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not send verification token"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the account exists and is unverified, a verification token has been sent"})
+}
+
+// RequestEmailChange godoc
+// @Summary      Change my email address
+// @Description  Send a confirmation token to the new address and a notice to the current one. The address changes once the token is confirmed.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ChangeEmailRequest  true  "Current password and new email address"
+// @Success      202      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /profile/email [post]
+// @Security     ApiKeyAuth
+func (h *Handler) RequestEmailChange(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.RequestEmailChange(getContext(c), c.GetInt64("userID"), req.CurrentPassword, req.Email); err != nil {
+		if loginLocked(c, err) {
+			return
+		}
+		emailChangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "a confirmation token has been sent to the new address"})
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Confirm email change
+// @Description  Swap in the new email address with the token sent to it
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ConfirmEmailChangeRequest  true  "Email change token"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /email/change/confirm [post]
+func (h *Handler) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.ConfirmEmailChange(getContext(c), req.Token); err != nil {
+		emailChangeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func emailChangeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrEmailChangeDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCurrentPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrEmailUnchanged), errors.Is(err, service.ErrInvalidEmailChangeToken):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
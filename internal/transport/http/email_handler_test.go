@@ -0,0 +1,122 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_VerifyEmail(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("VerifyEmail", mock.Anything, "tok").Return(nil)
+	mockSvc.On("VerifyEmail", mock.Anything, "old").Return(service.ErrInvalidVerificationToken)
+
+	for token, code := range map[string]int{"tok": http.StatusNoContent, "old": http.StatusBadRequest} {
+		buf, _ := json.Marshal(map[string]string{"token": token})
+		req := httptest.NewRequest(http.MethodPost, "/v1/email/verify", bytes.NewBuffer(buf))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, token)
+	}
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_ResendVerification(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ResendVerification", mock.Anything, "user@x.com").Return(nil)
+
+	buf, _ := json.Marshal(map[string]string{"email": "user@x.com"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/email/verify/resend", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Login_Unverified(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("Login", mock.Anything, "user@x.com", "pwd1234").Return(nil, service.ErrEmailNotVerified)
+
+	buf, _ := json.Marshal(map[string]string{"email": "user@x.com", "password": "pwd1234"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/login", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_RequestEmailChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"sent", nil, http.StatusAccepted},
+		{"taken", service.ErrEmailTaken, http.StatusConflict},
+		{"unchanged", service.ErrEmailUnchanged, http.StatusBadRequest},
+		{"feature off", service.ErrEmailChangeDisabled, http.StatusNotFound},
+		{"wrong password", service.ErrInvalidCurrentPassword, http.StatusForbidden},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+			mockSvc.On("RequestEmailChange", mock.Anything, int64(10), "secret", "new@x.com").Return(tc.err)
+
+			buf, _ := json.Marshal(map[string]string{"current_password": "secret", "email": "new@x.com"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/profile/email", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("userID", int64(10))
+
+			handler.RequestEmailChange(c)
+			assert.Equal(t, tc.status, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ConfirmEmailChange(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ConfirmEmailChange", mock.Anything, "tok").Return(nil)
+	mockSvc.On("ConfirmEmailChange", mock.Anything, "old").Return(service.ErrInvalidEmailChangeToken)
+	mockSvc.On("ConfirmEmailChange", mock.Anything, "raced").Return(service.ErrEmailTaken)
+
+	for token, code := range map[string]int{
+		"tok":   http.StatusNoContent,
+		"old":   http.StatusBadRequest,
+		"raced": http.StatusConflict,
+	} {
+		buf, _ := json.Marshal(map[string]string{"token": token})
+		req := httptest.NewRequest(http.MethodPost, "/v1/email/change/confirm", bytes.NewBuffer(buf))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, token)
+	}
+	mockSvc.AssertExpectations(t)
+}
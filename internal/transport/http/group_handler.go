@@ -0,0 +1,364 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListGroups godoc
+// @Summary      List groups
+// @Description  List every group, without members, subgroups and roles (requires groups:read)
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   model.Group
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/groups [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListGroups(c *gin.Context) {
+	groups, err := h.svc.ListGroups(getContext(c))
+	if err != nil {
+		groupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, groups)
+}
+
+// CreateGroup godoc
+// @Summary      Create a group
+// @Description  Create an empty group (requires groups:manage)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.CreateGroupRequest  true  "Group"
+// @Success      201      {object}  model.Group
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/groups [post]
+// @Security     ApiKeyAuth
+func (h *Handler) CreateGroup(c *gin.Context) {
+	var req CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	g, err := h.svc.CreateGroup(getContext(c), req.Name, req.Description)
+	if err != nil {
+		groupError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, g)
+}
+
+// GetGroup godoc
+// @Summary      Get a group
+// @Description  Get a group with its direct members and subgroups and the roles granted to it (requires groups:read)
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "Group ID"
+// @Success      200  {object}  model.Group
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/groups/{id} [get]
+// @Security     ApiKeyAuth
+func (h *Handler) GetGroup(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	g, err := h.svc.GetGroup(getContext(c), id)
+	if err != nil {
+		groupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// RenameGroup godoc
+// @Summary      Rename a group
+// @Description  Rename a group (requires groups:manage)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                      true  "Group ID"
+// @Param        payload  body      http.RenameGroupRequest  true  "New name"
+// @Success      200      {object}  model.Group
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/groups/{id} [patch]
+// @Security     ApiKeyAuth
+func (h *Handler) RenameGroup(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	var req RenameGroupRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	g, err := h.svc.RenameGroup(getContext(c), id, req.Name)
+	if err != nil {
+		groupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, g)
+}
+
+// DeleteGroup godoc
+// @Summary      Delete a group
+// @Description  Delete a group. Its members lose the roles granted to it and their access tokens are revoked. Refused if that would leave no admin (requires groups:manage)
+// @Tags         admin
+// @Param        id   path      int  true  "Group ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      409  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/groups/{id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	if err = h.svc.DeleteGroup(getContext(c), id); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddGroupMember godoc
+// @Summary      Add a member to a group
+// @Description  Make a user a member of a group. They hold the roles of the group from the next tokens issued to them (requires groups:manage)
+// @Tags         admin
+// @Param        id       path      int  true  "Group ID"
+// @Param        user_id  path      int  true  "User ID"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/groups/{id}/members/{user_id} [put]
+// @Security     ApiKeyAuth
+func (h *Handler) AddGroupMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if err = h.svc.AddGroupMember(getContext(c), id, userID); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveGroupMember godoc
+// @Summary      Remove a member from a group
+// @Description  Remove a user from a group and revoke their access tokens. Refused if that would leave no admin (requires groups:manage)
+// @Tags         admin
+// @Param        id       path      int  true  "Group ID"
+// @Param        user_id  path      int  true  "User ID"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/groups/{id}/members/{user_id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RemoveGroupMember(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if err = h.svc.RemoveGroupMember(getContext(c), id, userID); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddSubgroup godoc
+// @Summary      Nest a group
+// @Description  Make a group a subgroup of another, so that its members are members of the other too. A group cannot become its own subgroup, directly or through others (requires groups:manage)
+// @Tags         admin
+// @Param        id           path      int  true  "Group ID"
+// @Param        subgroup_id  path      int  true  "Subgroup ID"
+// @Success      204          "No Content"
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  map[string]string
+// @Failure      403          {object}  map[string]string
+// @Failure      404          {object}  map[string]string
+// @Failure      409          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /admin/groups/{id}/subgroups/{subgroup_id} [put]
+// @Security     ApiKeyAuth
+func (h *Handler) AddSubgroup(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	subgroupID, err := strconv.ParseInt(c.Param("subgroup_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subgroup ID"})
+		return
+	}
+	if err = h.svc.AddSubgroup(getContext(c), id, subgroupID); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveSubgroup godoc
+// @Summary      Remove a subgroup
+// @Description  Take a subgroup out of a group and revoke the access tokens of its members. Refused if that would leave no admin (requires groups:manage)
+// @Tags         admin
+// @Param        id           path      int  true  "Group ID"
+// @Param        subgroup_id  path      int  true  "Subgroup ID"
+// @Success      204          "No Content"
+// @Failure      400          {object}  map[string]string
+// @Failure      401          {object}  map[string]string
+// @Failure      403          {object}  map[string]string
+// @Failure      404          {object}  map[string]string
+// @Failure      409          {object}  map[string]string
+// @Failure      500          {object}  map[string]string
+// @Router       /admin/groups/{id}/subgroups/{subgroup_id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RemoveSubgroup(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	subgroupID, err := strconv.ParseInt(c.Param("subgroup_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subgroup ID"})
+		return
+	}
+	if err = h.svc.RemoveSubgroup(getContext(c), id, subgroupID); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GrantGroupRole godoc
+// @Summary      Grant a role to a group
+// @Description  Grant a role to a group. Its members, and those of its subgroups, hold the role from the next tokens issued to them (requires groups:manage)
+// @Tags         admin
+// @Accept       json
+// @Param        id       path      int                    true  "Group ID"
+// @Param        payload  body      http.GrantRoleRequest  true  "Role"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/groups/{id}/roles [post]
+// @Security     ApiKeyAuth
+func (h *Handler) GrantGroupRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	var req GrantRoleRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = h.svc.GrantGroupRole(getContext(c), id, req.Role); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeGroupRole godoc
+// @Summary      Revoke a role from a group
+// @Description  Take a role from a group and revoke the access tokens of its members, and those of its subgroups. Refused if that would leave no admin (requires groups:manage)
+// @Tags         admin
+// @Param        id    path      int     true  "Group ID"
+// @Param        role  path      string  true  "Role name"
+// @Success      204   "No Content"
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/groups/{id}/roles/{role} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RevokeGroupRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid group ID"})
+		return
+	}
+	if err = h.svc.RevokeGroupRole(getContext(c), id, c.Param("role")); err != nil {
+		groupError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// groupError answers a failed group request.
+func groupError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrGroupsDisabled),
+		errors.Is(err, service.ErrGroupNotFound),
+		errors.Is(err, service.ErrNotGroupMember),
+		errors.Is(err, service.ErrGroupRoleNotHeld),
+		errors.Is(err, service.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, service.ErrGroupExists), errors.Is(err, service.ErrGroupCycle),
+		errors.Is(err, service.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,154 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_CreateGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusCreated},
+		{"name taken", service.ErrGroupExists, http.StatusConflict},
+		{"feature off", service.ErrGroupsDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var g *model.Group
+			if tc.err == nil {
+				g = &model.Group{ID: 3, Name: "editors"}
+			}
+			mockSvc.On("CreateGroup", mock.Anything, "editors", "Editorial team").Return(g, tc.err)
+
+			buf, _ := json.Marshal(httptransport.CreateGroupRequest{Name: "editors", Description: "Editorial team"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/groups", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateGroup(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GetGroup(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("GetGroup", mock.Anything, int64(3)).Return(&model.Group{
+		ID: 3, Name: "editors",
+		Members:   []model.GroupMember{{UserID: 7, Email: "user@x.com"}},
+		Subgroups: []model.Group{{ID: 5, Name: "desk"}},
+		Roles:     []string{"editor"},
+	}, nil)
+	mockSvc.On("GetGroup", mock.Anything, int64(4)).Return(nil, service.ErrGroupNotFound)
+
+	for id, want := range map[string]int{"3": http.StatusOK, "4": http.StatusNotFound, "x": http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: id}}
+
+		handler.GetGroup(c)
+
+		assert.Equal(t, want, w.Code, id)
+		if want == http.StatusOK {
+			var g model.Group
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &g))
+			assert.Equal(t, []string{"editor"}, g.Roles)
+			assert.Len(t, g.Members, 1)
+			assert.Len(t, g.Subgroups, 1)
+		}
+	}
+}
+
+func TestHandler_AddSubgroup(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"cycle", service.ErrGroupCycle, http.StatusConflict},
+		{"unknown group", service.ErrGroupNotFound, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("AddSubgroup", mock.Anything, int64(3), int64(5)).Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "subgroup_id", Value: "5"}}
+
+			handler.AddSubgroup(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_GrantGroupRole(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("GrantGroupRole", mock.Anything, int64(3), "editor").Return(nil)
+	mockSvc.On("GrantGroupRole", mock.Anything, int64(3), "nobody").Return(service.ErrRoleNotFound)
+
+	for role, want := range map[string]int{"editor": http.StatusNoContent, "nobody": http.StatusNotFound} {
+		buf, _ := json.Marshal(httptransport.GrantRoleRequest{Role: role})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "3"}}
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/groups/3/roles", bytes.NewBuffer(buf))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.GrantGroupRole(c)
+
+		assert.Equal(t, want, c.Writer.Status(), role)
+	}
+}
+
+func TestHandler_RemoveGroupMember(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("RemoveGroupMember", mock.Anything, int64(3), int64(7)).Return(nil)
+	mockSvc.On("RemoveGroupMember", mock.Anything, int64(3), int64(8)).Return(service.ErrNotGroupMember)
+	mockSvc.On("RemoveGroupMember", mock.Anything, int64(3), int64(9)).Return(service.ErrLastAdmin)
+
+	for user, want := range map[string]int{
+		"7": http.StatusNoContent, "8": http.StatusNotFound, "9": http.StatusConflict, "x": http.StatusBadRequest,
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "3"}, {Key: "user_id", Value: user}}
+
+		handler.RemoveGroupMember(c)
+
+		assert.Equal(t, want, c.Writer.Status(), user)
+	}
+}
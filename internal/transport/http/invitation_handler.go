@@ -0,0 +1,165 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateInvitation godoc
+// @Summary      Invite someone to an organization
+// @Description  Email an invitation to join the organization with an org_admin or org_member role. The invitation expires after a while (requires members:manage, with a token scoped to the organization)
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        id       path      int                           true  "Organization ID"
+// @Param        payload  body      http.CreateInvitationRequest  true  "Invitation"
+// @Success      201      {object}  model.Invitation
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /orgs/{id}/invitations [post]
+// @Security     ApiKeyAuth
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	inv, err := h.svc.InviteMember(getContext(c), c.GetInt64("orgID"), c.GetInt64("userID"), req.Email, req.Role)
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, inv)
+}
+
+// ListInvitations godoc
+// @Summary      List pending invitations
+// @Description  List the invitations of an organization that can still be accepted (requires members:manage, with a token scoped to the organization)
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {array}   model.Invitation
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orgs/{id}/invitations [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListInvitations(c *gin.Context) {
+	invitations, err := h.svc.ListInvitations(getContext(c), c.GetInt64("orgID"))
+	if err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invitations)
+}
+
+// RevokeInvitation godoc
+// @Summary      Revoke an invitation
+// @Description  Withdraw a pending invitation, so that it can no longer be accepted (requires members:manage, with a token scoped to the organization)
+// @Tags         organizations
+// @Param        id             path      int  true  "Organization ID"
+// @Param        invitation_id  path      int  true  "Invitation ID"
+// @Success      204            "No Content"
+// @Failure      400            {object}  map[string]string
+// @Failure      401            {object}  map[string]string
+// @Failure      403            {object}  map[string]string
+// @Failure      404            {object}  map[string]string
+// @Failure      500            {object}  map[string]string
+// @Router       /orgs/{id}/invitations/{invitation_id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid invitation ID"})
+		return
+	}
+	if err = h.svc.RevokeInvitation(getContext(c), c.GetInt64("orgID"), id); err != nil {
+		invitationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation godoc
+// @Summary      Accept an invitation with a new account
+// @Description  Join the organization of an invitation with an account created with the given password. If an account with the invited email address exists, its owner accepts through /profile/invitations/accept instead.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.AcceptInvitationRequest  true  "Invitation token"
+// @Success      200      {object}  model.Membership
+// @Failure      400      {object}  http.PasswordPolicyErrorResponse
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /invitations/accept [post]
+func (h *Handler) AcceptInvitation(c *gin.Context) {
+	h.acceptInvitation(c, 0)
+}
+
+// AcceptInvitationAsUser godoc
+// @Summary      Accept an invitation with my account
+// @Description  Join the organization of an invitation sent to the authenticated user's email address. No password is needed.
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.AcceptInvitationRequest  true  "Invitation token"
+// @Success      200      {object}  model.Membership
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /profile/invitations/accept [post]
+// @Security     ApiKeyAuth
+func (h *Handler) AcceptInvitationAsUser(c *gin.Context) {
+	h.acceptInvitation(c, c.GetInt64("userID"))
+}
+
+// acceptInvitation accepts an invitation for userID, or for a new account
+// if userID is 0.
+func (h *Handler) acceptInvitation(c *gin.Context, userID int64) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	m, err := h.svc.AcceptInvitation(getContext(c), userID, req.Token, req.Password)
+	if err != nil {
+		if passwordPolicyViolation(c, err) {
+			return
+		}
+		invitationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, m)
+}
+
+// invitationError answers a failed invitation request.
+func invitationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvitationsDisabled), errors.Is(err, service.ErrInvitationNotFound),
+		errors.Is(err, service.ErrNotMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidInvitation), errors.Is(err, service.ErrPasswordRequired),
+		errors.Is(err, service.ErrInvalidOrgRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationLogin):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationForOther):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,138 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_CreateInvitation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusCreated},
+		{"bad role", service.ErrInvalidOrgRole, http.StatusBadRequest},
+		{"already member", service.ErrAlreadyMember, http.StatusConflict},
+		{"existing account", service.ErrInvitationLogin, http.StatusUnauthorized},
+		{"feature off", service.ErrInvitationsDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var inv *model.Invitation
+			if tc.err == nil {
+				inv = &model.Invitation{ID: 9, OrgID: 4, Email: "new@x.com", Role: model.RoleOrgMember}
+			}
+			mockSvc.On("InviteMember", mock.Anything, int64(4), int64(10), "new@x.com", model.RoleOrgMember).
+				Return(inv, tc.err)
+
+			buf, _ := json.Marshal(httptransport.CreateInvitationRequest{Email: "new@x.com", Role: model.RoleOrgMember})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("userID", int64(10))
+			c.Set("orgID", int64(4))
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/orgs/4/invitations", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateInvitation(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RevokeInvitation(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("RevokeInvitation", mock.Anything, int64(4), int64(9)).Return(nil)
+	mockSvc.On("RevokeInvitation", mock.Anything, int64(4), int64(8)).Return(service.ErrInvitationNotFound)
+
+	for id, want := range map[string]int{"9": http.StatusNoContent, "8": http.StatusNotFound, "x": http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("orgID", int64(4))
+		c.Params = gin.Params{{Key: "id", Value: "4"}, {Key: "invitation_id", Value: id}}
+
+		handler.RevokeInvitation(c)
+
+		assert.Equal(t, want, c.Writer.Status(), id)
+	}
+}
+
+func TestHandler_AcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"expired", service.ErrInvalidInvitation, http.StatusBadRequest},
+		{"no password", service.ErrPasswordRequired, http.StatusBadRequest},
+		{"weak password", &service.PasswordPolicyError{Violations: []string{service.ViolationTooShort}}, http.StatusBadRequest},
+		{"existing account", service.ErrInvitationLogin, http.StatusUnauthorized},
+		{"feature off", service.ErrInvitationsDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var m *model.Membership
+			if tc.err == nil {
+				m = &model.Membership{OrgID: 4, UserID: 8, Email: "new@x.com", Role: model.RoleOrgMember}
+			}
+			mockSvc.On("AcceptInvitation", mock.Anything, int64(0), "tok", "pwd12345").Return(m, tc.err)
+
+			buf, _ := json.Marshal(httptransport.AcceptInvitationRequest{Token: "tok", Password: "pwd12345"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/invitations/accept", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.AcceptInvitation(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_AcceptInvitationAsUser(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	m := &model.Membership{OrgID: 4, UserID: 8, Email: "user@x.com", Role: model.RoleOrgMember}
+	mockSvc.On("AcceptInvitation", mock.Anything, int64(8), "tok", "").Return(m, nil)
+	mockSvc.On("AcceptInvitation", mock.Anything, int64(5), "tok", "").Return(nil, service.ErrInvitationForOther)
+
+	for user, want := range map[int64]int{8: http.StatusOK, 5: http.StatusForbidden} {
+		buf, _ := json.Marshal(httptransport.AcceptInvitationRequest{Token: "tok"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/profile/invitations/accept", bytes.NewBuffer(buf))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("userID", user)
+
+		handler.AcceptInvitationAsUser(c)
+
+		assert.Equal(t, want, w.Code, user)
+	}
+	mockSvc.AssertExpectations(t)
+}
@@ -0,0 +1,22 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public keys other services can use to verify tokens issued by this service
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  auth.JWKSet
+// @Router       /.well-known/jwks.json [get]
+func JWKS(keys *auth.Keyring) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKSet())
+	}
+}
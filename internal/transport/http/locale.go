@@ -0,0 +1,33 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/enson89/user-service-go/internal/notify"
+	"github.com/gin-gonic/gin"
+)
+
+// Locale stores the client's preferred language from Accept-Language in the
+// request context, so that notifications sent while handling the request
+// are rendered in it.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if locale := preferredLocale(c.GetHeader("Accept-Language")); locale != "" {
+			c.Request = c.Request.WithContext(notify.WithLocale(c.Request.Context(), locale))
+		}
+		c.Next()
+	}
+}
+
+// preferredLocale returns the first language of an Accept-Language header.
+// Clients list their preferred language first in practice, so q-values are
+// not weighed.
+func preferredLocale(header string) string {
+	for _, tag := range strings.Split(header, ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		if tag = strings.TrimSpace(tag); tag != "" && tag != "*" {
+			return tag
+		}
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/enson89/user-service-go/internal/notify"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+)
+
+func TestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(httptransport.Locale())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, notify.LocaleFrom(c.Request.Context()))
+	})
+
+	for header, want := range map[string]string{
+		"":                          "",
+		"de-AT":                     "de-AT",
+		"fr-CH, fr;q=0.9, en;q=0.8": "fr-CH",
+		"*":                         "",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, want, w.Body.String(), header)
+	}
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestMagicLink godoc
+// @Summary      Request a login link
+// @Description  Send a single-use login link to the given address. The response is the same whether or not an account exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.MagicLinkRequest  true  "Account email"
+// @Success      202      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /login/magic-link [post]
+func (h *Handler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.RequestMagicLink(getContext(c), req.Email); err != nil {
+		if errors.Is(err, service.ErrMagicLinkDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not send login link"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the account exists, a login link has been sent"})
+}
+
+// LoginMagicLink godoc
+// @Summary      Log in with a login link
+// @Description  Exchange the token of a login link for the same response as /login. Each link works once.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ConsumeMagicLinkRequest  true  "Link token"
+// @Success      200      {object}  model.TokenPair
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /login/magic-link/consume [post]
+func (h *Handler) LoginMagicLink(c *gin.Context) {
+	var req ConsumeMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.svc.LoginMagicLink(getContext(c), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidMagicLink):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrMagicLinkDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,61 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_RequestMagicLink(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("RequestMagicLink", mock.Anything, "user@x.com").Return(nil)
+
+	buf, _ := json.Marshal(map[string]string{"email": "user@x.com"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/login/magic-link", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_LoginMagicLink(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("LoginMagicLink", mock.Anything, "link123").
+		Return(&model.TokenPair{AccessToken: "token123", TokenType: "Bearer", ExpiresIn: 60}, nil)
+	mockSvc.On("LoginMagicLink", mock.Anything, "spent").Return(nil, service.ErrInvalidMagicLink)
+
+	buf, _ := json.Marshal(map[string]string{"token": "link123"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/login/magic-link/consume", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "token123", resp["token"])
+
+	buf, _ = json.Marshal(map[string]string{"token": "spent"})
+	req = httptest.NewRequest(http.MethodPost, "/v1/login/magic-link/consume", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockSvc.AssertExpectations(t)
+}
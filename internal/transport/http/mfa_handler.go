@@ -0,0 +1,155 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA godoc
+// @Summary      Complete an MFA login
+// @Description  Exchange the mfa_token returned by /login and an authenticator or recovery code for tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.LoginMFARequest  true  "MFA token and code"
+// @Success      200      {object}  model.TokenPair
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /login/mfa [post]
+func (h *Handler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.svc.LoginMFA(getContext(c), req.MFAToken, req.Code)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidMFAChallenge) || errors.Is(err, service.ErrInvalidMFACode) ||
+			errors.Is(err, service.ErrMFADisabled) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		if loginLocked(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// EnrollTOTP godoc
+// @Summary      Enroll an authenticator app
+// @Description  Generate a TOTP secret and its otpauth:// URI for a QR code, confirmed with the current password. It takes effect once confirmed with a code.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.MFAEnrollRequest  true  "Current password"
+// @Success      200      {object}  model.TOTPEnrollment
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /mfa/totp/enroll [post]
+// @Security     ApiKeyAuth
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	var req MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	enrollment, err := h.svc.EnrollTOTP(getContext(c), c.GetInt64("userID"), req.CurrentPassword)
+	if err != nil {
+		if loginLocked(c, err) {
+			return
+		}
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmTOTP godoc
+// @Summary      Confirm an authenticator app
+// @Description  Enable MFA with a first code from the enrolled authenticator. Returns one-time recovery codes, which are shown only once.
+// @Tags         mfa
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.MFACodeRequest  true  "Authenticator code"
+// @Success      200      {object}  map[string][]string
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /mfa/totp/confirm [post]
+// @Security     ApiKeyAuth
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	codes, err := h.svc.ConfirmTOTP(getContext(c), c.GetInt64("userID"), req.Code)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// DisableMFA godoc
+// @Summary      Disable MFA
+// @Description  Remove the authenticator and recovery codes, confirmed with the current password and an authenticator or recovery code
+// @Tags         mfa
+// @Accept       json
+// @Param        payload  body      http.MFADisableRequest  true  "Current password and authenticator or recovery code"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /mfa/totp/disable [post]
+// @Security     ApiKeyAuth
+func (h *Handler) DisableMFA(c *gin.Context) {
+	var req MFADisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.DisableMFA(getContext(c), c.GetInt64("userID"), req.CurrentPassword, req.Code); err != nil {
+		if loginLocked(c, err) {
+			return
+		}
+		mfaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// mfaError answers a failed MFA management request.
+func mfaError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrMFADisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMFAAlreadyEnabled), errors.Is(err, service.ErrMFANotEnrolled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCurrentPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
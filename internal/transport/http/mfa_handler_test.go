@@ -0,0 +1,163 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_LoginMFA(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("LoginMFA", mock.Anything, "mfa123", "123456").
+		Return(&model.TokenPair{AccessToken: "token123", TokenType: "Bearer", ExpiresIn: 60}, nil)
+	mockSvc.On("LoginMFA", mock.Anything, "mfa123", "000000").
+		Return(nil, service.ErrInvalidMFACode)
+	mockSvc.On("LoginMFA", mock.Anything, "mfa123", "111111").
+		Return(nil, service.ErrLoginLocked)
+
+	buf, _ := json.Marshal(map[string]string{"mfa_token": "mfa123", "code": "123456"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/login/mfa", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "token123", resp["token"])
+
+	buf, _ = json.Marshal(map[string]string{"mfa_token": "mfa123", "code": "000000"})
+	req = httptest.NewRequest(http.MethodPost, "/v1/login/mfa", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// too many wrong codes lock the account like wrong passwords
+	buf, _ = json.Marshal(map[string]string{"mfa_token": "mfa123", "code": "111111"})
+	req = httptest.NewRequest(http.MethodPost, "/v1/login/mfa", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_EnrollTOTP(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("EnrollTOTP", mock.Anything, int64(10), "secret").
+		Return(&model.TOTPEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/x"}, nil)
+
+	buf, _ := json.Marshal(map[string]string{"current_password": "secret"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/mfa/totp/enroll", bytes.NewBuffer(buf))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", int64(10))
+
+	handler.EnrollTOTP(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]string
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", resp["secret"])
+	assert.Equal(t, "otpauth://totp/x", resp["uri"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_EnrollTOTP_AlreadyEnabled(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("EnrollTOTP", mock.Anything, int64(10), "secret").Return(nil, service.ErrMFAAlreadyEnabled)
+	mockSvc.On("EnrollTOTP", mock.Anything, int64(10), "wrong").Return(nil, service.ErrInvalidCurrentPassword)
+
+	for password, want := range map[string]int{"secret": http.StatusConflict, "wrong": http.StatusForbidden} {
+		buf, _ := json.Marshal(map[string]string{"current_password": password})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/mfa/totp/enroll", bytes.NewBuffer(buf))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("userID", int64(10))
+
+		handler.EnrollTOTP(c)
+
+		assert.Equal(t, want, w.Code, password)
+	}
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_ConfirmTOTP(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("ConfirmTOTP", mock.Anything, int64(10), "123456").
+		Return([]string{"abcde-fghij", "klmno-pqrst"}, nil)
+
+	buf, _ := json.Marshal(map[string]string{"code": "123456"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/mfa/totp/confirm", bytes.NewBuffer(buf))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", int64(10))
+
+	handler.ConfirmTOTP(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string][]string
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, []string{"abcde-fghij", "klmno-pqrst"}, resp["recovery_codes"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_DisableMFA(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"wrong code", service.ErrInvalidMFACode, http.StatusBadRequest},
+		{"wrong password", service.ErrInvalidCurrentPassword, http.StatusForbidden},
+		{"not set up", service.ErrMFANotEnrolled, http.StatusConflict},
+		{"feature off", service.ErrMFADisabled, http.StatusNotFound},
+		{"locked out", service.ErrLoginLocked, http.StatusTooManyRequests},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("DisableMFA", mock.Anything, int64(10), "secret", "123456").Return(tc.err)
+
+			buf, _ := json.Marshal(map[string]string{"current_password": "secret", "code": "123456"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/mfa/totp/disable", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("userID", int64(10))
+
+			handler.DisableMFA(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
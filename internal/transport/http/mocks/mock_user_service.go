@@ -8,6 +8,8 @@ import (
 	"context"
 
 	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/ratelimit"
+	"github.com/go-webauthn/webauthn/protocol"
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -38,161 +40,2745 @@ func (_m *MockUserService) EXPECT() *MockUserService_Expecter {
 	return &MockUserService_Expecter{mock: &_m.Mock}
 }
 
+// AcceptInvitation provides a mock function for the type MockUserService
+func (_mock *MockUserService) AcceptInvitation(ctx context.Context, userID int64, token string, password string) (*model.Membership, error) {
+	ret := _mock.Called(ctx, userID, token, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptInvitation")
+	}
+
+	var r0 *model.Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) (*model.Membership, error)); ok {
+		return returnFunc(ctx, userID, token, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) *model.Membership); ok {
+		r0 = returnFunc(ctx, userID, token, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = returnFunc(ctx, userID, token, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_AcceptInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptInvitation'
+type MockUserService_AcceptInvitation_Call struct {
+	*mock.Call
+}
+
+// AcceptInvitation is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - token
+//   - password
+func (_e *MockUserService_Expecter) AcceptInvitation(ctx interface{}, userID interface{}, token interface{}, password interface{}) *MockUserService_AcceptInvitation_Call {
+	return &MockUserService_AcceptInvitation_Call{Call: _e.mock.On("AcceptInvitation", ctx, userID, token, password)}
+}
+
+func (_c *MockUserService_AcceptInvitation_Call) Run(run func(ctx context.Context, userID int64, token string, password string)) *MockUserService_AcceptInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_AcceptInvitation_Call) Return(membership *model.Membership, err error) *MockUserService_AcceptInvitation_Call {
+	_c.Call.Return(membership, err)
+	return _c
+}
+
+func (_c *MockUserService_AcceptInvitation_Call) RunAndReturn(run func(ctx context.Context, userID int64, token string, password string) (*model.Membership, error)) *MockUserService_AcceptInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddGroupMember provides a mock function for the type MockUserService
+func (_mock *MockUserService) AddGroupMember(ctx context.Context, groupID int64, userID int64) error {
+	ret := _mock.Called(ctx, groupID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddGroupMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, groupID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_AddGroupMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddGroupMember'
+type MockUserService_AddGroupMember_Call struct {
+	*mock.Call
+}
+
+// AddGroupMember is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - userID
+func (_e *MockUserService_Expecter) AddGroupMember(ctx interface{}, groupID interface{}, userID interface{}) *MockUserService_AddGroupMember_Call {
+	return &MockUserService_AddGroupMember_Call{Call: _e.mock.On("AddGroupMember", ctx, groupID, userID)}
+}
+
+func (_c *MockUserService_AddGroupMember_Call) Run(run func(ctx context.Context, groupID int64, userID int64)) *MockUserService_AddGroupMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_AddGroupMember_Call) Return(err error) *MockUserService_AddGroupMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_AddGroupMember_Call) RunAndReturn(run func(ctx context.Context, groupID int64, userID int64) error) *MockUserService_AddGroupMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddSubgroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) AddSubgroup(ctx context.Context, groupID int64, subgroupID int64) error {
+	ret := _mock.Called(ctx, groupID, subgroupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddSubgroup")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_AddSubgroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddSubgroup'
+type MockUserService_AddSubgroup_Call struct {
+	*mock.Call
+}
+
+// AddSubgroup is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - subgroupID
+func (_e *MockUserService_Expecter) AddSubgroup(ctx interface{}, groupID interface{}, subgroupID interface{}) *MockUserService_AddSubgroup_Call {
+	return &MockUserService_AddSubgroup_Call{Call: _e.mock.On("AddSubgroup", ctx, groupID, subgroupID)}
+}
+
+func (_c *MockUserService_AddSubgroup_Call) Run(run func(ctx context.Context, groupID int64, subgroupID int64)) *MockUserService_AddSubgroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_AddSubgroup_Call) Return(err error) *MockUserService_AddSubgroup_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_AddSubgroup_Call) RunAndReturn(run func(ctx context.Context, groupID int64, subgroupID int64) error) *MockUserService_AddSubgroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginWebAuthnLogin provides a mock function for the type MockUserService
+func (_mock *MockUserService) BeginWebAuthnLogin(ctx context.Context, email string) (string, *protocol.CredentialAssertion, error) {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BeginWebAuthnLogin")
+	}
+
+	var r0 string
+	var r1 *protocol.CredentialAssertion
+	var r2 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (string, *protocol.CredentialAssertion, error)); ok {
+		return returnFunc(ctx, email)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) *protocol.CredentialAssertion); ok {
+		r1 = returnFunc(ctx, email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*protocol.CredentialAssertion)
+		}
+	}
+	if returnFunc, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = returnFunc(ctx, email)
+	} else {
+		r2 = ret.Error(2)
+	}
+	return r0, r1, r2
+}
+
+// MockUserService_BeginWebAuthnLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginWebAuthnLogin'
+type MockUserService_BeginWebAuthnLogin_Call struct {
+	*mock.Call
+}
+
+// BeginWebAuthnLogin is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) BeginWebAuthnLogin(ctx interface{}, email interface{}) *MockUserService_BeginWebAuthnLogin_Call {
+	return &MockUserService_BeginWebAuthnLogin_Call{Call: _e.mock.On("BeginWebAuthnLogin", ctx, email)}
+}
+
+func (_c *MockUserService_BeginWebAuthnLogin_Call) Run(run func(ctx context.Context, email string)) *MockUserService_BeginWebAuthnLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_BeginWebAuthnLogin_Call) Return(s string, credentialAssertion *protocol.CredentialAssertion, err error) *MockUserService_BeginWebAuthnLogin_Call {
+	_c.Call.Return(s, credentialAssertion, err)
+	return _c
+}
+
+func (_c *MockUserService_BeginWebAuthnLogin_Call) RunAndReturn(run func(ctx context.Context, email string) (string, *protocol.CredentialAssertion, error)) *MockUserService_BeginWebAuthnLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BeginWebAuthnRegistration provides a mock function for the type MockUserService
+func (_mock *MockUserService) BeginWebAuthnRegistration(ctx context.Context, userID int64, password string, code string) (*protocol.CredentialCreation, error) {
+	ret := _mock.Called(ctx, userID, password, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BeginWebAuthnRegistration")
+	}
+
+	var r0 *protocol.CredentialCreation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) (*protocol.CredentialCreation, error)); ok {
+		return returnFunc(ctx, userID, password, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) *protocol.CredentialCreation); ok {
+		r0 = returnFunc(ctx, userID, password, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*protocol.CredentialCreation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = returnFunc(ctx, userID, password, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_BeginWebAuthnRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BeginWebAuthnRegistration'
+type MockUserService_BeginWebAuthnRegistration_Call struct {
+	*mock.Call
+}
+
+// BeginWebAuthnRegistration is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - password
+//   - code
+func (_e *MockUserService_Expecter) BeginWebAuthnRegistration(ctx interface{}, userID interface{}, password interface{}, code interface{}) *MockUserService_BeginWebAuthnRegistration_Call {
+	return &MockUserService_BeginWebAuthnRegistration_Call{Call: _e.mock.On("BeginWebAuthnRegistration", ctx, userID, password, code)}
+}
+
+func (_c *MockUserService_BeginWebAuthnRegistration_Call) Run(run func(ctx context.Context, userID int64, password string, code string)) *MockUserService_BeginWebAuthnRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_BeginWebAuthnRegistration_Call) Return(credentialCreation *protocol.CredentialCreation, err error) *MockUserService_BeginWebAuthnRegistration_Call {
+	_c.Call.Return(credentialCreation, err)
+	return _c
+}
+
+func (_c *MockUserService_BeginWebAuthnRegistration_Call) RunAndReturn(run func(ctx context.Context, userID int64, password string, code string) (*protocol.CredentialCreation, error)) *MockUserService_BeginWebAuthnRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangePassword provides a mock function for the type MockUserService
+func (_mock *MockUserService) ChangePassword(ctx context.Context, userID int64, current string, newPassword string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, userID, current, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, userID, current, newPassword)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, userID, current, newPassword)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = returnFunc(ctx, userID, current, newPassword)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ChangePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangePassword'
+type MockUserService_ChangePassword_Call struct {
+	*mock.Call
+}
+
+// ChangePassword is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - current
+//   - newPassword
+func (_e *MockUserService_Expecter) ChangePassword(ctx interface{}, userID interface{}, current interface{}, newPassword interface{}) *MockUserService_ChangePassword_Call {
+	return &MockUserService_ChangePassword_Call{Call: _e.mock.On("ChangePassword", ctx, userID, current, newPassword)}
+}
+
+func (_c *MockUserService_ChangePassword_Call) Run(run func(ctx context.Context, userID int64, current string, newPassword string)) *MockUserService_ChangePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ChangePassword_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_ChangePassword_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_ChangePassword_Call) RunAndReturn(run func(ctx context.Context, userID int64, current string, newPassword string) (*model.TokenPair, error)) *MockUserService_ChangePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmEmailChange provides a mock function for the type MockUserService
+func (_mock *MockUserService) ConfirmEmailChange(ctx context.Context, token string) error {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmEmailChange")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ConfirmEmailChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmEmailChange'
+type MockUserService_ConfirmEmailChange_Call struct {
+	*mock.Call
+}
+
+// ConfirmEmailChange is a helper method to define mock.On call
+//   - ctx
+//   - token
+func (_e *MockUserService_Expecter) ConfirmEmailChange(ctx interface{}, token interface{}) *MockUserService_ConfirmEmailChange_Call {
+	return &MockUserService_ConfirmEmailChange_Call{Call: _e.mock.On("ConfirmEmailChange", ctx, token)}
+}
+
+func (_c *MockUserService_ConfirmEmailChange_Call) Run(run func(ctx context.Context, token string)) *MockUserService_ConfirmEmailChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ConfirmEmailChange_Call) Return(err error) *MockUserService_ConfirmEmailChange_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ConfirmEmailChange_Call) RunAndReturn(run func(ctx context.Context, token string) error) *MockUserService_ConfirmEmailChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ConfirmTOTP provides a mock function for the type MockUserService
+func (_mock *MockUserService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	ret := _mock.Called(ctx, userID, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmTOTP")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) ([]string, error)); ok {
+		return returnFunc(ctx, userID, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) []string); ok {
+		r0 = returnFunc(ctx, userID, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, userID, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ConfirmTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConfirmTOTP'
+type MockUserService_ConfirmTOTP_Call struct {
+	*mock.Call
+}
+
+// ConfirmTOTP is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - code
+func (_e *MockUserService_Expecter) ConfirmTOTP(ctx interface{}, userID interface{}, code interface{}) *MockUserService_ConfirmTOTP_Call {
+	return &MockUserService_ConfirmTOTP_Call{Call: _e.mock.On("ConfirmTOTP", ctx, userID, code)}
+}
+
+func (_c *MockUserService_ConfirmTOTP_Call) Run(run func(ctx context.Context, userID int64, code string)) *MockUserService_ConfirmTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ConfirmTOTP_Call) Return(ss []string, err error) *MockUserService_ConfirmTOTP_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockUserService_ConfirmTOTP_Call) RunAndReturn(run func(ctx context.Context, userID int64, code string) ([]string, error)) *MockUserService_ConfirmTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateGroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) CreateGroup(ctx context.Context, name string, description string) (*model.Group, error) {
+	ret := _mock.Called(ctx, name, description)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateGroup")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*model.Group, error)); ok {
+		return returnFunc(ctx, name, description)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *model.Group); ok {
+		r0 = returnFunc(ctx, name, description)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, name, description)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_CreateGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateGroup'
+type MockUserService_CreateGroup_Call struct {
+	*mock.Call
+}
+
+// CreateGroup is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - description
+func (_e *MockUserService_Expecter) CreateGroup(ctx interface{}, name interface{}, description interface{}) *MockUserService_CreateGroup_Call {
+	return &MockUserService_CreateGroup_Call{Call: _e.mock.On("CreateGroup", ctx, name, description)}
+}
+
+func (_c *MockUserService_CreateGroup_Call) Run(run func(ctx context.Context, name string, description string)) *MockUserService_CreateGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_CreateGroup_Call) Return(group *model.Group, err error) *MockUserService_CreateGroup_Call {
+	_c.Call.Return(group, err)
+	return _c
+}
+
+func (_c *MockUserService_CreateGroup_Call) RunAndReturn(run func(ctx context.Context, name string, description string) (*model.Group, error)) *MockUserService_CreateGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateOrganization provides a mock function for the type MockUserService
+func (_mock *MockUserService) CreateOrganization(ctx context.Context, userID int64, slug string, name string) (*model.Organization, error) {
+	ret := _mock.Called(ctx, userID, slug, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateOrganization")
+	}
+
+	var r0 *model.Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) (*model.Organization, error)); ok {
+		return returnFunc(ctx, userID, slug, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) *model.Organization); ok {
+		r0 = returnFunc(ctx, userID, slug, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = returnFunc(ctx, userID, slug, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_CreateOrganization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateOrganization'
+type MockUserService_CreateOrganization_Call struct {
+	*mock.Call
+}
+
+// CreateOrganization is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - slug
+//   - name
+func (_e *MockUserService_Expecter) CreateOrganization(ctx interface{}, userID interface{}, slug interface{}, name interface{}) *MockUserService_CreateOrganization_Call {
+	return &MockUserService_CreateOrganization_Call{Call: _e.mock.On("CreateOrganization", ctx, userID, slug, name)}
+}
+
+func (_c *MockUserService_CreateOrganization_Call) Run(run func(ctx context.Context, userID int64, slug string, name string)) *MockUserService_CreateOrganization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_CreateOrganization_Call) Return(organization *model.Organization, err error) *MockUserService_CreateOrganization_Call {
+	_c.Call.Return(organization, err)
+	return _c
+}
+
+func (_c *MockUserService_CreateOrganization_Call) RunAndReturn(run func(ctx context.Context, userID int64, slug string, name string) (*model.Organization, error)) *MockUserService_CreateOrganization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) CreateRole(ctx context.Context, name string, description string, parent string, permissions []string) (*model.Role, error) {
+	ret := _mock.Called(ctx, name, description, parent, permissions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRole")
+	}
+
+	var r0 *model.Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, []string) (*model.Role, error)); ok {
+		return returnFunc(ctx, name, description, parent, permissions)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string, string, []string) *model.Role); ok {
+		r0 = returnFunc(ctx, name, description, parent, permissions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string, string, []string) error); ok {
+		r1 = returnFunc(ctx, name, description, parent, permissions)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_CreateRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRole'
+type MockUserService_CreateRole_Call struct {
+	*mock.Call
+}
+
+// CreateRole is a helper method to define mock.On call
+//   - ctx
+//   - name
+//   - description
+//   - parent
+//   - permissions
+func (_e *MockUserService_Expecter) CreateRole(ctx interface{}, name interface{}, description interface{}, parent interface{}, permissions interface{}) *MockUserService_CreateRole_Call {
+	return &MockUserService_CreateRole_Call{Call: _e.mock.On("CreateRole", ctx, name, description, parent, permissions)}
+}
+
+func (_c *MockUserService_CreateRole_Call) Run(run func(ctx context.Context, name string, description string, parent string, permissions []string)) *MockUserService_CreateRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_CreateRole_Call) Return(role *model.Role, err error) *MockUserService_CreateRole_Call {
+	_c.Call.Return(role, err)
+	return _c
+}
+
+func (_c *MockUserService_CreateRole_Call) RunAndReturn(run func(ctx context.Context, name string, description string, parent string, permissions []string) (*model.Role, error)) *MockUserService_CreateRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteGroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) DeleteGroup(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteGroup")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_DeleteGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteGroup'
+type MockUserService_DeleteGroup_Call struct {
+	*mock.Call
+}
+
+// DeleteGroup is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) DeleteGroup(ctx interface{}, id interface{}) *MockUserService_DeleteGroup_Call {
+	return &MockUserService_DeleteGroup_Call{Call: _e.mock.On("DeleteGroup", ctx, id)}
+}
+
+func (_c *MockUserService_DeleteGroup_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_DeleteGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_DeleteGroup_Call) Return(err error) *MockUserService_DeleteGroup_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_DeleteGroup_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserService_DeleteGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeleteUser provides a mock function for the type MockUserService
 func (_mock *MockUserService) DeleteUser(ctx context.Context, id int64) error {
 	ret := _mock.Called(ctx, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for DeleteUser")
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type MockUserService_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) DeleteUser(ctx interface{}, id interface{}) *MockUserService_DeleteUser_Call {
+	return &MockUserService_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id)}
+}
+
+func (_c *MockUserService_DeleteUser_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_DeleteUser_Call) Return(err error) *MockUserService_DeleteUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserService_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteWebAuthnCredential provides a mock function for the type MockUserService
+func (_mock *MockUserService) DeleteWebAuthnCredential(ctx context.Context, userID int64, id int64) error {
+	ret := _mock.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteWebAuthnCredential")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_DeleteWebAuthnCredential_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteWebAuthnCredential'
+type MockUserService_DeleteWebAuthnCredential_Call struct {
+	*mock.Call
+}
+
+// DeleteWebAuthnCredential is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - id
+func (_e *MockUserService_Expecter) DeleteWebAuthnCredential(ctx interface{}, userID interface{}, id interface{}) *MockUserService_DeleteWebAuthnCredential_Call {
+	return &MockUserService_DeleteWebAuthnCredential_Call{Call: _e.mock.On("DeleteWebAuthnCredential", ctx, userID, id)}
+}
+
+func (_c *MockUserService_DeleteWebAuthnCredential_Call) Run(run func(ctx context.Context, userID int64, id int64)) *MockUserService_DeleteWebAuthnCredential_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_DeleteWebAuthnCredential_Call) Return(err error) *MockUserService_DeleteWebAuthnCredential_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_DeleteWebAuthnCredential_Call) RunAndReturn(run func(ctx context.Context, userID int64, id int64) error) *MockUserService_DeleteWebAuthnCredential_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DisableMFA provides a mock function for the type MockUserService
+func (_mock *MockUserService) DisableMFA(ctx context.Context, userID int64, password string, code string) error {
+	ret := _mock.Called(ctx, userID, password, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DisableMFA")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) error); ok {
+		r0 = returnFunc(ctx, userID, password, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_DisableMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DisableMFA'
+type MockUserService_DisableMFA_Call struct {
+	*mock.Call
+}
+
+// DisableMFA is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - password
+//   - code
+func (_e *MockUserService_Expecter) DisableMFA(ctx interface{}, userID interface{}, password interface{}, code interface{}) *MockUserService_DisableMFA_Call {
+	return &MockUserService_DisableMFA_Call{Call: _e.mock.On("DisableMFA", ctx, userID, password, code)}
+}
+
+func (_c *MockUserService_DisableMFA_Call) Run(run func(ctx context.Context, userID int64, password string, code string)) *MockUserService_DisableMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_DisableMFA_Call) Return(err error) *MockUserService_DisableMFA_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_DisableMFA_Call) RunAndReturn(run func(ctx context.Context, userID int64, password string, code string) error) *MockUserService_DisableMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// EnrollTOTP provides a mock function for the type MockUserService
+func (_mock *MockUserService) EnrollTOTP(ctx context.Context, userID int64, password string) (*model.TOTPEnrollment, error) {
+	ret := _mock.Called(ctx, userID, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnrollTOTP")
+	}
+
+	var r0 *model.TOTPEnrollment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (*model.TOTPEnrollment, error)); ok {
+		return returnFunc(ctx, userID, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) *model.TOTPEnrollment); ok {
+		r0 = returnFunc(ctx, userID, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TOTPEnrollment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, userID, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_EnrollTOTP_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EnrollTOTP'
+type MockUserService_EnrollTOTP_Call struct {
+	*mock.Call
+}
+
+// EnrollTOTP is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - password
+func (_e *MockUserService_Expecter) EnrollTOTP(ctx interface{}, userID interface{}, password interface{}) *MockUserService_EnrollTOTP_Call {
+	return &MockUserService_EnrollTOTP_Call{Call: _e.mock.On("EnrollTOTP", ctx, userID, password)}
+}
+
+func (_c *MockUserService_EnrollTOTP_Call) Run(run func(ctx context.Context, userID int64, password string)) *MockUserService_EnrollTOTP_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_EnrollTOTP_Call) Return(tOTPEnrollment *model.TOTPEnrollment, err error) *MockUserService_EnrollTOTP_Call {
+	_c.Call.Return(tOTPEnrollment, err)
+	return _c
+}
+
+func (_c *MockUserService_EnrollTOTP_Call) RunAndReturn(run func(ctx context.Context, userID int64, password string) (*model.TOTPEnrollment, error)) *MockUserService_EnrollTOTP_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishWebAuthnLogin provides a mock function for the type MockUserService
+func (_mock *MockUserService) FinishWebAuthnLogin(ctx context.Context, sessionID string, response []byte) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, sessionID, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishWebAuthnLogin")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []byte) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, sessionID, response)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, []byte) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, sessionID, response)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, []byte) error); ok {
+		r1 = returnFunc(ctx, sessionID, response)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_FinishWebAuthnLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishWebAuthnLogin'
+type MockUserService_FinishWebAuthnLogin_Call struct {
+	*mock.Call
+}
+
+// FinishWebAuthnLogin is a helper method to define mock.On call
+//   - ctx
+//   - sessionID
+//   - response
+func (_e *MockUserService_Expecter) FinishWebAuthnLogin(ctx interface{}, sessionID interface{}, response interface{}) *MockUserService_FinishWebAuthnLogin_Call {
+	return &MockUserService_FinishWebAuthnLogin_Call{Call: _e.mock.On("FinishWebAuthnLogin", ctx, sessionID, response)}
+}
+
+func (_c *MockUserService_FinishWebAuthnLogin_Call) Run(run func(ctx context.Context, sessionID string, response []byte)) *MockUserService_FinishWebAuthnLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockUserService_FinishWebAuthnLogin_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_FinishWebAuthnLogin_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_FinishWebAuthnLogin_Call) RunAndReturn(run func(ctx context.Context, sessionID string, response []byte) (*model.TokenPair, error)) *MockUserService_FinishWebAuthnLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FinishWebAuthnRegistration provides a mock function for the type MockUserService
+func (_mock *MockUserService) FinishWebAuthnRegistration(ctx context.Context, userID int64, response []byte) (*model.WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, userID, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinishWebAuthnRegistration")
+	}
+
+	var r0 *model.WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, []byte) (*model.WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, userID, response)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, []byte) *model.WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, userID, response)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, []byte) error); ok {
+		r1 = returnFunc(ctx, userID, response)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_FinishWebAuthnRegistration_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinishWebAuthnRegistration'
+type MockUserService_FinishWebAuthnRegistration_Call struct {
+	*mock.Call
+}
+
+// FinishWebAuthnRegistration is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - response
+func (_e *MockUserService_Expecter) FinishWebAuthnRegistration(ctx interface{}, userID interface{}, response interface{}) *MockUserService_FinishWebAuthnRegistration_Call {
+	return &MockUserService_FinishWebAuthnRegistration_Call{Call: _e.mock.On("FinishWebAuthnRegistration", ctx, userID, response)}
+}
+
+func (_c *MockUserService_FinishWebAuthnRegistration_Call) Run(run func(ctx context.Context, userID int64, response []byte)) *MockUserService_FinishWebAuthnRegistration_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *MockUserService_FinishWebAuthnRegistration_Call) Return(webAuthnCredential *model.WebAuthnCredential, err error) *MockUserService_FinishWebAuthnRegistration_Call {
+	_c.Call.Return(webAuthnCredential, err)
+	return _c
+}
+
+func (_c *MockUserService_FinishWebAuthnRegistration_Call) RunAndReturn(run func(ctx context.Context, userID int64, response []byte) (*model.WebAuthnCredential, error)) *MockUserService_FinishWebAuthnRegistration_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForgotPassword provides a mock function for the type MockUserService
+func (_mock *MockUserService) ForgotPassword(ctx context.Context, email string) error {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ForgotPassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ForgotPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForgotPassword'
+type MockUserService_ForgotPassword_Call struct {
+	*mock.Call
+}
+
+// ForgotPassword is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) ForgotPassword(ctx interface{}, email interface{}) *MockUserService_ForgotPassword_Call {
+	return &MockUserService_ForgotPassword_Call{Call: _e.mock.On("ForgotPassword", ctx, email)}
+}
+
+func (_c *MockUserService_ForgotPassword_Call) Run(run func(ctx context.Context, email string)) *MockUserService_ForgotPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ForgotPassword_Call) Return(err error) *MockUserService_ForgotPassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ForgotPassword_Call) RunAndReturn(run func(ctx context.Context, email string) error) *MockUserService_ForgotPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetGroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) GetGroup(ctx context.Context, id int64) (*model.Group, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetGroup")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.Group, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.Group); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_GetGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetGroup'
+type MockUserService_GetGroup_Call struct {
+	*mock.Call
+}
+
+// GetGroup is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) GetGroup(ctx interface{}, id interface{}) *MockUserService_GetGroup_Call {
+	return &MockUserService_GetGroup_Call{Call: _e.mock.On("GetGroup", ctx, id)}
+}
+
+func (_c *MockUserService_GetGroup_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_GetGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GetGroup_Call) Return(group *model.Group, err error) *MockUserService_GetGroup_Call {
+	_c.Call.Return(group, err)
+	return _c
+}
+
+func (_c *MockUserService_GetGroup_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.Group, error)) *MockUserService_GetGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProfile provides a mock function for the type MockUserService
+func (_mock *MockUserService) GetProfile(ctx context.Context, id int64) (*model.User, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProfile")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.User, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.User); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_GetProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProfile'
+type MockUserService_GetProfile_Call struct {
+	*mock.Call
+}
+
+// GetProfile is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) GetProfile(ctx interface{}, id interface{}) *MockUserService_GetProfile_Call {
+	return &MockUserService_GetProfile_Call{Call: _e.mock.On("GetProfile", ctx, id)}
+}
+
+func (_c *MockUserService_GetProfile_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_GetProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GetProfile_Call) Return(user *model.User, err error) *MockUserService_GetProfile_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUserService_GetProfile_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.User, error)) *MockUserService_GetProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUser provides a mock function for the type MockUserService
+func (_mock *MockUserService) GetUser(ctx context.Context, id int64) (*model.User, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUser")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.User, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.User); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_GetUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUser'
+type MockUserService_GetUser_Call struct {
+	*mock.Call
+}
+
+// GetUser is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) GetUser(ctx interface{}, id interface{}) *MockUserService_GetUser_Call {
+	return &MockUserService_GetUser_Call{Call: _e.mock.On("GetUser", ctx, id)}
+}
+
+func (_c *MockUserService_GetUser_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_GetUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GetUser_Call) Return(user *model.User, err error) *MockUserService_GetUser_Call {
+	_c.Call.Return(user, err)
+	return _c
+}
+
+func (_c *MockUserService_GetUser_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.User, error)) *MockUserService_GetUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GrantGroupRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) GrantGroupRole(ctx context.Context, groupID int64, role string) error {
+	ret := _mock.Called(ctx, groupID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantGroupRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, groupID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_GrantGroupRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GrantGroupRole'
+type MockUserService_GrantGroupRole_Call struct {
+	*mock.Call
+}
+
+// GrantGroupRole is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - role
+func (_e *MockUserService_Expecter) GrantGroupRole(ctx interface{}, groupID interface{}, role interface{}) *MockUserService_GrantGroupRole_Call {
+	return &MockUserService_GrantGroupRole_Call{Call: _e.mock.On("GrantGroupRole", ctx, groupID, role)}
+}
+
+func (_c *MockUserService_GrantGroupRole_Call) Run(run func(ctx context.Context, groupID int64, role string)) *MockUserService_GrantGroupRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GrantGroupRole_Call) Return(err error) *MockUserService_GrantGroupRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_GrantGroupRole_Call) RunAndReturn(run func(ctx context.Context, groupID int64, role string) error) *MockUserService_GrantGroupRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GrantRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) GrantRole(ctx context.Context, userID int64, role string) error {
+	ret := _mock.Called(ctx, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_GrantRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GrantRole'
+type MockUserService_GrantRole_Call struct {
+	*mock.Call
+}
+
+// GrantRole is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - role
+func (_e *MockUserService_Expecter) GrantRole(ctx interface{}, userID interface{}, role interface{}) *MockUserService_GrantRole_Call {
+	return &MockUserService_GrantRole_Call{Call: _e.mock.On("GrantRole", ctx, userID, role)}
+}
+
+func (_c *MockUserService_GrantRole_Call) Run(run func(ctx context.Context, userID int64, role string)) *MockUserService_GrantRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_GrantRole_Call) Return(err error) *MockUserService_GrantRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_GrantRole_Call) RunAndReturn(run func(ctx context.Context, userID int64, role string) error) *MockUserService_GrantRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InviteMember provides a mock function for the type MockUserService
+func (_mock *MockUserService) InviteMember(ctx context.Context, orgID int64, inviterID int64, email string, role string) (*model.Invitation, error) {
+	ret := _mock.Called(ctx, orgID, inviterID, email, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InviteMember")
+	}
+
+	var r0 *model.Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) (*model.Invitation, error)); ok {
+		return returnFunc(ctx, orgID, inviterID, email, role)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string, string) *model.Invitation); ok {
+		r0 = returnFunc(ctx, orgID, inviterID, email, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64, string, string) error); ok {
+		r1 = returnFunc(ctx, orgID, inviterID, email, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_InviteMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InviteMember'
+type MockUserService_InviteMember_Call struct {
+	*mock.Call
+}
+
+// InviteMember is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - inviterID
+//   - email
+//   - role
+func (_e *MockUserService_Expecter) InviteMember(ctx interface{}, orgID interface{}, inviterID interface{}, email interface{}, role interface{}) *MockUserService_InviteMember_Call {
+	return &MockUserService_InviteMember_Call{Call: _e.mock.On("InviteMember", ctx, orgID, inviterID, email, role)}
+}
+
+func (_c *MockUserService_InviteMember_Call) Run(run func(ctx context.Context, orgID int64, inviterID int64, email string, role string)) *MockUserService_InviteMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(string), args[4].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_InviteMember_Call) Return(invitation *model.Invitation, err error) *MockUserService_InviteMember_Call {
+	_c.Call.Return(invitation, err)
+	return _c
+}
+
+func (_c *MockUserService_InviteMember_Call) RunAndReturn(run func(ctx context.Context, orgID int64, inviterID int64, email string, role string) (*model.Invitation, error)) *MockUserService_InviteMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListGroups provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListGroups(ctx context.Context) ([]model.Group, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListGroups")
+	}
+
+	var r0 []model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Group, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Group); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListGroups_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListGroups'
+type MockUserService_ListGroups_Call struct {
+	*mock.Call
+}
+
+// ListGroups is a helper method to define mock.On call
+//   - ctx
+func (_e *MockUserService_Expecter) ListGroups(ctx interface{}) *MockUserService_ListGroups_Call {
+	return &MockUserService_ListGroups_Call{Call: _e.mock.On("ListGroups", ctx)}
+}
+
+func (_c *MockUserService_ListGroups_Call) Run(run func(ctx context.Context)) *MockUserService_ListGroups_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListGroups_Call) Return(groups []model.Group, err error) *MockUserService_ListGroups_Call {
+	_c.Call.Return(groups, err)
+	return _c
+}
+
+func (_c *MockUserService_ListGroups_Call) RunAndReturn(run func(ctx context.Context) ([]model.Group, error)) *MockUserService_ListGroups_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListInvitations provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListInvitations(ctx context.Context, orgID int64) ([]model.Invitation, error) {
+	ret := _mock.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInvitations")
+	}
+
+	var r0 []model.Invitation
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Invitation, error)); ok {
+		return returnFunc(ctx, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Invitation); ok {
+		r0 = returnFunc(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Invitation)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListInvitations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInvitations'
+type MockUserService_ListInvitations_Call struct {
+	*mock.Call
+}
+
+// ListInvitations is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+func (_e *MockUserService_Expecter) ListInvitations(ctx interface{}, orgID interface{}) *MockUserService_ListInvitations_Call {
+	return &MockUserService_ListInvitations_Call{Call: _e.mock.On("ListInvitations", ctx, orgID)}
+}
+
+func (_c *MockUserService_ListInvitations_Call) Run(run func(ctx context.Context, orgID int64)) *MockUserService_ListInvitations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListInvitations_Call) Return(invitations []model.Invitation, err error) *MockUserService_ListInvitations_Call {
+	_c.Call.Return(invitations, err)
+	return _c
+}
+
+func (_c *MockUserService_ListInvitations_Call) RunAndReturn(run func(ctx context.Context, orgID int64) ([]model.Invitation, error)) *MockUserService_ListInvitations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListMembers provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error) {
+	ret := _mock.Called(ctx, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListMembers")
+	}
+
+	var r0 []model.Membership
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Membership, error)); ok {
+		return returnFunc(ctx, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Membership); ok {
+		r0 = returnFunc(ctx, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Membership)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListMembers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListMembers'
+type MockUserService_ListMembers_Call struct {
+	*mock.Call
+}
+
+// ListMembers is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+func (_e *MockUserService_Expecter) ListMembers(ctx interface{}, orgID interface{}) *MockUserService_ListMembers_Call {
+	return &MockUserService_ListMembers_Call{Call: _e.mock.On("ListMembers", ctx, orgID)}
+}
+
+func (_c *MockUserService_ListMembers_Call) Run(run func(ctx context.Context, orgID int64)) *MockUserService_ListMembers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListMembers_Call) Return(memberships []model.Membership, err error) *MockUserService_ListMembers_Call {
+	_c.Call.Return(memberships, err)
+	return _c
+}
+
+func (_c *MockUserService_ListMembers_Call) RunAndReturn(run func(ctx context.Context, orgID int64) ([]model.Membership, error)) *MockUserService_ListMembers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListOrganizations provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListOrganizations(ctx context.Context, userID int64) ([]model.Organization, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrganizations")
+	}
+
+	var r0 []model.Organization
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Organization, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Organization); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Organization)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListOrganizations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListOrganizations'
+type MockUserService_ListOrganizations_Call struct {
+	*mock.Call
+}
+
+// ListOrganizations is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockUserService_Expecter) ListOrganizations(ctx interface{}, userID interface{}) *MockUserService_ListOrganizations_Call {
+	return &MockUserService_ListOrganizations_Call{Call: _e.mock.On("ListOrganizations", ctx, userID)}
+}
+
+func (_c *MockUserService_ListOrganizations_Call) Run(run func(ctx context.Context, userID int64)) *MockUserService_ListOrganizations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListOrganizations_Call) Return(organizations []model.Organization, err error) *MockUserService_ListOrganizations_Call {
+	_c.Call.Return(organizations, err)
+	return _c
+}
+
+func (_c *MockUserService_ListOrganizations_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.Organization, error)) *MockUserService_ListOrganizations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListRoles provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListRoles(ctx context.Context) ([]model.Role, error) {
+	ret := _mock.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListRoles")
+	}
+
+	var r0 []model.Role
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]model.Role, error)); ok {
+		return returnFunc(ctx)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []model.Role); ok {
+		r0 = returnFunc(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Role)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListRoles'
+type MockUserService_ListRoles_Call struct {
+	*mock.Call
+}
+
+// ListRoles is a helper method to define mock.On call
+//   - ctx
+func (_e *MockUserService_Expecter) ListRoles(ctx interface{}) *MockUserService_ListRoles_Call {
+	return &MockUserService_ListRoles_Call{Call: _e.mock.On("ListRoles", ctx)}
+}
+
+func (_c *MockUserService_ListRoles_Call) Run(run func(ctx context.Context)) *MockUserService_ListRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListRoles_Call) Return(roles []model.Role, err error) *MockUserService_ListRoles_Call {
+	_c.Call.Return(roles, err)
+	return _c
+}
+
+func (_c *MockUserService_ListRoles_Call) RunAndReturn(run func(ctx context.Context) ([]model.Role, error)) *MockUserService_ListRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListSessions provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListSessions(ctx context.Context, userID int64) ([]model.Session, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListSessions")
+	}
+
+	var r0 []model.Session
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.Session, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.Session); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Session)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListSessions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListSessions'
+type MockUserService_ListSessions_Call struct {
+	*mock.Call
+}
+
+// ListSessions is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockUserService_Expecter) ListSessions(ctx interface{}, userID interface{}) *MockUserService_ListSessions_Call {
+	return &MockUserService_ListSessions_Call{Call: _e.mock.On("ListSessions", ctx, userID)}
+}
+
+func (_c *MockUserService_ListSessions_Call) Run(run func(ctx context.Context, userID int64)) *MockUserService_ListSessions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListSessions_Call) Return(sessions []model.Session, err error) *MockUserService_ListSessions_Call {
+	_c.Call.Return(sessions, err)
+	return _c
+}
+
+func (_c *MockUserService_ListSessions_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.Session, error)) *MockUserService_ListSessions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListWebAuthnCredentials provides a mock function for the type MockUserService
+func (_mock *MockUserService) ListWebAuthnCredentials(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListWebAuthnCredentials")
+	}
+
+	var r0 []model.WebAuthnCredential
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]model.WebAuthnCredential, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []model.WebAuthnCredential); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.WebAuthnCredential)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_ListWebAuthnCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListWebAuthnCredentials'
+type MockUserService_ListWebAuthnCredentials_Call struct {
+	*mock.Call
+}
+
+// ListWebAuthnCredentials is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockUserService_Expecter) ListWebAuthnCredentials(ctx interface{}, userID interface{}) *MockUserService_ListWebAuthnCredentials_Call {
+	return &MockUserService_ListWebAuthnCredentials_Call{Call: _e.mock.On("ListWebAuthnCredentials", ctx, userID)}
+}
+
+func (_c *MockUserService_ListWebAuthnCredentials_Call) Run(run func(ctx context.Context, userID int64)) *MockUserService_ListWebAuthnCredentials_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ListWebAuthnCredentials_Call) Return(webAuthnCredentials []model.WebAuthnCredential, err error) *MockUserService_ListWebAuthnCredentials_Call {
+	_c.Call.Return(webAuthnCredentials, err)
+	return _c
+}
+
+func (_c *MockUserService_ListWebAuthnCredentials_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)) *MockUserService_ListWebAuthnCredentials_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Login provides a mock function for the type MockUserService
+func (_mock *MockUserService) Login(ctx context.Context, email string, password string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, email, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Login")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, email, password)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, email, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, email, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_Login_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Login'
+type MockUserService_Login_Call struct {
+	*mock.Call
+}
+
+// Login is a helper method to define mock.On call
+//   - ctx
+//   - email
+//   - password
+func (_e *MockUserService_Expecter) Login(ctx interface{}, email interface{}, password interface{}) *MockUserService_Login_Call {
+	return &MockUserService_Login_Call{Call: _e.mock.On("Login", ctx, email, password)}
+}
+
+func (_c *MockUserService_Login_Call) Run(run func(ctx context.Context, email string, password string)) *MockUserService_Login_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Login_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_Login_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_Login_Call) RunAndReturn(run func(ctx context.Context, email string, password string) (*model.TokenPair, error)) *MockUserService_Login_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginMFA provides a mock function for the type MockUserService
+func (_mock *MockUserService) LoginMFA(ctx context.Context, mfaToken string, code string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, mfaToken, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginMFA")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, mfaToken, code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, mfaToken, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = returnFunc(ctx, mfaToken, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_LoginMFA_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginMFA'
+type MockUserService_LoginMFA_Call struct {
+	*mock.Call
+}
+
+// LoginMFA is a helper method to define mock.On call
+//   - ctx
+//   - mfaToken
+//   - code
+func (_e *MockUserService_Expecter) LoginMFA(ctx interface{}, mfaToken interface{}, code interface{}) *MockUserService_LoginMFA_Call {
+	return &MockUserService_LoginMFA_Call{Call: _e.mock.On("LoginMFA", ctx, mfaToken, code)}
+}
+
+func (_c *MockUserService_LoginMFA_Call) Run(run func(ctx context.Context, mfaToken string, code string)) *MockUserService_LoginMFA_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_LoginMFA_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_LoginMFA_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_LoginMFA_Call) RunAndReturn(run func(ctx context.Context, mfaToken string, code string) (*model.TokenPair, error)) *MockUserService_LoginMFA_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LoginMagicLink provides a mock function for the type MockUserService
+func (_mock *MockUserService) LoginMagicLink(ctx context.Context, token string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoginMagicLink")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, token)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_LoginMagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LoginMagicLink'
+type MockUserService_LoginMagicLink_Call struct {
+	*mock.Call
+}
+
+// LoginMagicLink is a helper method to define mock.On call
+//   - ctx
+//   - token
+func (_e *MockUserService_Expecter) LoginMagicLink(ctx interface{}, token interface{}) *MockUserService_LoginMagicLink_Call {
+	return &MockUserService_LoginMagicLink_Call{Call: _e.mock.On("LoginMagicLink", ctx, token)}
+}
+
+func (_c *MockUserService_LoginMagicLink_Call) Run(run func(ctx context.Context, token string)) *MockUserService_LoginMagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_LoginMagicLink_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_LoginMagicLink_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_LoginMagicLink_Call) RunAndReturn(run func(ctx context.Context, token string) (*model.TokenPair, error)) *MockUserService_LoginMagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Logout provides a mock function for the type MockUserService
+func (_mock *MockUserService) Logout(ctx context.Context, token string, refreshToken string) error {
+	ret := _mock.Called(ctx, token, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, token, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
+type MockUserService_Logout_Call struct {
+	*mock.Call
+}
+
+// Logout is a helper method to define mock.On call
+//   - ctx
+//   - token
+//   - refreshToken
+func (_e *MockUserService_Expecter) Logout(ctx interface{}, token interface{}, refreshToken interface{}) *MockUserService_Logout_Call {
+	return &MockUserService_Logout_Call{Call: _e.mock.On("Logout", ctx, token, refreshToken)}
+}
+
+func (_c *MockUserService_Logout_Call) Run(run func(ctx context.Context, token string, refreshToken string)) *MockUserService_Logout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Logout_Call) Return(err error) *MockUserService_Logout_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_Logout_Call) RunAndReturn(run func(ctx context.Context, token string, refreshToken string) error) *MockUserService_Logout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LogoutAll provides a mock function for the type MockUserService
+func (_mock *MockUserService) LogoutAll(ctx context.Context, userID int64) error {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LogoutAll")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_LogoutAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LogoutAll'
+type MockUserService_LogoutAll_Call struct {
+	*mock.Call
+}
+
+// LogoutAll is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockUserService_Expecter) LogoutAll(ctx interface{}, userID interface{}) *MockUserService_LogoutAll_Call {
+	return &MockUserService_LogoutAll_Call{Call: _e.mock.On("LogoutAll", ctx, userID)}
+}
+
+func (_c *MockUserService_LogoutAll_Call) Run(run func(ctx context.Context, userID int64)) *MockUserService_LogoutAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_LogoutAll_Call) Return(err error) *MockUserService_LogoutAll_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_LogoutAll_Call) RunAndReturn(run func(ctx context.Context, userID int64) error) *MockUserService_LogoutAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Refresh provides a mock function for the type MockUserService
+func (_mock *MockUserService) Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Refresh")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, refreshToken)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_Refresh_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Refresh'
+type MockUserService_Refresh_Call struct {
+	*mock.Call
+}
+
+// Refresh is a helper method to define mock.On call
+//   - ctx
+//   - refreshToken
+func (_e *MockUserService_Expecter) Refresh(ctx interface{}, refreshToken interface{}) *MockUserService_Refresh_Call {
+	return &MockUserService_Refresh_Call{Call: _e.mock.On("Refresh", ctx, refreshToken)}
+}
+
+func (_c *MockUserService_Refresh_Call) Run(run func(ctx context.Context, refreshToken string)) *MockUserService_Refresh_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_Refresh_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_Refresh_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_Refresh_Call) RunAndReturn(run func(ctx context.Context, refreshToken string) (*model.TokenPair, error)) *MockUserService_Refresh_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveGroupMember provides a mock function for the type MockUserService
+func (_mock *MockUserService) RemoveGroupMember(ctx context.Context, groupID int64, userID int64) error {
+	ret := _mock.Called(ctx, groupID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveGroupMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, groupID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RemoveGroupMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveGroupMember'
+type MockUserService_RemoveGroupMember_Call struct {
+	*mock.Call
+}
+
+// RemoveGroupMember is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - userID
+func (_e *MockUserService_Expecter) RemoveGroupMember(ctx interface{}, groupID interface{}, userID interface{}) *MockUserService_RemoveGroupMember_Call {
+	return &MockUserService_RemoveGroupMember_Call{Call: _e.mock.On("RemoveGroupMember", ctx, groupID, userID)}
+}
+
+func (_c *MockUserService_RemoveGroupMember_Call) Run(run func(ctx context.Context, groupID int64, userID int64)) *MockUserService_RemoveGroupMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RemoveGroupMember_Call) Return(err error) *MockUserService_RemoveGroupMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RemoveGroupMember_Call) RunAndReturn(run func(ctx context.Context, groupID int64, userID int64) error) *MockUserService_RemoveGroupMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveMember provides a mock function for the type MockUserService
+func (_mock *MockUserService) RemoveMember(ctx context.Context, orgID int64, userID int64) error {
+	ret := _mock.Called(ctx, orgID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveMember")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, orgID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RemoveMember_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveMember'
+type MockUserService_RemoveMember_Call struct {
+	*mock.Call
+}
+
+// RemoveMember is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - userID
+func (_e *MockUserService_Expecter) RemoveMember(ctx interface{}, orgID interface{}, userID interface{}) *MockUserService_RemoveMember_Call {
+	return &MockUserService_RemoveMember_Call{Call: _e.mock.On("RemoveMember", ctx, orgID, userID)}
+}
+
+func (_c *MockUserService_RemoveMember_Call) Run(run func(ctx context.Context, orgID int64, userID int64)) *MockUserService_RemoveMember_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RemoveMember_Call) Return(err error) *MockUserService_RemoveMember_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RemoveMember_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64) error) *MockUserService_RemoveMember_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveSubgroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) RemoveSubgroup(ctx context.Context, groupID int64, subgroupID int64) error {
+	ret := _mock.Called(ctx, groupID, subgroupID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveSubgroup")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, groupID, subgroupID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RemoveSubgroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveSubgroup'
+type MockUserService_RemoveSubgroup_Call struct {
+	*mock.Call
+}
+
+// RemoveSubgroup is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - subgroupID
+func (_e *MockUserService_Expecter) RemoveSubgroup(ctx interface{}, groupID interface{}, subgroupID interface{}) *MockUserService_RemoveSubgroup_Call {
+	return &MockUserService_RemoveSubgroup_Call{Call: _e.mock.On("RemoveSubgroup", ctx, groupID, subgroupID)}
+}
+
+func (_c *MockUserService_RemoveSubgroup_Call) Run(run func(ctx context.Context, groupID int64, subgroupID int64)) *MockUserService_RemoveSubgroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RemoveSubgroup_Call) Return(err error) *MockUserService_RemoveSubgroup_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RemoveSubgroup_Call) RunAndReturn(run func(ctx context.Context, groupID int64, subgroupID int64) error) *MockUserService_RemoveSubgroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenameGroup provides a mock function for the type MockUserService
+func (_mock *MockUserService) RenameGroup(ctx context.Context, id int64, name string) (*model.Group, error) {
+	ret := _mock.Called(ctx, id, name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameGroup")
+	}
+
+	var r0 *model.Group
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) (*model.Group, error)); ok {
+		return returnFunc(ctx, id, name)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) *model.Group); ok {
+		r0 = returnFunc(ctx, id, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Group)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = returnFunc(ctx, id, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_RenameGroup_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenameGroup'
+type MockUserService_RenameGroup_Call struct {
+	*mock.Call
+}
+
+// RenameGroup is a helper method to define mock.On call
+//   - ctx
+//   - id
+//   - name
+func (_e *MockUserService_Expecter) RenameGroup(ctx interface{}, id interface{}, name interface{}) *MockUserService_RenameGroup_Call {
+	return &MockUserService_RenameGroup_Call{Call: _e.mock.On("RenameGroup", ctx, id, name)}
+}
+
+func (_c *MockUserService_RenameGroup_Call) Run(run func(ctx context.Context, id int64, name string)) *MockUserService_RenameGroup_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RenameGroup_Call) Return(group *model.Group, err error) *MockUserService_RenameGroup_Call {
+	_c.Call.Return(group, err)
+	return _c
+}
+
+func (_c *MockUserService_RenameGroup_Call) RunAndReturn(run func(ctx context.Context, id int64, name string) (*model.Group, error)) *MockUserService_RenameGroup_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestEmailChange provides a mock function for the type MockUserService
+func (_mock *MockUserService) RequestEmailChange(ctx context.Context, userID int64, current string, newEmail string) error {
+	ret := _mock.Called(ctx, userID, current, newEmail)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestEmailChange")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string, string) error); ok {
+		r0 = returnFunc(ctx, userID, current, newEmail)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RequestEmailChange_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestEmailChange'
+type MockUserService_RequestEmailChange_Call struct {
+	*mock.Call
+}
+
+// RequestEmailChange is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - current
+//   - newEmail
+func (_e *MockUserService_Expecter) RequestEmailChange(ctx interface{}, userID interface{}, current interface{}, newEmail interface{}) *MockUserService_RequestEmailChange_Call {
+	return &MockUserService_RequestEmailChange_Call{Call: _e.mock.On("RequestEmailChange", ctx, userID, current, newEmail)}
+}
+
+func (_c *MockUserService_RequestEmailChange_Call) Run(run func(ctx context.Context, userID int64, current string, newEmail string)) *MockUserService_RequestEmailChange_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RequestEmailChange_Call) Return(err error) *MockUserService_RequestEmailChange_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RequestEmailChange_Call) RunAndReturn(run func(ctx context.Context, userID int64, current string, newEmail string) error) *MockUserService_RequestEmailChange_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RequestMagicLink provides a mock function for the type MockUserService
+func (_mock *MockUserService) RequestMagicLink(ctx context.Context, email string) error {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RequestMagicLink")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RequestMagicLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RequestMagicLink'
+type MockUserService_RequestMagicLink_Call struct {
+	*mock.Call
+}
+
+// RequestMagicLink is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) RequestMagicLink(ctx interface{}, email interface{}) *MockUserService_RequestMagicLink_Call {
+	return &MockUserService_RequestMagicLink_Call{Call: _e.mock.On("RequestMagicLink", ctx, email)}
+}
+
+func (_c *MockUserService_RequestMagicLink_Call) Run(run func(ctx context.Context, email string)) *MockUserService_RequestMagicLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RequestMagicLink_Call) Return(err error) *MockUserService_RequestMagicLink_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RequestMagicLink_Call) RunAndReturn(run func(ctx context.Context, email string) error) *MockUserService_RequestMagicLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResendVerification provides a mock function for the type MockUserService
+func (_mock *MockUserService) ResendVerification(ctx context.Context, email string) error {
+	ret := _mock.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResendVerification")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ResendVerification_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResendVerification'
+type MockUserService_ResendVerification_Call struct {
+	*mock.Call
+}
+
+// ResendVerification is a helper method to define mock.On call
+//   - ctx
+//   - email
+func (_e *MockUserService_Expecter) ResendVerification(ctx interface{}, email interface{}) *MockUserService_ResendVerification_Call {
+	return &MockUserService_ResendVerification_Call{Call: _e.mock.On("ResendVerification", ctx, email)}
+}
+
+func (_c *MockUserService_ResendVerification_Call) Run(run func(ctx context.Context, email string)) *MockUserService_ResendVerification_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ResendVerification_Call) Return(err error) *MockUserService_ResendVerification_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ResendVerification_Call) RunAndReturn(run func(ctx context.Context, email string) error) *MockUserService_ResendVerification_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetPassword provides a mock function for the type MockUserService
+func (_mock *MockUserService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	ret := _mock.Called(ctx, token, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetPassword")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = returnFunc(ctx, token, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_ResetPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetPassword'
+type MockUserService_ResetPassword_Call struct {
+	*mock.Call
+}
+
+// ResetPassword is a helper method to define mock.On call
+//   - ctx
+//   - token
+//   - newPassword
+func (_e *MockUserService_Expecter) ResetPassword(ctx interface{}, token interface{}, newPassword interface{}) *MockUserService_ResetPassword_Call {
+	return &MockUserService_ResetPassword_Call{Call: _e.mock.On("ResetPassword", ctx, token, newPassword)}
+}
+
+func (_c *MockUserService_ResetPassword_Call) Run(run func(ctx context.Context, token string, newPassword string)) *MockUserService_ResetPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_ResetPassword_Call) Return(err error) *MockUserService_ResetPassword_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_ResetPassword_Call) RunAndReturn(run func(ctx context.Context, token string, newPassword string) error) *MockUserService_ResetPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeGroupRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) RevokeGroupRole(ctx context.Context, groupID int64, role string) error {
+	ret := _mock.Called(ctx, groupID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeGroupRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, groupID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RevokeGroupRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeGroupRole'
+type MockUserService_RevokeGroupRole_Call struct {
+	*mock.Call
+}
+
+// RevokeGroupRole is a helper method to define mock.On call
+//   - ctx
+//   - groupID
+//   - role
+func (_e *MockUserService_Expecter) RevokeGroupRole(ctx interface{}, groupID interface{}, role interface{}) *MockUserService_RevokeGroupRole_Call {
+	return &MockUserService_RevokeGroupRole_Call{Call: _e.mock.On("RevokeGroupRole", ctx, groupID, role)}
+}
+
+func (_c *MockUserService_RevokeGroupRole_Call) Run(run func(ctx context.Context, groupID int64, role string)) *MockUserService_RevokeGroupRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RevokeGroupRole_Call) Return(err error) *MockUserService_RevokeGroupRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RevokeGroupRole_Call) RunAndReturn(run func(ctx context.Context, groupID int64, role string) error) *MockUserService_RevokeGroupRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeInvitation provides a mock function for the type MockUserService
+func (_mock *MockUserService) RevokeInvitation(ctx context.Context, orgID int64, id int64) error {
+	ret := _mock.Called(ctx, orgID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeInvitation")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = returnFunc(ctx, orgID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RevokeInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeInvitation'
+type MockUserService_RevokeInvitation_Call struct {
+	*mock.Call
+}
+
+// RevokeInvitation is a helper method to define mock.On call
+//   - ctx
+//   - orgID
+//   - id
+func (_e *MockUserService_Expecter) RevokeInvitation(ctx interface{}, orgID interface{}, id interface{}) *MockUserService_RevokeInvitation_Call {
+	return &MockUserService_RevokeInvitation_Call{Call: _e.mock.On("RevokeInvitation", ctx, orgID, id)}
+}
+
+func (_c *MockUserService_RevokeInvitation_Call) Run(run func(ctx context.Context, orgID int64, id int64)) *MockUserService_RevokeInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RevokeInvitation_Call) Return(err error) *MockUserService_RevokeInvitation_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RevokeInvitation_Call) RunAndReturn(run func(ctx context.Context, orgID int64, id int64) error) *MockUserService_RevokeInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) RevokeRole(ctx context.Context, userID int64, role string) error {
+	ret := _mock.Called(ctx, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRole")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_RevokeRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRole'
+type MockUserService_RevokeRole_Call struct {
+	*mock.Call
+}
+
+// RevokeRole is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - role
+func (_e *MockUserService_Expecter) RevokeRole(ctx interface{}, userID interface{}, role interface{}) *MockUserService_RevokeRole_Call {
+	return &MockUserService_RevokeRole_Call{Call: _e.mock.On("RevokeRole", ctx, userID, role)}
+}
+
+func (_c *MockUserService_RevokeRole_Call) Run(run func(ctx context.Context, userID int64, role string)) *MockUserService_RevokeRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_RevokeRole_Call) Return(err error) *MockUserService_RevokeRole_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_RevokeRole_Call) RunAndReturn(run func(ctx context.Context, userID int64, role string) error) *MockUserService_RevokeRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function for the type MockUserService
+func (_mock *MockUserService) RevokeSession(ctx context.Context, userID int64, id string) error {
+	ret := _mock.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = returnFunc(ctx, userID, id)
 	} else {
 		r0 = ret.Error(0)
 	}
 	return r0
 }
 
-// MockUserService_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
-type MockUserService_DeleteUser_Call struct {
+// MockUserService_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type MockUserService_RevokeSession_Call struct {
 	*mock.Call
 }
 
-// DeleteUser is a helper method to define mock.On call
+// RevokeSession is a helper method to define mock.On call
 //   - ctx
+//   - userID
 //   - id
-func (_e *MockUserService_Expecter) DeleteUser(ctx interface{}, id interface{}) *MockUserService_DeleteUser_Call {
-	return &MockUserService_DeleteUser_Call{Call: _e.mock.On("DeleteUser", ctx, id)}
+func (_e *MockUserService_Expecter) RevokeSession(ctx interface{}, userID interface{}, id interface{}) *MockUserService_RevokeSession_Call {
+	return &MockUserService_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, userID, id)}
 }
 
-func (_c *MockUserService_DeleteUser_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_DeleteUser_Call {
+func (_c *MockUserService_RevokeSession_Call) Run(run func(ctx context.Context, userID int64, id string)) *MockUserService_RevokeSession_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64))
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockUserService_DeleteUser_Call) Return(err error) *MockUserService_DeleteUser_Call {
+func (_c *MockUserService_RevokeSession_Call) Return(err error) *MockUserService_RevokeSession_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockUserService_DeleteUser_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserService_DeleteUser_Call {
+func (_c *MockUserService_RevokeSession_Call) RunAndReturn(run func(ctx context.Context, userID int64, id string) error) *MockUserService_RevokeSession_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetProfile provides a mock function for the type MockUserService
-func (_mock *MockUserService) GetProfile(ctx context.Context, id int64) (*model.User, error) {
-	ret := _mock.Called(ctx, id)
+// RotateSigningKey provides a mock function for the type MockUserService
+func (_mock *MockUserService) RotateSigningKey(ctx context.Context) (string, error) {
+	ret := _mock.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetProfile")
+		panic("no return value specified for RotateSigningKey")
 	}
 
-	var r0 *model.User
+	var r0 string
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) (*model.User, error)); ok {
-		return returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) (string, error)); ok {
+		return returnFunc(ctx)
 	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) *model.User); ok {
-		r0 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = returnFunc(ctx)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model.User)
-		}
+		r0 = ret.Get(0).(string)
 	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = returnFunc(ctx, id)
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockUserService_GetProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProfile'
-type MockUserService_GetProfile_Call struct {
+// MockUserService_RotateSigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateSigningKey'
+type MockUserService_RotateSigningKey_Call struct {
 	*mock.Call
 }
 
-// GetProfile is a helper method to define mock.On call
+// RotateSigningKey is a helper method to define mock.On call
 //   - ctx
-//   - id
-func (_e *MockUserService_Expecter) GetProfile(ctx interface{}, id interface{}) *MockUserService_GetProfile_Call {
-	return &MockUserService_GetProfile_Call{Call: _e.mock.On("GetProfile", ctx, id)}
+func (_e *MockUserService_Expecter) RotateSigningKey(ctx interface{}) *MockUserService_RotateSigningKey_Call {
+	return &MockUserService_RotateSigningKey_Call{Call: _e.mock.On("RotateSigningKey", ctx)}
 }
 
-func (_c *MockUserService_GetProfile_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_GetProfile_Call {
+func (_c *MockUserService_RotateSigningKey_Call) Run(run func(ctx context.Context)) *MockUserService_RotateSigningKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(int64))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockUserService_GetProfile_Call) Return(user *model.User, err error) *MockUserService_GetProfile_Call {
-	_c.Call.Return(user, err)
+func (_c *MockUserService_RotateSigningKey_Call) Return(s string, err error) *MockUserService_RotateSigningKey_Call {
+	_c.Call.Return(s, err)
 	return _c
 }
 
-func (_c *MockUserService_GetProfile_Call) RunAndReturn(run func(ctx context.Context, id int64) (*model.User, error)) *MockUserService_GetProfile_Call {
+func (_c *MockUserService_RotateSigningKey_Call) RunAndReturn(run func(ctx context.Context) (string, error)) *MockUserService_RotateSigningKey_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Login provides a mock function for the type MockUserService
-func (_mock *MockUserService) Login(ctx context.Context, email string, password string) (string, error) {
-	ret := _mock.Called(ctx, email, password)
+// SetMemberRole provides a mock function for the type MockUserService
+func (_mock *MockUserService) SetMemberRole(ctx context.Context, orgID int64, userID int64, role string) error {
+	ret := _mock.Called(ctx, orgID, userID, role)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Login")
+		panic("no return value specified for SetMemberRole")
 	}
 
-	var r0 string
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
-		return returnFunc(ctx, email, password)
-	}
-	if returnFunc, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
-		r0 = returnFunc(ctx, email, password)
-	} else {
-		r0 = ret.Get(0).(string)
-	}
-	if returnFunc, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = returnFunc(ctx, email, password)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, string) error); ok {
+		r0 = returnFunc(ctx, orgID, userID, role)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockUserService_Login_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Login'
-type MockUserService_Login_Call struct {
+// MockUserService_SetMemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMemberRole'
+type MockUserService_SetMemberRole_Call struct {
 	*mock.Call
 }
 
-// Login is a helper method to define mock.On call
+// SetMemberRole is a helper method to define mock.On call
 //   - ctx
-//   - email
-//   - password
-func (_e *MockUserService_Expecter) Login(ctx interface{}, email interface{}, password interface{}) *MockUserService_Login_Call {
-	return &MockUserService_Login_Call{Call: _e.mock.On("Login", ctx, email, password)}
+//   - orgID
+//   - userID
+//   - role
+func (_e *MockUserService_Expecter) SetMemberRole(ctx interface{}, orgID interface{}, userID interface{}, role interface{}) *MockUserService_SetMemberRole_Call {
+	return &MockUserService_SetMemberRole_Call{Call: _e.mock.On("SetMemberRole", ctx, orgID, userID, role)}
 }
 
-func (_c *MockUserService_Login_Call) Run(run func(ctx context.Context, email string, password string)) *MockUserService_Login_Call {
+func (_c *MockUserService_SetMemberRole_Call) Run(run func(ctx context.Context, orgID int64, userID int64, role string)) *MockUserService_SetMemberRole_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(string))
 	})
 	return _c
 }
 
-func (_c *MockUserService_Login_Call) Return(s string, err error) *MockUserService_Login_Call {
-	_c.Call.Return(s, err)
+func (_c *MockUserService_SetMemberRole_Call) Return(err error) *MockUserService_SetMemberRole_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockUserService_Login_Call) RunAndReturn(run func(ctx context.Context, email string, password string) (string, error)) *MockUserService_Login_Call {
+func (_c *MockUserService_SetMemberRole_Call) RunAndReturn(run func(ctx context.Context, orgID int64, userID int64, role string) error) *MockUserService_SetMemberRole_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -255,6 +2841,110 @@ func (_c *MockUserService_SignUp_Call) RunAndReturn(run func(ctx context.Context
 	return _c
 }
 
+// SwitchOrganization provides a mock function for the type MockUserService
+func (_mock *MockUserService) SwitchOrganization(ctx context.Context, userID int64, orgID int64) (*model.TokenPair, error) {
+	ret := _mock.Called(ctx, userID, orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SwitchOrganization")
+	}
+
+	var r0 *model.TokenPair
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) (*model.TokenPair, error)); ok {
+		return returnFunc(ctx, userID, orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64) *model.TokenPair); ok {
+		r0 = returnFunc(ctx, userID, orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.TokenPair)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = returnFunc(ctx, userID, orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_SwitchOrganization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SwitchOrganization'
+type MockUserService_SwitchOrganization_Call struct {
+	*mock.Call
+}
+
+// SwitchOrganization is a helper method to define mock.On call
+//   - ctx
+//   - userID
+//   - orgID
+func (_e *MockUserService_Expecter) SwitchOrganization(ctx interface{}, userID interface{}, orgID interface{}) *MockUserService_SwitchOrganization_Call {
+	return &MockUserService_SwitchOrganization_Call{Call: _e.mock.On("SwitchOrganization", ctx, userID, orgID)}
+}
+
+func (_c *MockUserService_SwitchOrganization_Call) Run(run func(ctx context.Context, userID int64, orgID int64)) *MockUserService_SwitchOrganization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_SwitchOrganization_Call) Return(tokenPair *model.TokenPair, err error) *MockUserService_SwitchOrganization_Call {
+	_c.Call.Return(tokenPair, err)
+	return _c
+}
+
+func (_c *MockUserService_SwitchOrganization_Call) RunAndReturn(run func(ctx context.Context, userID int64, orgID int64) (*model.TokenPair, error)) *MockUserService_SwitchOrganization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UnlockUser provides a mock function for the type MockUserService
+func (_mock *MockUserService) UnlockUser(ctx context.Context, id int64) error {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnlockUser")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_UnlockUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UnlockUser'
+type MockUserService_UnlockUser_Call struct {
+	*mock.Call
+}
+
+// UnlockUser is a helper method to define mock.On call
+//   - ctx
+//   - id
+func (_e *MockUserService_Expecter) UnlockUser(ctx interface{}, id interface{}) *MockUserService_UnlockUser_Call {
+	return &MockUserService_UnlockUser_Call{Call: _e.mock.On("UnlockUser", ctx, id)}
+}
+
+func (_c *MockUserService_UnlockUser_Call) Run(run func(ctx context.Context, id int64)) *MockUserService_UnlockUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_UnlockUser_Call) Return(err error) *MockUserService_UnlockUser_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_UnlockUser_Call) RunAndReturn(run func(ctx context.Context, id int64) error) *MockUserService_UnlockUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateUser provides a mock function for the type MockUserService
 func (_mock *MockUserService) UpdateUser(ctx context.Context, id int64, newName string) (*model.User, error) {
 	ret := _mock.Called(ctx, id, newName)
@@ -312,3 +3002,191 @@ func (_c *MockUserService_UpdateUser_Call) RunAndReturn(run func(ctx context.Con
 	_c.Call.Return(run)
 	return _c
 }
+
+// UserRoles provides a mock function for the type MockUserService
+func (_mock *MockUserService) UserRoles(ctx context.Context, userID int64) ([]string, error) {
+	ret := _mock.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserRoles")
+	}
+
+	var r0 []string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) ([]string, error)); ok {
+		return returnFunc(ctx, userID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = returnFunc(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = returnFunc(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockUserService_UserRoles_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserRoles'
+type MockUserService_UserRoles_Call struct {
+	*mock.Call
+}
+
+// UserRoles is a helper method to define mock.On call
+//   - ctx
+//   - userID
+func (_e *MockUserService_Expecter) UserRoles(ctx interface{}, userID interface{}) *MockUserService_UserRoles_Call {
+	return &MockUserService_UserRoles_Call{Call: _e.mock.On("UserRoles", ctx, userID)}
+}
+
+func (_c *MockUserService_UserRoles_Call) Run(run func(ctx context.Context, userID int64)) *MockUserService_UserRoles_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUserService_UserRoles_Call) Return(ss []string, err error) *MockUserService_UserRoles_Call {
+	_c.Call.Return(ss, err)
+	return _c
+}
+
+func (_c *MockUserService_UserRoles_Call) RunAndReturn(run func(ctx context.Context, userID int64) ([]string, error)) *MockUserService_UserRoles_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifyEmail provides a mock function for the type MockUserService
+func (_mock *MockUserService) VerifyEmail(ctx context.Context, token string) error {
+	ret := _mock.Called(ctx, token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyEmail")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = returnFunc(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUserService_VerifyEmail_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifyEmail'
+type MockUserService_VerifyEmail_Call struct {
+	*mock.Call
+}
+
+// VerifyEmail is a helper method to define mock.On call
+//   - ctx
+//   - token
+func (_e *MockUserService_Expecter) VerifyEmail(ctx interface{}, token interface{}) *MockUserService_VerifyEmail_Call {
+	return &MockUserService_VerifyEmail_Call{Call: _e.mock.On("VerifyEmail", ctx, token)}
+}
+
+func (_c *MockUserService_VerifyEmail_Call) Run(run func(ctx context.Context, token string)) *MockUserService_VerifyEmail_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUserService_VerifyEmail_Call) Return(err error) *MockUserService_VerifyEmail_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUserService_VerifyEmail_Call) RunAndReturn(run func(ctx context.Context, token string) error) *MockUserService_VerifyEmail_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRateLimiter creates a new instance of MockRateLimiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRateLimiter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRateLimiter {
+	mock := &MockRateLimiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRateLimiter is an autogenerated mock type for the RateLimiter type
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+type MockRateLimiter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRateLimiter) EXPECT() *MockRateLimiter_Expecter {
+	return &MockRateLimiter_Expecter{mock: &_m.Mock}
+}
+
+// Allow provides a mock function for the type MockRateLimiter
+func (_mock *MockRateLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error) {
+	ret := _mock.Called(ctx, key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Allow")
+	}
+
+	var r0 *ratelimit.Result
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) (*ratelimit.Result, error)); ok {
+		return returnFunc(ctx, key, limit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string, ratelimit.Limit) *ratelimit.Result); ok {
+		r0 = returnFunc(ctx, key, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ratelimit.Result)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string, ratelimit.Limit) error); ok {
+		r1 = returnFunc(ctx, key, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRateLimiter_Allow_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Allow'
+type MockRateLimiter_Allow_Call struct {
+	*mock.Call
+}
+
+// Allow is a helper method to define mock.On call
+//   - ctx
+//   - key
+//   - limit
+func (_e *MockRateLimiter_Expecter) Allow(ctx interface{}, key interface{}, limit interface{}) *MockRateLimiter_Allow_Call {
+	return &MockRateLimiter_Allow_Call{Call: _e.mock.On("Allow", ctx, key, limit)}
+}
+
+func (_c *MockRateLimiter_Allow_Call) Run(run func(ctx context.Context, key string, limit ratelimit.Limit)) *MockRateLimiter_Allow_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(ratelimit.Limit))
+	})
+	return _c
+}
+
+func (_c *MockRateLimiter_Allow_Call) Return(result *ratelimit.Result, err error) *MockRateLimiter_Allow_Call {
+	_c.Call.Return(result, err)
+	return _c
+}
+
+func (_c *MockRateLimiter_Allow_Call) RunAndReturn(run func(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error)) *MockRateLimiter_Allow_Call {
+	_c.Call.Return(run)
+	return _c
+}
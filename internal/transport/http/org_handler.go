@@ -0,0 +1,188 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganization godoc
+// @Summary      Create an organization
+// @Description  Create an organization, administered by the current user
+// @Tags         organizations
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.CreateOrganizationRequest  true  "Organization"
+// @Success      201      {object}  model.Organization
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /orgs [post]
+// @Security     ApiKeyAuth
+func (h *Handler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	org, err := h.svc.CreateOrganization(getContext(c), c.GetInt64("userID"), req.Slug, req.Name)
+	if err != nil {
+		orgError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// ListOrganizations godoc
+// @Summary      List my organizations
+// @Description  List the organizations the current user is a member of, with their role in each
+// @Tags         organizations
+// @Produce      json
+// @Success      200  {array}   model.Organization
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orgs [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.svc.ListOrganizations(getContext(c), c.GetInt64("userID"))
+	if err != nil {
+		orgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orgs)
+}
+
+// SwitchOrganization godoc
+// @Summary      Get tokens for an organization
+// @Description  Issue tokens scoped to one of the current user's organizations. They carry the roles held within it, and only reach its users.
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {object}  model.TokenPair
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orgs/{id}/token [post]
+// @Security     ApiKeyAuth
+func (h *Handler) SwitchOrganization(c *gin.Context) {
+	orgID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid organization ID"})
+		return
+	}
+	tokens, err := h.svc.SwitchOrganization(getContext(c), c.GetInt64("userID"), orgID)
+	if err != nil {
+		orgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// ListMembers godoc
+// @Summary      List members
+// @Description  List the members of an organization and their roles (requires members:read, with a token scoped to the organization)
+// @Tags         organizations
+// @Produce      json
+// @Param        id   path      int  true  "Organization ID"
+// @Success      200  {array}   model.Membership
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /orgs/{id}/members [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListMembers(c *gin.Context) {
+	members, err := h.svc.ListMembers(getContext(c), c.GetInt64("orgID"))
+	if err != nil {
+		orgError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// SetMemberRole godoc
+// @Summary      Change the role of a member
+// @Description  Make a member an org_admin or an org_member and revoke their access tokens. The last admin cannot be demoted (requires members:manage, with a token scoped to the organization)
+// @Tags         organizations
+// @Accept       json
+// @Param        id       path      int                        true  "Organization ID"
+// @Param        user_id  path      int                        true  "User ID"
+// @Param        payload  body      http.SetMemberRoleRequest  true  "Role"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /orgs/{id}/members/{user_id} [put]
+// @Security     ApiKeyAuth
+func (h *Handler) SetMemberRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	var req SetMemberRoleRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = h.svc.SetMemberRole(getContext(c), c.GetInt64("orgID"), userID, req.Role); err != nil {
+		orgError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveMember godoc
+// @Summary      Remove a member
+// @Description  Remove a user from an organization and revoke their access tokens. The last admin cannot be removed (requires members:manage, with a token scoped to the organization)
+// @Tags         organizations
+// @Param        id       path      int  true  "Organization ID"
+// @Param        user_id  path      int  true  "User ID"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /orgs/{id}/members/{user_id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RemoveMember(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if err = h.svc.RemoveMember(getContext(c), c.GetInt64("orgID"), userID); err != nil {
+		orgError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// orgError answers a failed organization request.
+func orgError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrOrganizationsDisabled), errors.Is(err, service.ErrNotMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, service.ErrInvalidSlug), errors.Is(err, service.ErrInvalidOrgRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSlugTaken), errors.Is(err, service.ErrLastOrgAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,126 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_CreateOrganization(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusCreated},
+		{"bad slug", service.ErrInvalidSlug, http.StatusBadRequest},
+		{"slug taken", service.ErrSlugTaken, http.StatusConflict},
+		{"feature off", service.ErrOrganizationsDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var org *model.Organization
+			if tc.err == nil {
+				org = &model.Organization{ID: 4, Slug: "acme", Name: "Acme", Role: model.RoleOrgAdmin}
+			}
+			mockSvc.On("CreateOrganization", mock.Anything, int64(10), "acme", "Acme").Return(org, tc.err)
+
+			buf, _ := json.Marshal(httptransport.CreateOrganizationRequest{Slug: "acme", Name: "Acme"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("userID", int64(10))
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/orgs", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateOrganization(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_SwitchOrganization(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("SwitchOrganization", mock.Anything, int64(10), int64(4)).
+		Return(&model.TokenPair{AccessToken: "tok", TokenType: "Bearer"}, nil)
+	mockSvc.On("SwitchOrganization", mock.Anything, int64(10), int64(5)).Return(nil, service.ErrNotMember)
+
+	for org, want := range map[string]int{"4": http.StatusOK, "5": http.StatusNotFound, "x": http.StatusBadRequest} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", int64(10))
+		c.Params = gin.Params{{Key: "id", Value: org}}
+
+		handler.SwitchOrganization(c)
+
+		assert.Equal(t, want, w.Code, org)
+	}
+}
+
+func TestHandler_SetMemberRole(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"not a member", service.ErrNotMember, http.StatusNotFound},
+		{"invalid role", service.ErrInvalidOrgRole, http.StatusBadRequest},
+		{"last admin", service.ErrLastOrgAdmin, http.StatusConflict},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("SetMemberRole", mock.Anything, int64(4), int64(8), model.RoleOrgMember).Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("orgID", int64(4))
+			c.Params = gin.Params{{Key: "id", Value: "4"}, {Key: "user_id", Value: "8"}}
+			c.Request = httptest.NewRequest(http.MethodPut, "/v1/orgs/4/members/8",
+				bytes.NewBufferString(`{"role":"org_member"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.SetMemberRole(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RemoveMember(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("RemoveMember", mock.Anything, int64(4), int64(8)).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("orgID", int64(4))
+	c.Params = gin.Params{{Key: "id", Value: "4"}, {Key: "user_id", Value: "8"}}
+
+	handler.RemoveMember(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockSvc.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// PasswordPolicyErrorResponse answers passwords that break the password
+// policy, with the codes of the rules they break.
+type PasswordPolicyErrorResponse struct {
+	Error      string   `json:"error"`
+	Violations []string `json:"violations" example:"too_short,breached"`
+}
+
+// passwordPolicyViolation answers with 400 and the violations if err is a
+// *service.PasswordPolicyError, and reports whether it did.
+func passwordPolicyViolation(c *gin.Context, err error) bool {
+	var pe *service.PasswordPolicyError
+	if !errors.As(err, &pe) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, PasswordPolicyErrorResponse{
+		Error:      service.ErrWeakPassword.Error(),
+		Violations: pe.Violations,
+	})
+	return true
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Send a single-use password reset token to the given address. The response is the same whether or not an account exists.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ForgotPasswordRequest  true  "Account email"
+// @Success      202      {object}  map[string]string
+// @Failure      400      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.ForgotPassword(getContext(c), req.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not send reset token"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "if the account exists, a reset token has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Set a new password with a reset token. All existing sessions of the user are revoked. A password that breaks the password policy is refused with the violated rules, and the token stays valid.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ResetPasswordRequest  true  "Reset token and new password"
+// @Success      204      "No Content"
+// @Failure      400      {object}  http.PasswordPolicyErrorResponse
+// @Failure      500      {object}  map[string]string
+// @Router       /password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.svc.ResetPassword(getContext(c), req.Token, req.Password); err != nil {
+		if passwordPolicyViolation(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidResetToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ChangePassword godoc
+// @Summary      Change my password
+// @Description  Replace the authenticated user's password, confirmed with the current one. A password that breaks the password policy or was used recently is refused with the violated rules. All sessions are revoked; the caller continues with the returned tokens.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.ChangePasswordRequest  true  "Current and new password"
+// @Success      200      {object}  model.TokenPair
+// @Failure      400      {object}  http.PasswordPolicyErrorResponse
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /profile/password [put]
+// @Security     ApiKeyAuth
+func (h *Handler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.svc.ChangePassword(getContext(c), c.GetInt64("userID"), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if passwordPolicyViolation(c, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCurrentPassword) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if loginLocked(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,135 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ForgotPassword", mock.Anything, "user@x.com").Return(nil)
+
+	buf, _ := json.Marshal(map[string]string{"email": "user@x.com"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/password/forgot", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ResetPassword", mock.Anything, "tok", "n3wpassword").Return(nil)
+
+	buf, _ := json.Marshal(map[string]string{"token": "tok", "password": "n3wpassword"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/password/reset", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword_InvalidToken(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ResetPassword", mock.Anything, "used", "n3wpassword").
+		Return(fmt.Errorf("reset: %w", service.ErrInvalidResetToken))
+
+	buf, _ := json.Marshal(map[string]string{"token": "used", "password": "n3wpassword"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/password/reset", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_ResetPassword_WeakPassword(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("ResetPassword", mock.Anything, "tok", "pw").
+		Return(&service.PasswordPolicyError{Violations: []string{service.ViolationTooShort, service.ViolationBreached}})
+
+	buf, _ := json.Marshal(map[string]string{"token": "tok", "password": "pw"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/password/reset", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"password does not meet the password policy","violations":["too_short","breached"]}`,
+		w.Body.String())
+}
+
+func TestHandler_ChangePassword(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("ChangePassword", mock.Anything, int64(10), "old-secret", "new-secret").
+		Return(&model.TokenPair{AccessToken: "token123", TokenType: "Bearer", ExpiresIn: 60}, nil)
+	mockSvc.On("ChangePassword", mock.Anything, int64(10), "wrong", "new-secret").
+		Return(nil, service.ErrInvalidCurrentPassword)
+	mockSvc.On("ChangePassword", mock.Anything, int64(10), "guess", "new-secret").
+		Return(nil, &service.LoginLockedError{RetryAfter: time.Minute})
+	mockSvc.On("ChangePassword", mock.Anything, int64(10), "old-secret", "old-secret").
+		Return(nil, &service.PasswordPolicyError{Violations: []string{service.ViolationReused}})
+
+	tests := []struct {
+		name    string
+		current string
+		status  int
+	}{
+		{"changed", "old-secret", http.StatusOK},
+		{"wrong current password", "wrong", http.StatusForbidden},
+		{"locked out", "guess", http.StatusTooManyRequests},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf, _ := json.Marshal(map[string]string{"current_password": tc.current, "new_password": "new-secret"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/v1/profile/password", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("userID", int64(10))
+
+			handler.ChangePassword(c)
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+
+	buf, _ := json.Marshal(map[string]string{"current_password": "old-secret", "new_password": "old-secret"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPut, "/v1/profile/password", bytes.NewBuffer(buf))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", int64(10))
+
+	handler.ChangePassword(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"violations":["reused"]`)
+}
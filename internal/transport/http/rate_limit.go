@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/enson89/user-service-go/internal/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// Identities requests can be rate limited by.
+const (
+	IdentityIP     = "ip"
+	IdentityUser   = "user"
+	IdentityAPIKey = "api_key"
+)
+
+// APIKeyHeader carries the API key requests are limited by with IdentityAPIKey.
+const APIKeyHeader = "X-API-Key"
+
+// RateLimiter takes a request from the budget of key.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit ratelimit.Limit) (*ratelimit.Result, error)
+}
+
+// RateLimitPolicy limits the requests every identity makes to Routes.
+type RateLimitPolicy struct {
+	// Name keeps the budgets of policies apart.
+	Name string
+	// Routes are "METHOD /path" or "/path" (any method), with paths as
+	// registered on the router, e.g. "/v1/user/:id". "*" matches any route.
+	Routes []string
+	// Identity is IdentityIP, IdentityUser or IdentityAPIKey. Requests
+	// without the identity, e.g. without an API key, are not limited. The
+	// client IP honours X-Forwarded-For only from trusted proxies, see
+	// WithTrustedProxies.
+	Identity string
+	Limit    ratelimit.Limit
+}
+
+// matches reports whether p applies to requests of method to route.
+func (p RateLimitPolicy) matches(method, route string) bool {
+	for _, r := range p.Routes {
+		if r == "*" || r == route || r == method+" "+route {
+			return true
+		}
+	}
+	return false
+}
+
+// identity returns whose budget the request is taken from, or "".
+func (p RateLimitPolicy) identity(c *gin.Context) string {
+	switch p.Identity {
+	case IdentityIP:
+		if ip := c.ClientIP(); ip != "" {
+			return "ip:" + ip
+		}
+	case IdentityUser:
+		if id := c.GetInt64("userID"); id != 0 {
+			return "user:" + strconv.FormatInt(id, 10)
+		}
+	case IdentityAPIKey:
+		// hashed, so API keys do not end up in Redis
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			sum := sha256.Sum256([]byte(key))
+			return "api_key:" + hex.EncodeToString(sum[:])
+		}
+	}
+	return ""
+}
+
+// RateLimit limits requests as described by the policies that match their
+// route. It reports the most restrictive budget in RateLimit-* headers and
+// answers requests over budget with 429 and Retry-After. Requests are let
+// through if the limiter fails, so an outage of Redis does not take the
+// service down with it.
+func RateLimit(limiter RateLimiter, policies []RateLimitPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var (
+			tightest *ratelimit.Result
+			policy   RateLimitPolicy
+		)
+		for _, p := range policies {
+			if !p.matches(c.Request.Method, c.FullPath()) {
+				continue
+			}
+			id := p.identity(c)
+			if id == "" {
+				continue
+			}
+			res, err := limiter.Allow(c.Request.Context(), p.Name+":"+id, p.Limit)
+			if err != nil {
+				_ = c.Error(err)
+				continue
+			}
+			if tighter(res, tightest) {
+				tightest, policy = res, p
+			}
+		}
+		if tightest == nil {
+			c.Next()
+			return
+		}
+
+		h := c.Writer.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(policy.Limit.Requests))
+		h.Set("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+		h.Set("RateLimit-Reset", strconv.Itoa(seconds(tightest.ResetAfter)))
+		h.Set("RateLimit-Policy", strconv.Itoa(policy.Limit.Requests)+";w="+strconv.Itoa(seconds(policy.Limit.Period)))
+		if !tightest.Allowed {
+			h.Set("Retry-After", strconv.Itoa(seconds(tightest.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// tighter reports whether res is more restrictive than other: it is denied
+// for longer, or allowed with fewer requests to spare.
+func tighter(res, other *ratelimit.Result) bool {
+	switch {
+	case other == nil:
+		return true
+	case res.Allowed != other.Allowed:
+		return !res.Allowed
+	case !res.Allowed:
+		return res.RetryAfter > other.RetryAfter
+	default:
+		return res.Remaining < other.Remaining
+	}
+}
+
+// splitRateLimitPolicies separates the policies by user, which can only be
+// applied once the request is authenticated, from the others.
+func splitRateLimitPolicies(policies []RateLimitPolicy) (anonymous, byUser []RateLimitPolicy) {
+	for _, p := range policies {
+		if p.Identity == IdentityUser {
+			byUser = append(byUser, p)
+		} else {
+			anonymous = append(anonymous, p)
+		}
+	}
+	return anonymous, byUser
+}
+
+// seconds rounds d up to whole seconds, as the headers count in seconds.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,200 @@
+package http_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/auth"
+	authMocks "github.com/enson89/user-service-go/internal/auth/mocks"
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/ratelimit"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+var loginLimit = httptransport.RateLimitPolicy{
+	Name:     "login",
+	Routes:   []string{"POST /v1/login"},
+	Identity: httptransport.IdentityIP,
+	Limit:    ratelimit.Limit{Requests: 5, Period: time.Minute},
+}
+
+func rateLimitedRouter(limiter *httphandlermocks.MockRateLimiter, policies ...httptransport.RateLimitPolicy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(httptransport.RateLimit(limiter, policies))
+	r.POST("/v1/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/v1/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestRateLimit_Allowed(t *testing.T) {
+	limiter := new(httphandlermocks.MockRateLimiter)
+	r := rateLimitedRouter(limiter, loginLimit)
+
+	limiter.On("Allow", mock.Anything, "login:ip:10.0.0.1", loginLimit.Limit).
+		Return(&ratelimit.Result{Allowed: true, Remaining: 4, ResetAfter: 11500 * time.Millisecond}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/login", nil)
+	req.RemoteAddr = "10.0.0.1:52100"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "4", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "12", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "5;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	// other routes are not limited
+	req = httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	limiter.AssertNumberOfCalls(t, "Allow", 1)
+}
+
+func TestRateLimit_Denied(t *testing.T) {
+	limiter := new(httphandlermocks.MockRateLimiter)
+	global := httptransport.RateLimitPolicy{
+		Name:     "global",
+		Routes:   []string{"*"},
+		Identity: httptransport.IdentityIP,
+		Limit:    ratelimit.Limit{Requests: 100, Period: time.Minute},
+	}
+	r := rateLimitedRouter(limiter, global, loginLimit)
+
+	limiter.On("Allow", mock.Anything, "global:ip:10.0.0.1", global.Limit).
+		Return(&ratelimit.Result{Allowed: true, Remaining: 97, ResetAfter: time.Second}, nil)
+	limiter.On("Allow", mock.Anything, "login:ip:10.0.0.1", loginLimit.Limit).
+		Return(&ratelimit.Result{Allowed: false, RetryAfter: 2100 * time.Millisecond, ResetAfter: time.Minute}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/login", nil)
+	req.RemoteAddr = "10.0.0.1:52100"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// the budget of the login policy is reported
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3", w.Header().Get("Retry-After"))
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+}
+
+func TestRateLimit_APIKey(t *testing.T) {
+	limiter := new(httphandlermocks.MockRateLimiter)
+	policy := httptransport.RateLimitPolicy{
+		Name:     "api",
+		Routes:   []string{"/v1/health"},
+		Identity: httptransport.IdentityAPIKey,
+		Limit:    ratelimit.Limit{Requests: 1000, Period: time.Hour},
+	}
+	r := rateLimitedRouter(limiter, policy)
+
+	// keys are hashed
+	sum := sha256.Sum256([]byte("secret-key"))
+	limiter.On("Allow", mock.Anything, "api:api_key:"+hex.EncodeToString(sum[:]), policy.Limit).
+		Return(&ratelimit.Result{Allowed: true, Remaining: 999}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	req.Header.Set(httptransport.APIKeyHeader, "secret-key")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, "999", w.Header().Get("RateLimit-Remaining"))
+
+	// requests without an API key are not limited by the policy
+	req = httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	limiter.AssertNumberOfCalls(t, "Allow", 1)
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	limiter := new(httphandlermocks.MockRateLimiter)
+	r := rateLimitedRouter(limiter, loginLimit)
+
+	limiter.On("Allow", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("redis down"))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/login", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestRouter_RateLimitsByUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := new(httphandlermocks.MockRateLimiter)
+	mockSvc := new(httphandlermocks.MockUserService)
+	key := auth.NewHMACKey("test", []byte("test-secret"))
+	keys := auth.NewStaticKeyring(key)
+	store := new(authMocks.MockSessionStore)
+	policy := httptransport.RateLimitPolicy{
+		Name:     "api",
+		Routes:   []string{"*"},
+		Identity: httptransport.IdentityUser,
+		Limit:    ratelimit.Limit{Requests: 60, Period: time.Minute},
+	}
+	r, err := httptransport.NewRouter(mockSvc, keys, auth.TokenOptions{}, store,
+		httptransport.WithRateLimit(limiter, []httptransport.RateLimitPolicy{policy}))
+	require.NoError(t, err)
+
+	limiter.On("Allow", mock.Anything, "api:user:10", policy.Limit).
+		Return(&ratelimit.Result{Allowed: false, RetryAfter: time.Second}, nil)
+
+	token, err := auth.GenerateToken(&model.User{ID: 10, Role: "user"}, key, auth.TokenOptions{}, time.Hour)
+	require.NoError(t, err)
+	store.On("IsBlacklisted", mock.Anything, token).Return(false, nil)
+	store.On("RevokedBefore", mock.Anything, int64(10)).Return(time.Time{}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	// anonymous requests are not limited by user
+	req = httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	limiter.AssertNumberOfCalls(t, "Allow", 1)
+}
+
+func TestRouter_RateLimitIgnoresForgedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limiter := new(httphandlermocks.MockRateLimiter)
+	mockSvc := new(httphandlermocks.MockUserService)
+	r, err := httptransport.NewRouter(mockSvc, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("test-secret"))),
+		auth.TokenOptions{}, nil, httptransport.WithRateLimit(limiter, []httptransport.RateLimitPolicy{loginLimit}))
+	require.NoError(t, err)
+
+	limiter.On("Allow", mock.Anything, "login:ip:10.0.0.1", loginLimit.Limit).
+		Return(&ratelimit.Result{Allowed: false, RetryAfter: time.Minute}, nil)
+
+	// rotating the header does not buy a fresh bucket
+	for _, forged := range []string{"203.0.113.1", "203.0.113.2", "198.51.100.7"} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/login", nil)
+		req.RemoteAddr = "10.0.0.1:52100"
+		req.Header.Set("X-Forwarded-For", forged)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code, forged)
+	}
+	limiter.AssertNumberOfCalls(t, "Allow", 3)
+	mockSvc.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything)
+}
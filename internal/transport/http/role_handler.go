@@ -0,0 +1,174 @@
+package http
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRoles godoc
+// @Summary      List roles
+// @Description  List every role with the permissions it grants, without those inherited from its parent (requires roles:read)
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   model.Role
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/roles [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles, err := h.svc.ListRoles(getContext(c))
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateRole godoc
+// @Summary      Create a role
+// @Description  Create a role granting the given permissions, and those of its parent role if it has one (requires roles:manage)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.CreateRoleRequest  true  "Role"
+// @Success      201      {object}  model.Role
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/roles [post]
+// @Security     ApiKeyAuth
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role, err := h.svc.CreateRole(getContext(c), req.Name, req.Description, req.Parent, req.Permissions)
+	if err != nil {
+		// an unknown parent is a bad request, not a missing resource
+		if errors.Is(err, service.ErrRoleNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parent role not found"})
+			return
+		}
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// ListUserRoles godoc
+// @Summary      List the roles of a user
+// @Description  List the roles granted to a user, without those they inherit (requires roles:read)
+// @Tags         admin
+// @Produce      json
+// @Param        id   path      int  true  "User ID"
+// @Success      200  {array}   string
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      403  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /admin/users/{id}/roles [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListUserRoles(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	roles, err := h.svc.UserRoles(getContext(c), id)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GrantRole godoc
+// @Summary      Grant a role
+// @Description  Grant a role to a user. Their access tokens are revoked, so the next ones carry the role (requires roles:manage)
+// @Tags         admin
+// @Accept       json
+// @Param        id       path      int                    true  "User ID"
+// @Param        payload  body      http.GrantRoleRequest  true  "Role"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/users/{id}/roles [post]
+// @Security     ApiKeyAuth
+func (h *Handler) GrantRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	var req GrantRoleRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err = h.svc.GrantRole(getContext(c), id, req.Role); err != nil {
+		roleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeRole godoc
+// @Summary      Revoke a role
+// @Description  Take a role from a user and revoke their access tokens. The admin role cannot be taken from the last admin (requires roles:manage)
+// @Tags         admin
+// @Param        id    path      int     true  "User ID"
+// @Param        role  path      string  true  "Role name"
+// @Success      204   "No Content"
+// @Failure      400   {object}  map[string]string
+// @Failure      401   {object}  map[string]string
+// @Failure      403   {object}  map[string]string
+// @Failure      404   {object}  map[string]string
+// @Failure      409   {object}  map[string]string
+// @Failure      500   {object}  map[string]string
+// @Router       /admin/users/{id}/roles/{role} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RevokeRole(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+	if err = h.svc.RevokeRole(getContext(c), id, c.Param("role")); err != nil {
+		roleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// roleError answers a failed role request.
+func roleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRBACDisabled),
+		errors.Is(err, service.ErrRoleNotFound),
+		errors.Is(err, service.ErrRoleNotHeld):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, sql.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, service.ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRoleExists), errors.Is(err, service.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,137 @@
+package http_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_CreateRole(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusCreated},
+		{"name taken", service.ErrRoleExists, http.StatusConflict},
+		{"unknown parent", service.ErrRoleNotFound, http.StatusBadRequest},
+		{"unknown permission", service.ErrUnknownPermission, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var role *model.Role
+			if tc.err == nil {
+				role = &model.Role{ID: 3, Name: "support", Permissions: []string{model.PermissionUsersUnlock}}
+			}
+			mockSvc.On("CreateRole", mock.Anything, "support", "Helps users", "user", []string{model.PermissionUsersUnlock}).
+				Return(role, tc.err)
+
+			buf, _ := json.Marshal(httptransport.CreateRoleRequest{
+				Name: "support", Description: "Helps users", Parent: "user",
+				Permissions: []string{model.PermissionUsersUnlock},
+			})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/roles", bytes.NewBuffer(buf))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.CreateRole(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_ListUserRoles(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("UserRoles", mock.Anything, int64(10)).Return([]string{"admin", "user"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "10"}}
+
+	handler.ListUserRoles(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["admin","user"]`, w.Body.String())
+}
+
+func TestHandler_GrantRole(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"no such role", service.ErrRoleNotFound, http.StatusNotFound},
+		{"no such user", sql.ErrNoRows, http.StatusNotFound},
+		{"rbac off", service.ErrRBACDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("GrantRole", mock.Anything, int64(10), "admin").Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "10"}}
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/users/10/roles",
+				bytes.NewBufferString(`{"role":"admin"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.GrantRole(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_RevokeRole(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusNoContent},
+		{"not held", service.ErrRoleNotHeld, http.StatusNotFound},
+		{"last admin", service.ErrLastAdmin, http.StatusConflict},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			mockSvc.On("RevokeRole", mock.Anything, int64(10), "admin").Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: "10"}, {Key: "role", Value: "admin"}}
+
+			handler.RevokeRole(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
@@ -2,15 +2,71 @@ package http
 
 import (
 	"github.com/enson89/user-service-go/internal/auth"
+	"github.com/enson89/user-service-go/internal/model"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
+// RouterOption configures optional middleware of the router.
+type RouterOption func(*routerConfig)
+
+type routerConfig struct {
+	limiter        RateLimiter
+	rateLimits     []RateLimitPolicy
+	tokenVersions  auth.TokenVersions
+	trustedProxies []string
+}
+
+// WithRateLimit limits requests with limiter as described by policies.
+func WithRateLimit(limiter RateLimiter, policies []RateLimitPolicy) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.limiter = limiter
+		cfg.rateLimits = policies
+	}
+}
+
+// WithTokenVersions refuses access tokens that do not carry the user's
+// current token version.
+func WithTokenVersions(versions auth.TokenVersions) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.tokenVersions = versions
+	}
+}
+
+// WithTrustedProxies trusts the X-Forwarded-For headers set by proxies, IPs
+// or CIDR ranges, in front of the service. Without it the client IP is the
+// peer address of the connection, as headers sent by anyone else can be
+// forged to dodge lockouts and rate limits by IP.
+func WithTrustedProxies(proxies []string) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.trustedProxies = proxies
+	}
+}
+
 // NewRouter sets up routes and middleware
-func NewRouter(svc UserService, jwtSecret []byte, sessionStore auth.SessionStore) *gin.Engine {
+func NewRouter(svc UserService, keys *auth.Keyring, tokenOpts auth.TokenOptions, sessionStore auth.SessionStore,
+	opts ...RouterOption,
+) (*gin.Engine, error) {
+	var cfg routerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	anonymousLimits, userLimits := splitRateLimitPolicies(cfg.rateLimits)
+
 	h := NewHandler(svc)
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(Locale())
+	r.Use(ClientIP())
+	r.Use(UserAgent())
+	if len(anonymousLimits) > 0 {
+		r.Use(RateLimit(cfg.limiter, anonymousLimits))
+	}
+
+	r.GET("/.well-known/jwks.json", JWKS(keys))
 
 	v1 := r.Group("/v1")
 	v1.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -19,18 +75,83 @@ func NewRouter(svc UserService, jwtSecret []byte, sessionStore auth.SessionStore
 	v1.GET("/health", h.HealthCheck)
 	v1.POST("/signup", h.SignUp)
 	v1.POST("/login", h.Login)
+	v1.POST("/login/mfa", h.LoginMFA)
+	v1.POST("/login/magic-link", h.RequestMagicLink)
+	v1.POST("/login/magic-link/consume", h.LoginMagicLink)
+	v1.POST("/token/refresh", h.Refresh)
+	v1.POST("/password/forgot", h.ForgotPassword)
+	v1.POST("/password/reset", h.ResetPassword)
+	v1.POST("/email/verify", h.VerifyEmail)
+	v1.POST("/email/verify/resend", h.ResendVerification)
+	v1.POST("/email/change/confirm", h.ConfirmEmailChange)
+	v1.POST("/invitations/accept", h.AcceptInvitation)
+	v1.POST("/webauthn/login/begin", h.BeginWebAuthnLogin)
+	v1.POST("/webauthn/login/finish", h.FinishWebAuthnLogin)
 
 	// Protected
 	authGroup := v1.Group("/")
-	authGroup.Use(auth.AuthenticationMiddleware(jwtSecret, sessionStore))
+	authGroup.Use(auth.AuthenticationMiddleware(keys, tokenOpts, sessionStore, cfg.tokenVersions))
+	authGroup.Use(Tenant())
+	if len(userLimits) > 0 {
+		authGroup.Use(RateLimit(cfg.limiter, userLimits))
+	}
 	{
+		authGroup.POST("/logout", h.Logout)
+		authGroup.POST("/logout-all", h.LogoutAll)
 		authGroup.GET("/profile", h.Profile)
-		authGroup.PUT("/profile", h.UpdateProfile)
+		authGroup.GET("/sessions", h.ListSessions)
+		authGroup.DELETE("/sessions/:id", h.RevokeSession)
+
+		// Not for restricted tokens of unverified users
+		verified := authGroup.Group("/")
+		verified.Use(auth.RejectRestricted())
+		verified.PUT("/profile", h.UpdateProfile)
+		verified.PUT("/profile/password", h.ChangePassword)
+		verified.POST("/profile/email", h.RequestEmailChange)
+		verified.POST("/profile/invitations/accept", h.AcceptInvitationAsUser)
+		verified.POST("/mfa/totp/enroll", h.EnrollTOTP)
+		verified.POST("/mfa/totp/confirm", h.ConfirmTOTP)
+		verified.POST("/mfa/totp/disable", h.DisableMFA)
+		verified.POST("/webauthn/register/begin", h.BeginWebAuthnRegistration)
+		verified.POST("/webauthn/register/finish", h.FinishWebAuthnRegistration)
+		verified.GET("/webauthn/credentials", h.ListWebAuthnCredentials)
+		verified.DELETE("/webauthn/credentials/:id", h.DeleteWebAuthnCredential)
+		verified.POST("/orgs", h.CreateOrganization)
+		verified.GET("/orgs", h.ListOrganizations)
+		verified.POST("/orgs/:id/token", h.SwitchOrganization)
+
+		// Within an organization, with a token scoped to it
+		org := verified.Group("/orgs/:id")
+		org.Use(auth.RequireOrg("id"))
+		org.GET("/members", auth.RequirePermission(model.PermissionMembersRead), h.ListMembers)
+		org.PUT("/members/:user_id", auth.RequirePermission(model.PermissionMembersManage), h.SetMemberRole)
+		org.DELETE("/members/:user_id", auth.RequirePermission(model.PermissionMembersManage), h.RemoveMember)
+		org.POST("/invitations", auth.RequirePermission(model.PermissionMembersManage), h.CreateInvitation)
+		org.GET("/invitations", auth.RequirePermission(model.PermissionMembersManage), h.ListInvitations)
+		org.DELETE("/invitations/:invitation_id", auth.RequirePermission(model.PermissionMembersManage), h.RevokeInvitation)
 
-		// Admin-only
-		admin := authGroup.Group("/")
-		admin.Use(auth.RequireRole("admin"))
-		admin.DELETE("/user/:id", h.DeleteUser)
+		// Admin-only, by the permissions the caller's roles grant
+		verified.DELETE("/user/:id", auth.RequirePermission(model.PermissionUsersDelete), h.DeleteUser)
+		verified.POST("/admin/keys/rotate", auth.RequirePermission(model.PermissionKeysRotate), h.RotateSigningKey)
+		verified.GET("/admin/users/:id", auth.RequirePermission(model.PermissionUsersRead), h.GetUser)
+		verified.POST("/admin/users/:id/unlock", auth.RequirePermission(model.PermissionUsersUnlock), h.UnlockUser)
+		verified.GET("/admin/roles", auth.RequirePermission(model.PermissionRolesRead), h.ListRoles)
+		verified.POST("/admin/roles", auth.RequirePermission(model.PermissionRolesManage), h.CreateRole)
+		verified.GET("/admin/users/:id/roles", auth.RequirePermission(model.PermissionRolesRead), h.ListUserRoles)
+		verified.POST("/admin/users/:id/roles", auth.RequirePermission(model.PermissionRolesManage), h.GrantRole)
+		verified.DELETE("/admin/users/:id/roles/:role", auth.RequirePermission(model.PermissionRolesManage), h.RevokeRole)
+		verified.GET("/admin/groups", auth.RequirePermission(model.PermissionGroupsRead), h.ListGroups)
+		verified.POST("/admin/groups", auth.RequirePermission(model.PermissionGroupsManage), h.CreateGroup)
+		verified.GET("/admin/groups/:id", auth.RequirePermission(model.PermissionGroupsRead), h.GetGroup)
+		verified.PATCH("/admin/groups/:id", auth.RequirePermission(model.PermissionGroupsManage), h.RenameGroup)
+		verified.DELETE("/admin/groups/:id", auth.RequirePermission(model.PermissionGroupsManage), h.DeleteGroup)
+		verified.PUT("/admin/groups/:id/members/:user_id", auth.RequirePermission(model.PermissionGroupsManage), h.AddGroupMember)
+		verified.DELETE("/admin/groups/:id/members/:user_id", auth.RequirePermission(model.PermissionGroupsManage), h.RemoveGroupMember)
+		verified.PUT("/admin/groups/:id/subgroups/:subgroup_id", auth.RequirePermission(model.PermissionGroupsManage), h.AddSubgroup)
+		verified.DELETE("/admin/groups/:id/subgroups/:subgroup_id", auth.RequirePermission(model.PermissionGroupsManage), h.RemoveSubgroup)
+		// granting roles to groups is role management
+		verified.POST("/admin/groups/:id/roles", auth.RequirePermission(model.PermissionRolesManage), h.GrantGroupRole)
+		verified.DELETE("/admin/groups/:id/roles/:role", auth.RequirePermission(model.PermissionRolesManage), h.RevokeGroupRole)
 	}
-	return r
+	return r, nil
 }
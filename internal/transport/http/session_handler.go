@@ -0,0 +1,62 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ListSessions godoc
+// @Summary      List my sessions
+// @Description  List the devices the current user is logged in on. The caller's own session is marked as current.
+// @Tags         sessions
+// @Produce      json
+// @Success      200      {array}   model.Session
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /sessions [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListSessions(c *gin.Context) {
+	sessions, err := h.svc.ListSessions(getContext(c), c.GetInt64("userID"))
+	if err != nil {
+		sessionError(c, err)
+		return
+	}
+	current := c.GetString("sessionID")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary      End a session
+// @Description  Log the current user out of one of their sessions, e.g. on a lost device. Its access and refresh tokens stop working immediately.
+// @Tags         sessions
+// @Param        id   path      string  true  "Session ID"
+// @Success      204  "No Content"
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /sessions/{id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) RevokeSession(c *gin.Context) {
+	if err := h.svc.RevokeSession(getContext(c), c.GetInt64("userID"), c.Param("id")); err != nil {
+		sessionError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sessionError answers a failed session request.
+func sessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSessionsDisabled), errors.Is(err, service.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
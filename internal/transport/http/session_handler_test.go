@@ -0,0 +1,69 @@
+package http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_ListSessions(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("ListSessions", mock.Anything, int64(10)).
+		Return([]model.Session{{ID: "s1", UserAgent: "curl"}, {ID: "s2", UserAgent: "Firefox"}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/sessions", nil)
+	c.Set("userID", int64(10))
+	c.Set("sessionID", "s2")
+
+	handler.ListSessions(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sessions []model.Session
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	require.Len(t, sessions, 2)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
+
+func TestHandler_RevokeSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"revoked", nil, http.StatusNoContent},
+		{"not mine", service.ErrSessionNotFound, http.StatusNotFound},
+		{"feature off", service.ErrSessionsDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+			mockSvc.On("RevokeSession", mock.Anything, int64(10), "s1").Return(tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/v1/sessions/s1", nil)
+			c.Params = gin.Params{{Key: "id", Value: "s1"}}
+			c.Set("userID", int64(10))
+
+			handler.RevokeSession(c)
+			assert.Equal(t, tc.status, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
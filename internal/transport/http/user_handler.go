@@ -2,18 +2,76 @@ package http
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/enson89/user-service-go/internal/auth"
 	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
 )
 
 type UserService interface {
 	SignUp(ctx context.Context, email, password string) (*model.User, error)
-	Login(ctx context.Context, email, password string) (string, error)
+	Login(ctx context.Context, email, password string) (*model.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*model.TokenPair, error)
+	Logout(ctx context.Context, token, refreshToken string) error
+	LogoutAll(ctx context.Context, userID int64) error
+	ListSessions(ctx context.Context, userID int64) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID int64, id string) error
+	RotateSigningKey(ctx context.Context) (string, error)
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
+	ChangePassword(ctx context.Context, userID int64, current, newPassword string) (*model.TokenPair, error)
+	VerifyEmail(ctx context.Context, token string) error
+	ResendVerification(ctx context.Context, email string) error
+	RequestEmailChange(ctx context.Context, userID int64, current, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	LoginMFA(ctx context.Context, mfaToken, code string) (*model.TokenPair, error)
+	RequestMagicLink(ctx context.Context, email string) error
+	LoginMagicLink(ctx context.Context, token string) (*model.TokenPair, error)
+	EnrollTOTP(ctx context.Context, userID int64, password string) (*model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID int64, password, code string) error
+	BeginWebAuthnRegistration(ctx context.Context, userID int64, password, code string) (*protocol.CredentialCreation, error)
+	FinishWebAuthnRegistration(ctx context.Context, userID int64, response []byte) (*model.WebAuthnCredential, error)
+	BeginWebAuthnLogin(ctx context.Context, email string) (string, *protocol.CredentialAssertion, error)
+	FinishWebAuthnLogin(ctx context.Context, sessionID string, response []byte) (*model.TokenPair, error)
+	ListWebAuthnCredentials(ctx context.Context, userID int64) ([]model.WebAuthnCredential, error)
+	DeleteWebAuthnCredential(ctx context.Context, userID, id int64) error
 	GetProfile(ctx context.Context, id int64) (*model.User, error)
+	GetUser(ctx context.Context, id int64) (*model.User, error)
 	DeleteUser(ctx context.Context, id int64) error
+	UnlockUser(ctx context.Context, id int64) error
+	ListRoles(ctx context.Context) ([]model.Role, error)
+	CreateRole(ctx context.Context, name, description, parent string, permissions []string) (*model.Role, error)
+	UserRoles(ctx context.Context, userID int64) ([]string, error)
+	GrantRole(ctx context.Context, userID int64, role string) error
+	RevokeRole(ctx context.Context, userID int64, role string) error
+	CreateOrganization(ctx context.Context, userID int64, slug, name string) (*model.Organization, error)
+	ListOrganizations(ctx context.Context, userID int64) ([]model.Organization, error)
+	SwitchOrganization(ctx context.Context, userID, orgID int64) (*model.TokenPair, error)
+	ListMembers(ctx context.Context, orgID int64) ([]model.Membership, error)
+	SetMemberRole(ctx context.Context, orgID, userID int64, role string) error
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+	InviteMember(ctx context.Context, orgID, inviterID int64, email, role string) (*model.Invitation, error)
+	ListInvitations(ctx context.Context, orgID int64) ([]model.Invitation, error)
+	RevokeInvitation(ctx context.Context, orgID, id int64) error
+	AcceptInvitation(ctx context.Context, userID int64, token, password string) (*model.Membership, error)
+	ListGroups(ctx context.Context) ([]model.Group, error)
+	GetGroup(ctx context.Context, id int64) (*model.Group, error)
+	CreateGroup(ctx context.Context, name, description string) (*model.Group, error)
+	RenameGroup(ctx context.Context, id int64, name string) (*model.Group, error)
+	DeleteGroup(ctx context.Context, id int64) error
+	AddGroupMember(ctx context.Context, groupID, userID int64) error
+	RemoveGroupMember(ctx context.Context, groupID, userID int64) error
+	AddSubgroup(ctx context.Context, groupID, subgroupID int64) error
+	RemoveSubgroup(ctx context.Context, groupID, subgroupID int64) error
+	GrantGroupRole(ctx context.Context, groupID int64, role string) error
+	RevokeGroupRole(ctx context.Context, groupID int64, role string) error
 	UpdateUser(ctx context.Context, id int64, newName string) (*model.User, error)
 }
 
@@ -39,13 +97,13 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 
 // SignUp godoc
 // @Summary      Register a new user
-// @Description  Create a new user account with email and password
+// @Description  Create a new user account with email and password. A password that breaks the password policy is refused with the violated rules.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        payload  body      http.SignUpRequest  true  "Signup payload"
 // @Success      201      {object}  model.User
-// @Failure      400      {object}  map[string]string
+// @Failure      400      {object}  http.PasswordPolicyErrorResponse
 // @Router       /signup [post]
 func (h *Handler) SignUp(c *gin.Context) {
 	var req SignUpRequest
@@ -55,6 +113,9 @@ func (h *Handler) SignUp(c *gin.Context) {
 	}
 	user, err := h.svc.SignUp(getContext(c), req.Email, req.Password)
 	if err != nil {
+		if passwordPolicyViolation(c, err) {
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -67,13 +128,15 @@ func (h *Handler) SignUp(c *gin.Context) {
 
 // Login godoc
 // @Summary      Authenticate user
-// @Description  Log in a user and return a JWT token
+// @Description  Log in a user and return a JWT access token and a refresh token, or an mfa_token to complete at /login/mfa if the user has MFA enabled
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        payload  body      http.LoginRequest  true  "Login payload"
-// @Success      200      {object}  map[string]string
+// @Success      200      {object}  model.TokenPair
 // @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
 // @Router       /login [post]
 func (h *Handler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -81,12 +144,107 @@ func (h *Handler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	token, err := h.svc.Login(getContext(c), req.Email, req.Password)
+	tokens, err := h.svc.Login(getContext(c), req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, service.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if loginLocked(c, err) {
+			return
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// loginLocked answers with 429 if err refuses a login for a lockout, with
+// Retry-After set to when it ends if known, and reports whether it did.
+func loginLocked(c *gin.Context, err error) bool {
+	if !errors.Is(err, service.ErrLoginLocked) {
+		return false
+	}
+	var le *service.LoginLockedError
+	if errors.As(err, &le) {
+		c.Header("Retry-After", strconv.Itoa(seconds(le.RetryAfter)))
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	return true
+}
+
+// Refresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access token and a rotated refresh token
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.RefreshRequest  true  "Refresh payload"
+// @Success      200      {object}  model.TokenPair
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Router       /token/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.svc.Refresh(getContext(c), req.RefreshToken)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout godoc
+// @Summary      Log out
+// @Description  Revoke the access token presented with this request and, if given, its refresh token
+// @Tags         auth
+// @Accept       json
+// @Param        payload  body      http.LogoutRequest  false  "Refresh token to revoke"
+// @Success      204      "No Content"
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /logout [post]
+// @Security     ApiKeyAuth
+func (h *Handler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	// the body is optional
+	if c.Request != nil && c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.svc.Logout(getContext(c), c.GetString("token"), req.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidToken) || errors.Is(err, service.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary      Log out everywhere
+// @Description  Revoke every token issued to the authenticated user
+// @Tags         auth
+// @Success      204      "No Content"
+// @Failure      401      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /logout-all [post]
+// @Security     ApiKeyAuth
+func (h *Handler) LogoutAll(c *gin.Context) {
+	if err := h.svc.LogoutAll(getContext(c), c.GetInt64("userID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
 }
 
 // Profile godoc
@@ -121,13 +279,15 @@ func (h *Handler) Profile(c *gin.Context) {
 
 // DeleteUser godoc
 // @Summary      Delete a user
-// @Description  Delete a user by ID (admin only)
+// @Description  Delete a user by ID (requires users:delete). With a token scoped to an organization, only users who are members of it and of no other organization can be deleted. The last admin cannot be deleted.
 // @Tags         users
 // @Param        id       path      int  true  "User ID"
 // @Success      200      "No Content"
 // @Failure      400      {object}  map[string]string
 // @Failure      401      {object}  map[string]string
 // @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
 // @Failure      500      {object}  map[string]string
 // @Router       /user/{id} [delete]
 // @Security     ApiKeyAuth
@@ -140,6 +300,14 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 	if err = h.svc.DeleteUser(getContext(c), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		if errors.Is(err, service.ErrLastAdmin) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -172,6 +340,31 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// RotateSigningKey godoc
+// @Summary      Rotate the token signing key
+// @Description  Sign new tokens with the next configured key; tokens signed with the previous key stay valid until it retires (requires keys:rotate)
+// @Tags         auth
+// @Produce      json
+// @Success      200      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      409      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /admin/keys/rotate [post]
+// @Security     ApiKeyAuth
+func (h *Handler) RotateSigningKey(c *gin.Context) {
+	kid, err := h.svc.RotateSigningKey(getContext(c))
+	if err != nil {
+		if errors.Is(err, auth.ErrNoStandbyKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"kid": kid})
+}
+
 // getContext safely retrieves the request context.
 func getContext(c *gin.Context) context.Context {
 	if c.Request != nil && c.Request.Context() != nil {
@@ -2,24 +2,34 @@ package http_test
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/enson89/user-service-go/internal/auth"
 	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
 	httptransport "github.com/enson89/user-service-go/internal/transport/http"
 	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
 )
 
 func setupRouter(mockSvc *httphandlermocks.MockUserService) *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return httptransport.NewRouter(mockSvc, []byte("test-secret"), nil)
+	r, err := httptransport.NewRouter(mockSvc, auth.NewStaticKeyring(auth.NewHMACKey("test", []byte("test-secret"))),
+		auth.TokenOptions{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	return r
 }
 
 func TestHandler_HealthCheck(t *testing.T) {
@@ -36,6 +46,21 @@ func TestHandler_HealthCheck(t *testing.T) {
 	assert.Equal(t, "ok", resp["status"])
 }
 
+func TestHandler_JWKS(t *testing.T) {
+	router := setupRouter(nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp auth.JWKSet
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	// the HMAC test key must never be published
+	assert.Empty(t, resp.Keys)
+}
+
 func TestHandler_SignUp(t *testing.T) {
 	mockSvc := new(httphandlermocks.MockUserService)
 	router := setupRouter(mockSvc)
@@ -65,6 +90,25 @@ func TestHandler_SignUp(t *testing.T) {
 	mockSvc.AssertExpectations(t)
 }
 
+func TestHandler_SignUp_WeakPassword(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("SignUp", mock.Anything, "new@x.com", "new").
+		Return(nil, &service.PasswordPolicyError{Violations: []string{service.ViolationTooShort}})
+
+	buf, _ := json.Marshal(map[string]string{"email": "new@x.com", "password": "new"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/signup", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp httptransport.PasswordPolicyErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"too_short"}, resp.Violations)
+}
+
 func TestHandler_Login(t *testing.T) {
 	mockSvc := new(httphandlermocks.MockUserService)
 	router := setupRouter(mockSvc)
@@ -74,7 +118,7 @@ func TestHandler_Login(t *testing.T) {
 
 	mockSvc.
 		On("Login", mock.Anything, "ok@x.com", "pw").
-		Return("token123", nil)
+		Return(&model.TokenPair{AccessToken: "token123", RefreshToken: "refresh123", TokenType: "Bearer", ExpiresIn: 60}, nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/v1/login", bytes.NewBuffer(buf))
 	req.Header.Set("Content-Type", "application/json")
@@ -82,10 +126,134 @@ func TestHandler_Login(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var resp map[string]string
+	var resp map[string]interface{}
 	_ = json.Unmarshal(w.Body.Bytes(), &resp)
 	assert.Equal(t, "token123", resp["token"])
+	assert.Equal(t, "refresh123", resp["refresh_token"])
+
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Login_Locked(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	mockSvc.On("Login", mock.Anything, "ok@x.com", "pw").
+		Return(nil, &service.LoginLockedError{RetryAfter: 90500 * time.Millisecond})
+
+	buf, _ := json.Marshal(map[string]string{"email": "ok@x.com", "password": "pw"})
+	req := httptest.NewRequest(http.MethodPost, "/v1/login", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "91", w.Header().Get("Retry-After"))
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Refresh(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	body := map[string]string{"refresh_token": "refresh123"}
+	buf, _ := json.Marshal(body)
+
+	mockSvc.
+		On("Refresh", mock.Anything, "refresh123").
+		Return(&model.TokenPair{AccessToken: "token456", RefreshToken: "refresh456", TokenType: "Bearer", ExpiresIn: 60}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/token/refresh", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "token456", resp["token"])
+	assert.Equal(t, "refresh456", resp["refresh_token"])
+
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Refresh_Invalid(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	body := map[string]string{"refresh_token": "stolen"}
+	buf, _ := json.Marshal(body)
+
+	mockSvc.
+		On("Refresh", mock.Anything, "stolen").
+		Return(nil, errors.New("refresh token reuse detected"))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/token/refresh", bytes.NewBuffer(buf))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
 
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Logout(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.
+		On("Logout", mock.Anything, "token123", "refresh123").
+		Return(nil)
+
+	buf, _ := json.Marshal(map[string]string{"refresh_token": "refresh123"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/logout", bytes.NewBuffer(buf))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("token", "token123")
+
+	handler.Logout(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_Logout_InvalidRefreshToken(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.
+		On("Logout", mock.Anything, "token123", "bogus").
+		Return(service.ErrInvalidRefreshToken)
+
+	buf, _ := json.Marshal(map[string]string{"refresh_token": "bogus"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/logout", bytes.NewBuffer(buf))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("token", "token123")
+
+	handler.Logout(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_LogoutAll(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.
+		On("LogoutAll", mock.Anything, int64(10)).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("userID", int64(10))
+
+	handler.LogoutAll(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
 	mockSvc.AssertExpectations(t)
 }
 
@@ -130,6 +298,76 @@ func TestHandler_DeleteUser(t *testing.T) {
 	mockSvc.AssertExpectations(t)
 }
 
+func TestHandler_DeleteUser_OtherOrganization(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	// the user belongs to another organization than the caller's
+	mockSvc.On("DeleteUser", mock.Anything, int64(11)).Return(sql.ErrNoRows)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "11"}}
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_DeleteUser_LastAdmin(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("DeleteUser", mock.Anything, int64(1)).Return(service.ErrLastAdmin)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.DeleteUser(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_RotateSigningKey(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.
+		On("RotateSigningKey", mock.Anything).
+		Return("k2", nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.RotateSigningKey(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]string
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "k2", resp["kid"])
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_RotateSigningKey_NoStandby(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.
+		On("RotateSigningKey", mock.Anything).
+		Return("", auth.ErrNoStandbyKey)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	handler.RotateSigningKey(c)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockSvc.AssertExpectations(t)
+}
+
 func TestHandler_UpdateProfile(t *testing.T) {
 	// Create mock service
 	mockSvc := new(httphandlermocks.MockUserService)
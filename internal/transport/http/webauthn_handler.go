@@ -0,0 +1,195 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/enson89/user-service-go/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// BeginWebAuthnLogin godoc
+// @Summary      Begin a passkey login
+// @Description  Get the options for navigator.credentials.get and the session_id to finish the login with. Without an email any discoverable credential is accepted.
+// @Tags         webauthn
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.WebAuthnLoginBeginRequest  false  "Optional email"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webauthn/login/begin [post]
+func (h *Handler) BeginWebAuthnLogin(c *gin.Context) {
+	var req WebAuthnLoginBeginRequest
+	// the body is optional
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sessionID, assertion, err := h.svc.BeginWebAuthnLogin(getContext(c), req.Email)
+	if err != nil {
+		webAuthnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "options": assertion})
+}
+
+// FinishWebAuthnLogin godoc
+// @Summary      Finish a passkey login
+// @Description  Verify the credential returned by navigator.credentials.get and return tokens
+// @Tags         webauthn
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.WebAuthnLoginFinishRequest  true  "Session ID and credential"
+// @Success      200      {object}  model.TokenPair
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webauthn/login/finish [post]
+func (h *Handler) FinishWebAuthnLogin(c *gin.Context) {
+	var req WebAuthnLoginFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := h.svc.FinishWebAuthnLogin(getContext(c), req.SessionID, req.Credential)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWebAuthnSession), errors.Is(err, service.ErrInvalidWebAuthnResponse),
+			errors.Is(err, service.ErrWebAuthnCloneDetected):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrEmailNotVerified):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			webAuthnError(c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// BeginWebAuthnRegistration godoc
+// @Summary      Begin registering a passkey
+// @Description  Get the options for navigator.credentials.create, confirmed with the current password and, if MFA is enabled, an authenticator or recovery code
+// @Tags         webauthn
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.WebAuthnRegisterBeginRequest  true  "Current password and second factor"
+// @Success      200      {object}  map[string]interface{}
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      403      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      429      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webauthn/register/begin [post]
+// @Security     ApiKeyAuth
+func (h *Handler) BeginWebAuthnRegistration(c *gin.Context) {
+	var req WebAuthnRegisterBeginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	creation, err := h.svc.BeginWebAuthnRegistration(getContext(c), c.GetInt64("userID"), req.CurrentPassword, req.Code)
+	if err != nil {
+		if loginLocked(c, err) {
+			return
+		}
+		webAuthnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, creation)
+}
+
+// FinishWebAuthnRegistration godoc
+// @Summary      Finish registering a passkey
+// @Description  Verify the credential returned by navigator.credentials.create and store it
+// @Tags         webauthn
+// @Accept       json
+// @Produce      json
+// @Param        payload  body      http.WebAuthnRegisterFinishRequest  true  "Credential"
+// @Success      201      {object}  model.WebAuthnCredential
+// @Failure      400      {object}  map[string]string
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webauthn/register/finish [post]
+// @Security     ApiKeyAuth
+func (h *Handler) FinishWebAuthnRegistration(c *gin.Context) {
+	var req WebAuthnRegisterFinishRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	credential, err := h.svc.FinishWebAuthnRegistration(getContext(c), c.GetInt64("userID"), req.Credential)
+	if err != nil {
+		webAuthnError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, credential)
+}
+
+// ListWebAuthnCredentials godoc
+// @Summary      List passkeys
+// @Description  List the passkeys and security keys registered by the current user
+// @Tags         webauthn
+// @Produce      json
+// @Success      200      {array}   model.WebAuthnCredential
+// @Failure      401      {object}  map[string]string
+// @Failure      404      {object}  map[string]string
+// @Failure      500      {object}  map[string]string
+// @Router       /webauthn/credentials [get]
+// @Security     ApiKeyAuth
+func (h *Handler) ListWebAuthnCredentials(c *gin.Context) {
+	creds, err := h.svc.ListWebAuthnCredentials(getContext(c), c.GetInt64("userID"))
+	if err != nil {
+		webAuthnError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, creds)
+}
+
+// DeleteWebAuthnCredential godoc
+// @Summary      Remove a passkey
+// @Description  Remove a passkey or security key of the current user
+// @Tags         webauthn
+// @Param        id   path      int  true  "Credential ID"
+// @Success      204  "No Content"
+// @Failure      400  {object}  map[string]string
+// @Failure      401  {object}  map[string]string
+// @Failure      404  {object}  map[string]string
+// @Failure      500  {object}  map[string]string
+// @Router       /webauthn/credentials/{id} [delete]
+// @Security     ApiKeyAuth
+func (h *Handler) DeleteWebAuthnCredential(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid credential ID"})
+		return
+	}
+	if err = h.svc.DeleteWebAuthnCredential(getContext(c), c.GetInt64("userID"), id); err != nil {
+		webAuthnError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// webAuthnError answers a failed WebAuthn request.
+func webAuthnError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrWebAuthnDisabled), errors.Is(err, service.ErrWebAuthnCredentialNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidWebAuthnSession), errors.Is(err, service.ErrInvalidWebAuthnResponse),
+		errors.Is(err, service.ErrInvalidMFACode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCurrentPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
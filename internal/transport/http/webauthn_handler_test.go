@@ -0,0 +1,191 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/enson89/user-service-go/internal/model"
+	"github.com/enson89/user-service-go/internal/service"
+	httptransport "github.com/enson89/user-service-go/internal/transport/http"
+	httphandlermocks "github.com/enson89/user-service-go/internal/transport/http/mocks"
+)
+
+func TestHandler_BeginWebAuthnLogin(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	router := setupRouter(mockSvc)
+
+	assertion := &protocol.CredentialAssertion{
+		Response: protocol.PublicKeyCredentialRequestOptions{Challenge: []byte("challenge"), RelyingPartyID: "example.com"},
+	}
+	mockSvc.On("BeginWebAuthnLogin", mock.Anything, "").Return("sess", assertion, nil)
+
+	// the body is optional
+	req := httptest.NewRequest(http.MethodPost, "/v1/webauthn/login/begin", nil)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		SessionID string                       `json:"session_id"`
+		Options   protocol.CredentialAssertion `json:"options"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, "sess", resp.SessionID)
+	assert.Equal(t, "example.com", resp.Options.Response.RelyingPartyID)
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_FinishWebAuthnLogin(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"bad signature", service.ErrInvalidWebAuthnResponse, http.StatusUnauthorized},
+		{"expired", service.ErrInvalidWebAuthnSession, http.StatusUnauthorized},
+		{"cloned", service.ErrWebAuthnCloneDetected, http.StatusUnauthorized},
+		{"unverified", service.ErrEmailNotVerified, http.StatusForbidden},
+		{"feature off", service.ErrWebAuthnDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			router := setupRouter(mockSvc)
+
+			var tokens *model.TokenPair
+			if tc.err == nil {
+				tokens = &model.TokenPair{AccessToken: "token123", TokenType: "Bearer", ExpiresIn: 60}
+			}
+			mockSvc.On("FinishWebAuthnLogin", mock.Anything, "sess", []byte(`{"id":"abc"}`)).Return(tokens, tc.err)
+
+			buf := []byte(`{"session_id":"sess","credential":{"id":"abc"}}`)
+			req := httptest.NewRequest(http.MethodPost, "/v1/webauthn/login/finish", bytes.NewBuffer(buf))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHandler_BeginWebAuthnRegistration(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, http.StatusOK},
+		{"wrong password", service.ErrInvalidCurrentPassword, http.StatusForbidden},
+		{"wrong code", service.ErrInvalidMFACode, http.StatusBadRequest},
+		{"locked out", service.ErrLoginLocked, http.StatusTooManyRequests},
+		{"feature off", service.ErrWebAuthnDisabled, http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			var creation *protocol.CredentialCreation
+			if tc.err == nil {
+				creation = &protocol.CredentialCreation{}
+			}
+			mockSvc.On("BeginWebAuthnRegistration", mock.Anything, int64(10), "secret", "123456").Return(creation, tc.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/v1/webauthn/register/begin",
+				bytes.NewBufferString(`{"current_password":"secret","code":"123456"}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Set("userID", int64(10))
+
+			handler.BeginWebAuthnRegistration(c)
+
+			assert.Equal(t, tc.want, w.Code)
+			mockSvc.AssertExpectations(t)
+		})
+	}
+
+	// a password is required
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/webauthn/register/begin", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", int64(10))
+
+	handler.BeginWebAuthnRegistration(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockSvc.AssertNotCalled(t, "BeginWebAuthnRegistration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandler_FinishWebAuthnRegistration(t *testing.T) {
+	mockSvc := new(httphandlermocks.MockUserService)
+	handler := httptransport.NewHandler(mockSvc)
+
+	mockSvc.On("FinishWebAuthnRegistration", mock.Anything, int64(10), []byte(`{"id":"abc"}`)).
+		Return(&model.WebAuthnCredential{ID: 3, UserID: 10, AttestationType: "none"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/webauthn/register/finish",
+		bytes.NewBufferString(`{"credential":{"id":"abc"}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("userID", int64(10))
+
+	handler.FinishWebAuthnRegistration(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var resp map[string]interface{}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	assert.Equal(t, float64(3), resp["id"])
+	assert.NotContains(t, resp, "public_key")
+	mockSvc.AssertExpectations(t)
+}
+
+func TestHandler_DeleteWebAuthnCredential(t *testing.T) {
+	tests := []struct {
+		name  string
+		param string
+		err   error
+		want  int
+	}{
+		{"ok", "3", nil, http.StatusNoContent},
+		{"not found", "3", service.ErrWebAuthnCredentialNotFound, http.StatusNotFound},
+		{"bad id", "abc", nil, http.StatusBadRequest},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockSvc := new(httphandlermocks.MockUserService)
+			handler := httptransport.NewHandler(mockSvc)
+
+			if tc.param == "3" {
+				mockSvc.On("DeleteWebAuthnCredential", mock.Anything, int64(10), int64(3)).Return(tc.err)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/v1/webauthn/credentials/"+tc.param, nil)
+			c.Params = gin.Params{{Key: "id", Value: tc.param}}
+			c.Set("userID", int64(10))
+
+			handler.DeleteWebAuthnCredential(c)
+
+			assert.Equal(t, tc.want, c.Writer.Status())
+			mockSvc.AssertExpectations(t)
+		})
+	}
+}
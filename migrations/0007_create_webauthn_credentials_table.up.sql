@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    credential_id      BYTEA       NOT NULL UNIQUE,
+    public_key         BYTEA       NOT NULL,
+    attestation_type   TEXT        NOT NULL DEFAULT '',
+    aaguid             BYTEA,
+    sign_count         BIGINT      NOT NULL DEFAULT 0,
+    transports         TEXT        NOT NULL DEFAULT '',
+    backup_eligible    BOOLEAN     NOT NULL DEFAULT FALSE,
+    backup_state       BOOLEAN     NOT NULL DEFAULT FALSE,
+    clone_detected_at  TIMESTAMPTZ,
+    last_used_at       TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+-- Loading a user's credentials for registration exclusion and login
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials (user_id);
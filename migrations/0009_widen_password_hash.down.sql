@@ -0,0 +1,3 @@
+-- Fails while any hash is longer than a bcrypt hash; reset those users first
+ALTER TABLE users
+    ALTER COLUMN password_hash TYPE VARCHAR(60);
@@ -0,0 +1,3 @@
+-- Room for PHC formatted hashes of other algorithms than bcrypt, e.g. Argon2id
+ALTER TABLE users
+    ALTER COLUMN password_hash TYPE VARCHAR(255);
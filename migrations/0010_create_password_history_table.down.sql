@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_password_history_user_id;
+DROP TABLE IF EXISTS password_history;
\ No newline at end of file
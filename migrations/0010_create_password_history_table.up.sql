@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS password_history (
+    id             BIGSERIAL PRIMARY KEY,
+    user_id        BIGINT       NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    password_hash  VARCHAR(255) NOT NULL,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+    );
+
+-- Listing and trimming a user's most recent passwords
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history (user_id, id DESC);
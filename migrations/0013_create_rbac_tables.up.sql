@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS roles (
+    id           BIGSERIAL PRIMARY KEY,
+    name         VARCHAR(50) NOT NULL UNIQUE,
+    description  TEXT        NOT NULL DEFAULT '',
+    -- a role inherits the permissions of its parent, and of the parent's parent
+    parent_id    BIGINT      REFERENCES roles (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id           BIGSERIAL PRIMARY KEY,
+    -- "resource:action", e.g. "users:delete"
+    name         VARCHAR(100) NOT NULL UNIQUE,
+    description  TEXT         NOT NULL DEFAULT ''
+    );
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id        BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id  BIGINT NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+    );
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id     BIGINT      NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+    );
+
+-- Listing the holders of a role
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
@@ -0,0 +1,4 @@
+DELETE FROM user_roles;
+DELETE FROM role_permissions;
+DELETE FROM permissions WHERE name IN ('users:read', 'users:delete', 'users:unlock', 'keys:rotate');
+DELETE FROM roles WHERE name IN ('admin', 'user');
@@ -0,0 +1,29 @@
+-- Today's roles: admins can do everything users can, and administer users
+INSERT INTO roles (name, description) VALUES
+    ('user', 'Every registered user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description, parent_id)
+SELECT 'admin', 'Administers users and signing keys', id FROM roles WHERE name = 'user'
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('users:read',   'View any user'),
+    ('users:delete', 'Delete any user'),
+    ('users:unlock', 'Lift login lockouts'),
+    ('keys:rotate',  'Rotate the token signing key')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+  FROM roles r, permissions p
+ WHERE r.name = 'admin'
+   AND p.name IN ('users:read', 'users:delete', 'users:unlock', 'keys:rotate')
+ON CONFLICT DO NOTHING;
+
+-- Every user keeps the role they have in users.role
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+  FROM users u
+  JOIN roles r ON r.name = u.role
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,11 @@
+INSERT INTO permissions (name, description) VALUES
+    ('roles:read',   'View roles and who holds them'),
+    ('roles:manage', 'Create roles, grant and revoke them')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+  FROM roles r, permissions p
+ WHERE r.name = 'admin'
+   AND p.name IN ('roles:read', 'roles:manage')
+ON CONFLICT DO NOTHING;
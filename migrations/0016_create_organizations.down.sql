@@ -0,0 +1,4 @@
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS org_id;
+DROP INDEX IF EXISTS idx_memberships_user_id;
+DROP TABLE IF EXISTS memberships;
+DROP TABLE IF EXISTS organizations;
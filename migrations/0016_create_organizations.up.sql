@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS organizations (
+    id          BIGSERIAL PRIMARY KEY,
+    -- unique, URL-friendly name, e.g. "acme"
+    slug        VARCHAR(100) NOT NULL UNIQUE,
+    name        VARCHAR(255) NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS memberships (
+    org_id      BIGINT      NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    -- the role the user holds within the organization only
+    role_id     BIGINT      NOT NULL REFERENCES roles (id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, user_id)
+    );
+
+-- Listing the organizations of a user
+CREATE INDEX IF NOT EXISTS idx_memberships_user_id ON memberships (user_id);
+
+-- Tokens refreshed within a family stay scoped to its organization
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS org_id BIGINT REFERENCES organizations (id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DELETE FROM memberships;
+DELETE FROM permissions WHERE name IN ('members:read', 'members:manage');
+DELETE FROM roles WHERE name IN ('org_admin', 'org_member');
@@ -0,0 +1,20 @@
+-- Roles held within an organization: admins manage its members and users
+INSERT INTO roles (name, description) VALUES
+    ('org_member', 'Member of an organization')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO roles (name, description, parent_id)
+SELECT 'org_admin', 'Administers the members of an organization', id FROM roles WHERE name = 'org_member'
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO permissions (name, description) VALUES
+    ('members:read',   'View the members of the organization'),
+    ('members:manage', 'Change the roles of members and remove them')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+  FROM roles r, permissions p
+ WHERE (r.name = 'org_member' AND p.name = 'members:read')
+    OR (r.name = 'org_admin' AND p.name IN ('members:manage', 'users:read', 'users:delete', 'users:unlock'))
+ON CONFLICT DO NOTHING;
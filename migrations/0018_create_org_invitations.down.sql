@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_org_invitations_org_id;
+DROP TABLE IF EXISTS org_invitations;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS org_invitations (
+    id           BIGSERIAL PRIMARY KEY,
+    org_id       BIGINT       NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+    email        VARCHAR(255) NOT NULL,
+    -- the role the invitee holds within the organization once they accept
+    role_id      BIGINT       NOT NULL REFERENCES roles (id),
+    token_hash   CHAR(64)     NOT NULL UNIQUE,
+    invited_by   BIGINT       REFERENCES users (id) ON DELETE SET NULL,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    accepted_at  TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+    );
+
+-- Listing the pending invitations of an organization
+CREATE INDEX IF NOT EXISTS idx_org_invitations_org_id ON org_invitations (org_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS group_roles;
+DROP INDEX IF EXISTS idx_group_subgroups_subgroup_id;
+DROP TABLE IF EXISTS group_subgroups;
+DROP INDEX IF EXISTS idx_group_members_user_id;
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
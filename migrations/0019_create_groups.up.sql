@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS groups (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+    );
+
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id    BIGINT      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id     BIGINT      NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+    );
+
+-- Resolving the groups of a user
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members (user_id);
+
+-- Nested groups: the members of a subgroup are members of its group too.
+-- Cycles are refused when a subgroup is added.
+CREATE TABLE IF NOT EXISTS group_subgroups (
+    group_id    BIGINT      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    subgroup_id BIGINT      NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, subgroup_id),
+    CHECK (group_id <> subgroup_id)
+    );
+
+CREATE INDEX IF NOT EXISTS idx_group_subgroups_subgroup_id ON group_subgroups (subgroup_id);
+
+-- Roles granted to every member of a group
+CREATE TABLE IF NOT EXISTS group_roles (
+    group_id    BIGINT NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    role_id     BIGINT NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, role_id)
+    );
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE name IN ('groups:read', 'groups:manage');
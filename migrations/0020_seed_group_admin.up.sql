@@ -0,0 +1,11 @@
+INSERT INTO permissions (name, description) VALUES
+    ('groups:read',   'View groups, their members and roles'),
+    ('groups:manage', 'Create, rename and delete groups, manage their members and roles')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+  FROM roles r, permissions p
+ WHERE r.name = 'admin'
+   AND p.name IN ('groups:read', 'groups:manage')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- Give organization admins back the grants of 0017_seed_org_roles
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+  FROM roles r, permissions p
+ WHERE r.name = 'org_admin'
+   AND p.name IN ('users:delete', 'users:unlock')
+ON CONFLICT DO NOTHING;
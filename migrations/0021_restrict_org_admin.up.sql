@@ -0,0 +1,5 @@
+-- Accounts are global: organization admins remove memberships, they do not
+-- delete or unlock the accounts of their members
+DELETE FROM role_permissions
+ WHERE role_id = (SELECT id FROM roles WHERE name = 'org_admin')
+   AND permission_id IN (SELECT id FROM permissions WHERE name IN ('users:delete', 'users:unlock'));